@@ -0,0 +1,615 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/model/external"
+)
+
+// 알림 평가 기본값
+const (
+	defaultSweepInterval = time.Minute
+	defaultQueueSize     = 10000
+	evaluateTimeout      = 30 * time.Second
+)
+
+var ErrInvalidEngineRequired = errors.New("missing required inputs to create alert Engine")
+
+type Config struct {
+	SweepInterval time.Duration // 오프라인 점검 및 규칙 재조회 주기 (기본값 1분)
+	QueueSize     int           // 평가 대기열에 쌓을 수 있는 보고 묶음 수, 가득 차면 평가하지 않고 버림 (기본값 10000)
+}
+
+// Stats는 알림 평가 현황입니다.
+type Stats struct {
+	Rules       int       `json:"rules"`                 // 평가 중인(활성) 규칙 수
+	Queued      int       `json:"queued"`                // 평가 대기 중인 보고 묶음 수
+	Capacity    int       `json:"capacity"`              // 대기열 크기
+	Evaluated   int64     `json:"evaluated"`             // 평가한 보고 수 (기동 이후)
+	Dropped     int64     `json:"dropped"`               // 대기열이 가득 차 평가하지 못한 보고 수 (기동 이후)
+	Fired       int64     `json:"fired"`                 // 발생시키거나 발생 횟수를 늘린 알림 수 (기동 이후)
+	Resolved    int64     `json:"resolved"`              // 조건이 해소되어 닫은 알림 수 (기동 이후)
+	Sweeps      int64     `json:"sweeps"`                // 오프라인 점검 횟수 (기동 이후)
+	LastSweepAt time.Time `json:"lastSweepAt,omitempty"` // 마지막 오프라인 점검 시간
+	LastError   string    `json:"lastError,omitempty"`   // 마지막 평가/점검 오류
+}
+
+// Engine은 저장된 보고와 주기 점검으로 알림 규칙을 평가합니다.
+// 보고로 판단하는 규칙은 보고가 커밋된 뒤 대기열을 거쳐 평가하므로 보고 저장을 지연시키지 않으며,
+// 오프라인 규칙은 SweepInterval마다 디바이스의 마지막 보고 시간으로 평가합니다.
+// 같은 규칙/디바이스의 열린 알림은 하나만 유지되고, 조건이 해소되면 resolved로 닫습니다.
+// 규칙은 메모리에 보관하며 규칙 변경 시와 점검 주기마다 다시 조회합니다. (다른 인스턴스의 변경 반영)
+// 새로 발생한 알림과 확인/해소된 알림은 알림 변경과 같은 트랜잭션으로 outbox에 이벤트로 기록합니다. (발생 횟수 증가는 제외)
+type Engine struct {
+	cfg    Config
+	txMgr  db.TxManager
+	repo   db.AlertsDataService
+	outbox db.OutboxDataService
+	logger *logger.AppLogger
+
+	queue chan []data.DeviceInfo
+
+	mu          sync.RWMutex
+	rules       []data.AlertRule // 활성 규칙 (교체만 하고 변경하지 않음)
+	lastSweepAt time.Time
+	lastError   string
+
+	evaluated atomic.Int64
+	dropped   atomic.Int64
+	fired     atomic.Int64
+	resolved  atomic.Int64
+	sweeps    atomic.Int64
+}
+
+// outbox는 알림 이벤트를 기록하지 않으면 nil입니다.
+func NewEngine(lgr *logger.AppLogger, txMgr db.TxManager, repo db.AlertsDataService, outbox db.OutboxDataService, cfg Config) (*Engine, error) {
+	if lgr == nil || txMgr == nil || repo == nil {
+		return nil, ErrInvalidEngineRequired
+	}
+
+	if cfg.SweepInterval <= 0 {
+		cfg.SweepInterval = defaultSweepInterval
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+
+	return &Engine{
+		cfg:    cfg,
+		txMgr:  txMgr,
+		repo:   repo,
+		outbox: outbox,
+		logger: lgr,
+		queue:  make(chan []data.DeviceInfo, cfg.QueueSize),
+	}, nil
+}
+
+// Observe - 커밋된 보고를 평가 대기열에 넣습니다. 대기열이 가득 차면 기다리지 않고 버립니다.
+func (e *Engine) Observe(reports []data.DeviceInfo) {
+	if len(reports) == 0 {
+		return
+	}
+
+	select {
+	case e.queue <- reports:
+	default:
+		e.dropped.Add(int64(len(reports)))
+	}
+}
+
+// Stats - 평가 현황을 반환합니다.
+func (e *Engine) Stats() Stats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return Stats{
+		Rules:       len(e.rules),
+		Queued:      len(e.queue),
+		Capacity:    cap(e.queue),
+		Evaluated:   e.evaluated.Load(),
+		Dropped:     e.dropped.Load(),
+		Fired:       e.fired.Load(),
+		Resolved:    e.resolved.Load(),
+		Sweeps:      e.sweeps.Load(),
+		LastSweepAt: e.lastSweepAt,
+		LastError:   e.lastError,
+	}
+}
+
+// Run - ctx가 종료될 때까지 대기열의 보고를 평가하고 주기적으로 오프라인 점검을 실행합니다.
+func (e *Engine) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.SweepInterval)
+	defer ticker.Stop()
+
+	e.Sweep(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case reports := <-e.queue:
+			e.Evaluate(ctx, reports)
+		case <-ticker.C:
+			e.Sweep(ctx, time.Now())
+		}
+	}
+}
+
+// Reload - 활성 규칙을 다시 조회합니다.
+func (e *Engine) Reload(ctx context.Context) error {
+	rules, err := e.repo.ListRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	enabled := make([]data.AlertRule, 0, len(rules))
+	for i := range rules {
+		if rules[i].Enabled {
+			enabled = append(enabled, rules[i])
+		}
+	}
+
+	e.mu.Lock()
+	e.rules = enabled
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *Engine) snapshot() []data.AlertRule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.rules
+}
+
+// CreateRule - 알림 규칙을 등록하고 평가 대상 규칙을 다시 조회합니다.
+func (e *Engine) CreateRule(ctx context.Context, rule *data.AlertRule) (*data.AlertRule, error) {
+	now := time.Now()
+	rule.CreatedAt, rule.UpdatedAt = now, now
+
+	ruleID, err := e.repo.CreateRule(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	rule.RuleID = ruleID
+
+	e.reloadAfterChange(ctx)
+	return rule, nil
+}
+
+// UpdateRule - 알림 규칙을 변경합니다.
+// 규칙을 비활성화하거나 조건(종류, 제품군, 기준값, 에러 코드, 횟수)을 바꾸면 기존 조건으로 열린 알림을 해소합니다.
+func (e *Engine) UpdateRule(ctx context.Context, rule *data.AlertRule) (*data.AlertRule, error) {
+	now := time.Now()
+
+	var updated *data.AlertRule
+	var resolved int64
+	err := e.txMgr.WithTx(ctx, func(tx db.DBTX) error {
+		repo := e.repo.WithTx(tx)
+
+		// 1. 기존 규칙 조회
+		current, err := repo.GetRule(ctx, rule.RuleID)
+		if err != nil {
+			return err
+		}
+
+		// 2. 규칙 변경 (생성 시간은 유지)
+		next := *rule
+		next.CreatedAt, next.UpdatedAt = current.CreatedAt, now
+		if err := repo.UpdateRule(ctx, &next); err != nil {
+			return err
+		}
+		updated = &next
+
+		// 3. 기존 조건으로 열린 알림 해소
+		resolved = 0
+		if !next.Enabled || conditionChanged(current, &next) {
+			resolved, err = e.resolveByRule(ctx, tx, repo, next.RuleID, now)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	e.resolved.Add(resolved)
+	e.reloadAfterChange(ctx)
+	return updated, nil
+}
+
+// DeleteRule - 알림 규칙을 삭제하고 규칙의 열린 알림을 해소합니다. (알림 이력은 유지)
+func (e *Engine) DeleteRule(ctx context.Context, ruleID int64) error {
+	var resolved int64
+	err := e.txMgr.WithTx(ctx, func(tx db.DBTX) error {
+		repo := e.repo.WithTx(tx)
+		if err := repo.DeleteRule(ctx, ruleID); err != nil {
+			return err
+		}
+
+		var err error
+		resolved, err = e.resolveByRule(ctx, tx, repo, ruleID, time.Now())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	e.resolved.Add(resolved)
+	e.reloadAfterChange(ctx)
+	return nil
+}
+
+// 규칙의 열린 알림을 해소하고, 이벤트를 기록하면 해소한 알림의 이벤트를 같은 트랜잭션으로 기록한다
+func (e *Engine) resolveByRule(ctx context.Context, tx db.DBTX, repo db.AlertsDataService, ruleID int64, now time.Time) (int64, error) {
+	var open []data.Alert
+	if e.outbox != nil {
+		var err error
+		if open, err = repo.ListOpenByRule(ctx, ruleID); err != nil {
+			return 0, err
+		}
+	}
+
+	resolved, err := repo.ResolveByRule(ctx, ruleID, now)
+	if err != nil {
+		return 0, err
+	}
+
+	events := make([]data.Event, 0, len(open))
+	for i := range open {
+		events = e.appendEvent(events, data.EventAlertResolved, resolvedAlert(open[i], now), now)
+	}
+	return resolved, e.record(ctx, tx, events)
+}
+
+// Acknowledge - firing 상태의 알림을 확인 처리합니다.
+func (e *Engine) Acknowledge(ctx context.Context, alertID int64, by string) (*data.Alert, error) {
+	now := time.Now()
+
+	var acked *data.Alert
+	err := e.txMgr.WithTx(ctx, func(tx db.DBTX) error {
+		var err error
+		if acked, err = e.repo.WithTx(tx).Acknowledge(ctx, alertID, by, now); err != nil {
+			return err
+		}
+		return e.record(ctx, tx, e.appendEvent(nil, data.EventAlertAcknowledged, *acked, now))
+	})
+	return acked, err
+}
+
+// Resolve - 열린 알림을 직접 해소합니다. 조건이 계속되면 다음 평가에서 새 알림이 발생합니다.
+func (e *Engine) Resolve(ctx context.Context, alertID int64) (*data.Alert, error) {
+	now := time.Now()
+
+	var resolved *data.Alert
+	err := e.txMgr.WithTx(ctx, func(tx db.DBTX) error {
+		var err error
+		if resolved, err = e.repo.WithTx(tx).ResolveByID(ctx, alertID, now); err != nil {
+			return err
+		}
+		return e.record(ctx, tx, e.appendEvent(nil, data.EventAlertResolved, *resolved, now))
+	})
+	if err != nil {
+		return resolved, err
+	}
+
+	e.resolved.Add(1)
+	return resolved, nil
+}
+
+// 규칙 변경은 이미 커밋되었으므로 재조회 실패는 다음 점검 주기에 다시 시도한다
+func (e *Engine) reloadAfterChange(ctx context.Context) {
+	if err := e.Reload(ctx); err != nil {
+		e.logger.Error().Err(err).Msg("failed to reload alert rules")
+	}
+}
+
+func conditionChanged(a, b *data.AlertRule) bool {
+	return a.Kind != b.Kind ||
+		a.ProductLine != b.ProductLine ||
+		!reflect.DeepEqual(a.MinValue, b.MinValue) ||
+		!reflect.DeepEqual(a.MaxValue, b.MaxValue) ||
+		!slices.Equal(a.ErrorCodes, b.ErrorCodes) ||
+		a.Count != b.Count
+}
+
+// Evaluate - 보고 묶음으로 규칙을 평가합니다.
+// 디바이스별로 보고 시간 순서대로 평가하여 조건에 해당한 보고마다 발생 횟수를 늘리고,
+// 마지막 보고가 조건에 해당하지 않으면 열린 알림을 해소합니다. (오프라인 알림은 보고가 오면 해소)
+func (e *Engine) Evaluate(ctx context.Context, reports []data.DeviceInfo) error {
+	ctx, cancel := context.WithTimeout(ctx, evaluateTimeout)
+	defer cancel()
+
+	e.evaluated.Add(int64(len(reports)))
+	rules := e.snapshot()
+	if len(rules) == 0 || len(reports) == 0 {
+		return nil
+	}
+
+	// 1. 디바이스별 보고 (보고 시간 순서)
+	byDevice := make(map[string][]data.DeviceInfo)
+	var productNumbers []string
+	for i := range reports {
+		pn := reports[i].ProductNumber
+		if _, ok := byDevice[pn]; !ok {
+			productNumbers = append(productNumbers, pn)
+		}
+		byDevice[pn] = append(byDevice[pn], reports[i])
+	}
+
+	// 2. 디바이스들의 열린 알림 조회
+	open, err := e.repo.ListOpen(ctx, productNumbers)
+	if err != nil {
+		return e.fail(err, "failed to evaluate alert rules")
+	}
+	openByKey := make(map[string]*data.Alert, len(open))
+	for i := range open {
+		openByKey[data.AlertOpenKey(open[i].RuleID, open[i].ProductNumber)] = &open[i]
+	}
+
+	// 3. 디바이스/규칙별 평가
+	var fires []data.Alert
+	var resolves []string
+	for _, pn := range productNumbers {
+		deviceReports := byDevice[pn]
+		slices.SortStableFunc(deviceReports, func(a, b data.DeviceInfo) int {
+			return a.ReportAt.Compare(b.ReportAt)
+		})
+		latest := &deviceReports[len(deviceReports)-1]
+
+		for i := range rules {
+			rule := &rules[i]
+			if !rule.Applies(pn) {
+				continue
+			}
+
+			fire, firing, err := e.evaluateRule(ctx, rule, deviceReports)
+			if err != nil {
+				return e.fail(err, "failed to evaluate alert rules")
+			}
+			if fire != nil {
+				fires = append(fires, *fire)
+			}
+			if firing {
+				continue
+			}
+
+			// 늦게 도착한(WAL 재전송 등) 이전 보고로 최근 발생한 알림을 해소하지 않는다
+			key := data.AlertOpenKey(rule.RuleID, pn)
+			if current, ok := openByKey[key]; fire != nil || (ok && !latest.ReportAt.Before(current.LastSeenAt)) {
+				resolves = append(resolves, key)
+			}
+		}
+	}
+
+	// 4. 발생 및 해소 반영
+	return e.apply(ctx, fires, resolves, time.Now())
+}
+
+// 규칙을 디바이스의 보고(시간 순서)로 평가하여 발생시킬 알림과 마지막 보고 기준 조건 해당 여부를 반환한다.
+func (e *Engine) evaluateRule(ctx context.Context, rule *data.AlertRule, reports []data.DeviceInfo) (*data.Alert, bool, error) {
+	latest := &reports[len(reports)-1]
+
+	switch rule.Kind {
+	case data.RuleOffline:
+		return nil, false, nil
+
+	case data.RuleErrorRepeated:
+		// 마지막 보고가 ERROR일 때만 최근 Count건의 보고 상태를 조회한다
+		if latest.ReportedStatus != data.ReportError {
+			return nil, false, nil
+		}
+		statuses, err := e.repo.RecentStatuses(ctx, latest.ProductNumber, rule.Count)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(statuses) < rule.Count || slices.ContainsFunc(statuses, func(s data.DeviceStatus) bool { return s != data.ReportError }) {
+			return nil, false, nil
+		}
+		return newAlert(rule, latest.ProductNumber, float64(rule.Count), latest.ReportAt), true, nil
+	}
+
+	var alert *data.Alert
+	matched := false
+	for i := range reports {
+		report := &reports[i]
+
+		var value float64
+		matched, value = rule.Evaluate(report)
+		if !matched {
+			continue
+		}
+
+		if alert == nil {
+			alert = newAlert(rule, report.ProductNumber, value, report.ReportAt)
+			continue
+		}
+		alert.Occurrences++
+		alert.ObservedValue = value
+		alert.Message = rule.Message(value)
+		alert.LastSeenAt = report.ReportAt
+	}
+
+	return alert, matched, nil
+}
+
+func newAlert(rule *data.AlertRule, productNumber string, value float64, at time.Time) *data.Alert {
+	return &data.Alert{
+		RuleID:        rule.RuleID,
+		ProductNumber: productNumber,
+		Severity:      rule.Severity,
+		State:         data.AlertFiring,
+		Message:       rule.Message(value),
+		ObservedValue: value,
+		Occurrences:   1,
+		FiredAt:       at,
+		LastSeenAt:    at,
+	}
+}
+
+// Sweep - 규칙을 다시 조회하고 오프라인 규칙을 평가합니다.
+// 마지막 보고 후 Count 보고 주기가 지난 디바이스는 알림을 발생시키고(점검마다 발생 횟수 증가),
+// 그 사이 보고한 디바이스의 열린 알림은 해소합니다.
+func (e *Engine) Sweep(ctx context.Context, now time.Time) error {
+	e.sweeps.Add(1)
+	e.mu.Lock()
+	e.lastSweepAt = now
+	e.mu.Unlock()
+
+	// 1. 규칙 재조회
+	if err := e.Reload(ctx); err != nil {
+		return e.fail(err, "failed to reload alert rules")
+	}
+
+	// 2. 오프라인 규칙 평가
+	for _, rule := range e.snapshot() {
+		if rule.Kind != data.RuleOffline {
+			continue
+		}
+
+		offline, err := e.repo.OfflineDevices(ctx, now.Add(-rule.OfflineAfter()), rule.ProductLine)
+		if err != nil {
+			return e.fail(err, "failed to sweep offline devices")
+		}
+		open, err := e.repo.ListOpenByRule(ctx, rule.RuleID)
+		if err != nil {
+			return e.fail(err, "failed to sweep offline devices")
+		}
+
+		fires := make([]data.Alert, 0, len(offline))
+		for pn, lastSeenAt := range offline {
+			fires = append(fires, *newAlert(&rule, pn, now.Sub(lastSeenAt).Truncate(time.Second).Seconds(), now))
+		}
+
+		var resolves []string
+		for i := range open {
+			if _, ok := offline[open[i].ProductNumber]; !ok {
+				resolves = append(resolves, data.AlertOpenKey(rule.RuleID, open[i].ProductNumber))
+			}
+		}
+
+		if err := e.apply(ctx, fires, resolves, now); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 발생과 해소를 하나의 트랜잭션으로 반영한다
+func (e *Engine) apply(ctx context.Context, fires []data.Alert, resolves []string, now time.Time) error {
+	if len(fires) == 0 && len(resolves) == 0 {
+		return nil
+	}
+
+	var resolved int64
+	err := e.txMgr.WithTx(ctx, func(tx db.DBTX) error {
+		repo := e.repo.WithTx(tx)
+		if err := repo.Fire(ctx, fires); err != nil {
+			return err
+		}
+
+		// 이벤트를 기록하면 해소 전에 열린 알림을 조회하여 새로 발생한 알림과 해소할 알림을 찾는다
+		var events []data.Event
+		var err error
+		if e.outbox != nil {
+			if events, err = e.applyEvents(ctx, repo, fires, resolves, now); err != nil {
+				return err
+			}
+		}
+
+		if resolved, err = repo.Resolve(ctx, resolves, now); err != nil {
+			return err
+		}
+		return e.record(ctx, tx, events)
+	})
+	if err != nil {
+		return e.fail(err, "failed to apply alerts")
+	}
+
+	e.fired.Add(int64(len(fires)))
+	e.resolved.Add(resolved)
+	return nil
+}
+
+// 발생 반영 후 열린 알림 중 이번에 새로 만들어진 알림(발생 횟수가 이번 발생 횟수와 같은 알림)과 해소할 알림의 이벤트를 만든다
+func (e *Engine) applyEvents(ctx context.Context, repo db.AlertsDataService, fires []data.Alert, resolves []string, now time.Time) ([]data.Event, error) {
+	occurrences := make(map[string]int64, len(fires))
+	var productNumbers []string
+	seen := make(map[string]bool)
+	for i := range fires {
+		occurrences[data.AlertOpenKey(fires[i].RuleID, fires[i].ProductNumber)] = fires[i].Occurrences
+		if !seen[fires[i].ProductNumber] {
+			seen[fires[i].ProductNumber] = true
+			productNumbers = append(productNumbers, fires[i].ProductNumber)
+		}
+	}
+	resolving := make(map[string]bool, len(resolves))
+	for _, key := range resolves {
+		resolving[key] = true
+		if pn := key[strings.IndexByte(key, ':')+1:]; !seen[pn] {
+			seen[pn] = true
+			productNumbers = append(productNumbers, pn)
+		}
+	}
+
+	open, err := repo.ListOpen(ctx, productNumbers)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []data.Event
+	for i := range open {
+		key := data.AlertOpenKey(open[i].RuleID, open[i].ProductNumber)
+		if n, ok := occurrences[key]; ok && open[i].Occurrences == n {
+			events = e.appendEvent(events, data.EventAlertFired, open[i], open[i].FiredAt)
+		}
+		if resolving[key] {
+			events = e.appendEvent(events, data.EventAlertResolved, resolvedAlert(open[i], now), now)
+		}
+	}
+	return events, nil
+}
+
+func resolvedAlert(a data.Alert, at time.Time) data.Alert {
+	a.State = data.AlertResolved
+	a.ResolvedAt = &at
+	return a
+}
+
+func (e *Engine) appendEvent(events []data.Event, eventType data.EventType, a data.Alert, at time.Time) []data.Event {
+	if e.outbox == nil {
+		return events
+	}
+
+	event, err := data.NewEvent(eventType, a.ProductNumber, at, external.NewAlertRes(&a))
+	if err != nil {
+		e.logger.Error().Err(err).Int64("alertID", a.AlertID).Msg("failed to create alert event")
+		return events
+	}
+	return append(events, event)
+}
+
+// 알림 변경과 같은 트랜잭션으로 이벤트를 outbox에 기록한다
+func (e *Engine) record(ctx context.Context, tx db.DBTX, events []data.Event) error {
+	if e.outbox == nil || len(events) == 0 {
+		return nil
+	}
+	return e.outbox.WithTx(tx).Add(ctx, events)
+}
+
+func (e *Engine) fail(err error, msg string) error {
+	e.logger.Error().Err(err).Msg(msg)
+
+	e.mu.Lock()
+	e.lastError = err.Error()
+	e.mu.Unlock()
+	return err
+}
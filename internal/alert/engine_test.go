@@ -0,0 +1,273 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/model/external"
+)
+
+type testEnv struct {
+	engine *Engine
+	repo   db.AlertsDataService
+	dsRepo db.DevicesDataService
+	rsRepo db.ReportsDataService
+	outbox db.OutboxDataService
+}
+
+// SQLite 데이터베이스로 엔진을 구성하고 디바이스를 등록한다
+func newTestEnv(t *testing.T, cfg Config, devices ...data.Device) *testEnv {
+	t.Helper()
+
+	ctx := context.Background()
+	lgr := logger.Setup("error", "test")
+	mgr, err := db.NewSQLiteManager(filepath.Join(t.TempDir(), "test.db"), lgr)
+	if err != nil {
+		t.Fatalf("NewSQLiteManager: %v", err)
+	}
+	t.Cleanup(func() { mgr.Disconnect() })
+	migrator, err := db.NewMigrator(lgr, mgr)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	env := &testEnv{}
+	env.repo, _ = db.NewAlertsRepo(lgr, mgr.DB(), mgr.Dialect())
+	env.dsRepo, _ = db.NewDevicesRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+	env.rsRepo, _ = db.NewReportsRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+	env.outbox, _ = db.NewOutboxRepo(lgr, mgr.DB(), mgr.Dialect())
+	for i := range devices {
+		if _, err := env.dsRepo.Create(ctx, &devices[i]); err != nil {
+			t.Fatalf("create device %s: %v", devices[i].ProductNumber, err)
+		}
+	}
+
+	env.engine, err = NewEngine(lgr, mgr, env.repo, env.outbox, cfg)
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+	return env
+}
+
+func newTestDevice(productNumber string, lastSeenAt time.Time) data.Device {
+	productLine, hardwareRevision := data.ParseProductNumber(productNumber)
+	return data.Device{
+		ProductNumber:    productNumber,
+		MacAddress:       fmt.Sprintf("00:11:22:%02X:%s:%s", productNumber[0], productNumber[5:7], productNumber[7:9]),
+		ProductLine:      productLine,
+		HardwareRevision: hardwareRevision,
+		FirmwareVersion:  "1.0.0",
+		LastSeenAt:       lastSeenAt,
+		CreatedAt:        lastSeenAt,
+		Status:           data.StatusReady,
+	}
+}
+
+func newTestReport(productNumber string, battery int, status data.DeviceStatus, reportAt time.Time) data.DeviceInfo {
+	return data.DeviceInfo{
+		ProductNumber:      productNumber,
+		BatteryPercent:     battery,
+		Lat:                33.5,
+		Lon:                127.0,
+		TemperatureCelsius: 21.5,
+		IP:                 "10.0.0.1",
+		ReportAt:           reportAt,
+		ReportedStatus:     status,
+	}
+}
+
+func (env *testEnv) createRule(t *testing.T, rule data.AlertRule) *data.AlertRule {
+	t.Helper()
+	rule.Enabled = true
+	rule.Severity = data.SeverityWarning
+	created, err := env.engine.CreateRule(context.Background(), &rule)
+	if err != nil {
+		t.Fatalf("CreateRule %s: %v", rule.Name, err)
+	}
+	return created
+}
+
+// 규칙/디바이스의 알림 목록 (최신 순)
+func (env *testEnv) alerts(t *testing.T, ruleID int64, productNumber string) []data.Alert {
+	t.Helper()
+	alerts, err := env.repo.ListAlerts(context.Background(), data.AlertListQuery{RuleID: ruleID, ProductNumber: productNumber})
+	if err != nil {
+		t.Fatalf("ListAlerts: %v", err)
+	}
+	return alerts
+}
+
+// outbox에 기록된 알림 이벤트 종류별 수
+func (env *testEnv) alertEvents(t *testing.T) map[data.EventType]int {
+	t.Helper()
+	pending, err := env.outbox.ListPending(context.Background(), 1000)
+	if err != nil {
+		t.Fatalf("ListPending: %v", err)
+	}
+	counts := make(map[data.EventType]int)
+	for _, p := range pending {
+		counts[p.Event.Type]++
+	}
+	return counts
+}
+
+func (env *testEnv) evaluate(t *testing.T, reports ...data.DeviceInfo) {
+	t.Helper()
+	if err := env.engine.Evaluate(context.Background(), reports); err != nil {
+		t.Fatalf("Evaluate: %v", err)
+	}
+}
+
+func TestEngineReportRules(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	env := newTestEnv(t, Config{}, newTestDevice("ABC010001", now), newTestDevice("XYZ010001", now))
+	minBattery := 20.0
+	battery := env.createRule(t, data.AlertRule{Name: "low battery", Kind: data.RuleBatteryBelow, ProductLine: "ABC", MinValue: &minBattery})
+
+	// 1. 조건에 해당한 보고마다 같은 알림의 발생 횟수를 늘린다 (다른 제품군은 평가하지 않음)
+	env.evaluate(t,
+		newTestReport("ABC010001", 15, data.ReportPowerOn, now.Add(time.Minute)),
+		newTestReport("ABC010001", 10, data.ReportPowerOn, now.Add(2*time.Minute)),
+		newTestReport("XYZ010001", 5, data.ReportPowerOn, now.Add(time.Minute)),
+	)
+	env.evaluate(t, newTestReport("ABC010001", 12, data.ReportPowerOn, now.Add(3*time.Minute)))
+
+	alerts := env.alerts(t, battery.RuleID, "ABC010001")
+	if len(alerts) != 1 || alerts[0].State != data.AlertFiring || alerts[0].Occurrences != 3 || alerts[0].ObservedValue != 12 {
+		t.Fatalf("battery alerts = %+v, want one firing alert with 3 occurrences", alerts)
+	}
+	if got := env.alerts(t, battery.RuleID, "XYZ010001"); len(got) != 0 {
+		t.Errorf("alerts for another product line = %+v", got)
+	}
+
+	// 2. 늦게 도착한 이전 보고는 열린 알림을 해소하지 않는다
+	env.evaluate(t, newTestReport("ABC010001", 90, data.ReportPowerOn, now.Add(time.Minute+30*time.Second)))
+	if alerts := env.alerts(t, battery.RuleID, "ABC010001"); alerts[0].State != data.AlertFiring {
+		t.Errorf("stale report resolved the alert: %+v", alerts[0])
+	}
+
+	// 3. 확인 후 조건이 해소되면 resolved로 닫고, 다시 조건에 해당하면 새 알림이 발생한다
+	if acked, err := env.engine.Acknowledge(ctx, alerts[0].AlertID, "operator"); err != nil || acked.State != data.AlertAcknowledged || acked.AcknowledgedBy != "operator" {
+		t.Fatalf("Acknowledge = %+v, %v", acked, err)
+	}
+	env.evaluate(t, newTestReport("ABC010001", 90, data.ReportPowerOn, now.Add(4*time.Minute)))
+	env.evaluate(t, newTestReport("ABC010001", 5, data.ReportPowerOn, now.Add(5*time.Minute)))
+
+	alerts = env.alerts(t, battery.RuleID, "ABC010001")
+	if len(alerts) != 2 || alerts[0].State != data.AlertFiring || alerts[1].State != data.AlertResolved || alerts[1].ResolvedAt == nil {
+		t.Fatalf("alerts after resolve and refire = %+v", alerts)
+	}
+
+	// 4. 조건을 바꾸면 기존 조건으로 열린 알림을 해소한다
+	lower := 3.0
+	changed := *battery
+	changed.MinValue = &lower
+	if _, err := env.engine.UpdateRule(ctx, &changed); err != nil {
+		t.Fatalf("UpdateRule: %v", err)
+	}
+	if alerts := env.alerts(t, battery.RuleID, "ABC010001"); alerts[0].State != data.AlertResolved {
+		t.Errorf("alert after condition change = %+v, want resolved", alerts[0])
+	}
+
+	// 새 알림, 확인, 해소(조건 해소, 조건 변경)는 outbox에 이벤트로 기록한다 (발생 횟수 증가는 제외)
+	events := env.alertEvents(t)
+	if events[data.EventAlertFired] != 2 || events[data.EventAlertAcknowledged] != 1 || events[data.EventAlertResolved] != 2 {
+		t.Errorf("alert events = %v, want 2 fired, 1 acknowledged, 2 resolved", events)
+	}
+	if stats := env.engine.Stats(); stats.Rules != 1 || stats.Resolved != 2 || stats.LastError != "" {
+		t.Errorf("Stats = %+v", stats)
+	}
+}
+
+func TestEngineErrorRepeated(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	env := newTestEnv(t, Config{}, newTestDevice("ABC010001", now))
+	rule := env.createRule(t, data.AlertRule{Name: "error loop", Kind: data.RuleErrorRepeated, Count: 3})
+
+	// 저장된 최근 Count건의 보고 상태가 모두 ERROR일 때만 발생한다
+	reports := []data.DeviceInfo{
+		newTestReport("ABC010001", 80, data.ReportPowerOn, now.Add(time.Minute)),
+		newTestReport("ABC010001", 80, data.ReportError, now.Add(2*time.Minute)),
+		newTestReport("ABC010001", 80, data.ReportError, now.Add(3*time.Minute)),
+		newTestReport("ABC010001", 80, data.ReportError, now.Add(4*time.Minute)),
+	}
+	for i := range reports {
+		if err := env.rsRepo.CreateBatch(ctx, reports[i:i+1]); err != nil {
+			t.Fatalf("CreateBatch: %v", err)
+		}
+		env.evaluate(t, reports[i])
+
+		alerts := env.alerts(t, rule.RuleID, "ABC010001")
+		if want := i == len(reports)-1; (len(alerts) == 1) != want {
+			t.Fatalf("after report %d: alerts = %+v, want firing %v", i, alerts, want)
+		}
+	}
+
+	// 규칙을 삭제하면 열린 알림을 해소한다
+	if err := env.engine.DeleteRule(ctx, rule.RuleID); err != nil {
+		t.Fatalf("DeleteRule: %v", err)
+	}
+	if alerts := env.alerts(t, rule.RuleID, "ABC010001"); len(alerts) != 1 || alerts[0].State != data.AlertResolved {
+		t.Errorf("alerts after DeleteRule = %+v", alerts)
+	}
+	if stats := env.engine.Stats(); stats.Rules != 0 {
+		t.Errorf("Stats.Rules after DeleteRule = %d, want 0", stats.Rules)
+	}
+}
+
+func TestEngineSweepOffline(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	env := newTestEnv(t, Config{}, newTestDevice("XYZ010001", now.Add(-time.Hour)), newTestDevice("XYZ010002", now), newTestDevice("ABC010001", now.Add(-time.Hour)))
+	rule := env.createRule(t, data.AlertRule{Name: "offline", Kind: data.RuleOffline, ProductLine: "XYZ", Count: 3})
+
+	// 보고 주기 Count번 동안 보고가 없는 디바이스는 점검마다 발생 횟수를 늘린다
+	for i := 0; i < 2; i++ {
+		if err := env.engine.Sweep(ctx, now.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("Sweep: %v", err)
+		}
+	}
+	alerts := env.alerts(t, rule.RuleID, "")
+	if len(alerts) != 1 || alerts[0].ProductNumber != "XYZ010001" || alerts[0].Occurrences != 2 || alerts[0].ObservedValue != 3660 {
+		t.Fatalf("offline alerts = %+v, want one alert for XYZ010001 with 2 occurrences", alerts)
+	}
+
+	// 그 사이 보고한 디바이스의 알림은 해소한다
+	lastSeenAt := now.Add(time.Minute)
+	if err := env.dsRepo.Update(ctx, "XYZ010001", &external.UpdateDeviceParams{LastSeenAt: &lastSeenAt}); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if err := env.engine.Sweep(ctx, now.Add(2*time.Minute)); err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if alerts := env.alerts(t, rule.RuleID, "XYZ010001"); alerts[0].State != data.AlertResolved {
+		t.Errorf("offline alert after report = %+v, want resolved", alerts[0])
+	}
+	if stats := env.engine.Stats(); stats.Sweeps != 3 || !stats.LastSweepAt.Equal(now.Add(2*time.Minute)) {
+		t.Errorf("Stats = %+v", stats)
+	}
+}
+
+func TestEngineObserveQueueFull(t *testing.T) {
+	env := newTestEnv(t, Config{QueueSize: 1})
+	report := newTestReport("ABC010001", 80, data.ReportPowerOn, time.Now())
+
+	// 대기열이 가득 차면 기다리지 않고 버린다
+	env.engine.Observe([]data.DeviceInfo{report})
+	env.engine.Observe([]data.DeviceInfo{report, report})
+	env.engine.Observe(nil)
+	if stats := env.engine.Stats(); stats.Queued != 1 || stats.Dropped != 2 {
+		t.Errorf("Stats = %+v, want 1 queued and 2 dropped", stats)
+	}
+}
@@ -0,0 +1,173 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/retention"
+)
+
+func newTestReport(productNumber string, battery int, reportAt time.Time) data.DeviceInfo {
+	return data.DeviceInfo{
+		ProductNumber:      productNumber,
+		BatteryPercent:     battery,
+		Lat:                33.5,
+		Lon:                127.0,
+		TemperatureCelsius: 21.5,
+		IP:                 "10.0.0.1",
+		ReportAt:           reportAt,
+		ReportedStatus:     data.ReportPowerOn,
+	}
+}
+
+// 보존 작업이 보관 파일로 저장한 보고를 다시 복원한다
+func TestArchiveRestoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	lgr := logger.Setup("error", "test")
+	mgr, err := db.NewSQLiteManager(filepath.Join(t.TempDir(), "test.db"), lgr)
+	if err != nil {
+		t.Fatalf("NewSQLiteManager: %v", err)
+	}
+	t.Cleanup(func() { mgr.Disconnect() })
+	migrator, err := db.NewMigrator(lgr, mgr)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	dsRepo, _ := db.NewDevicesRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+	rsRepo, _ := db.NewReportsRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+	rlRepo, _ := db.NewRollupsRepo(lgr, mgr.DB(), mgr.Dialect())
+	arRepo, _ := db.NewArchivesRepo(lgr, mgr.DB(), mgr.Dialect())
+	for _, pn := range []string{"ABC010001", "ABC010002"} {
+		device := data.Device{ProductNumber: pn, MacAddress: "00:11:22:33:01:" + pn[7:9], ProductLine: "ABC", HardwareRevision: "01", FirmwareVersion: "1.0.0", Status: data.StatusReady}
+		if _, err := dsRepo.Create(ctx, &device); err != nil {
+			t.Fatalf("create device: %v", err)
+		}
+	}
+
+	// 이틀에 걸친 두 디바이스의 지난 보고
+	day := data.RollupDaily.BucketStart(time.Now().Add(-5 * 24 * time.Hour))
+	expired := []data.DeviceInfo{
+		newTestReport("ABC010001", 80, day.Add(time.Hour)),
+		newTestReport("ABC010002", 75, day.Add(2*time.Hour)),
+		newTestReport("ABC010001", 70, day.Add(3*time.Hour)),
+		newTestReport("ABC010001", 60, day.Add(25*time.Hour)),
+	}
+	if err := rsRepo.CreateBatch(ctx, expired); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	store, err := NewDirStore(filepath.Join(t.TempDir(), "archive"))
+	if err != nil {
+		t.Fatalf("NewDirStore: %v", err)
+	}
+	archiver, err := NewArchiver(lgr, store, arRepo)
+	if err != nil {
+		t.Fatalf("NewArchiver: %v", err)
+	}
+	restorer, err := NewRestorer(lgr, store, mgr, arRepo, rsRepo)
+	if err != nil {
+		t.Fatalf("NewRestorer: %v", err)
+	}
+
+	// 1. 보존 작업이 디바이스/일 단위로 보관한 뒤 삭제
+	job, err := retention.NewJob(lgr, mgr, rsRepo, rlRepo, archiver, nil, retention.Config{Retention: 48 * time.Hour})
+	if err != nil {
+		t.Fatalf("NewJob: %v", err)
+	}
+	if err := job.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+	if stats := job.Stats(); stats.PurgedReports != 4 || stats.ArchivedFiles != 3 {
+		t.Fatalf("retention stats = %+v, want 4 reports in 3 files", stats)
+	}
+
+	archives, err := restorer.List(ctx, day, day.Add(48*time.Hour), "ABC010001")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(archives) != 2 || archives[0].Records != 2 || archives[1].Records != 1 {
+		t.Fatalf("List = %+v, want 2 files with 2 and 1 reports", archives)
+	}
+
+	// 2. 범위에 해당하는 보고만 복원하고, 이미 복원한 보고는 다시 저장하지 않는다
+	from, to := day.Add(2*time.Hour), day.Add(26*time.Hour)
+	result, err := restorer.Restore(ctx, from, to, "ABC010001")
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if result.Archives != 2 || result.Records != 2 || result.Restored != 2 {
+		t.Errorf("Restore = %+v, want 2 archives, 2 records, 2 restored", result)
+	}
+	restored, err := rsRepo.GetByID(ctx, "ABC010001")
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	batteries := make(map[int]bool)
+	for _, report := range *restored {
+		batteries[report.BatteryPercent] = true
+	}
+	if len(*restored) != 2 || !batteries[70] || !batteries[60] {
+		t.Errorf("restored reports = %+v, want batteries 70 and 60", *restored)
+	}
+	if again, err := restorer.Restore(ctx, from, to, "ABC010001"); err != nil || again.Restored != 0 {
+		t.Errorf("second Restore = %+v, %v, want nothing restored", again, err)
+	}
+
+	// 3. 복원한 보고는 다시 요약/보관하지 않고 보관 시간이 지나면 삭제한다
+	job, _ = retention.NewJob(lgr, mgr, rsRepo, rlRepo, archiver, nil, retention.Config{Retention: 48 * time.Hour, RestoreTTL: time.Nanosecond})
+	if err := job.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce with restored reports: %v", err)
+	}
+	if stats := job.Stats(); stats.PurgedReports != 0 || stats.ArchivedFiles != 0 || stats.PurgedRestored != 2 {
+		t.Errorf("retention stats with restored reports = %+v", stats)
+	}
+
+	// 4. 변경된 보관 파일은 복원하지 않는다
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Close()
+	if err := store.Put(ctx, archives[0].ObjectKey, &buf); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := restorer.Restore(ctx, from, to, "ABC010001"); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Restore modified archive: err = %v, want ErrChecksumMismatch", err)
+	}
+	if err := store.Put(ctx, archives[0].ObjectKey, strings.NewReader("not gzip")); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := restorer.Restore(ctx, from, to, "ABC010001"); !errors.Is(err, ErrCorruptArchive) {
+		t.Errorf("Restore corrupt archive: err = %v, want ErrCorruptArchive", err)
+	}
+}
+
+func TestDirStoreKeys(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewDirStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get(ctx, "reports/missing.ndjson.gz"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Get missing key: err = %v, want ErrObjectNotFound", err)
+	}
+
+	// 저장소 디렉터리를 벗어나는 키는 사용할 수 없다
+	for _, key := range []string{"", "/etc/passwd", "../outside", "reports/../../outside", "reports//a"} {
+		if err := store.Put(ctx, key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q): err = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
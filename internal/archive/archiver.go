@@ -0,0 +1,155 @@
+package archive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+var ErrInvalidArchiverRequired = errors.New("missing required inputs to create report Archiver")
+
+// Archiver는 보존 작업이 삭제하기 전의 보고를 디바이스/일 단위 gzip NDJSON 파일로 저장하고 목록(manifest)에 등록합니다.
+// 파일 저장은 보존 작업의 트랜잭션에 포함되지 않으므로 트랜잭션이 롤백되면 목록에 없는 파일이 남을 수 있으나,
+// 복원은 목록만 사용하므로 중복 복원되지 않고, 다시 실행 시 같은 키로 덮어씁니다.
+type Archiver struct {
+	store  Store
+	arRepo db.ArchivesDataService
+	logger *logger.AppLogger
+}
+
+func NewArchiver(lgr *logger.AppLogger, store Store, arRepo db.ArchivesDataService) (*Archiver, error) {
+	if lgr == nil || store == nil || arRepo == nil {
+		return nil, ErrInvalidArchiverRequired
+	}
+
+	return &Archiver{
+		store:  store,
+		arRepo: arRepo,
+		logger: lgr,
+	}, nil
+}
+
+// Archive - 보고를 디바이스/일(UTC)별 파일로 저장하고 tx로 목록을 등록한 뒤 등록한 목록을 반환합니다.
+// reports는 보고 시간 순서로 정렬되어 있어야 합니다.
+func (a *Archiver) Archive(ctx context.Context, tx db.DBTX, reports []data.DeviceInfo) ([]data.ReportArchive, error) {
+	// 1. 디바이스/일 단위로 묶음 (순서 유지)
+	type key struct {
+		productNumber string
+		dayStart      time.Time
+	}
+
+	var keys []key
+	groups := make(map[key][]data.DeviceInfo)
+	for i := range reports {
+		k := key{reports[i].ProductNumber, data.RollupDaily.BucketStart(reports[i].ReportAt)}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], reports[i])
+	}
+
+	// 2. 묶음별로 파일 저장
+	archives := make([]data.ReportArchive, 0, len(keys))
+	now := time.Now()
+	for _, k := range keys {
+		group := groups[k]
+
+		archive, err := a.put(ctx, k.productNumber, k.dayStart, group)
+		if err != nil {
+			a.logger.Error().Err(err).Str("productNumber", k.productNumber).Time("day", k.dayStart).Msg("failed to archive reports")
+			return nil, err
+		}
+		archive.CreatedAt = now
+		archives = append(archives, archive)
+	}
+
+	// 3. 목록 등록 : 보고 삭제와 같은 트랜잭션
+	if err := a.arRepo.WithTx(tx).Create(ctx, archives); err != nil {
+		return nil, err
+	}
+
+	return archives, nil
+}
+
+func (a *Archiver) put(ctx context.Context, productNumber string, dayStart time.Time, reports []data.DeviceInfo) (data.ReportArchive, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(zw)
+	for i := range reports {
+		if err := enc.Encode(&reports[i]); err != nil {
+			return data.ReportArchive{}, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return data.ReportArchive{}, err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	archive := data.ReportArchive{
+		ProductNumber: productNumber,
+		DayStart:      dayStart,
+		ObjectKey:     objectKey(productNumber, dayStart, reports[0].ReportID),
+		Format:        data.ArchiveFormatNDJSONGzip,
+		Records:       int64(len(reports)),
+		Bytes:         int64(buf.Len()),
+		Checksum:      hex.EncodeToString(sum[:]),
+		FirstReportAt: reports[0].ReportAt,
+		LastReportAt:  reports[len(reports)-1].ReportAt,
+	}
+
+	if err := a.store.Put(ctx, archive.ObjectKey, &buf); err != nil {
+		return data.ReportArchive{}, err
+	}
+	return archive, nil
+}
+
+// 보관 파일 키 : reports/YYYY/MM/DD/<ProductNumber>-<첫 ReportID>.ndjson.gz
+// 같은 디바이스/일의 보고가 여러 번 나누어 보관되므로 첫 ReportID로 구분한다
+func objectKey(productNumber string, dayStart time.Time, firstReportID int64) string {
+	return fmt.Sprintf("reports/%s/%s-%d.%s", dayStart.Format("2006/01/02"), productNumber, firstReportID, data.ArchiveFormatNDJSONGzip)
+}
+
+// 보관 파일을 읽어 체크섬을 확인하고 보고 목록으로 반환한다
+func readArchive(r io.Reader, checksum string) ([]data.DeviceInfo, error) {
+	h := sha256.New()
+	tee := io.TeeReader(r, h)
+	zr, err := gzip.NewReader(tee)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorruptArchive, err)
+	}
+
+	var reports []data.DeviceInfo
+	dec := json.NewDecoder(zr)
+	for {
+		var report data.DeviceInfo
+		err := dec.Decode(&report)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCorruptArchive, err)
+		}
+		reports = append(reports, report)
+	}
+
+	// gzip 뒤에 남은 바이트까지 읽어 파일 전체의 체크섬을 계산한다
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		return nil, err
+	}
+	if hex.EncodeToString(h.Sum(nil)) != checksum {
+		return nil, ErrChecksumMismatch
+	}
+
+	return reports, nil
+}
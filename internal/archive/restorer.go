@@ -0,0 +1,123 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 다중 row INSERT 한 번에 복원할 보고 수 (행당 11개 placeholder)
+const restoreBatch = 1000
+
+var (
+	ErrInvalidRestorerRequired = errors.New("missing required inputs to create report Restorer")
+	ErrCorruptArchive          = errors.New("archive file is corrupt")
+	ErrChecksumMismatch        = errors.New("archive file checksum mismatch")
+)
+
+// RestoreResult는 복원 결과입니다.
+type RestoreResult struct {
+	Archives int   `json:"archives"` // 읽은 보관 파일 수
+	Records  int64 `json:"records"`  // 범위에 해당하는 보고 수
+	Restored int64 `json:"restored"` // reports 테이블에 저장한 보고 수 (이미 있는 보고 제외)
+}
+
+// Restorer는 조사를 위해 보관 파일의 보고를 reports 테이블로 복원합니다.
+// 복원한 보고는 RestoredAt이 표시되어 보존 작업이 다시 요약/보관하지 않으며, 보존 작업이 일정 시간 후 삭제합니다.
+type Restorer struct {
+	store  Store
+	txMgr  db.TxManager
+	arRepo db.ArchivesDataService
+	rsRepo db.ReportsDataService
+	logger *logger.AppLogger
+}
+
+func NewRestorer(lgr *logger.AppLogger, store Store, txMgr db.TxManager, arRepo db.ArchivesDataService, rsRepo db.ReportsDataService) (*Restorer, error) {
+	if lgr == nil || store == nil || txMgr == nil || arRepo == nil || rsRepo == nil {
+		return nil, ErrInvalidRestorerRequired
+	}
+
+	return &Restorer{
+		store:  store,
+		txMgr:  txMgr,
+		arRepo: arRepo,
+		rsRepo: rsRepo,
+		logger: lgr,
+	}, nil
+}
+
+// List - [from, to) 범위의 보고를 포함하는 보관 파일 목록을 반환합니다.
+func (r *Restorer) List(ctx context.Context, from, to time.Time, productNumber string) ([]data.ReportArchive, error) {
+	return r.arRepo.GetRange(ctx, from, to, productNumber)
+}
+
+// Restore - 보고 시간이 [from, to) 범위인 보관된 보고를 복원합니다. productNumber가 비어 있으면 모든 디바이스를 복원합니다.
+// 보관 파일 단위로 트랜잭션을 나누므로 중간에 실패하면 그 전까지 복원한 결과를 함께 반환합니다.
+func (r *Restorer) Restore(ctx context.Context, from, to time.Time, productNumber string) (RestoreResult, error) {
+	var result RestoreResult
+
+	archives, err := r.arRepo.GetRange(ctx, from, to, productNumber)
+	if err != nil {
+		return result, err
+	}
+
+	restoredAt := time.Now()
+	for i := range archives {
+		archive := &archives[i]
+
+		// 1. 보관 파일 읽기 및 체크섬 확인
+		reports, err := r.read(ctx, archive)
+		if err != nil {
+			r.logger.Error().Err(err).Str("objectKey", archive.ObjectKey).Msg("failed to read report archive")
+			return result, fmt.Errorf("%s: %w", archive.ObjectKey, err)
+		}
+		result.Archives++
+
+		// 2. 범위에 해당하는 보고만 복원
+		inRange := reports[:0]
+		for _, report := range reports {
+			if !report.ReportAt.Before(from) && report.ReportAt.Before(to) {
+				inRange = append(inRange, report)
+			}
+		}
+		result.Records += int64(len(inRange))
+
+		err = r.txMgr.WithTx(ctx, func(tx db.DBTX) error {
+			rsRepo := r.rsRepo.WithTx(tx)
+			for start := 0; start < len(inRange); start += restoreBatch {
+				restored, err := rsRepo.Restore(ctx, inRange[start:min(start+restoreBatch, len(inRange))], restoredAt)
+				if err != nil {
+					return err
+				}
+				result.Restored += restored
+			}
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+
+	r.logger.Info().Time("from", from).Time("to", to).Str("productNumber", productNumber).
+		Int("archives", result.Archives).Int64("restored", result.Restored).Msg("restored archived reports")
+	return result, nil
+}
+
+func (r *Restorer) read(ctx context.Context, archive *data.ReportArchive) ([]data.DeviceInfo, error) {
+	if archive.Format != data.ArchiveFormatNDJSONGzip {
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrCorruptArchive, archive.Format)
+	}
+
+	rc, err := r.store.Get(ctx, archive.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return readArchive(rc, archive.Checksum)
+}
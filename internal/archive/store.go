@@ -0,0 +1,99 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrInvalidStoreDir = errors.New("archive store directory is required")
+	ErrInvalidKey      = errors.New("invalid archive object key")
+	ErrObjectNotFound  = errors.New("archive object not found")
+)
+
+// Store는 보관 파일을 저장하는 객체 저장소 인터페이스입니다.
+// 키는 "/"로 구분된 상대 경로이며, 같은 키로 다시 저장하면 덮어씁니다.
+// 로컬 디렉터리 외의 저장소(S3 등)는 이 인터페이스를 구현하여 교체합니다.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// DirStore는 로컬 디렉터리를 객체 저장소로 사용합니다.
+type DirStore struct {
+	root string
+}
+
+// 컴파일 타임에 DirStore가 Store 인터페이스를 구현하는지 확인합니다.
+var _ Store = (*DirStore)(nil)
+
+func NewDirStore(root string) (*DirStore, error) {
+	if root == "" {
+		return nil, ErrInvalidStoreDir
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return nil, err
+	}
+	return &DirStore{root: root}, nil
+}
+
+// Put - 임시 파일에 기록하고 fsync 후 이름을 바꾸므로, 중간에 실패해도 불완전한 파일이 키로 보이지 않습니다.
+func (s *DirStore) Put(ctx context.Context, key string, r io.Reader) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *DirStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	return f, err
+}
+
+// 키가 저장소 디렉터리를 벗어나지 않는지 확인하고 파일 경로로 바꾼다
+func (s *DirStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || strings.HasPrefix(key, "..") {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
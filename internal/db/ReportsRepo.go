@@ -3,7 +3,9 @@ package db
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"go-rest-example/internal/logger"
@@ -29,35 +31,59 @@ var (
 // ReportsRepo를 통해 사용할 메서드를 제약하고 규정하기 위한 인터페이스 
 type ReportsDataService interface {
 	Create(ctx context.Context, di *data.DeviceInfo) (string, error)
+	CreateBatch(ctx context.Context, dis []data.DeviceInfo) error
 	GetAll(ctx context.Context) (*[]data.DeviceInfo, error)
 	GetByID(ctx context.Context, ID string) (*[]data.DeviceInfo, error)
 	Delete(ctx context.Context, ID string)  error
+	GetBefore(ctx context.Context, before time.Time, limit int) ([]data.DeviceInfo, error)
+	DeleteByIDs(ctx context.Context, IDs []int64, from, to time.Time) (int64, error)
+	Restore(ctx context.Context, reports []data.DeviceInfo, restoredAt time.Time) (int64, error)
+	DeleteRestored(ctx context.Context, before time.Time, limit int) (int64, error)
+	WithTx(tx DBTX) ReportsDataService
 }
 
 // reports 테이블을 접근하기 위한 커넥션 관리
 type ReportsRepo struct {
 	connection DBTX
+	reader     DBTX // 목록/이력 조회용 (replica 우선)
+	dialect    Dialect
 	logger     *logger.AppLogger
 }
 
-func NewReportsRepo(lgr *logger.AppLogger, db DBTX) (*ReportsRepo, error) {
-	if lgr == nil || db == nil {
+func NewReportsRepo(lgr *logger.AppLogger, db DBTX, reader DBTX, dialect Dialect) (*ReportsRepo, error) {
+	if lgr == nil || db == nil || reader == nil || dialect == nil {
 		return nil, ErrInvalidReportRequired
 	}
 	return &ReportsRepo{
 		connection: db,
+		reader:     reader,
+		dialect:    dialect,
 		logger:     lgr,
 	}, nil
 }
 
+// 트랜잭션에 바인딩된 ReportsRepo 반환
+// 트랜잭션 안의 조회는 방금 쓴 값을 읽어야 하므로 reader도 tx를 사용한다.
+func (d *ReportsRepo) WithTx(tx DBTX) ReportsDataService {
+	return &ReportsRepo{
+		connection: tx,
+		reader:     tx,
+		dialect:    d.dialect,
+		logger:     d.logger,
+	}
+}
+
 // 주기보고 정보 row 생성
 func (d *ReportsRepo) Create(ctx context.Context, di *data.DeviceInfo) (string, error) {
 	// ReportAt 설정 (테이블에 DEFAULT가 없으면)
-	di.ReportAt = time.Now()
+	// 장애 중 버퍼링된 보고를 재전송하는 경우 원래 보고 시간을 유지한다
+	if di.ReportAt.IsZero() {
+		di.ReportAt = time.Now()
+	}
 
 	query := "INSERT INTO reports (ProductNumber, BatteryPercent, Lat, Lon, TemperatureCelsius, IP, ErrorCode, ReportAt, ReportedStatus) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)"
 
-	result, err := d.connection.ExecContext(ctx, query,
+	lastID, err := d.dialect.InsertID(ctx, d.connection, query, "ReportID",
 		di.ProductNumber,
 		di.BatteryPercent, 
 		di.Lat, 
@@ -70,22 +96,66 @@ func (d *ReportsRepo) Create(ctx context.Context, di *data.DeviceInfo) (string,
 
 	if err != nil {
 		d.logger.Error().Err(err).Msg("failed to create device_info")
+		if IsUnavailable(err) {
+			return "", fmt.Errorf("%w: %w", ErrFailedToCreateReportInfo, ErrUnavailable)
+		}
 		return "", ErrFailedToCreateReportInfo
 	}
 
-	lastID, err := result.LastInsertId()
-	if err != nil {
-		return "", err
+	return strconv.FormatInt(lastID, 10), nil
+}
+
+// 주기보고 정보 여러 건을 하나의 multi-row INSERT로 생성
+func (d *ReportsRepo) CreateBatch(ctx context.Context, dis []data.DeviceInfo) error {
+	if len(dis) == 0 {
+		return nil
 	}
 
-	return strconv.FormatInt(lastID, 10), nil
+	// 1. 행 수만큼 VALUES 절 생성
+	var query strings.Builder
+	query.WriteString("INSERT INTO reports (ProductNumber, BatteryPercent, Lat, Lon, TemperatureCelsius, IP, ErrorCode, ReportAt, ReportedStatus) VALUES ")
+
+	args := make([]interface{}, 0, len(dis)*9)
+	now := time.Now()
+	for i := range dis {
+		di := &dis[i]
+		if di.ReportAt.IsZero() {
+			di.ReportAt = now
+		}
+
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args,
+			di.ProductNumber,
+			di.BatteryPercent,
+			di.Lat,
+			di.Lon,
+			di.TemperatureCelsius,
+			di.IP,
+			di.ErrorCode,
+			di.ReportAt,
+			di.ReportedStatus)
+	}
+
+	// 2. 쿼리 실행
+	if _, err := d.connection.ExecContext(ctx, d.dialect.Rebind(query.String()), args...); err != nil {
+		d.logger.Error().Err(err).Int("rows", len(dis)).Msg("failed to create device_info batch")
+		if IsUnavailable(err) {
+			return fmt.Errorf("%w: %w", ErrFailedToCreateReportInfo, ErrUnavailable)
+		}
+		return ErrFailedToCreateReportInfo
+	}
+
+	return nil
 }
 
 // 장비 식별자 추가 필요 
 func (d *ReportsRepo) GetAll(ctx context.Context) (*[]data.DeviceInfo, error) {
-	query := "SELECT ProductNumber, BatteryPercent, Lat, Lon, TemperatureCelsius, IP, ErrorCode, ReportAt, ReportedStatus FROM reports LIMIT ?"
+	query := "SELECT ProductNumber, BatteryPercent, Lat, Lon, TemperatureCelsius, IP, ErrorCode, ReportAt, ReportedStatus FROM reports " + d.dialect.Limit(DefLimit)
 
-	rows, err := d.connection.QueryContext(ctx, query, DefLimit)
+	rows, err := d.reader.QueryContext(ctx, query)
 	if err != nil {
 		d.logger.Error().Err(err).Msg("failed to select device_info")
 		return nil, ErrFailedToSelectReportInfo
@@ -115,10 +185,11 @@ func (d *ReportsRepo) GetAll(ctx context.Context) (*[]data.DeviceInfo, error) {
 }
 
 // Device ID에 해당하는 정보 획득 (단일 반환으로 변경)
+// 최근 보고부터 반환하며, 파티션마다 (ProductNumber, ReportAt) 인덱스를 역순으로 읽는다.
 func (d *ReportsRepo) GetByID(ctx context.Context, ID string) (*[]data.DeviceInfo, error) {
-	query := "SELECT ProductNumber, BatteryPercent, Lat, Lon, TemperatureCelsius, IP, ErrorCode, ReportAt, ReportedStatus FROM reports WHERE ProductNumber = ? LIMIT ?"
+	query := "SELECT ProductNumber, BatteryPercent, Lat, Lon, TemperatureCelsius, IP, ErrorCode, ReportAt, ReportedStatus FROM reports WHERE ProductNumber = ? ORDER BY ReportAt DESC " + d.dialect.Limit(DefLimit)
 
-	rows, err := d.connection.QueryContext(ctx, query, ID, DefLimit)
+	rows, err := d.reader.QueryContext(ctx, d.dialect.Rebind(query), ID)
 	if err != nil {
 		d.logger.Error().Err(err).Msg("failed to select device_info by ID")
 		return nil, ErrFailedToSelectReportInfo
@@ -152,7 +223,7 @@ func (d *ReportsRepo) GetByID(ctx context.Context, ID string) (*[]data.DeviceInf
 func (d *ReportsRepo) Delete(ctx context.Context, ID string) error {
 	query := "DELETE FROM reports WHERE ProductNumber = ?"
 
-	result, err := d.connection.ExecContext(ctx, query, ID)
+	result, err := d.connection.ExecContext(ctx, d.dialect.Rebind(query), ID)
 	if err != nil {
 		d.logger.Error().Err(err).Msg("failed to delete device_info")
 		return ErrFailedToDeleteReportInfo
@@ -164,4 +235,202 @@ func (d *ReportsRepo) Delete(ctx context.Context, ID string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// 보고 시간이 before 이전인 보고를 오래된 순서로 최대 limit건 획득 (보존 기간 정리용)
+// 보관 파일에서 복원한 보고는 이미 요약/보관되었으므로 제외한다.
+// 삭제와 같은 트랜잭션에서 읽어야 하므로 reader 대신 connection을 사용한다.
+func (d *ReportsRepo) GetBefore(ctx context.Context, before time.Time, limit int) ([]data.DeviceInfo, error) {
+	query := "SELECT ReportID, ProductNumber, BatteryPercent, Lat, Lon, TemperatureCelsius, IP, ErrorCode, ReportAt, ReportedStatus FROM reports WHERE ReportAt < ? AND RestoredAt IS NULL ORDER BY ReportAt, ReportID " + d.dialect.Limit(limit)
+
+	rows, err := d.connection.QueryContext(ctx, d.dialect.Rebind(query), before)
+	if err != nil {
+		d.logger.Error().Err(err).Msg("failed to select expired device_info")
+		if IsUnavailable(err) {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToSelectReportInfo, ErrUnavailable)
+		}
+		return nil, ErrFailedToSelectReportInfo
+	}
+	defer rows.Close()
+
+	var responseData []data.DeviceInfo
+
+	for rows.Next() {
+		var report data.DeviceInfo
+		err := rows.Scan(
+			&report.ReportID, &report.ProductNumber, &report.BatteryPercent, &report.Lat, &report.Lon, &report.TemperatureCelsius,
+			&report.IP, &report.ErrorCode, &report.ReportAt, &report.ReportedStatus,
+		)
+		if err != nil {
+			d.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, err
+		}
+		responseData = append(responseData, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return responseData, nil
+}
+
+// ReportID에 해당하는 보고 제거, 제거된 row 수 반환
+// from, to는 보고들의 ReportAt 범위(양 끝 포함)로, 월 파티션 중 해당 범위만 읽도록 한다.
+func (d *ReportsRepo) DeleteByIDs(ctx context.Context, IDs []int64, from, to time.Time) (int64, error) {
+	if len(IDs) == 0 {
+		return 0, nil
+	}
+
+	args := make([]interface{}, 0, len(IDs)+2)
+	args = append(args, from, to)
+	for _, id := range IDs {
+		args = append(args, id)
+	}
+	query := "DELETE FROM reports WHERE ReportAt >= ? AND ReportAt <= ? AND ReportID IN (?" + strings.Repeat(", ?", len(IDs)-1) + ")"
+
+	result, err := d.connection.ExecContext(ctx, d.dialect.Rebind(query), args...)
+	if err != nil {
+		d.logger.Error().Err(err).Int("rows", len(IDs)).Msg("failed to delete device_info by IDs")
+		if IsUnavailable(err) {
+			return 0, fmt.Errorf("%w: %w", ErrFailedToDeleteReportInfo, ErrUnavailable)
+		}
+		return 0, ErrFailedToDeleteReportInfo
+	}
+
+	return result.RowsAffected()
+}
+
+// 보관 파일의 보고를 원래 ReportID로 다시 저장하고 복원 시간을 표시, 저장된 row 수 반환
+// 이미 있는 보고(같은 ReportID)는 건너뛰므로 같은 범위를 여러 번 복원해도 중복되지 않는다.
+func (d *ReportsRepo) Restore(ctx context.Context, reports []data.DeviceInfo, restoredAt time.Time) (int64, error) {
+	if len(reports) == 0 {
+		return 0, nil
+	}
+
+	// 1. 이미 있는 보고 확인 : 보고 시간 범위를 함께 지정하여 해당 파티션만 읽는다
+	from, to := reports[0].ReportAt, reports[0].ReportAt
+	args := make([]interface{}, 0, len(reports)+2)
+	args = append(args, nil, nil)
+	for i := range reports {
+		if reports[i].ReportAt.Before(from) {
+			from = reports[i].ReportAt
+		}
+		if reports[i].ReportAt.After(to) {
+			to = reports[i].ReportAt
+		}
+		args = append(args, reports[i].ReportID)
+	}
+	args[0], args[1] = from, to
+
+	query := "SELECT ReportID FROM reports WHERE ReportAt >= ? AND ReportAt <= ? AND ReportID IN (?" + strings.Repeat(", ?", len(reports)-1) + ")"
+	rows, err := d.connection.QueryContext(ctx, d.dialect.Rebind(query), args...)
+	if err != nil {
+		d.logger.Error().Err(err).Msg("failed to select existing device_info")
+		if IsUnavailable(err) {
+			return 0, fmt.Errorf("%w: %w", ErrFailedToSelectReportInfo, ErrUnavailable)
+		}
+		return 0, ErrFailedToSelectReportInfo
+	}
+	defer rows.Close()
+
+	existing := make(map[int64]struct{})
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			d.logger.Error().Err(err).Msg("failed to scan row")
+			return 0, err
+		}
+		existing[id] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// 2. 없는 보고만 multi-row INSERT
+	var values strings.Builder
+	args = args[:0]
+	for i := range reports {
+		di := &reports[i]
+		if _, ok := existing[di.ReportID]; ok {
+			continue
+		}
+
+		if len(args) > 0 {
+			values.WriteString(", ")
+		}
+		values.WriteString("(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args,
+			di.ReportID,
+			di.ProductNumber,
+			di.BatteryPercent,
+			di.Lat,
+			di.Lon,
+			di.TemperatureCelsius,
+			di.IP,
+			di.ErrorCode,
+			di.ReportAt,
+			di.ReportedStatus,
+			restoredAt)
+	}
+	if len(args) == 0 {
+		return 0, nil
+	}
+
+	query = "INSERT INTO reports (ReportID, ProductNumber, BatteryPercent, Lat, Lon, TemperatureCelsius, IP, ErrorCode, ReportAt, ReportedStatus, RestoredAt) VALUES " + values.String()
+	result, err := d.connection.ExecContext(ctx, d.dialect.Rebind(query), args...)
+	if err != nil {
+		d.logger.Error().Err(err).Int("rows", len(reports)-len(existing)).Msg("failed to restore device_info")
+		if IsUnavailable(err) {
+			return 0, fmt.Errorf("%w: %w", ErrFailedToCreateReportInfo, ErrUnavailable)
+		}
+		return 0, ErrFailedToCreateReportInfo
+	}
+
+	return result.RowsAffected()
+}
+
+// 복원 시간이 before 이전인 복원된 보고를 최대 limit건 제거, 제거된 row 수 반환
+func (d *ReportsRepo) DeleteRestored(ctx context.Context, before time.Time, limit int) (int64, error) {
+	query := "SELECT ReportID FROM reports WHERE RestoredAt IS NOT NULL AND RestoredAt < ? ORDER BY RestoredAt " + d.dialect.Limit(limit)
+
+	rows, err := d.connection.QueryContext(ctx, d.dialect.Rebind(query), before)
+	if err != nil {
+		d.logger.Error().Err(err).Msg("failed to select restored device_info")
+		if IsUnavailable(err) {
+			return 0, fmt.Errorf("%w: %w", ErrFailedToSelectReportInfo, ErrUnavailable)
+		}
+		return 0, ErrFailedToSelectReportInfo
+	}
+	defer rows.Close()
+
+	var args []interface{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			d.logger.Error().Err(err).Msg("failed to scan row")
+			return 0, err
+		}
+		args = append(args, id)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	rows.Close()
+
+	if len(args) == 0 {
+		return 0, nil
+	}
+
+	query = "DELETE FROM reports WHERE RestoredAt IS NOT NULL AND ReportID IN (?" + strings.Repeat(", ?", len(args)-1) + ")"
+	result, err := d.connection.ExecContext(ctx, d.dialect.Rebind(query), args...)
+	if err != nil {
+		d.logger.Error().Err(err).Msg("failed to delete restored device_info")
+		if IsUnavailable(err) {
+			return 0, fmt.Errorf("%w: %w", ErrFailedToDeleteReportInfo, ErrUnavailable)
+		}
+		return 0, ErrFailedToDeleteReportInfo
+	}
+
+	return result.RowsAffected()
+}
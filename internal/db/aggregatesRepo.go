@@ -0,0 +1,227 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 시간대 오프셋의 최소 단위 (모든 시간대 오프셋은 15분의 배수이다)
+const zoneOffsetUnit = 15 * 60
+
+// 오류 상수 선언
+var (
+	ErrInvalidAggregateRequired = errors.New("missing required inputs to create AggregatesRepo")
+	ErrInvalidAggregateQuery    = errors.New("invalid report aggregate query")
+	ErrFailedToAggregate        = errors.New("failed to aggregate reports")
+)
+
+// 플릿 집계 그룹 기준별 devices 컬럼
+var aggregateGroupColumns = map[data.AggregateGroup]string{
+	data.AggregateByStatus:   "d.Status",
+	data.AggregateByFirmware: "d.FirmwareVersion",
+	data.AggregateByGroup:    "d.ProductLine",
+}
+
+// AggregatesRepo를 통해 사용할 메서드를 제약하고 규정하기 위한 인터페이스
+type AggregatesDataService interface {
+	Aggregate(ctx context.Context, q data.AggregateQuery) ([]data.ReportAggregate, error)
+}
+
+// 대시보드용 보고 집계 조회 (조회 전용이므로 replica 커넥션 사용)
+type AggregatesRepo struct {
+	reader  DBTX
+	dialect Dialect
+	logger  *logger.AppLogger
+}
+
+func NewAggregatesRepo(lgr *logger.AppLogger, reader DBTX, dialect Dialect) (*AggregatesRepo, error) {
+	if lgr == nil || reader == nil || dialect == nil {
+		return nil, ErrInvalidAggregateRequired
+	}
+	return &AggregatesRepo{
+		reader:  reader,
+		dialect: dialect,
+		logger:  lgr,
+	}, nil
+}
+
+// Aggregate - 보고를 구간별 최소/최대/평균/건수로 집계하여 그룹, 구간 순서로 반환합니다.
+// 원본 보고는 SQL로 집계하고, 구간 길이가 요약 단위(1시간, 1일)와 같으면 보존 기간이 지나 삭제된 보고의 요약을 합칩니다.
+// 요약에 이미 반영된 복원 보고는 이 경우 원본 집계에서 제외합니다.
+func (r *AggregatesRepo) Aggregate(ctx context.Context, q data.AggregateQuery) ([]data.ReportAggregate, error) {
+	bucketSec := int64(q.Bucket / time.Second)
+	if bucketSec <= 0 || data.RollupDaily.Duration()%q.Bucket != 0 {
+		return nil, ErrInvalidAggregateQuery
+	}
+
+	groupColumn := ""
+	if q.ProductNumber == "" && q.GroupBy != "" {
+		var ok bool
+		if groupColumn, ok = aggregateGroupColumns[q.GroupBy]; !ok {
+			return nil, ErrInvalidAggregateQuery
+		}
+	}
+
+	from, to := q.Range()
+	granularity, useRollup := q.Rollup()
+
+	// 1. 원본 보고 집계 : 유닉스 시간을 구간 길이로 내림하여 묶는다
+	// DB 시간대가 UTC가 아니면 SQL의 유닉스 시간이 시간대 오프셋만큼 밀려 있으므로,
+	// 오프셋 단위로 나누어 떨어지는 간격으로 먼저 묶은 뒤 실제 시각으로 변환하여 구간별로 합친다.
+	step := gcd(bucketSec, zoneOffsetUnit)
+	unix := r.dialect.UnixSeconds("r.ReportAt")
+	bucketExpr := fmt.Sprintf("%s - %s %% %s", unix, unix, strconv.FormatInt(step, 10))
+	query := "SELECT " + groupSelect(groupColumn) + bucketExpr + " AS Bucket, COUNT(*), MIN(r.BatteryPercent), MAX(r.BatteryPercent), SUM(r.BatteryPercent), " +
+		"MIN(r.TemperatureCelsius), MAX(r.TemperatureCelsius), SUM(r.TemperatureCelsius) FROM reports r" +
+		groupJoin(groupColumn, "r") + " WHERE r.ReportAt >= ? AND r.ReportAt < ?"
+	args := []interface{}{from, to}
+	if q.ProductNumber != "" {
+		query += " AND r.ProductNumber = ?"
+		args = append(args, q.ProductNumber)
+	}
+	if useRollup {
+		query += " AND r.RestoredAt IS NULL"
+	}
+	query += " GROUP BY " + groupBy(groupColumn, "Bucket")
+
+	raw, err := r.query(ctx, query, args, bucketSec)
+	if err != nil {
+		return nil, err
+	}
+	aggregates := mergeAggregates(nil, raw)
+
+	// 2. 요약 집계 : 같은 그룹/구간의 통계를 합친다
+	if useRollup {
+		query := "SELECT " + groupSelect(groupColumn) + "ro.BucketStart, SUM(ro.Samples), MIN(ro.BatteryMin), MAX(ro.BatteryMax), SUM(ro.BatterySum), " +
+			"MIN(ro.TemperatureMin), MAX(ro.TemperatureMax), SUM(ro.TemperatureSum) FROM report_rollups ro" +
+			groupJoin(groupColumn, "ro") + " WHERE ro.Granularity = ? AND ro.BucketStart >= ? AND ro.BucketStart < ?"
+		args := []interface{}{granularity, from, to}
+		if q.ProductNumber != "" {
+			query += " AND ro.ProductNumber = ?"
+			args = append(args, q.ProductNumber)
+		}
+		query += " GROUP BY " + groupBy(groupColumn, "ro.BucketStart")
+
+		rollups, err := r.query(ctx, query, args, 0)
+		if err != nil {
+			return nil, err
+		}
+		aggregates = mergeAggregates(aggregates, rollups)
+	}
+
+	sort.Slice(aggregates, func(i, j int) bool {
+		if aggregates[i].Group != aggregates[j].Group {
+			return aggregates[i].Group < aggregates[j].Group
+		}
+		return aggregates[i].BucketStart.Before(aggregates[j].BucketStart)
+	})
+
+	return aggregates, nil
+}
+
+// 집계 쿼리 실행 : 구간 시작은 원본 집계의 경우 유닉스 시간(초), 요약 집계의 경우 타임스탬프이다
+// bucketSec가 0보다 크면 유닉스 시간을 실제 시각으로 변환한 뒤 구간 길이로 내림한다.
+func (r *AggregatesRepo) query(ctx context.Context, query string, args []interface{}, bucketSec int64) ([]data.ReportAggregate, error) {
+	unixBucket := bucketSec > 0
+
+	rows, err := r.reader.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to aggregate reports")
+		if IsUnavailable(err) {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToAggregate, ErrUnavailable)
+		}
+		return nil, ErrFailedToAggregate
+	}
+	defer rows.Close()
+
+	var aggregates []data.ReportAggregate
+	for rows.Next() {
+		var a data.ReportAggregate
+		var bucketUnix int64
+
+		bucket := interface{}(&a.BucketStart)
+		if unixBucket {
+			bucket = &bucketUnix
+		}
+		err := rows.Scan(&a.Group, bucket, &a.Samples, &a.BatteryMin, &a.BatteryMax, &a.BatterySum,
+			&a.TemperatureMin, &a.TemperatureMax, &a.TemperatureSum)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, err
+		}
+
+		if unixBucket {
+			start := r.dialect.UnixTime(bucketUnix).Unix()
+			a.BucketStart = time.Unix(start-start%bucketSec, 0)
+		}
+		a.BucketStart = a.BucketStart.UTC()
+		aggregates = append(aggregates, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return aggregates, nil
+}
+
+// 그룹 기준이 없으면 빈 문자열을 그룹 값으로 조회한다
+func groupSelect(groupColumn string) string {
+	if groupColumn == "" {
+		return "'' AS GroupKey, "
+	}
+	return groupColumn + " AS GroupKey, "
+}
+
+func groupJoin(groupColumn, alias string) string {
+	if groupColumn == "" {
+		return ""
+	}
+	return " JOIN devices d ON d.ProductNumber = " + alias + ".ProductNumber"
+}
+
+func groupBy(groupColumn, bucket string) string {
+	if groupColumn == "" {
+		return bucket
+	}
+	return groupColumn + ", " + bucket
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// 같은 그룹/구간의 통계를 합친다
+func mergeAggregates(aggregates, others []data.ReportAggregate) []data.ReportAggregate {
+	type key struct {
+		group  string
+		bucket int64
+	}
+
+	index := make(map[key]int, len(aggregates))
+	for i := range aggregates {
+		index[key{aggregates[i].Group, aggregates[i].BucketStart.Unix()}] = i
+	}
+
+	for i := range others {
+		k := key{others[i].Group, others[i].BucketStart.Unix()}
+		if j, ok := index[k]; ok {
+			aggregates[j].Merge(&others[i])
+			continue
+		}
+		index[k] = len(aggregates)
+		aggregates = append(aggregates, others[i])
+	}
+
+	return aggregates
+}
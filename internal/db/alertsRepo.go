@@ -0,0 +1,518 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 알림 조회 및 다중 row 처리 기본/최대 수
+const (
+	defaultAlertListLimit = 100
+	maxAlertListLimit     = 1000
+	alertBatch            = 500 // 다중 row upsert 한 번에 반영할 알림 수 (행당 10개 placeholder)
+)
+
+// 오류 상수 선언
+var (
+	ErrInvalidAlertRequired  = errors.New("missing required inputs to create AlertsRepo")
+	ErrFailedToCreateRule    = errors.New("failed to create alert rule")
+	ErrFailedToSelectRule    = errors.New("failed to select alert rule")
+	ErrFailedToUpdateRule    = errors.New("failed to update alert rule")
+	ErrFailedToDeleteRule    = errors.New("failed to delete alert rule")
+	ErrRuleNotFound          = errors.New("alert rule not found")
+	ErrFailedToFireAlert     = errors.New("failed to fire alert")
+	ErrFailedToSelectAlert   = errors.New("failed to select alert")
+	ErrFailedToUpdateAlert   = errors.New("failed to update alert")
+	ErrAlertNotFound         = errors.New("alert not found")
+	ErrAlertStateConflict    = errors.New("alert state does not allow this change")
+	ErrFailedToEvaluateAlert = errors.New("failed to select alert evaluation inputs")
+)
+
+// AlertsRepo를 통해 사용할 메서드를 제약하고 규정하기 위한 인터페이스
+type AlertsDataService interface {
+	CreateRule(ctx context.Context, rule *data.AlertRule) (int64, error)
+	GetRule(ctx context.Context, ruleID int64) (*data.AlertRule, error)
+	ListRules(ctx context.Context) ([]data.AlertRule, error)
+	UpdateRule(ctx context.Context, rule *data.AlertRule) error
+	DeleteRule(ctx context.Context, ruleID int64) error
+
+	Fire(ctx context.Context, alerts []data.Alert) error
+	Resolve(ctx context.Context, openKeys []string, resolvedAt time.Time) (int64, error)
+	ResolveByRule(ctx context.Context, ruleID int64, resolvedAt time.Time) (int64, error)
+	ResolveByID(ctx context.Context, alertID int64, resolvedAt time.Time) (*data.Alert, error)
+	Acknowledge(ctx context.Context, alertID int64, by string, acknowledgedAt time.Time) (*data.Alert, error)
+	GetAlert(ctx context.Context, alertID int64) (*data.Alert, error)
+	ListAlerts(ctx context.Context, q data.AlertListQuery) ([]data.Alert, error)
+	ListOpen(ctx context.Context, productNumbers []string) ([]data.Alert, error)
+	ListOpenByRule(ctx context.Context, ruleID int64) ([]data.Alert, error)
+
+	RecentStatuses(ctx context.Context, productNumber string, n int) ([]data.DeviceStatus, error)
+	OfflineDevices(ctx context.Context, before time.Time, productLine string) (map[string]time.Time, error)
+	WithTx(tx DBTX) AlertsDataService
+}
+
+// alert_rules, alerts 테이블을 접근하기 위한 커넥션 관리
+type AlertsRepo struct {
+	connection DBTX
+	dialect    Dialect
+	logger     *logger.AppLogger
+}
+
+func NewAlertsRepo(lgr *logger.AppLogger, db DBTX, dialect Dialect) (*AlertsRepo, error) {
+	if lgr == nil || db == nil || dialect == nil {
+		return nil, ErrInvalidAlertRequired
+	}
+	return &AlertsRepo{
+		connection: db,
+		dialect:    dialect,
+		logger:     lgr,
+	}, nil
+}
+
+// 트랜잭션에 바인딩된 AlertsRepo 반환
+func (r *AlertsRepo) WithTx(tx DBTX) AlertsDataService {
+	return &AlertsRepo{
+		connection: tx,
+		dialect:    r.dialect,
+		logger:     r.logger,
+	}
+}
+
+const alertRuleColumns = "RuleID, Name, Kind, Severity, ProductLine, MinValue, MaxValue, ErrorCodes, Count, Enabled, CreatedAt, UpdatedAt"
+
+// CreateRule - 알림 규칙을 등록하고 생성된 RuleID를 반환합니다.
+func (r *AlertsRepo) CreateRule(ctx context.Context, rule *data.AlertRule) (int64, error) {
+	query := "INSERT INTO alert_rules (Name, Kind, Severity, ProductLine, MinValue, MaxValue, ErrorCodes, Count, Enabled, CreatedAt, UpdatedAt) " +
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+
+	ruleID, err := r.dialect.InsertID(ctx, r.connection, query, "RuleID",
+		rule.Name, rule.Kind, rule.Severity, rule.ProductLine, nullFloat(rule.MinValue), nullFloat(rule.MaxValue),
+		joinErrorCodes(rule.ErrorCodes), rule.Count, rule.Enabled, rule.CreatedAt, rule.UpdatedAt)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to create alert rule")
+		return 0, r.wrap(ErrFailedToCreateRule, err)
+	}
+
+	return ruleID, nil
+}
+
+func (r *AlertsRepo) GetRule(ctx context.Context, ruleID int64) (*data.AlertRule, error) {
+	query := "SELECT " + alertRuleColumns + " FROM alert_rules WHERE RuleID = ?"
+
+	rules, err := r.selectRules(ctx, query, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, ErrRuleNotFound
+	}
+
+	return &rules[0], nil
+}
+
+// ListRules - 모든 알림 규칙을 RuleID 순서로 반환합니다.
+func (r *AlertsRepo) ListRules(ctx context.Context) ([]data.AlertRule, error) {
+	return r.selectRules(ctx, "SELECT "+alertRuleColumns+" FROM alert_rules ORDER BY RuleID")
+}
+
+// UpdateRule - 알림 규칙의 조건과 설정을 변경합니다. (CreatedAt은 유지)
+func (r *AlertsRepo) UpdateRule(ctx context.Context, rule *data.AlertRule) error {
+	query := "UPDATE alert_rules SET Name = ?, Kind = ?, Severity = ?, ProductLine = ?, MinValue = ?, MaxValue = ?, " +
+		"ErrorCodes = ?, Count = ?, Enabled = ?, UpdatedAt = ? WHERE RuleID = ?"
+
+	result, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query),
+		rule.Name, rule.Kind, rule.Severity, rule.ProductLine, nullFloat(rule.MinValue), nullFloat(rule.MaxValue),
+		joinErrorCodes(rule.ErrorCodes), rule.Count, rule.Enabled, rule.UpdatedAt, rule.RuleID)
+	if err != nil {
+		r.logger.Error().Err(err).Int64("ruleID", rule.RuleID).Msg("failed to update alert rule")
+		return r.wrap(ErrFailedToUpdateRule, err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrRuleNotFound
+	}
+
+	return nil
+}
+
+// DeleteRule - 알림 규칙을 삭제합니다. 규칙의 알림 이력은 남겨둡니다.
+func (r *AlertsRepo) DeleteRule(ctx context.Context, ruleID int64) error {
+	result, err := r.connection.ExecContext(ctx, r.dialect.Rebind("DELETE FROM alert_rules WHERE RuleID = ?"), ruleID)
+	if err != nil {
+		r.logger.Error().Err(err).Int64("ruleID", ruleID).Msg("failed to delete alert rule")
+		return r.wrap(ErrFailedToDeleteRule, err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrRuleNotFound
+	}
+
+	return nil
+}
+
+func (r *AlertsRepo) selectRules(ctx context.Context, query string, args ...interface{}) ([]data.AlertRule, error) {
+	rows, err := r.connection.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to select alert rule")
+		return nil, r.wrap(ErrFailedToSelectRule, err)
+	}
+	defer rows.Close()
+
+	rules := make([]data.AlertRule, 0)
+	for rows.Next() {
+		var rule data.AlertRule
+		var minValue, maxValue sql.NullFloat64
+		var errorCodes string
+		err := rows.Scan(&rule.RuleID, &rule.Name, &rule.Kind, &rule.Severity, &rule.ProductLine, &minValue, &maxValue,
+			&errorCodes, &rule.Count, &rule.Enabled, &rule.CreatedAt, &rule.UpdatedAt)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, ErrFailedToSelectRule
+		}
+
+		if minValue.Valid {
+			rule.MinValue = &minValue.Float64
+		}
+		if maxValue.Valid {
+			rule.MaxValue = &maxValue.Float64
+		}
+		rule.ErrorCodes = splitErrorCodes(errorCodes)
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, r.wrap(ErrFailedToSelectRule, err)
+	}
+
+	return rules, nil
+}
+
+// Fire - 알림을 발생시킵니다.
+// 같은 규칙/디바이스의 열린 알림이 있으면 새로 만들지 않고 발생 횟수를 더하며,
+// 마지막 발생 시간이 기존보다 이후일 때만 관측값과 설명을 바꿉니다. (상태, 최초 발생 시간은 유지)
+func (r *AlertsRepo) Fire(ctx context.Context, alerts []data.Alert) error {
+	d := r.dialect
+	// MySQL은 갱신 절을 왼쪽부터 적용하므로 LastSeenAt을 마지막에 갱신한다
+	newer := fmt.Sprintf("%s >= alerts.LastSeenAt", d.Excluded("LastSeenAt"))
+	upsertClause := d.OnConflict([]string{"OpenKey"}) + " " + strings.Join([]string{
+		fmt.Sprintf("ObservedValue = CASE WHEN %s THEN %s ELSE alerts.ObservedValue END", newer, d.Excluded("ObservedValue")),
+		fmt.Sprintf("Message = CASE WHEN %s THEN %s ELSE alerts.Message END", newer, d.Excluded("Message")),
+		fmt.Sprintf("Occurrences = alerts.Occurrences + %s", d.Excluded("Occurrences")),
+		fmt.Sprintf("LastSeenAt = %s", d.Greatest("alerts.LastSeenAt", d.Excluded("LastSeenAt"))),
+	}, ", ")
+
+	for start := 0; start < len(alerts); start += alertBatch {
+		batch := alerts[start:min(start+alertBatch, len(alerts))]
+
+		values := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch)*10)
+		for i := range batch {
+			a := &batch[i]
+			values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+			args = append(args, a.RuleID, a.ProductNumber, a.Severity, data.AlertFiring, a.Message, a.ObservedValue,
+				a.Occurrences, a.FiredAt, a.LastSeenAt, data.AlertOpenKey(a.RuleID, a.ProductNumber))
+		}
+
+		query := "INSERT INTO alerts (RuleID, ProductNumber, Severity, State, Message, ObservedValue, Occurrences, FiredAt, LastSeenAt, OpenKey) VALUES " +
+			strings.Join(values, ", ") + " " + upsertClause
+
+		if _, err := r.connection.ExecContext(ctx, d.Rebind(query), args...); err != nil {
+			r.logger.Error().Err(err).Int("alerts", len(batch)).Msg("failed to fire alerts")
+			return r.wrap(ErrFailedToFireAlert, err)
+		}
+	}
+
+	return nil
+}
+
+// Resolve - 열린 알림 중 키가 일치하는 알림을 해소하고 해소한 알림 수를 반환합니다.
+func (r *AlertsRepo) Resolve(ctx context.Context, openKeys []string, resolvedAt time.Time) (int64, error) {
+	var total int64
+	for start := 0; start < len(openKeys); start += maxAlertListLimit {
+		batch := openKeys[start:min(start+maxAlertListLimit, len(openKeys))]
+
+		args := make([]interface{}, 0, len(batch)+2)
+		args = append(args, data.AlertResolved, resolvedAt)
+		for _, key := range batch {
+			args = append(args, key)
+		}
+		query := "UPDATE alerts SET State = ?, ResolvedAt = ?, OpenKey = NULL WHERE OpenKey IN (?" + strings.Repeat(", ?", len(batch)-1) + ")"
+
+		affected, err := r.update(ctx, query, args...)
+		total += affected
+		if err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// ResolveByRule - 규칙의 열린 알림을 모두 해소합니다. (규칙 삭제, 비활성화 시)
+func (r *AlertsRepo) ResolveByRule(ctx context.Context, ruleID int64, resolvedAt time.Time) (int64, error) {
+	query := "UPDATE alerts SET State = ?, ResolvedAt = ?, OpenKey = NULL WHERE RuleID = ? AND OpenKey IS NOT NULL"
+	return r.update(ctx, query, data.AlertResolved, resolvedAt, ruleID)
+}
+
+// ResolveByID - 열린 알림을 직접 해소합니다. 이미 해소된 알림이면 ErrAlertStateConflict를 반환합니다.
+func (r *AlertsRepo) ResolveByID(ctx context.Context, alertID int64, resolvedAt time.Time) (*data.Alert, error) {
+	query := "UPDATE alerts SET State = ?, ResolvedAt = ?, OpenKey = NULL WHERE AlertID = ? AND OpenKey IS NOT NULL"
+	return r.transition(ctx, alertID, query, data.AlertResolved, resolvedAt, alertID)
+}
+
+// Acknowledge - firing 상태의 알림을 확인 처리합니다. 확인 후에도 조건이 해소될 때까지 열린 알림으로 유지됩니다.
+func (r *AlertsRepo) Acknowledge(ctx context.Context, alertID int64, by string, acknowledgedAt time.Time) (*data.Alert, error) {
+	query := "UPDATE alerts SET State = ?, AcknowledgedAt = ?, AcknowledgedBy = ? WHERE AlertID = ? AND State = ?"
+	return r.transition(ctx, alertID, query, data.AlertAcknowledged, acknowledgedAt, by, alertID, data.AlertFiring)
+}
+
+// 조건부 상태 변경 후 알림을 반환한다. 변경된 알림이 없으면 알림이 없는지, 상태가 맞지 않는지 구분한다.
+func (r *AlertsRepo) transition(ctx context.Context, alertID int64, query string, args ...interface{}) (*data.Alert, error) {
+	affected, err := r.update(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	alert, err := r.GetAlert(ctx, alertID)
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return alert, ErrAlertStateConflict
+	}
+
+	return alert, nil
+}
+
+func (r *AlertsRepo) update(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	result, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to update alert")
+		return 0, r.wrap(ErrFailedToUpdateAlert, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, ErrFailedToUpdateAlert
+	}
+
+	return affected, nil
+}
+
+const alertColumns = "AlertID, RuleID, ProductNumber, Severity, State, Message, ObservedValue, Occurrences, FiredAt, LastSeenAt, " +
+	"AcknowledgedAt, AcknowledgedBy, ResolvedAt"
+
+func (r *AlertsRepo) GetAlert(ctx context.Context, alertID int64) (*data.Alert, error) {
+	alerts, err := r.selectAlerts(ctx, "SELECT "+alertColumns+" FROM alerts WHERE AlertID = ?", alertID)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, ErrAlertNotFound
+	}
+
+	return &alerts[0], nil
+}
+
+// ListAlerts - 조건에 맞는 알림을 최신 순서로 반환합니다.
+func (r *AlertsRepo) ListAlerts(ctx context.Context, q data.AlertListQuery) ([]data.Alert, error) {
+	limit := min(orDefault(q.Limit, defaultAlertListLimit), maxAlertListLimit)
+
+	var conds []string
+	var args []interface{}
+	if q.State != "" {
+		conds = append(conds, "State = ?")
+		args = append(args, q.State)
+	}
+	if q.ProductNumber != "" {
+		conds = append(conds, "ProductNumber = ?")
+		args = append(args, q.ProductNumber)
+	}
+	if q.RuleID > 0 {
+		conds = append(conds, "RuleID = ?")
+		args = append(args, q.RuleID)
+	}
+	if q.Before > 0 {
+		conds = append(conds, "AlertID < ?")
+		args = append(args, q.Before)
+	}
+
+	query := "SELECT " + alertColumns + " FROM alerts"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY AlertID DESC " + r.dialect.Limit(limit)
+
+	return r.selectAlerts(ctx, query, args...)
+}
+
+// ListOpen - 디바이스들의 열린 알림을 반환합니다.
+func (r *AlertsRepo) ListOpen(ctx context.Context, productNumbers []string) ([]data.Alert, error) {
+	alerts := make([]data.Alert, 0)
+	for start := 0; start < len(productNumbers); start += maxAlertListLimit {
+		batch := productNumbers[start:min(start+maxAlertListLimit, len(productNumbers))]
+
+		args := make([]interface{}, 0, len(batch))
+		for _, pn := range batch {
+			args = append(args, pn)
+		}
+		query := "SELECT " + alertColumns + " FROM alerts WHERE OpenKey IS NOT NULL AND ProductNumber IN (?" + strings.Repeat(", ?", len(batch)-1) + ")"
+
+		open, err := r.selectAlerts(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, open...)
+	}
+
+	return alerts, nil
+}
+
+// ListOpenByRule - 규칙의 열린 알림을 반환합니다.
+func (r *AlertsRepo) ListOpenByRule(ctx context.Context, ruleID int64) ([]data.Alert, error) {
+	return r.selectAlerts(ctx, "SELECT "+alertColumns+" FROM alerts WHERE OpenKey IS NOT NULL AND RuleID = ?", ruleID)
+}
+
+func (r *AlertsRepo) selectAlerts(ctx context.Context, query string, args ...interface{}) ([]data.Alert, error) {
+	rows, err := r.connection.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to select alert")
+		return nil, r.wrap(ErrFailedToSelectAlert, err)
+	}
+	defer rows.Close()
+
+	alerts := make([]data.Alert, 0)
+	for rows.Next() {
+		var a data.Alert
+		var acknowledgedAt, resolvedAt sql.NullTime
+		err := rows.Scan(&a.AlertID, &a.RuleID, &a.ProductNumber, &a.Severity, &a.State, &a.Message, &a.ObservedValue, &a.Occurrences,
+			&a.FiredAt, &a.LastSeenAt, &acknowledgedAt, &a.AcknowledgedBy, &resolvedAt)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, ErrFailedToSelectAlert
+		}
+
+		if acknowledgedAt.Valid {
+			a.AcknowledgedAt = &acknowledgedAt.Time
+		}
+		if resolvedAt.Valid {
+			a.ResolvedAt = &resolvedAt.Time
+		}
+		alerts = append(alerts, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, r.wrap(ErrFailedToSelectAlert, err)
+	}
+
+	return alerts, nil
+}
+
+// RecentStatuses - 디바이스가 최근 보고한 상태를 최신 순서로 n건까지 반환합니다. (error_repeated 평가용)
+func (r *AlertsRepo) RecentStatuses(ctx context.Context, productNumber string, n int) ([]data.DeviceStatus, error) {
+	query := "SELECT ReportedStatus FROM reports WHERE ProductNumber = ? ORDER BY ReportAt DESC " + r.dialect.Limit(n)
+
+	rows, err := r.connection.QueryContext(ctx, r.dialect.Rebind(query), productNumber)
+	if err != nil {
+		r.logger.Error().Err(err).Str("productNumber", productNumber).Msg("failed to select recent report statuses")
+		return nil, r.wrap(ErrFailedToEvaluateAlert, err)
+	}
+	defer rows.Close()
+
+	statuses := make([]data.DeviceStatus, 0, n)
+	for rows.Next() {
+		var status data.DeviceStatus
+		if err := rows.Scan(&status); err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, ErrFailedToEvaluateAlert
+		}
+		statuses = append(statuses, status)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, r.wrap(ErrFailedToEvaluateAlert, err)
+	}
+
+	return statuses, nil
+}
+
+// OfflineDevices - 마지막 보고 시간이 before 이전인 디바이스와 마지막 보고 시간을 반환합니다.
+// productLine이 비어 있으면 모든 제품군이 대상입니다.
+func (r *AlertsRepo) OfflineDevices(ctx context.Context, before time.Time, productLine string) (map[string]time.Time, error) {
+	query := "SELECT ProductNumber, LastSeenAt FROM devices WHERE LastSeenAt < ?"
+	args := []interface{}{before}
+	if productLine != "" {
+		query += " AND ProductLine = ?"
+		args = append(args, productLine)
+	}
+
+	rows, err := r.connection.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to select offline devices")
+		return nil, r.wrap(ErrFailedToEvaluateAlert, err)
+	}
+	defer rows.Close()
+
+	devices := make(map[string]time.Time)
+	for rows.Next() {
+		var productNumber string
+		var lastSeenAt time.Time
+		if err := rows.Scan(&productNumber, &lastSeenAt); err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, ErrFailedToEvaluateAlert
+		}
+		devices[productNumber] = lastSeenAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, r.wrap(ErrFailedToEvaluateAlert, err)
+	}
+
+	return devices, nil
+}
+
+// 데이터베이스에 연결할 수 없는 오류는 ErrUnavailable을 함께 감싼다
+func (r *AlertsRepo) wrap(sentinel error, err error) error {
+	if IsUnavailable(err) {
+		return fmt.Errorf("%w: %w", sentinel, ErrUnavailable)
+	}
+	return sentinel
+}
+
+func nullFloat(v *float64) sql.NullFloat64 {
+	if v == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: *v, Valid: true}
+}
+
+// 에러 코드 목록은 쉼표로 구분하여 저장한다
+func joinErrorCodes(codes []int) string {
+	values := make([]string, len(codes))
+	for i, code := range codes {
+		values[i] = strconv.Itoa(code)
+	}
+	return strings.Join(values, ",")
+}
+
+func splitErrorCodes(s string) []int {
+	if s == "" {
+		return nil
+	}
+
+	var codes []int
+	for _, v := range strings.Split(s, ",") {
+		if code, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
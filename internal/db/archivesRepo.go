@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 오류 상수 선언
+var (
+	ErrInvalidArchiveRequired = errors.New("missing required inputs to create ArchivesRepo")
+	ErrFailedToCreateArchive  = errors.New("failed to create report archive")
+	ErrFailedToSelectArchive  = errors.New("failed to select report archive")
+)
+
+// ArchivesRepo를 통해 사용할 메서드를 제약하고 규정하기 위한 인터페이스
+type ArchivesDataService interface {
+	Create(ctx context.Context, archives []data.ReportArchive) error
+	GetRange(ctx context.Context, from, to time.Time, productNumber string) ([]data.ReportArchive, error)
+	WithTx(tx DBTX) ArchivesDataService
+}
+
+// report_archives 테이블을 접근하기 위한 커넥션 관리
+type ArchivesRepo struct {
+	connection DBTX
+	dialect    Dialect
+	logger     *logger.AppLogger
+}
+
+func NewArchivesRepo(lgr *logger.AppLogger, db DBTX, dialect Dialect) (*ArchivesRepo, error) {
+	if lgr == nil || db == nil || dialect == nil {
+		return nil, ErrInvalidArchiveRequired
+	}
+	return &ArchivesRepo{
+		connection: db,
+		dialect:    dialect,
+		logger:     lgr,
+	}, nil
+}
+
+// 트랜잭션에 바인딩된 ArchivesRepo 반환
+func (r *ArchivesRepo) WithTx(tx DBTX) ArchivesDataService {
+	return &ArchivesRepo{
+		connection: tx,
+		dialect:    r.dialect,
+		logger:     r.logger,
+	}
+}
+
+// Create - 보관 파일 목록을 저장합니다.
+func (r *ArchivesRepo) Create(ctx context.Context, archives []data.ReportArchive) error {
+	if len(archives) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(archives))
+	args := make([]interface{}, 0, len(archives)*10)
+	for i := range archives {
+		a := &archives[i]
+		values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args, a.ProductNumber, a.DayStart.UTC(), a.ObjectKey, a.Format, a.Records, a.Bytes, a.Checksum,
+			a.FirstReportAt, a.LastReportAt, a.CreatedAt)
+	}
+
+	query := "INSERT INTO report_archives (ProductNumber, DayStart, ObjectKey, Format, Records, Bytes, Checksum, FirstReportAt, LastReportAt, CreatedAt) VALUES " +
+		strings.Join(values, ", ")
+
+	if _, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), args...); err != nil {
+		r.logger.Error().Err(err).Int("rows", len(archives)).Msg("failed to create report archives")
+		if IsUnavailable(err) {
+			return fmt.Errorf("%w: %w", ErrFailedToCreateArchive, ErrUnavailable)
+		}
+		return ErrFailedToCreateArchive
+	}
+
+	return nil
+}
+
+// GetRange - [from, to) 범위의 보고를 포함하는 보관 파일 목록을 일자, 디바이스, 보고 시간 순서로 반환합니다.
+// productNumber가 비어 있으면 모든 디바이스를 대상으로 합니다.
+func (r *ArchivesRepo) GetRange(ctx context.Context, from, to time.Time, productNumber string) ([]data.ReportArchive, error) {
+	query := "SELECT ArchiveID, ProductNumber, DayStart, ObjectKey, Format, Records, Bytes, Checksum, FirstReportAt, LastReportAt, CreatedAt " +
+		"FROM report_archives WHERE DayStart >= ? AND DayStart < ? AND LastReportAt >= ? AND FirstReportAt < ?"
+	args := []interface{}{data.RollupDaily.BucketStart(from), to.UTC(), from, to}
+	if productNumber != "" {
+		query += " AND ProductNumber = ?"
+		args = append(args, productNumber)
+	}
+	query += " ORDER BY DayStart, ProductNumber, FirstReportAt"
+
+	rows, err := r.connection.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to select report archives")
+		if IsUnavailable(err) {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToSelectArchive, ErrUnavailable)
+		}
+		return nil, ErrFailedToSelectArchive
+	}
+	defer rows.Close()
+
+	var archives []data.ReportArchive
+	for rows.Next() {
+		var a data.ReportArchive
+		err := rows.Scan(&a.ArchiveID, &a.ProductNumber, &a.DayStart, &a.ObjectKey, &a.Format, &a.Records, &a.Bytes, &a.Checksum,
+			&a.FirstReportAt, &a.LastReportAt, &a.CreatedAt)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, err
+		}
+		archives = append(archives, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return archives, nil
+}
@@ -0,0 +1,246 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"go-rest-example/internal/logger"
+)
+
+// 서킷 브레이커 기본값
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerOpenTimeout      = 10 * time.Second
+)
+
+var (
+	ErrCircuitOpen = errors.New("database circuit breaker is open")
+	ErrUnavailable = errors.New("database is unavailable")
+)
+
+// BreakerState는 서킷 브레이커 상태입니다.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // 정상 : 모든 요청 허용
+	BreakerOpen     BreakerState = "open"      // 차단 : 데이터베이스에 접근하지 않고 즉시 실패
+	BreakerHalfOpen BreakerState = "half-open" // 확인 : 한 번의 시험 요청만 허용
+)
+
+type BreakerConfig struct {
+	FailureThreshold int           // 연속 실패가 이 횟수에 도달하면 open (기본값 5)
+	OpenTimeout      time.Duration // open 상태 유지 시간, 이후 half-open (기본값 10초)
+}
+
+// CircuitBreaker는 데이터베이스 장애 시 요청이 연결 대기로 묶이지 않도록 빠르게 실패시킵니다.
+// 연결 불가, 네트워크 오류 등 데이터베이스 가용성 오류만 실패로 집계하며
+// 결과 없음, 제약 조건 위반 같은 쿼리 오류는 집계하지 않습니다.
+type CircuitBreaker struct {
+	cfg    BreakerConfig
+	logger *logger.AppLogger
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool // half-open 상태에서 시험 요청이 진행 중인지 여부
+}
+
+func NewCircuitBreaker(lgr *logger.AppLogger, cfg BreakerConfig) *CircuitBreaker {
+	cfg.FailureThreshold = orDefault(cfg.FailureThreshold, defaultBreakerFailureThreshold)
+	cfg.OpenTimeout = orDefault(cfg.OpenTimeout, defaultBreakerOpenTimeout)
+
+	return &CircuitBreaker{
+		cfg:    cfg,
+		logger: lgr,
+		state:  BreakerClosed,
+	}
+}
+
+// State - 현재 상태를 반환합니다. open 유지 시간이 지났으면 half-open으로 봅니다.
+func (cb *CircuitBreaker) State() BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.currentState(time.Now())
+}
+
+// RetryAfter - open 상태가 끝날 때까지 남은 시간을 반환합니다. open이 아니면 0.
+func (cb *CircuitBreaker) RetryAfter() time.Duration {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	if cb.currentState(now) != BreakerOpen {
+		return 0
+	}
+	return cb.openedAt.Add(cb.cfg.OpenTimeout).Sub(now)
+}
+
+// Wrap - 호출 전 상태를 확인하고 결과를 집계하는 DBTX를 반환합니다.
+func (cb *CircuitBreaker) Wrap(d DBTX) DBTX {
+	return &breakerDB{inner: d, cb: cb}
+}
+
+// WrapTx - 트랜잭션 시작 전 상태를 확인하고, 트랜잭션 안의 쿼리 결과를 집계하는 TxManager를 반환합니다.
+func (cb *CircuitBreaker) WrapTx(t TxManager) TxManager {
+	return &breakerTx{inner: t, cb: cb}
+}
+
+func (cb *CircuitBreaker) currentState(now time.Time) BreakerState {
+	if cb.state == BreakerOpen && now.Sub(cb.openedAt) >= cb.cfg.OpenTimeout {
+		return BreakerHalfOpen
+	}
+	return cb.state
+}
+
+// 요청 허용 여부. half-open 상태에서는 진행 중인 시험 요청이 없을 때 하나만 허용한다.
+func (cb *CircuitBreaker) allow() bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.currentState(time.Now()) {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if cb.probing {
+			return false
+		}
+		cb.setState(BreakerHalfOpen, nil)
+		cb.probing = true
+	}
+	return true
+}
+
+// 호출 결과를 집계한다.
+func (cb *CircuitBreaker) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if !IsUnavailable(err) {
+		cb.failures = 0
+		if cb.state != BreakerClosed {
+			cb.setState(BreakerClosed, nil)
+		}
+		cb.probing = false
+		return
+	}
+
+	cb.failures++
+	if cb.state == BreakerHalfOpen || cb.failures >= cb.cfg.FailureThreshold {
+		cb.openedAt = time.Now()
+		cb.setState(BreakerOpen, err)
+	}
+	cb.probing = false
+}
+
+func (cb *CircuitBreaker) setState(state BreakerState, err error) {
+	if cb.state == state {
+		return
+	}
+
+	event := cb.logger.Info()
+	if state == BreakerOpen {
+		event = cb.logger.Error().Err(err).Dur("openTimeout", cb.cfg.OpenTimeout)
+	}
+	event.Str("from", string(cb.state)).Str("to", string(state)).Int("failures", cb.failures).Msg("DB circuit breaker state changed")
+
+	cb.state = state
+}
+
+// IsUnavailable - 데이터베이스 가용성 오류인지 판단합니다. (호출자 취소 및 쿼리 자체의 오류는 제외)
+// 서킷 브레이커 차단(ErrCircuitOpen)과 Repository가 반환한 ErrUnavailable도 포함합니다.
+func IsUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrCircuitOpen) || errors.Is(err, ErrUnavailable) {
+		return true
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, mysql.ErrInvalidConn) ||
+		errors.Is(err, ErrTxBegin) ||
+		errors.As(err, &netErr)
+}
+
+// breakerDB는 CircuitBreaker를 거쳐 쿼리를 실행하는 DBTX 구현체입니다.
+type breakerDB struct {
+	inner DBTX
+	cb    *CircuitBreaker
+}
+
+func (b *breakerDB) onCommit(fn func()) {
+	AfterCommit(b.inner, fn)
+}
+
+func (b *breakerDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if !b.cb.allow() {
+		return nil, ErrCircuitOpen
+	}
+	result, err := b.inner.ExecContext(ctx, query, args...)
+	b.cb.record(err)
+	return result, err
+}
+
+func (b *breakerDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if !b.cb.allow() {
+		return nil, ErrCircuitOpen
+	}
+	rows, err := b.inner.QueryContext(ctx, query, args...)
+	b.cb.record(err)
+	return rows, err
+}
+
+// sql.Row는 오류를 담아 직접 만들 수 없으므로, 차단 시에는 취소된 컨텍스트로 실행하여
+// 데이터베이스에 접근하지 않고 Scan에서 ErrCircuitOpen 오류가 나도록 한다.
+func (b *breakerDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if !b.cb.allow() {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		return b.inner.QueryRowContext(openCircuitContext{canceled}, query, args...)
+	}
+	row := b.inner.QueryRowContext(ctx, query, args...)
+	b.cb.record(row.Err())
+	return row
+}
+
+// breakerTx는 트랜잭션 단위로 CircuitBreaker를 적용하는 TxManager 구현체입니다.
+type breakerTx struct {
+	inner TxManager
+	cb    *CircuitBreaker
+}
+
+func (b *breakerTx) WithTx(ctx context.Context, fn func(tx DBTX) error) error {
+	// 트랜잭션 시작(BEGIN) 자체는 시험 요청 슬롯을 쓰지 않도록 상태만 확인한다
+	if b.cb.State() == BreakerOpen {
+		return ErrCircuitOpen
+	}
+
+	err := b.inner.WithTx(ctx, func(tx DBTX) error {
+		return fn(&breakerDB{inner: tx, cb: b.cb})
+	})
+	if errors.Is(err, ErrTxBegin) {
+		b.cb.record(err)
+	}
+	return err
+}
+
+// openCircuitContext는 취소된 컨텍스트의 오류를 ErrCircuitOpen으로 바꿔 반환합니다.
+type openCircuitContext struct {
+	context.Context
+}
+
+func (openCircuitContext) Err() error {
+	return ErrCircuitOpen
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeDBTX는 ExecContext가 err를 반환하는 DBTX입니다.
+type fakeDBTX struct {
+	DBTX
+	err   error
+	calls int
+}
+
+func (f *fakeDBTX) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	f.calls++
+	return nil, f.err
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	cb := NewCircuitBreaker(testLogger(), BreakerConfig{FailureThreshold: 3, OpenTimeout: 50 * time.Millisecond})
+	inner := &fakeDBTX{err: driver.ErrBadConn}
+	db := cb.Wrap(inner)
+
+	// 임계치 미만의 실패는 차단하지 않는다
+	for range 2 {
+		db.ExecContext(ctx, "SELECT 1")
+	}
+	if cb.State() != BreakerClosed {
+		t.Fatalf("State after 2 failures = %s, want closed", cb.State())
+	}
+
+	db.ExecContext(ctx, "SELECT 1")
+	if cb.State() != BreakerOpen || cb.RetryAfter() <= 0 {
+		t.Fatalf("State after 3 failures = %s (retry after %v), want open", cb.State(), cb.RetryAfter())
+	}
+
+	// open 상태에서는 데이터베이스에 접근하지 않는다
+	if _, err := db.ExecContext(ctx, "SELECT 1"); !errors.Is(err, ErrCircuitOpen) || inner.calls != 3 {
+		t.Fatalf("ExecContext while open: err = %v, calls = %d", err, inner.calls)
+	}
+	if !IsUnavailable(ErrCircuitOpen) {
+		t.Error("IsUnavailable(ErrCircuitOpen) = false")
+	}
+
+	// 유지 시간이 지나면 half-open, 시험 요청이 실패하면 다시 open
+	time.Sleep(60 * time.Millisecond)
+	if cb.State() != BreakerHalfOpen || cb.RetryAfter() != 0 {
+		t.Fatalf("State after timeout = %s, want half-open", cb.State())
+	}
+	db.ExecContext(ctx, "SELECT 1")
+	if cb.State() != BreakerOpen {
+		t.Fatalf("State after failed probe = %s, want open", cb.State())
+	}
+
+	// 시험 요청이 성공하면 closed
+	time.Sleep(60 * time.Millisecond)
+	inner.err = nil
+	if _, err := db.ExecContext(ctx, "SELECT 1"); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if cb.State() != BreakerClosed {
+		t.Fatalf("State after successful probe = %s, want closed", cb.State())
+	}
+}
+
+func TestCircuitBreakerSingleProbe(t *testing.T) {
+	cb := NewCircuitBreaker(testLogger(), BreakerConfig{FailureThreshold: 1, OpenTimeout: 10 * time.Millisecond})
+	cb.record(driver.ErrBadConn)
+	time.Sleep(20 * time.Millisecond)
+
+	// half-open 상태에서는 진행 중인 시험 요청이 끝날 때까지 다른 요청을 막는다
+	if !cb.allow() {
+		t.Fatal("first probe not allowed")
+	}
+	if cb.allow() {
+		t.Fatal("second concurrent probe allowed")
+	}
+	cb.record(nil)
+	if !cb.allow() || !cb.allow() {
+		t.Error("requests not allowed after successful probe")
+	}
+}
+
+func TestCircuitBreakerIgnoresQueryErrors(t *testing.T) {
+	ctx := context.Background()
+	cb := NewCircuitBreaker(testLogger(), BreakerConfig{FailureThreshold: 2})
+	inner := &fakeDBTX{err: sql.ErrNoRows}
+	db := cb.Wrap(inner)
+
+	// 결과 없음, 제약 조건 위반 등 쿼리 오류는 가용성 실패로 집계하지 않는다
+	for _, err := range []error{sql.ErrNoRows, errors.New("UNIQUE constraint failed"), context.Canceled} {
+		inner.err = err
+		for range 3 {
+			db.ExecContext(ctx, "SELECT 1")
+		}
+	}
+	if cb.State() != BreakerClosed {
+		t.Errorf("State = %s, want closed", cb.State())
+	}
+
+	// 성공하면 연속 실패 횟수를 초기화한다
+	inner.err = driver.ErrBadConn
+	db.ExecContext(ctx, "SELECT 1")
+	inner.err = nil
+	db.ExecContext(ctx, "SELECT 1")
+	inner.err = driver.ErrBadConn
+	db.ExecContext(ctx, "SELECT 1")
+	if cb.State() != BreakerClosed {
+		t.Errorf("State after interleaved success = %s, want closed", cb.State())
+	}
+}
@@ -4,11 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"go-rest-example/internal/logger"
+	"math/rand"
+	"net"
+	"os"
+	"strconv"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql" // 데이터베이스 드라이버 구현체 추가
+	"github.com/go-sql-driver/mysql" // 데이터베이스 드라이버 구현체 추가
 )
 
 // --- 새로 추가된 인터페이스들 ---
@@ -21,11 +27,30 @@ type DBTX interface {
 	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
+// TxManager는 여러 Repository 작업을 하나의 트랜잭션으로 묶는 인터페이스입니다.
+// fn에 전달된 DBTX로 Repository를 바인딩(WithTx)하여 사용합니다.
+// fn이 오류를 반환하면 롤백, 성공하면 커밋하며, 데드락 등 재시도 가능한 오류는 자동으로 재시도합니다.
+// 재시도될 수 있으므로 fn은 여러 번 실행되어도 안전해야 합니다.
+type TxManager interface {
+	WithTx(ctx context.Context, fn func(tx DBTX) error) error
+}
+
 // DBManager는 데이터베이스 연결의 생명주기를 관리하는 인터페이스입니다.
 type DBManager interface {
+	TxManager
 	DB() DBTX // DB 또는 Tx를 나타내는 DBTX 인터페이스 반환
+	Conn(ctx context.Context) (*sql.Conn, error) // 세션 단위 작업(잠금 등)을 위한 전용 커넥션 반환
+	Dialect() Dialect // 데이터베이스 엔진별 SQL 구문 차이
+	ReadDB() DBTX // 조회 전용 DBTX 반환 (replica 우선, 없거나 장애 시 primary)
 	Ping() error
 	Disconnect() error
+	Stats() PoolStats // 커넥션 풀 상태
+}
+
+// PoolStats는 primary 및 replica 커넥션 풀의 현재 상태입니다.
+type PoolStats struct {
+	Primary  sql.DBStats            `json:"primary"`
+	Replicas map[string]sql.DBStats `json:"replicas,omitempty"`
 }
 
 // --- 기존 코드 (일부 수정) ---
@@ -36,71 +61,265 @@ type MariaDBCredentials struct {
 	Host     string
 	Port     int
 	Database string
+	Replicas []string // 조회 전용 replica 주소 목록 (host:port), 계정 및 DB 이름은 primary와 동일
+
+	// 커넥션 풀 설정 (0 이하이면 기본값 사용)
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+
+	// 네트워크 제한 시간 (0이면 드라이버 기본값 : 제한 없음)
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// TLS 설정
+	// TLS : "" 또는 "false"(사용 안 함), "true"(인증서 검증), "skip-verify"(검증 생략), "preferred"(서버 지원 시 사용)
+	// TLSCAFile : 서버 인증서 검증에 사용할 CA 인증서(PEM) 경로, 지정 시 TLS 기본값은 "true"
+	TLS       string
+	TLSCAFile string
+
+	// 문자셋, 정렬 규칙, time.Time 변환 시 사용할 시간대 (IANA 이름, 기본값 UTC)
+	Charset   string
+	Collation string
+	Loc       string
+
+	// 기동 시 접속 재시도 (ConnectAttempts가 1 이하이면 한 번만 시도)
+	// 대기 시간은 ConnectBackoff부터 두 배씩 늘어나며 ConnectMaxBackoff를 넘지 않는다
+	ConnectAttempts   int
+	ConnectBackoff    time.Duration
+	ConnectMaxBackoff time.Duration
 }
 
 type MariaDBManager struct {
-	db     *sql.DB
-	logger *logger.AppLogger
+	db      *sql.DB
+	reader  *readRouter
+	dialect Dialect // DB 시간대(Loc)를 반영한 MySQLDialect
+	logger  *logger.AppLogger
 }
 
 // 컴파일 타임에 MariaDBManager가 DBManager 인터페이스를 구현하는지 확인합니다.
 var _ DBManager = (*MariaDBManager)(nil)
 
+// 커넥션 풀 기본값
+const (
+	defaultMaxOpenConns    = 10
+	defaultMaxIdleConns    = 10
+	defaultConnMaxLifetime = 3 * time.Minute
+)
+
+// 기동 시 접속 재시도 기본값
+const (
+	defaultConnectBackoff    = time.Second
+	defaultConnectMaxBackoff = 30 * time.Second
+)
+
 var (
 	ErrInvalidConnURL    = errors.New("failed to connect to DB, as the connection string is invalid")
 	ErrConnectionEstablish = errors.New("failed to establish connection to DB")
 	ErrClientInit        = errors.New("failed to initialize DB client")
 	ErrConnectionLeak    = errors.New("unable to disconnect from DB, potential connection leak")
 	ErrPingDB            = errors.New("failed to ping DB")
+	ErrTxBegin           = errors.New("failed to begin DB transaction")
+	ErrTxCommit          = errors.New("failed to commit DB transaction")
+	ErrInvalidTLSConfig  = errors.New("invalid DB TLS configuration")
+	ErrInvalidLocation   = errors.New("invalid DB time zone")
 )
 
-func NewMariaDBManager(creds *MariaDBCredentials, lgr *logger.AppLogger) (DBManager, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true", // parseTime=true 추가 권장
-		creds.User,
-		creds.Password,
-		creds.Host,
-		creds.Port,
-		creds.Database,
-	)
+// MariaDB 재시도 가능 오류 코드
+const (
+	mysqlErrLockWaitTimeout = 1205 // ER_LOCK_WAIT_TIMEOUT
+	mysqlErrLockDeadlock    = 1213 // ER_LOCK_DEADLOCK
+)
 
+// ctx가 취소되면(종료 시그널 등) 접속 재시도 대기를 중단하고 ctx.Err()를 반환합니다.
+func NewMariaDBManager(ctx context.Context, creds *MariaDBCredentials, lgr *logger.AppLogger) (DBManager, error) {
 	lgr.Info().Str("connURL", MaskConnectionDSN(creds)).Msg("connecting to MariaDB")
 
-	db, err := sql.Open("mysql", dsn)
+	cfg, err := mariaDBConfig(creds)
+	if err != nil {
+		lgr.Error().Err(err).Msg("invalid DB connection options")
+		return nil, err
+	}
+
+	db, err := openMariaDB(cfg, creds, creds.Host, creds.Port)
 	if err != nil {
 		lgr.Error().Err(err).Msg("failed to prepare DB connection")
 		return nil, ErrClientInit
 	}
 
 	mgr := &MariaDBManager{
-		db:     db,
-		logger: lgr,
+		db:      db,
+		dialect: NewMySQLDialect(cfg.Loc),
+		logger:  lgr,
 	}
 
-	if err := mgr.Ping(); err != nil {
+	if err := mgr.connect(ctx, creds); err != nil {
 		// Ping 실패 시 생성된 db 객체를 닫아주는 것이 좋습니다.
 		db.Close()
 		return nil, err
 	}
 
-	db.SetConnMaxLifetime(time.Minute * 3)
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(10)
+	// replica 연결 준비 : 장애 상태의 replica는 상태 확인으로 복구될 때까지 사용하지 않는다
+	replicas := make(map[string]*sql.DB, len(creds.Replicas))
+	for _, addr := range creds.Replicas {
+		host, port, err := splitReplicaAddr(addr)
+		if err == nil {
+			replicas[addr], err = openMariaDB(cfg, creds, host, port)
+		}
+		if err != nil {
+			lgr.Error().Err(err).Str("replica", addr).Msg("failed to prepare replica connection")
+			for _, r := range replicas {
+				r.Close()
+			}
+			db.Close()
+			return nil, ErrClientInit
+		}
+	}
+	mgr.reader = newReadRouter(db, replicas, lgr)
 
 	return mgr, nil
 }
 
+// 접속 정보를 드라이버 설정으로 변환한다. 호스트 주소는 openMariaDB에서 지정한다.
+func mariaDBConfig(creds *MariaDBCredentials) (*mysql.Config, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = creds.User
+	cfg.Passwd = creds.Password
+	cfg.Net = "tcp"
+	cfg.DBName = creds.Database
+	cfg.ParseTime = true
+	cfg.Timeout = creds.DialTimeout
+	cfg.ReadTimeout = creds.ReadTimeout
+	cfg.WriteTimeout = creds.WriteTimeout
+	cfg.Collation = creds.Collation
+
+	if creds.Charset != "" {
+		if err := cfg.Apply(mysql.Charset(creds.Charset, creds.Collation)); err != nil {
+			return nil, err
+		}
+	}
+
+	if creds.Loc != "" {
+		loc, err := time.LoadLocation(creds.Loc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidLocation, err)
+		}
+		cfg.Loc = loc
+	}
+
+	tlsMode := creds.TLS
+	if tlsMode == "" && creds.TLSCAFile != "" {
+		tlsMode = "true"
+	}
+
+	switch tlsMode {
+	case "", "false":
+	case "true", "skip-verify", "preferred":
+		tlsConf := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: tlsMode != "true",
+		}
+		if creds.TLSCAFile != "" {
+			pem, err := os.ReadFile(creds.TLSCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidTLSConfig, err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("%w: no certificates in %s", ErrInvalidTLSConfig, creds.TLSCAFile)
+			}
+			tlsConf.RootCAs = pool
+		}
+		cfg.TLS = tlsConf
+		cfg.AllowFallbackToPlaintext = tlsMode == "preferred"
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %q", ErrInvalidTLSConfig, tlsMode)
+	}
+
+	return cfg, nil
+}
+
+// 지정한 호스트로 커넥션 풀을 만든다. primary와 replica가 같은 설정을 사용한다.
+func openMariaDB(base *mysql.Config, creds *MariaDBCredentials, host string, port int) (*sql.DB, error) {
+	cfg := base.Clone()
+	// 인증서의 호스트 이름 검증은 드라이버가 접속하는 호스트 기준으로 설정한다
+	cfg.Addr = net.JoinHostPort(host, strconv.Itoa(port))
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(connector)
+
+	db.SetMaxOpenConns(orDefault(creds.MaxOpenConns, defaultMaxOpenConns))
+	db.SetMaxIdleConns(orDefault(creds.MaxIdleConns, defaultMaxIdleConns))
+	db.SetConnMaxLifetime(orDefault(creds.ConnMaxLifetime, defaultConnMaxLifetime))
+	db.SetConnMaxIdleTime(creds.ConnMaxIdleTime)
+
+	return db, nil
+}
+
+func orDefault[T int | time.Duration](v, def T) T {
+	if v <= 0 {
+		return def
+	}
+	return v
+}
+
 // DB - DBTX 인터페이스를 반환합니다. *sql.DB는 DBTX를 구현하므로 그대로 반환할 수 있습니다.
 func (m *MariaDBManager) DB() DBTX {
 	return m.db
 }
 
+// ReadDB - 조회 전용 DBTX를 반환합니다. 읽기는 replica로 분배되고 쓰기는 primary에서 실행됩니다.
+func (m *MariaDBManager) ReadDB() DBTX {
+	return m.reader
+}
+
+// Stats - primary 및 replica 커넥션 풀 상태를 반환합니다.
+func (m *MariaDBManager) Stats() PoolStats {
+	return PoolStats{
+		Primary:  m.db.Stats(),
+		Replicas: m.reader.Stats(),
+	}
+}
+
+// Conn - 풀에서 전용 커넥션을 하나 꺼내 반환합니다. 사용 후 반드시 Close 해야 합니다.
+func (m *MariaDBManager) Conn(ctx context.Context) (*sql.Conn, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		m.logger.Error().Err(err).Msg("failed to get dedicated DB connection")
+		return nil, ErrConnectionEstablish
+	}
+	return conn, nil
+}
+
+// WithTx - 트랜잭션 안에서 fn을 실행하고, 데드락/잠금 대기 시간 초과 시 트랜잭션 전체를 재시도합니다.
+func (m *MariaDBManager) WithTx(ctx context.Context, fn func(tx DBTX) error) error {
+	return withTx(ctx, m.db, m.dialect, m.logger, fn)
+}
+
+// Dialect - MariaDB SQL 구문 차이를 반환합니다.
+func (m *MariaDBManager) Dialect() Dialect {
+	return m.dialect
+}
+
 func (m *MariaDBManager) Disconnect() error {
 	m.logger.Info().Msg("disconnecting from MariaDB")
+	if err := m.reader.Close(); err != nil {
+		m.logger.Error().Err(err).Msg("failed to close replica connections")
+	}
 	return m.db.Close()
 }
 
 func (m *MariaDBManager) Ping() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	return m.ping(context.Background())
+}
+
+func (m *MariaDBManager) ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 	if err := m.db.PingContext(ctx); err != nil {
 		m.logger.Error().Err(err).Msg("failed to ping DB")
@@ -109,6 +328,49 @@ func (m *MariaDBManager) Ping() error {
 	return nil
 }
 
+// 데이터베이스가 준비될 때까지 지수 백오프와 지터를 적용하여 접속을 재시도한다.
+// 컨테이너가 데이터베이스보다 먼저 기동되는 경우를 위한 것이다.
+func (m *MariaDBManager) connect(ctx context.Context, creds *MariaDBCredentials) error {
+	attempts := max(creds.ConnectAttempts, 1)
+	backoff := orDefault(creds.ConnectBackoff, defaultConnectBackoff)
+	maxBackoff := orDefault(creds.ConnectMaxBackoff, defaultConnectMaxBackoff)
+
+	for attempt := 1; ; attempt++ {
+		m.logger.Info().
+			Str("connURL", MaskConnectionDSN(creds)).
+			Int("attempt", attempt).
+			Int("maxAttempts", attempts).
+			Msg("pinging MariaDB")
+
+		err := m.ping(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= attempts {
+			return err
+		}
+
+		// [backoff/2, backoff) 범위에서 무작위로 대기하여 여러 인스턴스가 동시에 재접속하지 않도록 한다
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+		m.logger.Info().
+			Str("connURL", MaskConnectionDSN(creds)).
+			Int("attempt", attempt).
+			Dur("retryIn", wait).
+			Msg("MariaDB is not ready, retrying")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
 func MaskConnectionDSN(creds *MariaDBCredentials) string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s",
 		"******",
@@ -0,0 +1,313 @@
+package db
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/model/external"
+)
+
+// 디바이스 캐시 기본값
+const (
+	defaultDeviceCacheSize        = 10000
+	defaultDeviceCacheTTL         = 30 * time.Second
+	defaultDeviceCacheNegativeTTL = 5 * time.Second
+)
+
+var ErrInvalidDeviceCacheRequired = errors.New("missing required inputs to create DeviceCache")
+
+type DeviceCacheConfig struct {
+	Size        int           // 최대 보관 디바이스 수, 초과 시 가장 오래 사용되지 않은 항목부터 제거 (기본값 10000)
+	TTL         time.Duration // 조회 결과 보관 시간 (기본값 30초)
+	NegativeTTL time.Duration // 존재하지 않는 디바이스 조회 결과 보관 시간 (기본값 5초)
+}
+
+// DeviceCacheStats는 캐시 적중 현황입니다. (기동 이후 누적)
+type DeviceCacheStats struct {
+	Size         int     `json:"size"`
+	Capacity     int     `json:"capacity"`
+	Hits         int64   `json:"hits"`
+	NegativeHits int64   `json:"negativeHits"` // 존재하지 않는 디바이스 조회 적중
+	Misses       int64   `json:"misses"`
+	Evictions    int64   `json:"evictions"` // 용량 초과로 제거된 항목 수
+	HitRatio     float64 `json:"hitRatio"`
+}
+
+type deviceCacheEntry struct {
+	productNumber string
+	device        *data.Device // nil이면 존재하지 않는 디바이스
+	expiresAt     time.Time
+	seq           uint64 // 보관 순번 (변경 이후에 보관된 항목인지 판단)
+}
+
+// DeviceCache는 DevicesRepo의 GetByID 결과를 보관하는 read-through 캐시입니다.
+// 크기 제한(LRU)과 보관 시간(TTL)을 두며, 존재하지 않는 디바이스도 짧게 보관하여 반복 조회를 막습니다.
+// 이 인스턴스를 통한 Create, Delete 및 등록 정보 변경 시 해당 디바이스를 캐시에서 제거하고,
+// 보고로 바뀌는 값(마지막 보고 시간, 상태, 재시도 횟수)만 바꾸는 Update는 보관된 항목에 그대로 반영합니다.
+// 트랜잭션 안의 변경은 커밋된 뒤에 반영하며, 다른 인스턴스에서 변경된 값은 TTL 동안 이전 값으로 보일 수 있습니다.
+type DeviceCache struct {
+	inner DevicesDataService
+	cfg   DeviceCacheConfig
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // 앞쪽이 최근 사용
+	seq     uint64     // 마지막 보관 순번
+
+	hits         atomic.Int64
+	negativeHits atomic.Int64
+	misses       atomic.Int64
+	evictions    atomic.Int64
+}
+
+// DeviceCacheStatsProvider는 디바이스 조회 캐시 현황을 제공하는 인터페이스입니다.
+type DeviceCacheStatsProvider interface {
+	Stats() DeviceCacheStats
+}
+
+// 컴파일 타임에 DeviceCache가 DevicesDataService 인터페이스를 구현하는지 확인합니다.
+var _ DevicesDataService = (*DeviceCache)(nil)
+var _ DeviceCacheStatsProvider = (*DeviceCache)(nil)
+
+func NewDeviceCache(inner DevicesDataService, cfg DeviceCacheConfig) (*DeviceCache, error) {
+	if inner == nil {
+		return nil, ErrInvalidDeviceCacheRequired
+	}
+
+	cfg.Size = orDefault(cfg.Size, defaultDeviceCacheSize)
+	cfg.TTL = orDefault(cfg.TTL, defaultDeviceCacheTTL)
+	cfg.NegativeTTL = orDefault(cfg.NegativeTTL, defaultDeviceCacheNegativeTTL)
+
+	return &DeviceCache{
+		inner:   inner,
+		cfg:     cfg,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}, nil
+}
+
+// GetByID - 캐시에 있으면 복사본을 반환하고, 없으면 저장소에서 읽어 보관합니다.
+// 데이터베이스 장애 등 조회 오류는 보관하지 않습니다.
+func (c *DeviceCache) GetByID(ctx context.Context, productNumber string) (*data.Device, error) {
+	if device, found, ok := c.lookup(productNumber); ok {
+		if !found {
+			c.negativeHits.Add(1)
+			return nil, fmt.Errorf("%w: %w", ErrFailedToSelectDevice, ErrDeviceNotFound)
+		}
+		c.hits.Add(1)
+		return device, nil
+	}
+	c.misses.Add(1)
+
+	device, err := c.inner.GetByID(ctx, productNumber)
+	switch {
+	case err == nil:
+		c.store(productNumber, device, c.cfg.TTL)
+		copied := *device
+		return &copied, nil
+	case errors.Is(err, ErrDeviceNotFound):
+		c.store(productNumber, nil, c.cfg.NegativeTTL)
+	}
+	return device, err
+}
+
+func (c *DeviceCache) GetAll(ctx context.Context) (*[]data.Device, error) {
+	return c.inner.GetAll(ctx)
+}
+
+// LockByIDs - 잠금 조회는 트랜잭션의 최신 값이 필요하므로 캐시를 거치지 않습니다.
+func (c *DeviceCache) LockByIDs(ctx context.Context, productNumbers []string) (map[string]data.Device, error) {
+	return c.inner.LockByIDs(ctx, productNumbers)
+}
+
+func (c *DeviceCache) Create(ctx context.Context, di *data.Device) (string, error) {
+	id, err := c.inner.Create(ctx, di)
+	c.Invalidate(di.ProductNumber)
+	return id, err
+}
+
+func (c *DeviceCache) Update(ctx context.Context, ID string, parmas *external.UpdateDeviceParams) error {
+	since := c.mark()
+	err := c.inner.Update(ctx, ID, parmas)
+	c.apply(ID, parmas, since, err)
+	return err
+}
+
+func (c *DeviceCache) Delete(ctx context.Context, ID string) error {
+	err := c.inner.Delete(ctx, ID)
+	c.Invalidate(ID)
+	return err
+}
+
+// WithTx - 트랜잭션 안의 조회는 캐시를 거치지 않고, 변경은 트랜잭션이 커밋된 뒤 캐시에 반영합니다.
+// 커밋 전에 제거하면 그 사이 다른 요청이 이전 값이나 "존재하지 않음"을 다시 보관할 수 있기 때문입니다.
+func (c *DeviceCache) WithTx(tx DBTX) DevicesDataService {
+	return &txDeviceCache{DevicesDataService: c.inner.WithTx(tx), cache: c, tx: tx}
+}
+
+// Invalidate - 디바이스를 캐시에서 제거합니다.
+func (c *DeviceCache) Invalidate(productNumber string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[productNumber]; ok {
+		c.lru.Remove(elem)
+		delete(c.entries, productNumber)
+	}
+}
+
+// 현재 보관 순번. 이후 변경을 반영할 때 이 순번 이후에 보관된 항목은 변경 전 값인지 알 수 없어 제거한다.
+func (c *DeviceCache) mark() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seq
+}
+
+// 디바이스 변경을 캐시에 반영한다.
+// 보고로 바뀌는 값만 변경되었고 보관된 항목이 변경 전(since 이전)에 읽은 값이면 항목에 그대로 반영하고, 그 외에는 제거한다.
+func (c *DeviceCache) apply(productNumber string, params *external.UpdateDeviceParams, since uint64, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[productNumber]
+	if !ok {
+		return
+	}
+
+	entry := elem.Value.(*deviceCacheEntry)
+	if err != nil || entry.device == nil || entry.seq > since || !reportOnlyUpdate(params) {
+		c.lru.Remove(elem)
+		delete(c.entries, productNumber)
+		return
+	}
+
+	if params.LastSeenAt != nil {
+		entry.device.LastSeenAt = *params.LastSeenAt
+	}
+	if params.Status != nil {
+		entry.device.Status = *params.Status
+	}
+	switch {
+	case params.ReTry != nil:
+		entry.device.ReTry = *params.ReTry
+	case params.ReTryAdd != nil:
+		entry.device.ReTry += *params.ReTryAdd
+	}
+}
+
+// 보고 수신으로 바뀌는 값(마지막 보고 시간, 상태, 재시도 횟수)만 변경하는지 확인한다.
+func reportOnlyUpdate(params *external.UpdateDeviceParams) bool {
+	return params.FirmwareVersion == nil && params.UpdateCheck == nil
+}
+
+// Stats - 캐시 크기 및 적중 현황을 반환합니다.
+func (c *DeviceCache) Stats() DeviceCacheStats {
+	c.mu.Lock()
+	size := c.lru.Len()
+	c.mu.Unlock()
+
+	stats := DeviceCacheStats{
+		Size:         size,
+		Capacity:     c.cfg.Size,
+		Hits:         c.hits.Load(),
+		NegativeHits: c.negativeHits.Load(),
+		Misses:       c.misses.Load(),
+		Evictions:    c.evictions.Load(),
+	}
+	if total := stats.Hits + stats.NegativeHits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits+stats.NegativeHits) / float64(total)
+	}
+	return stats
+}
+
+// 보관된 항목을 찾는다. ok가 false이면 없거나 만료된 것이고, found가 false이면 존재하지 않는 디바이스이다.
+func (c *DeviceCache) lookup(productNumber string) (device *data.Device, found, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, exists := c.entries[productNumber]
+	if !exists {
+		return nil, false, false
+	}
+
+	entry := elem.Value.(*deviceCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.lru.Remove(elem)
+		delete(c.entries, productNumber)
+		return nil, false, false
+	}
+
+	c.lru.MoveToFront(elem)
+	if entry.device == nil {
+		return nil, false, true
+	}
+
+	// 호출자가 값을 바꿔도 캐시에 영향이 없도록 복사본을 반환한다
+	copied := *entry.device
+	return &copied, true, true
+}
+
+func (c *DeviceCache) store(productNumber string, device *data.Device, ttl time.Duration) {
+	var stored *data.Device
+	if device != nil {
+		copied := *device
+		stored = &copied
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.seq++
+	entry := &deviceCacheEntry{productNumber: productNumber, device: stored, expiresAt: time.Now().Add(ttl), seq: c.seq}
+	if elem, ok := c.entries[productNumber]; ok {
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.entries[productNumber] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.cfg.Size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*deviceCacheEntry).productNumber)
+		c.evictions.Add(1)
+	}
+}
+
+// txDeviceCache는 트랜잭션에 바인딩된 DevicesDataService로, 변경된 디바이스를 커밋 후 캐시에 반영합니다.
+type txDeviceCache struct {
+	DevicesDataService
+	cache *DeviceCache
+	tx    DBTX
+}
+
+func (t *txDeviceCache) Create(ctx context.Context, di *data.Device) (string, error) {
+	id, err := t.DevicesDataService.Create(ctx, di)
+	productNumber := di.ProductNumber
+	AfterCommit(t.tx, func() { t.cache.Invalidate(productNumber) })
+	return id, err
+}
+
+func (t *txDeviceCache) Update(ctx context.Context, ID string, parmas *external.UpdateDeviceParams) error {
+	since := t.cache.mark()
+	err := t.DevicesDataService.Update(ctx, ID, parmas)
+	params := *parmas
+	AfterCommit(t.tx, func() { t.cache.apply(ID, &params, since, err) })
+	return err
+}
+
+func (t *txDeviceCache) Delete(ctx context.Context, ID string) error {
+	err := t.DevicesDataService.Delete(ctx, ID)
+	AfterCommit(t.tx, func() { t.cache.Invalidate(ID) })
+	return err
+}
+
+func (t *txDeviceCache) WithTx(tx DBTX) DevicesDataService {
+	return t.cache.WithTx(tx)
+}
@@ -0,0 +1,327 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/model/external"
+)
+
+// fakeDevicesRepo는 GetByID 호출 횟수를 세는 DevicesDataService입니다.
+type fakeDevicesRepo struct {
+	DevicesDataService
+	devices map[string]data.Device
+	gets    int
+}
+
+func (f *fakeDevicesRepo) GetByID(_ context.Context, productNumber string) (*data.Device, error) {
+	f.gets++
+	device, ok := f.devices[productNumber]
+	if !ok {
+		return nil, ErrDeviceNotFound
+	}
+	return &device, nil
+}
+
+func (f *fakeDevicesRepo) Update(_ context.Context, ID string, params *external.UpdateDeviceParams) error {
+	device := f.devices[ID]
+	if params.FirmwareVersion != nil {
+		device.FirmwareVersion = *params.FirmwareVersion
+	}
+	f.devices[ID] = device
+	return nil
+}
+
+func (f *fakeDevicesRepo) Create(_ context.Context, di *data.Device) (string, error) {
+	f.devices[di.ProductNumber] = *di
+	return di.ProductNumber, nil
+}
+
+func (f *fakeDevicesRepo) WithTx(DBTX) DevicesDataService {
+	return f
+}
+
+func newFakeDevicesRepo(productNumbers ...string) *fakeDevicesRepo {
+	f := &fakeDevicesRepo{devices: make(map[string]data.Device)}
+	for _, pn := range productNumbers {
+		f.devices[pn] = data.Device{ProductNumber: pn, FirmwareVersion: "1.0.0"}
+	}
+	return f
+}
+
+func TestDeviceCacheHit(t *testing.T) {
+	ctx := context.Background()
+	inner := newFakeDevicesRepo("ABC010001")
+	cache, err := NewDeviceCache(inner, DeviceCacheConfig{Size: 10, TTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		if _, err := cache.GetByID(ctx, "ABC010001"); err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+	}
+	if inner.gets != 1 {
+		t.Errorf("inner GetByID called %d times, want 1", inner.gets)
+	}
+
+	// 반환된 값을 바꿔도 캐시에는 영향이 없다
+	device, _ := cache.GetByID(ctx, "ABC010001")
+	device.FirmwareVersion = "changed"
+	if device, _ := cache.GetByID(ctx, "ABC010001"); device.FirmwareVersion != "1.0.0" {
+		t.Errorf("cached FirmwareVersion = %q, want 1.0.0", device.FirmwareVersion)
+	}
+
+	// 이 캐시를 통해 변경하면 다음 조회는 저장소에서 읽는다
+	version := "2.0.0"
+	if err := cache.Update(ctx, "ABC010001", &external.UpdateDeviceParams{FirmwareVersion: &version}); err != nil {
+		t.Fatal(err)
+	}
+	if device, _ := cache.GetByID(ctx, "ABC010001"); device.FirmwareVersion != version || inner.gets != 2 {
+		t.Errorf("after Update: FirmwareVersion = %q, inner gets = %d", device.FirmwareVersion, inner.gets)
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 4 || stats.Misses != 2 || stats.Size != 1 || stats.Capacity != 10 {
+		t.Errorf("Stats = %+v", stats)
+	}
+}
+
+func TestDeviceCacheLRU(t *testing.T) {
+	ctx := context.Background()
+	inner := newFakeDevicesRepo("ABC010001", "ABC010002", "ABC010003")
+	cache, _ := NewDeviceCache(inner, DeviceCacheConfig{Size: 2, TTL: time.Minute})
+
+	cache.GetByID(ctx, "ABC010001")
+	cache.GetByID(ctx, "ABC010002")
+	cache.GetByID(ctx, "ABC010001") // ABC010002가 가장 오래 사용되지 않은 항목이 된다
+	cache.GetByID(ctx, "ABC010003")
+
+	if stats := cache.Stats(); stats.Size != 2 || stats.Evictions != 1 {
+		t.Errorf("Stats = %+v, want size 2 and 1 eviction", stats)
+	}
+
+	inner.gets = 0
+	cache.GetByID(ctx, "ABC010001")
+	cache.GetByID(ctx, "ABC010003")
+	if inner.gets != 0 {
+		t.Errorf("recently used devices were evicted (inner gets = %d)", inner.gets)
+	}
+	cache.GetByID(ctx, "ABC010002")
+	if inner.gets != 1 {
+		t.Errorf("least recently used device was not evicted (inner gets = %d)", inner.gets)
+	}
+}
+
+func TestDeviceCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	inner := newFakeDevicesRepo("ABC010001")
+	cache, _ := NewDeviceCache(inner, DeviceCacheConfig{TTL: 20 * time.Millisecond, NegativeTTL: time.Minute})
+
+	cache.GetByID(ctx, "ABC010001")
+	cache.GetByID(ctx, "ABC010001")
+	if inner.gets != 1 {
+		t.Fatalf("inner gets = %d before expiry, want 1", inner.gets)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	cache.GetByID(ctx, "ABC010001")
+	if inner.gets != 2 {
+		t.Errorf("inner gets = %d after expiry, want 2", inner.gets)
+	}
+}
+
+func TestDeviceCacheNegative(t *testing.T) {
+	ctx := context.Background()
+	inner := newFakeDevicesRepo()
+	cache, _ := NewDeviceCache(inner, DeviceCacheConfig{TTL: time.Minute, NegativeTTL: 20 * time.Millisecond})
+
+	// 존재하지 않는 디바이스도 NegativeTTL 동안 보관하며 같은 오류를 반환한다
+	for range 3 {
+		if _, err := cache.GetByID(ctx, "ABC019999"); !errors.Is(err, ErrDeviceNotFound) {
+			t.Fatalf("GetByID: err = %v, want ErrDeviceNotFound", err)
+		}
+	}
+	if inner.gets != 1 {
+		t.Errorf("inner gets = %d, want 1", inner.gets)
+	}
+	if stats := cache.Stats(); stats.NegativeHits != 2 {
+		t.Errorf("NegativeHits = %d, want 2", stats.NegativeHits)
+	}
+
+	// 보관 시간이 지나면 새로 등록된 디바이스를 조회한다
+	time.Sleep(30 * time.Millisecond)
+	inner.devices["ABC019999"] = data.Device{ProductNumber: "ABC019999"}
+	if _, err := cache.GetByID(ctx, "ABC019999"); err != nil {
+		t.Errorf("GetByID after NegativeTTL: %v", err)
+	}
+}
+
+func TestDeviceCacheSkipsErrors(t *testing.T) {
+	ctx := context.Background()
+	inner := &failingDevicesRepo{err: ErrUnavailable}
+	cache, _ := NewDeviceCache(inner, DeviceCacheConfig{})
+
+	// 데이터베이스 장애 등 조회 오류는 보관하지 않는다
+	for range 2 {
+		if _, err := cache.GetByID(ctx, "ABC010001"); !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("GetByID: err = %v, want ErrUnavailable", err)
+		}
+	}
+	if inner.gets != 2 {
+		t.Errorf("inner gets = %d, want 2", inner.gets)
+	}
+	if stats := cache.Stats(); stats.Size != 0 {
+		t.Errorf("Size = %d, want 0", stats.Size)
+	}
+}
+
+type failingDevicesRepo struct {
+	DevicesDataService
+	err  error
+	gets int
+}
+
+func (f *failingDevicesRepo) GetByID(context.Context, string) (*data.Device, error) {
+	f.gets++
+	return nil, f.err
+}
+
+// fakeTx는 commit 시 등록된 커밋 후 작업을 실행하는 트랜잭션입니다.
+type fakeTx struct {
+	DBTX
+	hooks []func()
+}
+
+func (f *fakeTx) onCommit(fn func()) {
+	f.hooks = append(f.hooks, fn)
+}
+
+func (f *fakeTx) commit() {
+	for _, hook := range f.hooks {
+		hook()
+	}
+}
+
+func TestDeviceCacheTx(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		lgr := testLogger()
+		repo, _ := NewDevicesRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+		reports, _ := NewReportsRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+		cache, err := NewDeviceCache(repo, DeviceCacheConfig{Size: 10, TTL: time.Minute})
+		if err != nil {
+			t.Fatal(err)
+		}
+		txMgr := NewCircuitBreaker(lgr, BreakerConfig{}).WrapTx(mgr)
+		createTestDevices(t, repo, newTestDevice("ABC010001", "1.0.0"))
+
+		// 보고 → 배치 기록 → 다음 보고: 보고로 바뀌는 값은 캐시에 반영되어 다시 읽지 않는다
+		if _, err := cache.GetByID(ctx, "ABC010001"); err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		reportAt, status, one := testNow.Add(time.Minute), data.ReportError, 1
+		err = txMgr.WithTx(ctx, func(tx DBTX) error {
+			if err := reports.WithTx(tx).CreateBatch(ctx, []data.DeviceInfo{newTestReport("ABC010001", 80, reportAt)}); err != nil {
+				return err
+			}
+			return cache.WithTx(tx).Update(ctx, "ABC010001", &external.UpdateDeviceParams{LastSeenAt: &reportAt, Status: &status, ReTryAdd: &one})
+		})
+		if err != nil {
+			t.Fatalf("WithTx: %v", err)
+		}
+		device, err := cache.GetByID(ctx, "ABC010001")
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if !device.LastSeenAt.Equal(reportAt) || device.Status != status || device.ReTry != 1 {
+			t.Errorf("cached device after flush = %+v", device)
+		}
+		if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+			t.Errorf("Stats = %+v, want 1 hit and 1 miss", stats)
+		}
+
+		// 롤백된 변경은 캐시에 반영하지 않는다
+		errAbort := errors.New("abort")
+		err = txMgr.WithTx(ctx, func(tx DBTX) error {
+			if err := cache.WithTx(tx).Update(ctx, "ABC010001", &external.UpdateDeviceParams{ReTryAdd: &one}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("WithTx: err = %v, want errAbort", err)
+		}
+		if device, _ := cache.GetByID(ctx, "ABC010001"); device.ReTry != 1 {
+			t.Errorf("ReTry after rollback = %d, want 1", device.ReTry)
+		}
+
+		// 등록 정보 변경은 커밋 후 캐시에서 제거하여 다음 조회에서 다시 읽는다
+		version := "1.1.0"
+		err = txMgr.WithTx(ctx, func(tx DBTX) error {
+			return cache.WithTx(tx).Update(ctx, "ABC010001", &external.UpdateDeviceParams{FirmwareVersion: &version})
+		})
+		if err != nil {
+			t.Fatalf("WithTx: %v", err)
+		}
+		if device, _ := cache.GetByID(ctx, "ABC010001"); device.FirmwareVersion != version || device.ReTry != 1 {
+			t.Errorf("after firmware update: %+v", device)
+		}
+		if stats := cache.Stats(); stats.Misses != 2 {
+			t.Errorf("Misses = %d, want 2", stats.Misses)
+		}
+	})
+}
+
+func TestDeviceCacheTxAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	inner := newFakeDevicesRepo("ABC010001")
+	cache, _ := NewDeviceCache(inner, DeviceCacheConfig{Size: 10, TTL: time.Minute, NegativeTTL: time.Minute})
+
+	// 등록 중인 디바이스를 커밋 전에 "존재하지 않음"으로 보관해도 커밋 후 제거된다
+	tx := &fakeTx{}
+	device := data.Device{ProductNumber: "ABC010002"}
+	cache.GetByID(ctx, "ABC010002")
+	if _, err := cache.WithTx(tx).Create(ctx, &device); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.GetByID(ctx, "ABC010002"); !errors.Is(err, ErrDeviceNotFound) {
+		t.Fatalf("GetByID before commit: err = %v, want cached ErrDeviceNotFound", err)
+	}
+	tx.commit()
+	if _, err := cache.GetByID(ctx, "ABC010002"); err != nil {
+		t.Errorf("GetByID after commit: %v", err)
+	}
+
+	// 변경 이후 커밋 전에 보관된 값은 변경 전 값인지 알 수 없으므로 반영하지 않고 제거한다
+	tx = &fakeTx{}
+	reportAt, one := testNow, 1
+	if err := cache.WithTx(tx).Update(ctx, "ABC010001", &external.UpdateDeviceParams{LastSeenAt: &reportAt, ReTryAdd: &one}); err != nil {
+		t.Fatal(err)
+	}
+	cache.GetByID(ctx, "ABC010001")
+	tx.commit()
+	gets := inner.gets
+	cache.GetByID(ctx, "ABC010001")
+	if inner.gets != gets+1 {
+		t.Error("entry stored before commit was not removed")
+	}
+
+	// 변경 전에 보관된 값에는 보고로 바뀌는 값만 반영한다
+	tx = &fakeTx{}
+	if err := cache.WithTx(tx).Update(ctx, "ABC010001", &external.UpdateDeviceParams{LastSeenAt: &reportAt, ReTryAdd: &one}); err != nil {
+		t.Fatal(err)
+	}
+	if device, _ := cache.GetByID(ctx, "ABC010001"); device.ReTry != 0 {
+		t.Errorf("ReTry before commit = %d, want 0", device.ReTry)
+	}
+	tx.commit()
+	gets = inner.gets
+	if device, _ := cache.GetByID(ctx, "ABC010001"); device.ReTry != 1 || !device.LastSeenAt.Equal(reportAt) || inner.gets != gets {
+		t.Errorf("after commit: %+v, inner gets = %d, want %d", device, inner.gets, gets)
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"strconv"
@@ -19,6 +20,7 @@ var (
 	ErrFailedToSelectDevice 		  = errors.New("failed to select device")
 	ErrFailedToUpdateDevice 		  = errors.New("failed to update device")
 	ErrFailedToDeleteDevice 	      = errors.New("failed to delete device")
+	ErrDeviceNotFound                 = errors.New("device not found")
 )
 
 // DeviceRepo를 통해 사용할 메서드를 제약하고 규정하기 위한 인터페이스 
@@ -27,38 +29,59 @@ type DevicesDataService interface {
 	Create(ctx context.Context, di *data.Device) (string, error) 
 	GetAll(ctx context.Context) (*[]data.Device, error)
 	GetByID(ctx context.Context, ID string) (*data.Device, error)
-	update(ctx context.Context, ID string, parmas *external.UpdateDeviceParams) error
+	LockByIDs(ctx context.Context, IDs []string) (map[string]data.Device, error)
+	Update(ctx context.Context, ID string, parmas *external.UpdateDeviceParams) error
 	Delete(ctx context.Context, ID string) error
+	WithTx(tx DBTX) DevicesDataService
 }
 
 // Device 테이블을 접근하기 위한 커넥션 관리
 type DevicesRepo struct {
 	connection DBTX
+	reader     DBTX // 목록/이력 조회용 (replica 우선)
+	dialect    Dialect
 	logger     *logger.AppLogger
 }
 
-func NewDevicesRepo(lgr *logger.AppLogger, db DBTX) (*DevicesRepo, error) {
-	if lgr == nil || db == nil {
+func NewDevicesRepo(lgr *logger.AppLogger, db DBTX, reader DBTX, dialect Dialect) (*DevicesRepo, error) {
+	if lgr == nil || db == nil || reader == nil || dialect == nil {
 		return nil, ErrInvalidReportRequired
 	}
 	return &DevicesRepo{
 		connection: db,
+		reader:     reader,
+		dialect:    dialect,
 		logger:     lgr,
 	}, nil
 }
 
+// 트랜잭션에 바인딩된 DevicesRepo 반환
+// 트랜잭션 안의 조회는 방금 쓴 값을 읽어야 하므로 reader도 tx를 사용한다.
+func (d *DevicesRepo) WithTx(tx DBTX) DevicesDataService {
+	return &DevicesRepo{
+		connection: tx,
+		reader:     tx,
+		dialect:    d.dialect,
+		logger:     d.logger,
+	}
+}
+
 func (d *DevicesRepo) Create(ctx context.Context, di *data.Device)(string, error){
 	// 쿼리문 생성
 	query := "INSERT INTO devices " +
-	"( ProductNumber, MacAddress, FirmwareVersion, LastSeenAt, CreatedAt, ReTry, UpdateCheck, Status)" +
-	"VALUES ( ?, ?, ?, ?, ?, ?, ?, ?)"
+	"( ProductNumber, MacAddress, ProductLine, HardwareRevision, FirmwareVersion, LastSeenAt, CreatedAt, ReTry, UpdateCheck, Status)" +
+	"VALUES ( ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
 
 	// 쿼리문 실행
-	result, err := d.connection.ExecContext(
+	lastID, err := d.dialect.InsertID(
 		ctx, 
+		d.connection,
 		query, 
+		"InternalID",
 		di.ProductNumber,
 		di.MacAddress,
+		di.ProductLine,
+		di.HardwareRevision,
 		di.FirmwareVersion,
 		di.LastSeenAt,
 		di.CreatedAt,
@@ -72,18 +95,13 @@ func (d *DevicesRepo) Create(ctx context.Context, di *data.Device)(string, error
 		return "", ErrFailedToCreateDevice
 	}
 
-	lastID, err := result.LastInsertId()
-	if err != nil {
-		return "", ErrFailedToCreateDevice
-	}
-
 	return strconv.FormatInt(lastID, 10), nil
 }
 
 func (d *DevicesRepo) GetAll(ctx context.Context) (*[]data.Device, error){
-	query := "SELECT InternalID, ProductNumber, MacAddress, FirmwareVersion, LastSeenAt, CreatedAt, ReTry, UpdateCheck, Status from devices"
+	query := "SELECT InternalID, ProductNumber, MacAddress, ProductLine, HardwareRevision, FirmwareVersion, LastSeenAt, CreatedAt, ReTry, UpdateCheck, Status from devices"
 
-	rows, err := d.connection.QueryContext(ctx, query)
+	rows, err := d.reader.QueryContext(ctx, query)
 	if err != nil {
 		d.logger.Error().Err(err).Msg("failed to select device_info")
 		return nil, ErrFailedToSelectReportInfo
@@ -100,6 +118,8 @@ func (d *DevicesRepo) GetAll(ctx context.Context) (*[]data.Device, error){
 			&device.InternalID,
 			&device.ProductNumber,
 			&device.MacAddress,
+			&device.ProductLine,
+			&device.HardwareRevision,
 			&device.FirmwareVersion,
 			&device.LastSeenAt,
 			&device.CreatedAt,
@@ -125,15 +145,17 @@ func (d *DevicesRepo) GetAll(ctx context.Context) (*[]data.Device, error){
 
 func (d *DevicesRepo) GetByID(ctx context.Context, productNumber string) (*data.Device, error){
 	//
-	query := "SELECT InternalID, ProductNumber, MacAddress, FirmwareVersion, LastSeenAt, CreatedAt, ReTry, UpdateCheck, Status from devices WHERE ProductNumber = ?"
+	query := "SELECT InternalID, ProductNumber, MacAddress, ProductLine, HardwareRevision, FirmwareVersion, LastSeenAt, CreatedAt, ReTry, UpdateCheck, Status from devices WHERE ProductNumber = ?"
 
-	row := d.connection.QueryRowContext(ctx, query, productNumber)
+	row := d.reader.QueryRowContext(ctx, d.dialect.Rebind(query), productNumber)
 
 	var device data.Device
 	err := row.Scan(
 		&device.InternalID,
 		&device.ProductNumber,
 		&device.MacAddress,
+		&device.ProductLine,
+		&device.HardwareRevision,
 		&device.FirmwareVersion,
 		&device.LastSeenAt,
 		&device.CreatedAt,
@@ -143,13 +165,74 @@ func (d *DevicesRepo) GetByID(ctx context.Context, productNumber string) (*data.
 	)
 
 	 if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToSelectDevice, ErrDeviceNotFound)
+		}
+		if IsUnavailable(err) {
+			d.logger.Error().Err(err).Msg("failed to select device")
+			return nil, fmt.Errorf("%w: %w", ErrFailedToSelectDevice, ErrUnavailable)
+		}
 		return nil, ErrFailedToSelectDevice
 	 }
 
 	 return &device, nil
 }
 
-func (d *DevicesRepo) update(ctx context.Context, ID string, parmas *external.UpdateDeviceParams) error{
+// 트랜잭션 안에서 디바이스들을 조회하고 커밋까지 잠근다. 존재하지 않는 디바이스는 결과에 포함되지 않는다.
+// 여러 트랜잭션이 같은 디바이스를 잠글 때 교착을 줄이기 위해 제품 번호 순서로 잠근다.
+func (d *DevicesRepo) LockByIDs(ctx context.Context, productNumbers []string) (map[string]data.Device, error){
+	devices := make(map[string]data.Device, len(productNumbers))
+	if len(productNumbers) == 0 {
+		return devices, nil
+	}
+
+	args := make([]interface{}, len(productNumbers))
+	for i, pn := range productNumbers {
+		args[i] = pn
+	}
+	query := "SELECT InternalID, ProductNumber, MacAddress, ProductLine, HardwareRevision, FirmwareVersion, LastSeenAt, CreatedAt, ReTry, UpdateCheck, Status from devices " +
+		"WHERE ProductNumber IN (?" + strings.Repeat(", ?", len(productNumbers)-1) + ") ORDER BY ProductNumber " + d.dialect.ForUpdate()
+
+	rows, err := d.connection.QueryContext(ctx, d.dialect.Rebind(query), args...)
+	if err != nil {
+		d.logger.Error().Err(err).Msg("failed to lock devices")
+		if IsUnavailable(err) {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToSelectDevice, ErrUnavailable)
+		}
+		return nil, ErrFailedToSelectDevice
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var device data.Device
+		err := rows.Scan(
+			&device.InternalID,
+			&device.ProductNumber,
+			&device.MacAddress,
+			&device.ProductLine,
+			&device.HardwareRevision,
+			&device.FirmwareVersion,
+			&device.LastSeenAt,
+			&device.CreatedAt,
+			&device.ReTry,
+			&device.UpdateCheck,
+			&device.Status,
+		)
+		if err != nil {
+			d.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, ErrFailedToSelectDevice
+		}
+		devices[device.ProductNumber] = device
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrFailedToSelectDevice
+	}
+
+	return devices, nil
+}
+
+func (d *DevicesRepo) Update(ctx context.Context, ID string, parmas *external.UpdateDeviceParams) error{
 
 	query, args := d.GenerateUpdateQuery(parmas)
 	if query == "" || args == nil {
@@ -163,6 +246,9 @@ func (d *DevicesRepo) update(ctx context.Context, ID string, parmas *external.Up
     result, err := d.connection.ExecContext(ctx, query, args...)
     if err != nil {
         d.logger.Error().Err(err).Msg("failed to update device")
+        if IsUnavailable(err) {
+            return fmt.Errorf("%w: %w", ErrFailedToUpdateDevice, ErrUnavailable)
+        }
         return ErrFailedToUpdateDevice
     }
 
@@ -182,7 +268,7 @@ func (d *DevicesRepo) update(ctx context.Context, ID string, parmas *external.Up
 func (d *DevicesRepo) Delete(ctx context.Context, productNumber string)  error {
 	query := "DELETE FROM devices WHERE ProductNumber = ? "
 
-	result, err := d.connection.ExecContext(ctx, query, productNumber)
+	result, err := d.connection.ExecContext(ctx, d.dialect.Rebind(query), productNumber)
 	if err != nil {
 		d.logger.Error().Err(err).Msg("failed to delete devices")
 		return ErrFailedToDeleteDevice
@@ -202,30 +288,35 @@ func (d *DevicesRepo) GenerateUpdateQuery(params *external.UpdateDeviceParams) (
 	setClauses := []string{}
 	args := []interface{}{}
 
-	// 2. 파라미터로 받은 값들을 확인하며 쿼리 조립 (MySQL용 ? 플레이스홀더 사용)
+	// 1. 컬럼과 값을 함께 추가 (플레이스홀더 번호는 Dialect가 결정 : ? 또는 $n)
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		setClauses = append(setClauses, column+" = "+d.dialect.Placeholder(len(args)))
+	}
+
+	// 2. 파라미터로 받은 값들을 확인하며 쿼리 조립
 	if params.FirmwareVersion != nil {
-		setClauses = append(setClauses, "FirmwareVersion = ?")
-		args = append(args, *params.FirmwareVersion)
+		set("FirmwareVersion", *params.FirmwareVersion)
 	}
 
 	if params.LastSeenAt != nil {
-		setClauses = append(setClauses, "LastSeenAt = ?")
-		args = append(args, *params.LastSeenAt)
+		set("LastSeenAt", *params.LastSeenAt)
 	}
 
+	// 재시도 횟수 증가는 저장된 값을 기준으로 하여 동시에 갱신되어도 누락되지 않도록 한다
 	if params.ReTry != nil {
-		setClauses = append(setClauses, "ReTry = ?")
-		args = append(args, *params.ReTry)
+		set("ReTry", *params.ReTry)
+	} else if params.ReTryAdd != nil {
+		args = append(args, *params.ReTryAdd)
+		setClauses = append(setClauses, "ReTry = ReTry + "+d.dialect.Placeholder(len(args)))
 	}
 
 	if params.UpdateCheck != nil {
-		setClauses = append(setClauses, "UpdateCheck = ?")
-		args = append(args, *params.UpdateCheck)
+		set("UpdateCheck", *params.UpdateCheck)
 	}
 
 	if params.Status != nil {
-		setClauses = append(setClauses, "Status = ?")
-		args = append(args, *params.Status)
+		set("Status", *params.Status)
 	}
 
 	// 3. 변경할 내용이 없으면 아무것도 하지 않고 종료
@@ -235,8 +326,9 @@ func (d *DevicesRepo) GenerateUpdateQuery(params *external.UpdateDeviceParams) (
 
 	// 4. 최종 쿼리문 생성 (WHERE 조건의 ID는 마지막에 추가)
 	query := fmt.Sprintf(
-		"UPDATE devices SET %s WHERE ProductNumber = ?",
+		"UPDATE devices SET %s WHERE ProductNumber = %s",
 		strings.Join(setClauses, ", "),
+		d.dialect.Placeholder(len(args)+1),
 	)
 
 	return query, args
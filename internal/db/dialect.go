@@ -0,0 +1,411 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Dialect는 데이터베이스 엔진별 SQL 차이를 한 곳에 모으기 위한 인터페이스입니다.
+// Repository는 엔진별 분기 대신 Dialect가 만들어 주는 구문을 사용합니다.
+type Dialect interface {
+	Name() string
+
+	// 마이그레이션 SQL 파일 디렉터리 (migrations/<name>)
+	MigrationsDir() string
+
+	// 타임스탬프 컬럼 타입
+	TimestampType() string
+
+	// n번째(1부터) 바인딩 파라미터 표기 (? 또는 $n)
+	Placeholder(n int) string
+
+	// ? 로 작성된 쿼리를 엔진의 플레이스홀더 표기로 변환
+	Rebind(query string) string
+
+	// 결과 row 수 제한 절
+	Limit(n int) string
+
+	// 트랜잭션 안에서 조회한 row를 커밋까지 잠그는 절 (SQLite는 쓰기가 직렬화되므로 빈 문자열)
+	ForUpdate() string
+
+	// INSERT를 실행하고 생성된 자동 증가 ID를 반환 (LastInsertId 또는 RETURNING)
+	InsertID(ctx context.Context, conn DBTX, query, idColumn string, args ...interface{}) (int64, error)
+
+	// 마이그레이션 잠금 획득/해제 (세션 단위이므로 같은 커넥션을 사용해야 함)
+	Lock(ctx context.Context, conn *sql.Conn, name string) error
+	Unlock(ctx context.Context, conn *sql.Conn, name string) error
+
+	// DDL을 트랜잭션으로 되돌릴 수 있는지 여부 (MariaDB/MySQL은 DDL이 자동 커밋됨)
+	TransactionalDDL() bool
+
+	// 마이그레이션 한 건과 적용 기록을 하나의 트랜잭션으로 묶기 위한 시작/종료 (commit이 false이면 롤백)
+	// TransactionalDDL이 false인 엔진에서는 아무것도 하지 않는다.
+	BeginMigration(ctx context.Context, conn *sql.Conn) error
+	EndMigration(ctx context.Context, conn *sql.Conn, commit bool) error
+
+	// INSERT 충돌 시 updateColumns를 새 값으로 갱신하는 절
+	Upsert(conflictColumns, updateColumns []string) string
+
+	// 갱신할 값을 직접 지정하는 upsert 절의 앞부분 (뒤에 "col = 식" 목록을 붙여 사용)
+	OnConflict(conflictColumns []string) string
+
+	// Upsert 갱신 절에서 INSERT하려던 새 값을 참조하는 표기 (VALUES(col) 또는 excluded.col)
+	// MySQL은 갱신 절을 왼쪽부터 차례로 적용하므로 앞에서 갱신한 컬럼은 이미 새 값이다.
+	Excluded(column string) string
+
+	// 두 값 중 작은 값/큰 값
+	Least(a, b string) string
+	Greatest(a, b string) string
+
+	// 타임스탬프 컬럼의 유닉스 시간 (초 단위 정수, 소수점 이하 버림)
+	// 시간대 없이 저장하는 엔진(MariaDB/MySQL)은 DB 시간대의 벽시계 기준 값이므로 UnixTime으로 실제 시각을 구해야 한다.
+	UnixSeconds(column string) string
+
+	// UnixSeconds로 조회한 값을 실제 시각(UTC)으로 변환
+	UnixTime(seconds int64) time.Time
+
+	// 트랜잭션 전체를 재시도하면 성공할 수 있는 오류인지 여부 (데드락, 잠금 대기 등)
+	IsRetryable(err error) bool
+}
+
+// --- MariaDB / MySQL ---
+
+// loc은 DATETIME 값을 해석하는 DB 시간대 (드라이버 Loc 설정과 같아야 함, nil이면 UTC)
+type mysqlDialect struct {
+	loc *time.Location
+}
+
+// MySQLDialect는 DB 시간대가 UTC인 MariaDB/MySQL 구문이다.
+var MySQLDialect Dialect = mysqlDialect{}
+
+// NewMySQLDialect - DB 시간대(loc)로 DATETIME을 저장하는 MariaDB/MySQL 구문을 반환합니다.
+func NewMySQLDialect(loc *time.Location) Dialect {
+	return mysqlDialect{loc: loc}
+}
+
+func (mysqlDialect) Name() string          { return "mysql" }
+func (mysqlDialect) MigrationsDir() string { return "migrations/mysql" }
+func (mysqlDialect) TimestampType() string { return "DATETIME(6)" }
+
+func (mysqlDialect) Placeholder(int) string     { return "?" }
+func (mysqlDialect) Rebind(query string) string { return query }
+func (mysqlDialect) Limit(n int) string         { return "LIMIT " + strconv.Itoa(n) }
+func (mysqlDialect) ForUpdate() string          { return "FOR UPDATE" }
+
+func (mysqlDialect) InsertID(ctx context.Context, conn DBTX, query, _ string, args ...interface{}) (int64, error) {
+	return lastInsertID(ctx, conn, query, args...)
+}
+
+func (mysqlDialect) Lock(ctx context.Context, conn *sql.Conn, name string) error {
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, migrationLockTimeout).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked.Valid || locked.Int64 != 1 {
+		return fmt.Errorf("lock %q is held by another session", name)
+	}
+	return nil
+}
+
+func (mysqlDialect) Unlock(ctx context.Context, conn *sql.Conn, name string) error {
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name)
+	return err
+}
+
+// MariaDB/MySQL은 DDL 실행 시 암묵적으로 커밋하므로 중간에 실패한 마이그레이션을 되돌릴 수 없다.
+// Migrator는 적용 전에 dirty로 기록하여 수동 정리가 필요함을 표시한다.
+func (mysqlDialect) TransactionalDDL() bool { return false }
+
+func (mysqlDialect) BeginMigration(context.Context, *sql.Conn) error     { return nil }
+func (mysqlDialect) EndMigration(context.Context, *sql.Conn, bool) error { return nil }
+
+func (d mysqlDialect) Upsert(conflictColumns, updateColumns []string) string {
+	sets := make([]string, 0, len(updateColumns))
+	for _, col := range updateColumns {
+		sets = append(sets, fmt.Sprintf("%s = VALUES(%s)", col, col))
+	}
+	return d.OnConflict(conflictColumns) + " " + strings.Join(sets, ", ")
+}
+
+// MySQL은 충돌 대상 컬럼을 지정하지 않고 모든 unique key 충돌에 적용된다.
+func (mysqlDialect) OnConflict([]string) string { return "ON DUPLICATE KEY UPDATE" }
+
+func (mysqlDialect) Excluded(column string) string { return "VALUES(" + column + ")" }
+func (mysqlDialect) Least(a, b string) string      { return "LEAST(" + a + ", " + b + ")" }
+func (mysqlDialect) Greatest(a, b string) string   { return "GREATEST(" + a + ", " + b + ")" }
+
+// UNIX_TIMESTAMP는 DATETIME을 세션 시간대로 해석하므로 저장된 값(dbLoc 기준) 그대로 차이를 계산한다.
+// 결과는 DB 시간대의 벽시계 기준이므로 UnixTime으로 변환해야 한다.
+func (mysqlDialect) UnixSeconds(column string) string {
+	return "TIMESTAMPDIFF(SECOND, '1970-01-01 00:00:00', " + column + ")"
+}
+
+// 벽시계 기준 초를 DB 시간대의 시각으로 해석한다. (일광 절약 시간 포함)
+func (d mysqlDialect) UnixTime(seconds int64) time.Time {
+	if d.loc == nil || d.loc == time.UTC {
+		return time.Unix(seconds, 0).UTC()
+	}
+	wall := time.Unix(seconds, 0).UTC()
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, d.loc).UTC()
+}
+
+func (mysqlDialect) IsRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlErrLockDeadlock || mysqlErr.Number == mysqlErrLockWaitTimeout
+	}
+	return false
+}
+
+// --- SQLite ---
+
+type sqliteDialect struct{}
+
+var SQLiteDialect Dialect = sqliteDialect{}
+
+func (sqliteDialect) Name() string          { return "sqlite" }
+func (sqliteDialect) MigrationsDir() string { return "migrations/sqlite" }
+func (sqliteDialect) TimestampType() string { return "DATETIME" }
+
+func (sqliteDialect) Placeholder(int) string     { return "?" }
+func (sqliteDialect) Rebind(query string) string { return query }
+func (sqliteDialect) Limit(n int) string         { return "LIMIT " + strconv.Itoa(n) }
+func (sqliteDialect) ForUpdate() string          { return "" }
+
+func (sqliteDialect) InsertID(ctx context.Context, conn DBTX, query, _ string, args ...interface{}) (int64, error) {
+	return lastInsertID(ctx, conn, query, args...)
+}
+
+// SQLite는 이름 있는 잠금이 없으므로 쓰기 잠금(BEGIN IMMEDIATE)으로 다른 프로세스의 마이그레이션을 막는다.
+// DDL도 트랜잭션에 포함되므로 Unlock 시 커밋된다.
+// 마이그레이션마다 savepoint를 두어 실패한 마이그레이션은 되돌린 뒤 커밋하므로, 성공한 마이그레이션만 반영된다.
+func (sqliteDialect) Lock(ctx context.Context, conn *sql.Conn, _ string) error {
+	_, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE")
+	return err
+}
+
+func (sqliteDialect) Unlock(ctx context.Context, conn *sql.Conn, _ string) error {
+	_, err := conn.ExecContext(ctx, "COMMIT")
+	return err
+}
+
+func (sqliteDialect) TransactionalDDL() bool { return true }
+
+func (sqliteDialect) BeginMigration(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "SAVEPOINT schema_migration")
+	return err
+}
+
+// ROLLBACK TO는 savepoint를 남겨두므로 되돌린 뒤에도 RELEASE로 제거한다.
+func (sqliteDialect) EndMigration(ctx context.Context, conn *sql.Conn, commit bool) error {
+	if !commit {
+		if _, err := conn.ExecContext(ctx, "ROLLBACK TO SAVEPOINT schema_migration"); err != nil {
+			return err
+		}
+	}
+	_, err := conn.ExecContext(ctx, "RELEASE SAVEPOINT schema_migration")
+	return err
+}
+
+func (sqliteDialect) Upsert(conflictColumns, updateColumns []string) string {
+	return onConflictUpdate(conflictColumns, updateColumns)
+}
+
+func (sqliteDialect) OnConflict(conflictColumns []string) string {
+	return onConflict(conflictColumns)
+}
+
+// SQLite는 LEAST/GREATEST 대신 인자가 여러 개인 MIN/MAX 스칼라 함수를 사용한다.
+func (sqliteDialect) Excluded(column string) string { return "excluded." + column }
+func (sqliteDialect) Least(a, b string) string      { return "MIN(" + a + ", " + b + ")" }
+func (sqliteDialect) Greatest(a, b string) string   { return "MAX(" + a + ", " + b + ")" }
+
+// _time_format=sqlite로 저장한 문자열은 시간대 오프셋을 포함하므로 strftime이 UTC로 변환한다.
+func (sqliteDialect) UnixSeconds(column string) string {
+	return "CAST(strftime('%s', " + column + ") AS INTEGER)"
+}
+
+func (sqliteDialect) UnixTime(seconds int64) time.Time { return time.Unix(seconds, 0).UTC() }
+
+func (sqliteDialect) IsRetryable(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		code := sqliteErr.Code() & 0xff // 확장 코드에서 기본 코드 추출
+		return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+	}
+	return false
+}
+
+// --- PostgreSQL ---
+
+// PostgreSQL 재시도 가능 오류 코드
+const (
+	pgErrSerializationFailure = "40001" // serialization_failure
+	pgErrDeadlockDetected     = "40P01" // deadlock_detected
+	pgErrLockNotAvailable     = "55P03" // lock_not_available
+)
+
+type postgresDialect struct{}
+
+var PostgresDialect Dialect = postgresDialect{}
+
+func (postgresDialect) Name() string          { return "postgres" }
+func (postgresDialect) MigrationsDir() string { return "migrations/postgres" }
+func (postgresDialect) TimestampType() string { return "TIMESTAMPTZ" }
+func (postgresDialect) Limit(n int) string    { return "LIMIT " + strconv.Itoa(n) }
+func (postgresDialect) ForUpdate() string     { return "FOR UPDATE" }
+
+func (postgresDialect) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// 문자열 리터럴 안의 ? 는 그대로 두고 나머지를 $1, $2 ... 로 바꾼다.
+func (d postgresDialect) Rebind(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+
+	n := 0
+	quoted := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case c == '?' && !quoted:
+			n++
+			b.WriteString(d.Placeholder(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// PostgreSQL 드라이버는 LastInsertId를 지원하지 않으므로 RETURNING 절로 ID를 받는다.
+func (d postgresDialect) InsertID(ctx context.Context, conn DBTX, query, idColumn string, args ...interface{}) (int64, error) {
+	var id int64
+	if err := conn.QueryRowContext(ctx, d.Rebind(query)+" RETURNING "+idColumn, args...).Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// 세션 단위 advisory lock을 사용한다. 잠금 키는 이름의 해시 값이다.
+// pg_advisory_lock은 무한 대기하므로 제한 시간 동안 pg_try_advisory_lock을 반복한다.
+func (postgresDialect) Lock(ctx context.Context, conn *sql.Conn, name string) error {
+	key := advisoryLockKey(name)
+	deadline := time.Now().Add(migrationLockTimeout * time.Second)
+
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+			return err
+		}
+		if locked {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("lock %q is held by another session", name)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+func (postgresDialect) Unlock(ctx context.Context, conn *sql.Conn, name string) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", advisoryLockKey(name))
+	return err
+}
+
+// advisory lock은 세션 단위이므로 잠금을 유지한 채 마이그레이션마다 트랜잭션을 시작/종료한다.
+func (postgresDialect) TransactionalDDL() bool { return true }
+
+func (postgresDialect) BeginMigration(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, "BEGIN")
+	return err
+}
+
+func (postgresDialect) EndMigration(ctx context.Context, conn *sql.Conn, commit bool) error {
+	stmt := "ROLLBACK"
+	if commit {
+		stmt = "COMMIT"
+	}
+	_, err := conn.ExecContext(ctx, stmt)
+	return err
+}
+
+func (postgresDialect) Upsert(conflictColumns, updateColumns []string) string {
+	return onConflictUpdate(conflictColumns, updateColumns)
+}
+
+func (postgresDialect) OnConflict(conflictColumns []string) string {
+	return onConflict(conflictColumns)
+}
+
+func (postgresDialect) Excluded(column string) string { return "excluded." + column }
+func (postgresDialect) Least(a, b string) string      { return "LEAST(" + a + ", " + b + ")" }
+func (postgresDialect) Greatest(a, b string) string   { return "GREATEST(" + a + ", " + b + ")" }
+
+// BIGINT 변환은 반올림하므로 먼저 내림한다.
+func (postgresDialect) UnixSeconds(column string) string {
+	return "CAST(FLOOR(EXTRACT(EPOCH FROM " + column + ")) AS BIGINT)"
+}
+
+func (postgresDialect) UnixTime(seconds int64) time.Time { return time.Unix(seconds, 0).UTC() }
+
+func (postgresDialect) IsRetryable(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pgErrSerializationFailure, pgErrDeadlockDetected, pgErrLockNotAvailable:
+			return true
+		}
+	}
+	return false
+}
+
+// --- 공통 ---
+
+// LastInsertId를 지원하는 드라이버의 INSERT 후 ID 획득
+func lastInsertID(ctx context.Context, conn DBTX, query string, args ...interface{}) (int64, error) {
+	result, err := conn.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// SQLite, PostgreSQL 공통 upsert 구문
+func onConflictUpdate(conflictColumns, updateColumns []string) string {
+	sets := make([]string, 0, len(updateColumns))
+	for _, col := range updateColumns {
+		sets = append(sets, fmt.Sprintf("%s = excluded.%s", col, col))
+	}
+	return onConflict(conflictColumns) + " " + strings.Join(sets, ", ")
+}
+
+func onConflict(conflictColumns []string) string {
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET", strings.Join(conflictColumns, ", "))
+}
+
+func advisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"SELECT * FROM devices WHERE ProductNumber = ?", "SELECT * FROM devices WHERE ProductNumber = $1"},
+		{"UPDATE devices SET ReTry = ?, Status = ? WHERE ProductNumber = ?", "UPDATE devices SET ReTry = $1, Status = $2 WHERE ProductNumber = $3"},
+		// 문자열 리터럴 안의 ? 는 바꾸지 않는다
+		{"SELECT '?' , ? FROM t WHERE a = 'x?y' AND b = ?", "SELECT '?' , $1 FROM t WHERE a = 'x?y' AND b = $2"},
+		{"SELECT 'it''s ?' , ?", "SELECT 'it''s ?' , $1"},
+	}
+	for _, tt := range tests {
+		if got := PostgresDialect.Rebind(tt.query); got != tt.want {
+			t.Errorf("PostgresDialect.Rebind(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+		for _, d := range []Dialect{MySQLDialect, SQLiteDialect} {
+			if got := d.Rebind(tt.query); got != tt.query {
+				t.Errorf("%s.Rebind(%q) = %q, want unchanged", d.Name(), tt.query, got)
+			}
+		}
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	conflict := []string{"ProductNumber"}
+	update := []string{"BatteryPercent", "ReportAt"}
+
+	tests := []struct {
+		dialect Dialect
+		want    string
+	}{
+		{MySQLDialect, "ON DUPLICATE KEY UPDATE BatteryPercent = VALUES(BatteryPercent), ReportAt = VALUES(ReportAt)"},
+		{SQLiteDialect, "ON CONFLICT (ProductNumber) DO UPDATE SET BatteryPercent = excluded.BatteryPercent, ReportAt = excluded.ReportAt"},
+		{PostgresDialect, "ON CONFLICT (ProductNumber) DO UPDATE SET BatteryPercent = excluded.BatteryPercent, ReportAt = excluded.ReportAt"},
+	}
+	for _, tt := range tests {
+		if got := tt.dialect.Upsert(conflict, update); got != tt.want {
+			t.Errorf("%s.Upsert = %q, want %q", tt.dialect.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestMySQLUnixTime(t *testing.T) {
+	seoul := time.FixedZone("KST", 9*60*60)
+	at := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+
+	// DATETIME에는 DB 시간대의 벽시계 값이 저장되므로 UNIX_TIMESTAMP 결과도 그 기준이다
+	wall := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC).Unix()
+	if got := NewMySQLDialect(seoul).UnixTime(wall); !got.Equal(at) {
+		t.Errorf("UnixTime in KST = %v, want %v", got, at)
+	}
+	if got := MySQLDialect.UnixTime(at.Unix()); !got.Equal(at) {
+		t.Errorf("UnixTime in UTC = %v, want %v", got, at)
+	}
+}
+
+// InsertID는 실제 SQLite 데이터베이스에서 확인한다. (SQLite도 RETURNING 절과 $n 표기를 지원함)
+func TestInsertID(t *testing.T) {
+	ctx := context.Background()
+	mgr := openTestSQLite(t, testLogger())
+	if _, err := mgr.DB().ExecContext(ctx, "CREATE TABLE items (ItemID INTEGER PRIMARY KEY AUTOINCREMENT, Name TEXT NOT NULL)"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, d := range []Dialect{SQLiteDialect, PostgresDialect, SQLiteDialect} {
+		id, err := d.InsertID(ctx, mgr.DB(), "INSERT INTO items (Name) VALUES (?)", "ItemID", d.Name())
+		if err != nil {
+			t.Fatalf("%s.InsertID: %v", d.Name(), err)
+		}
+		if id != int64(i+1) {
+			t.Errorf("%s.InsertID = %d, want %d", d.Name(), id, i+1)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 오류 상수 선언
+var (
+	ErrInvalidFirmwareRequired = errors.New("missing required inputs to create FirmwareRepo")
+	ErrFailedToCreateFirmware  = errors.New("failed to create firmware")
+	ErrFailedToSelectFirmware  = errors.New("failed to select firmware")
+	ErrFirmwareNotFound        = errors.New("firmware not found")
+	ErrFailedToCreateDelta     = errors.New("failed to create firmware delta")
+	ErrDeltaNotFound           = errors.New("firmware delta not found")
+)
+
+// FirmwareRepo를 통해 사용할 메서드를 제약하고 규정하기 위한 인터페이스
+type FirmwareDataService interface {
+	Create(ctx context.Context, fw *data.Firmware) error
+	GetAll(ctx context.Context) (*[]data.Firmware, error)
+	GetByVersion(ctx context.Context, version string) (*data.Firmware, error)
+	CreateDelta(ctx context.Context, delta *data.FirmwareDelta) error
+	GetDelta(ctx context.Context, fromVersion, toVersion string) (*data.FirmwareDelta, error)
+}
+
+// firmwares, firmware_deltas 테이블을 접근하기 위한 커넥션 관리
+// 펌웨어 등록은 여러 테이블을 변경하므로 txMgr의 트랜잭션으로 처리한다.
+type FirmwareRepo struct {
+	connection DBTX
+	txMgr      TxManager
+	dialect    Dialect
+	logger     *logger.AppLogger
+}
+
+func NewFirmwareRepo(lgr *logger.AppLogger, db DBTX, txMgr TxManager, dialect Dialect) (*FirmwareRepo, error) {
+	if lgr == nil || db == nil || txMgr == nil || dialect == nil {
+		return nil, ErrInvalidFirmwareRequired
+	}
+	return &FirmwareRepo{
+		connection: db,
+		txMgr:      txMgr,
+		dialect:    dialect,
+		logger:     lgr,
+	}, nil
+}
+
+// 트랜잭션에 바인딩된 FirmwareRepo 반환
+func (f *FirmwareRepo) withTx(tx DBTX) *FirmwareRepo {
+	return &FirmwareRepo{
+		connection: tx,
+		txMgr:      f.txMgr,
+		dialect:    f.dialect,
+		logger:     f.logger,
+	}
+}
+
+// 펌웨어 이미지 정보 및 호환 하드웨어 목록 등록
+// 하나의 트랜잭션으로 등록하여 호환 목록 없이 등록된 펌웨어가 남지 않도록 한다.
+func (f *FirmwareRepo) Create(ctx context.Context, fw *data.Firmware) error {
+	return f.txMgr.WithTx(ctx, func(tx DBTX) error {
+		return f.withTx(tx).create(ctx, fw)
+	})
+}
+
+func (f *FirmwareRepo) create(ctx context.Context, fw *data.Firmware) error {
+	query := "INSERT INTO firmwares (Version, Path, Checksum, Size, MinSourceVersion, CreatedAt) VALUES (?, ?, ?, ?, ?, ?)"
+
+	_, err := f.connection.ExecContext(ctx, f.dialect.Rebind(query),
+		fw.Version,
+		fw.Path,
+		fw.Checksum,
+		fw.Size,
+		fw.MinSourceVersion,
+		fw.CreatedAt,
+	)
+	if err != nil {
+		f.logger.Error().Err(err).Msg("failed to create firmware")
+		return ErrFailedToCreateFirmware
+	}
+
+	if len(fw.Compatibility) == 0 {
+		return nil
+	}
+
+	// 호환 목록은 다중 row INSERT 한 번으로 등록
+	values := make([]string, 0, len(fw.Compatibility))
+	args := make([]interface{}, 0, len(fw.Compatibility)*3)
+	for _, c := range fw.Compatibility {
+		values = append(values, "(?, ?, ?)")
+		args = append(args, fw.Version, c.ProductLine, c.HardwareRevision)
+	}
+
+	query = "INSERT INTO firmware_compatibility (Version, ProductLine, HardwareRevision) VALUES " + strings.Join(values, ", ")
+	if _, err := f.connection.ExecContext(ctx, f.dialect.Rebind(query), args...); err != nil {
+		f.logger.Error().Err(err).Msg("failed to create firmware compatibility")
+		return ErrFailedToCreateFirmware
+	}
+
+	return nil
+}
+
+func (f *FirmwareRepo) GetAll(ctx context.Context) (*[]data.Firmware, error) {
+	query := "SELECT Version, Path, Checksum, Size, MinSourceVersion, CreatedAt FROM firmwares ORDER BY CreatedAt DESC"
+
+	rows, err := f.connection.QueryContext(ctx, query)
+	if err != nil {
+		f.logger.Error().Err(err).Msg("failed to select firmwares")
+		return nil, ErrFailedToSelectFirmware
+	}
+	defer rows.Close()
+
+	var responseData []data.Firmware
+
+	for rows.Next() {
+		var fw data.Firmware
+		err := rows.Scan(&fw.Version, &fw.Path, &fw.Checksum, &fw.Size, &fw.MinSourceVersion, &fw.CreatedAt)
+		if err != nil {
+			f.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, err
+		}
+		responseData = append(responseData, fw)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 호환 목록은 한 번에 조회하여 버전별로 묶는다
+	compat, err := f.getCompatibility(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range responseData {
+		responseData[i].Compatibility = compat[responseData[i].Version]
+	}
+
+	return &responseData, nil
+}
+
+// 버전에 해당하는 펌웨어 정보 획득
+func (f *FirmwareRepo) GetByVersion(ctx context.Context, version string) (*data.Firmware, error) {
+	query := "SELECT Version, Path, Checksum, Size, MinSourceVersion, CreatedAt FROM firmwares WHERE Version = ?"
+
+	var fw data.Firmware
+	err := f.connection.QueryRowContext(ctx, f.dialect.Rebind(query), version).Scan(
+		&fw.Version, &fw.Path, &fw.Checksum, &fw.Size, &fw.MinSourceVersion, &fw.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFirmwareNotFound
+	}
+	if err != nil {
+		f.logger.Error().Err(err).Msg("failed to select firmware by version")
+		return nil, ErrFailedToSelectFirmware
+	}
+
+	compat, err := f.getCompatibility(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	fw.Compatibility = compat[version]
+
+	return &fw, nil
+}
+
+// 버전별 호환 하드웨어 목록 조회 (version이 비어 있으면 전체)
+func (f *FirmwareRepo) getCompatibility(ctx context.Context, version string) (map[string][]data.FirmwareCompat, error) {
+	query := "SELECT Version, ProductLine, HardwareRevision FROM firmware_compatibility"
+	args := []interface{}{}
+	if version != "" {
+		query += " WHERE Version = ?"
+		args = append(args, version)
+	}
+
+	rows, err := f.connection.QueryContext(ctx, f.dialect.Rebind(query), args...)
+	if err != nil {
+		f.logger.Error().Err(err).Msg("failed to select firmware compatibility")
+		return nil, ErrFailedToSelectFirmware
+	}
+	defer rows.Close()
+
+	compat := make(map[string][]data.FirmwareCompat)
+	for rows.Next() {
+		var v string
+		var c data.FirmwareCompat
+		if err := rows.Scan(&v, &c.ProductLine, &c.HardwareRevision); err != nil {
+			f.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, err
+		}
+		compat[v] = append(compat[v], c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return compat, nil
+}
+
+// 델타 패치 정보 등록
+// 같은 버전 쌍의 패치가 이미 있으면 새로 생성한 패치 정보로 교체한다.
+func (f *FirmwareRepo) CreateDelta(ctx context.Context, delta *data.FirmwareDelta) error {
+	query := "INSERT INTO firmware_deltas (FromVersion, ToVersion, Path, Checksum, Size, CreatedAt) VALUES (?, ?, ?, ?, ?, ?) " +
+		f.dialect.Upsert(
+			[]string{"FromVersion", "ToVersion"},
+			[]string{"Path", "Checksum", "Size", "CreatedAt"},
+		)
+
+	_, err := f.connection.ExecContext(ctx, f.dialect.Rebind(query),
+		delta.FromVersion,
+		delta.ToVersion,
+		delta.Path,
+		delta.Checksum,
+		delta.Size,
+		delta.CreatedAt,
+	)
+	if err != nil {
+		f.logger.Error().Err(err).Msg("failed to create firmware delta")
+		return ErrFailedToCreateDelta
+	}
+
+	return nil
+}
+
+// 버전 쌍에 해당하는 델타 패치 정보 획득
+func (f *FirmwareRepo) GetDelta(ctx context.Context, fromVersion, toVersion string) (*data.FirmwareDelta, error) {
+	query := "SELECT FromVersion, ToVersion, Path, Checksum, Size, CreatedAt FROM firmware_deltas WHERE FromVersion = ? AND ToVersion = ?"
+
+	var delta data.FirmwareDelta
+	err := f.connection.QueryRowContext(ctx, f.dialect.Rebind(query), fromVersion, toVersion).Scan(
+		&delta.FromVersion, &delta.ToVersion, &delta.Path, &delta.Checksum, &delta.Size, &delta.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDeltaNotFound
+	}
+	if err != nil {
+		f.logger.Error().Err(err).Msg("failed to select firmware delta")
+		return nil, ErrFailedToSelectFirmware
+	}
+
+	return &delta, nil
+}
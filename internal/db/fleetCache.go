@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go-rest-example/internal/model/data"
+)
+
+// 전체 디바이스 현황 기본 보관 시간
+const defaultFleetCacheTTL = 10 * time.Second
+
+var ErrInvalidFleetCacheRequired = errors.New("missing required inputs to create FleetCache")
+
+// FleetCache는 전체 디바이스 현황을 짧게 보관하여 관제 화면이 자주 조회해도 집계 쿼리는 보관 시간마다 한 번만 실행되게 합니다.
+// 동시에 만료된 조회는 하나만 집계하고 나머지는 그 결과를 기다립니다. 집계 오류는 보관하지 않습니다.
+type FleetCache struct {
+	inner FleetDataService
+	ttl   time.Duration
+
+	mu       sync.Mutex
+	overview *data.FleetOverview
+}
+
+// 컴파일 타임에 FleetCache가 FleetDataService 인터페이스를 구현하는지 확인합니다.
+var _ FleetDataService = (*FleetCache)(nil)
+
+func NewFleetCache(inner FleetDataService, ttl time.Duration) (*FleetCache, error) {
+	if inner == nil {
+		return nil, ErrInvalidFleetCacheRequired
+	}
+
+	return &FleetCache{
+		inner: inner,
+		ttl:   orDefault(ttl, defaultFleetCacheTTL),
+	}, nil
+}
+
+// Overview - 보관 시간 이내의 집계가 있으면 반환하고, 없으면 새로 집계하여 보관합니다.
+// 반환한 값은 다른 조회와 공유하므로 변경하지 않아야 합니다.
+func (c *FleetCache) Overview(ctx context.Context, now time.Time) (*data.FleetOverview, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.overview != nil && now.Sub(c.overview.GeneratedAt) < c.ttl {
+		return c.overview, nil
+	}
+
+	overview, err := c.inner.Overview(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	c.overview = overview
+	return overview, nil
+}
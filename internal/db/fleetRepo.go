@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 전체 디바이스 현황 기본값
+const (
+	defaultFleetOfflineCycles = 3
+	defaultFleetLowBattery    = 20
+)
+
+// 분당 보고 수 집계 기간 (분)
+const fleetReportMinutes = 60
+
+// 오류 상수 선언
+var (
+	ErrInvalidFleetRequired = errors.New("missing required inputs to create FleetRepo")
+	ErrFailedToSelectFleet  = errors.New("failed to select fleet overview")
+)
+
+type FleetConfig struct {
+	OfflineCycles int // 보고 주기의 몇 배 동안 보고가 없으면 오프라인으로 볼지 (기본값 3)
+	LowBattery    int // 배터리 부족 기준 (%, 이하, 기본값 20)
+}
+
+// FleetRepo를 통해 사용할 메서드를 제약하고 규정하기 위한 인터페이스
+type FleetDataService interface {
+	Overview(ctx context.Context, now time.Time) (*data.FleetOverview, error)
+}
+
+// devices, reports 테이블로 전체 디바이스 현황을 집계 (조회 전용이므로 replica 커넥션 사용)
+type FleetRepo struct {
+	reader  DBTX
+	dialect Dialect
+	cfg     FleetConfig
+	logger  *logger.AppLogger
+}
+
+func NewFleetRepo(lgr *logger.AppLogger, reader DBTX, dialect Dialect, cfg FleetConfig) (*FleetRepo, error) {
+	if lgr == nil || reader == nil || dialect == nil {
+		return nil, ErrInvalidFleetRequired
+	}
+
+	cfg.OfflineCycles = orDefault(cfg.OfflineCycles, defaultFleetOfflineCycles)
+	cfg.LowBattery = orDefault(cfg.LowBattery, defaultFleetLowBattery)
+
+	return &FleetRepo{
+		reader:  reader,
+		dialect: dialect,
+		cfg:     cfg,
+		logger:  lgr,
+	}, nil
+}
+
+// Overview - now 기준 전체 디바이스 현황을 집계합니다.
+func (r *FleetRepo) Overview(ctx context.Context, now time.Time) (*data.FleetOverview, error) {
+	overview := &data.FleetOverview{
+		ByStatus:    make(map[data.DeviceStatus]int64),
+		ByFirmware:  make(map[string]int64),
+		ByErrorCode: make(map[int]int64),
+		GeneratedAt: now,
+	}
+	offlineBefore := now.Add(-time.Duration(r.cfg.OfflineCycles) * data.ReportCycle)
+
+	// 1. 상태/펌웨어 버전별 디바이스 수
+	err := r.scan(ctx, "SELECT Status, FirmwareVersion, COUNT(*) FROM devices GROUP BY Status, FirmwareVersion", nil, func(scan func(...interface{}) error) error {
+		var status data.DeviceStatus
+		var firmwareVersion string
+		var count int64
+		if err := scan(&status, &firmwareVersion, &count); err != nil {
+			return err
+		}
+		overview.Devices += count
+		overview.ByStatus[status] += count
+		overview.ByFirmware[firmwareVersion] += count
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 오프라인 디바이스 수
+	err = r.scan(ctx, "SELECT COUNT(*) FROM devices WHERE LastSeenAt < ?", []interface{}{offlineBefore}, func(scan func(...interface{}) error) error {
+		return scan(&overview.Offline)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 오프라인이 아닌 디바이스의 마지막 보고 기준 에러 코드별, 배터리 부족 디바이스 수
+	query := "SELECT r.ErrorCode, COUNT(*), SUM(CASE WHEN r.BatteryPercent <= ? THEN 1 ELSE 0 END) FROM reports r " +
+		"JOIN (SELECT ProductNumber, MAX(ReportAt) AS ReportAt FROM reports WHERE ReportAt >= ? GROUP BY ProductNumber) l " +
+		"ON l.ProductNumber = r.ProductNumber AND l.ReportAt = r.ReportAt GROUP BY r.ErrorCode"
+	err = r.scan(ctx, query, []interface{}{r.cfg.LowBattery, offlineBefore}, func(scan func(...interface{}) error) error {
+		var errorCode int
+		var count, lowBattery int64
+		if err := scan(&errorCode, &count, &lowBattery); err != nil {
+			return err
+		}
+		overview.LowBattery += lowBattery
+		if errorCode != 0 {
+			overview.Errors += count
+			overview.ByErrorCode[errorCode] += count
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 4. 최근 1시간 분당 보고 수 (보고가 없는 분은 0)
+	// 버킷 값은 DB 시간대 기준일 수 있으므로 실제 시각으로 변환한 뒤 위치를 계산한다. (시간대 오프셋은 분 단위)
+	to := now.UTC().Truncate(time.Minute)
+	from := to.Add(-fleetReportMinutes * time.Minute)
+	overview.ReportsPerMinute = make([]int64, fleetReportMinutes)
+
+	unix := r.dialect.UnixSeconds("ReportAt")
+	query = fmt.Sprintf("SELECT %s - %s %% 60 AS Bucket, COUNT(*) FROM reports WHERE ReportAt >= ? AND ReportAt < ? GROUP BY Bucket", unix, unix)
+	err = r.scan(ctx, query, []interface{}{from, to}, func(scan func(...interface{}) error) error {
+		var bucket, count int64
+		if err := scan(&bucket, &count); err != nil {
+			return err
+		}
+		if i := int64(r.dialect.UnixTime(bucket).Sub(from) / time.Minute); i >= 0 && i < fleetReportMinutes {
+			overview.ReportsPerMinute[i] += count
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return overview, nil
+}
+
+// 쿼리를 실행하고 row마다 fn을 호출한다
+func (r *FleetRepo) scan(ctx context.Context, query string, args []interface{}, fn func(scan func(...interface{}) error) error) error {
+	rows, err := r.reader.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to select fleet overview")
+		if IsUnavailable(err) {
+			return fmt.Errorf("%w: %w", ErrFailedToSelectFleet, ErrUnavailable)
+		}
+		return ErrFailedToSelectFleet
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows.Scan); err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan row")
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,245 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 다중 row upsert 한 번에 반영할 상태 수 (행당 9개 placeholder)
+const latestStateBatch = 1000
+
+// 디바이스 목록 조회 기본/최대 수
+const (
+	defaultDeviceListLimit = 100
+	maxDeviceListLimit     = 1000
+)
+
+// 오류 상수 선언
+var (
+	ErrInvalidLatestStateRequired = errors.New("missing required inputs to create LatestStateRepo")
+	ErrFailedToUpsertLatestState  = errors.New("failed to upsert device latest state")
+	ErrFailedToSelectLatestState  = errors.New("failed to select device latest state")
+)
+
+// LatestStateRepo를 통해 사용할 메서드를 제약하고 규정하기 위한 인터페이스
+type LatestStateDataService interface {
+	Upsert(ctx context.Context, reports []data.DeviceInfo) error
+	GetByID(ctx context.Context, productNumber string) (*data.DeviceWithState, error)
+	List(ctx context.Context, q data.DeviceListQuery) ([]data.DeviceWithState, error)
+	WithTx(tx DBTX) LatestStateDataService
+}
+
+// device_latest_state 테이블을 접근하기 위한 커넥션 관리
+type LatestStateRepo struct {
+	connection DBTX
+	reader     DBTX // 상태/목록 조회용 (replica 우선)
+	dialect    Dialect
+	logger     *logger.AppLogger
+}
+
+func NewLatestStateRepo(lgr *logger.AppLogger, db DBTX, reader DBTX, dialect Dialect) (*LatestStateRepo, error) {
+	if lgr == nil || db == nil || reader == nil || dialect == nil {
+		return nil, ErrInvalidLatestStateRequired
+	}
+	return &LatestStateRepo{
+		connection: db,
+		reader:     reader,
+		dialect:    dialect,
+		logger:     lgr,
+	}, nil
+}
+
+// 트랜잭션에 바인딩된 LatestStateRepo 반환 (조회도 트랜잭션 안에서 실행)
+func (r *LatestStateRepo) WithTx(tx DBTX) LatestStateDataService {
+	return &LatestStateRepo{
+		connection: tx,
+		reader:     tx,
+		dialect:    r.dialect,
+		logger:     r.logger,
+	}
+}
+
+// Upsert - 보고로 디바이스별 마지막 보고 상태를 갱신합니다.
+// 보고 시간이 기존 상태보다 이전이면 갱신하지 않으며, 같은 디바이스의 보고가 여러 건이면 보고 시간이 가장 늦은 보고만 반영합니다.
+func (r *LatestStateRepo) Upsert(ctx context.Context, reports []data.DeviceInfo) error {
+	// 1. 디바이스별 마지막 보고 선택 (같은 시간이면 뒤의 보고)
+	index := make(map[string]int, len(reports))
+	states := make([]data.DeviceLatestState, 0, len(reports))
+	for i := range reports {
+		state := data.NewLatestState(&reports[i])
+		j, ok := index[state.ProductNumber]
+		if !ok {
+			index[state.ProductNumber] = len(states)
+			states = append(states, state)
+			continue
+		}
+		if !state.ReportAt.Before(states[j].ReportAt) {
+			states[j] = state
+		}
+	}
+
+	// 2. 다중 row upsert
+	upsertClause := r.upsertClause()
+	for start := 0; start < len(states); start += latestStateBatch {
+		batch := states[start:min(start+latestStateBatch, len(states))]
+
+		values := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch)*9)
+		for i := range batch {
+			s := &batch[i]
+			values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?)")
+			args = append(args, s.ProductNumber, s.BatteryPercent, s.Lat, s.Lon, s.TemperatureCelsius, s.IP, s.ErrorCode, s.ReportedStatus, s.ReportAt)
+		}
+
+		query := "INSERT INTO device_latest_state (ProductNumber, BatteryPercent, Lat, Lon, TemperatureCelsius, IP, ErrorCode, ReportedStatus, ReportAt) VALUES " +
+			strings.Join(values, ", ") + " " + upsertClause
+
+		if _, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), args...); err != nil {
+			r.logger.Error().Err(err).Int("rows", len(batch)).Msg("failed to upsert device latest state")
+			if IsUnavailable(err) {
+				return fmt.Errorf("%w: %w", ErrFailedToUpsertLatestState, ErrUnavailable)
+			}
+			return ErrFailedToUpsertLatestState
+		}
+	}
+
+	return nil
+}
+
+// GetByID - 디바이스와 마지막 보고 상태를 반환합니다.
+func (r *LatestStateRepo) GetByID(ctx context.Context, productNumber string) (*data.DeviceWithState, error) {
+	query := deviceWithStateSelect + " WHERE d.ProductNumber = ?"
+
+	rows, err := r.reader.QueryContext(ctx, r.dialect.Rebind(query), productNumber)
+	if err != nil {
+		return nil, r.selectError(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, r.selectError(err)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrFailedToSelectDevice, ErrDeviceNotFound)
+	}
+
+	device, err := scanDeviceWithState(rows)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to scan row")
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// List - 디바이스를 ProductNumber 순서로 마지막 보고 상태와 함께 반환합니다.
+// BatteryBelow를 지정하면 device_latest_state의 배터리 인덱스로 조회합니다.
+func (r *LatestStateRepo) List(ctx context.Context, q data.DeviceListQuery) ([]data.DeviceWithState, error) {
+	limit := min(orDefault(q.Limit, defaultDeviceListLimit), maxDeviceListLimit)
+
+	var conds []string
+	var args []interface{}
+	if q.BatteryBelow > 0 {
+		conds = append(conds, "s.BatteryPercent < ?")
+		args = append(args, q.BatteryBelow)
+	}
+	if q.After != "" {
+		conds = append(conds, "d.ProductNumber > ?")
+		args = append(args, q.After)
+	}
+
+	query := deviceWithStateSelect
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY d.ProductNumber " + r.dialect.Limit(limit)
+
+	rows, err := r.reader.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		return nil, r.selectError(err)
+	}
+	defer rows.Close()
+
+	devices := make([]data.DeviceWithState, 0)
+	for rows.Next() {
+		device, err := scanDeviceWithState(rows)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, err
+		}
+		devices = append(devices, *device)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, r.selectError(err)
+	}
+
+	return devices, nil
+}
+
+func (r *LatestStateRepo) selectError(err error) error {
+	r.logger.Error().Err(err).Msg("failed to select device latest state")
+	if IsUnavailable(err) {
+		return fmt.Errorf("%w: %w", ErrFailedToSelectLatestState, ErrUnavailable)
+	}
+	return ErrFailedToSelectLatestState
+}
+
+// 보고 시간이 기존 상태보다 이전이 아닐 때만 새 값으로 바꾸는 upsert 절
+// MySQL은 갱신 절을 왼쪽부터 적용하므로 ReportAt을 마지막에 갱신한다.
+func (r *LatestStateRepo) upsertClause() string {
+	d := r.dialect
+	cols := []string{"BatteryPercent", "Lat", "Lon", "TemperatureCelsius", "IP", "ErrorCode", "ReportedStatus", "ReportAt"}
+
+	sets := make([]string, 0, len(cols))
+	for _, col := range cols {
+		sets = append(sets, fmt.Sprintf("%s = CASE WHEN %s >= device_latest_state.ReportAt THEN %s ELSE device_latest_state.%s END",
+			col, d.Excluded("ReportAt"), d.Excluded(col), col))
+	}
+
+	return d.OnConflict([]string{"ProductNumber"}) + " " + strings.Join(sets, ", ")
+}
+
+// 디바이스와 마지막 보고 상태 조회 (보고가 없는 디바이스는 상태 컬럼이 NULL)
+const deviceWithStateSelect = "SELECT d.InternalID, d.ProductNumber, d.MacAddress, d.ProductLine, d.HardwareRevision, d.FirmwareVersion, " +
+	"d.LastSeenAt, d.CreatedAt, d.ReTry, d.UpdateCheck, d.Status, " +
+	"s.BatteryPercent, s.Lat, s.Lon, s.TemperatureCelsius, s.IP, s.ErrorCode, s.ReportedStatus, s.ReportAt " +
+	"FROM devices d LEFT JOIN device_latest_state s ON s.ProductNumber = d.ProductNumber"
+
+func scanDeviceWithState(rows *sql.Rows) (*data.DeviceWithState, error) {
+	var ds data.DeviceWithState
+	var battery, errorCode sql.NullInt64
+	var lat, lon, temperature sql.NullFloat64
+	var ip, reportedStatus sql.NullString
+	var reportAt sql.NullTime
+
+	d := &ds.Device
+	err := rows.Scan(&d.InternalID, &d.ProductNumber, &d.MacAddress, &d.ProductLine, &d.HardwareRevision, &d.FirmwareVersion,
+		&d.LastSeenAt, &d.CreatedAt, &d.ReTry, &d.UpdateCheck, &d.Status,
+		&battery, &lat, &lon, &temperature, &ip, &errorCode, &reportedStatus, &reportAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if reportAt.Valid {
+		ds.State = &data.DeviceLatestState{
+			ProductNumber:      d.ProductNumber,
+			BatteryPercent:     int(battery.Int64),
+			Lat:                lat.Float64,
+			Lon:                lon.Float64,
+			TemperatureCelsius: temperature.Float64,
+			IP:                 ip.String,
+			ErrorCode:          int(errorCode.Int64),
+			ReportedStatus:     data.DeviceStatus(reportedStatus.String),
+			ReportAt:           reportAt.Time,
+		}
+	}
+
+	return &ds, nil
+}
@@ -0,0 +1,499 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-rest-example/internal/logger"
+)
+
+// 스키마 마이그레이션 SQL 파일 (버전_이름.up.sql / 버전_이름.down.sql)
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+const (
+	migrationLockName    = "schema_migrations"
+	migrationLockTimeout = 60 // 초
+)
+
+// 오류 상수 선언
+var (
+	ErrInvalidMigratorRequired = errors.New("missing required inputs to create Migrator")
+	ErrMigrationLock           = errors.New("failed to acquire schema migration lock")
+	ErrMigrationChecksum       = errors.New("applied migration has been modified")
+	ErrMigrationMissing        = errors.New("applied migration is missing from embedded files")
+	ErrMigrationFailed         = errors.New("failed to apply schema migration")
+	ErrMigrationDirty          = errors.New("schema migration failed halfway and must be fixed manually (migrate force)")
+	ErrMigrationNotDirty       = errors.New("schema migration is not dirty")
+)
+
+// 임베드된 마이그레이션 한 건
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // up 스크립트 sha256 (hex)
+}
+
+// 마이그레이션 적용 현황
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	Dirty     bool // 적용/되돌리기 도중 실패하여 수동 정리가 필요함 (MariaDB/MySQL)
+	AppliedAt time.Time
+}
+
+// Migrator는 임베드된 SQL 파일로 스키마 버전을 관리한다.
+// 여러 인스턴스가 동시에 기동하더라도 DB 잠금으로 한 인스턴스만 마이그레이션을 수행한다.
+//
+// PostgreSQL, SQLite는 마이그레이션 한 건과 적용 기록을 하나의 트랜잭션으로 처리하므로 실패하면 모두 되돌려진다.
+// MariaDB/MySQL은 DDL이 자동 커밋되어 되돌릴 수 없으므로, 실행 전에 적용 기록을 dirty로 남기고 성공하면 해제한다.
+// dirty가 남으면 이후 up/down을 거부하며, 스키마를 수동으로 정리한 뒤 Force로 상태를 확정해야 한다.
+type Migrator struct {
+	dbMgr      DBManager
+	migrations []Migration
+	logger     *logger.AppLogger
+}
+
+func NewMigrator(lgr *logger.AppLogger, dbMgr DBManager) (*Migrator, error) {
+	if lgr == nil || dbMgr == nil {
+		return nil, ErrInvalidMigratorRequired
+	}
+
+	migrations, err := loadMigrations(migrationFiles, dbMgr.Dialect().MigrationsDir())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		dbMgr:      dbMgr,
+		migrations: migrations,
+		logger:     lgr,
+	}, nil
+}
+
+// Up은 적용되지 않은 마이그레이션을 버전 순서대로 모두 적용한다.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		// 이미 적용된 마이그레이션이 변경되지 않았고 중간에 실패한 마이그레이션이 없는지 먼저 확인
+		if err := m.verify(applied); err != nil {
+			return err
+		}
+		if err := m.checkDirty(applied); err != nil {
+			return err
+		}
+
+		for _, mg := range m.migrations {
+			if _, ok := applied[mg.Version]; ok {
+				continue
+			}
+
+			m.logger.Info().Int("version", mg.Version).Str("name", mg.Name).Msg("applying schema migration")
+			if err := m.run(ctx, conn, mg, mg.Up, true); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down은 가장 최근에 적용된 마이그레이션부터 steps개를 되돌린다.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.checkDirty(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			mg := m.migrations[i]
+			if _, ok := applied[mg.Version]; !ok {
+				continue
+			}
+
+			m.logger.Info().Int("version", mg.Version).Str("name", mg.Name).Msg("reverting schema migration")
+			if err := m.run(ctx, conn, mg, mg.Down, false); err != nil {
+				return err
+			}
+			steps--
+		}
+
+		return nil
+	})
+}
+
+// Force는 dirty로 남은 마이그레이션의 상태를 수동 정리 결과에 맞춰 확정한다.
+// applied가 true이면 적용된 것으로, false이면 적용되지 않은 것으로 기록한다. (스크립트는 실행하지 않음)
+func (m *Migrator) Force(ctx context.Context, version int, applied bool) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		if _, err := m.applied(ctx, conn); err != nil {
+			return err
+		}
+
+		dialect := m.dbMgr.Dialect()
+		query := "UPDATE schema_migrations SET Dirty = 0 WHERE Version = ? AND Dirty = 1"
+		if !applied {
+			query = "DELETE FROM schema_migrations WHERE Version = ? AND Dirty = 1"
+		}
+
+		result, err := conn.ExecContext(ctx, dialect.Rebind(query), version)
+		if err != nil {
+			m.logger.Error().Err(err).Int("version", version).Msg("failed to force schema migration")
+			return ErrMigrationFailed
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			return ErrMigrationNotDirty
+		}
+
+		m.logger.Info().Int("version", version).Bool("applied", applied).Msg("forced schema migration state")
+		return nil
+	})
+}
+
+// 스크립트를 실행하고 적용 기록을 남긴다. (up이면 기록 추가, down이면 기록 삭제)
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, mg Migration, script string, up bool) error {
+	dialect := m.dbMgr.Dialect()
+
+	// DDL을 되돌릴 수 없는 엔진은 실행 전에 dirty로 기록하고 성공 후 확정한다
+	if !dialect.TransactionalDDL() {
+		if err := m.markDirty(ctx, conn, mg, up); err != nil {
+			return err
+		}
+		if err := execScript(ctx, conn, script); err != nil {
+			m.logger.Error().Err(err).Int("version", mg.Version).Bool("up", up).
+				Msg("schema migration failed halfway, left dirty for manual fix")
+			return ErrMigrationDirty
+		}
+		return m.record(ctx, conn, mg, up)
+	}
+
+	// 스크립트와 적용 기록을 하나의 트랜잭션으로 처리하고 실패 시 모두 되돌린다
+	if err := dialect.BeginMigration(ctx, conn); err != nil {
+		m.logger.Error().Err(err).Int("version", mg.Version).Msg("failed to begin schema migration transaction")
+		return ErrMigrationFailed
+	}
+
+	err := execScript(ctx, conn, script)
+	if err != nil {
+		m.logger.Error().Err(err).Int("version", mg.Version).Bool("up", up).Msg("failed to run schema migration, rolling back")
+	} else {
+		err = m.record(ctx, conn, mg, up)
+	}
+	if err != nil {
+		if rbErr := dialect.EndMigration(context.Background(), conn, false); rbErr != nil {
+			m.logger.Error().Err(rbErr).Int("version", mg.Version).Msg("failed to roll back schema migration")
+		}
+		return ErrMigrationFailed
+	}
+
+	if err := dialect.EndMigration(ctx, conn, true); err != nil {
+		m.logger.Error().Err(err).Int("version", mg.Version).Msg("failed to commit schema migration")
+		return ErrMigrationFailed
+	}
+	return nil
+}
+
+// 적용 완료 기록 (up이면 추가 또는 dirty 해제, down이면 삭제)
+func (m *Migrator) record(ctx context.Context, conn *sql.Conn, mg Migration, up bool) error {
+	dialect := m.dbMgr.Dialect()
+
+	var err error
+	switch {
+	case !up:
+		_, err = conn.ExecContext(ctx, dialect.Rebind("DELETE FROM schema_migrations WHERE Version = ?"), mg.Version)
+	case dialect.TransactionalDDL():
+		_, err = conn.ExecContext(ctx, dialect.Rebind("INSERT INTO schema_migrations (Version, Name, Checksum, Dirty, AppliedAt) VALUES (?, ?, ?, 0, ?)"),
+			mg.Version, mg.Name, mg.Checksum, time.Now())
+	default:
+		_, err = conn.ExecContext(ctx, dialect.Rebind("UPDATE schema_migrations SET Dirty = 0, AppliedAt = ? WHERE Version = ?"),
+			time.Now(), mg.Version)
+	}
+	if err != nil {
+		m.logger.Error().Err(err).Int("version", mg.Version).Msg("failed to record schema migration")
+		return ErrMigrationFailed
+	}
+	return nil
+}
+
+// 실행 전 dirty 기록 (up이면 dirty 상태로 추가, down이면 기존 기록을 dirty로 변경)
+func (m *Migrator) markDirty(ctx context.Context, conn *sql.Conn, mg Migration, up bool) error {
+	dialect := m.dbMgr.Dialect()
+
+	var err error
+	if up {
+		_, err = conn.ExecContext(ctx, dialect.Rebind("INSERT INTO schema_migrations (Version, Name, Checksum, Dirty, AppliedAt) VALUES (?, ?, ?, 1, ?)"),
+			mg.Version, mg.Name, mg.Checksum, time.Now())
+	} else {
+		_, err = conn.ExecContext(ctx, dialect.Rebind("UPDATE schema_migrations SET Dirty = 1 WHERE Version = ?"), mg.Version)
+	}
+	if err != nil {
+		m.logger.Error().Err(err).Int("version", mg.Version).Msg("failed to record schema migration")
+		return ErrMigrationFailed
+	}
+	return nil
+}
+
+// Status는 임베드된 마이그레이션별 적용 여부를 반환한다.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mg := range m.migrations {
+			status := MigrationStatus{Version: mg.Version, Name: mg.Name}
+			if row, ok := applied[mg.Version]; ok {
+				status.Applied = true
+				status.Dirty = row.dirty
+				status.AppliedAt = row.appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+type appliedMigration struct {
+	checksum  string
+	dirty     bool
+	appliedAt time.Time
+}
+
+// 적용 이력 테이블을 준비하고 적용된 버전 목록을 조회한다.
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int]appliedMigration, error) {
+	query := "CREATE TABLE IF NOT EXISTS schema_migrations (" +
+		"Version INT NOT NULL, " +
+		"Name VARCHAR(255) NOT NULL, " +
+		"Checksum CHAR(64) NOT NULL, " +
+		"Dirty INT NOT NULL DEFAULT 0, " +
+		"AppliedAt " + m.dbMgr.Dialect().TimestampType() + " NOT NULL, " +
+		"PRIMARY KEY (Version))"
+	if _, err := conn.ExecContext(ctx, query); err != nil {
+		m.logger.Error().Err(err).Msg("failed to create schema_migrations table")
+		return nil, ErrMigrationFailed
+	}
+
+	// Dirty 컬럼이 추가되기 전에 만들어진 테이블이면 컬럼을 추가한다
+	if rows, err := conn.QueryContext(ctx, "SELECT Dirty FROM schema_migrations WHERE 1 = 0"); err == nil {
+		rows.Close()
+	} else if _, err := conn.ExecContext(ctx, "ALTER TABLE schema_migrations ADD COLUMN Dirty INT NOT NULL DEFAULT 0"); err != nil {
+		m.logger.Error().Err(err).Msg("failed to add schema_migrations dirty column")
+		return nil, ErrMigrationFailed
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT Version, Checksum, Dirty, AppliedAt FROM schema_migrations")
+	if err != nil {
+		m.logger.Error().Err(err).Msg("failed to select schema_migrations")
+		return nil, ErrMigrationFailed
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var version int
+		var row appliedMigration
+		if err := rows.Scan(&version, &row.checksum, &row.dirty, &row.appliedAt); err != nil {
+			m.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, err
+		}
+		applied[version] = row
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) verify(applied map[int]appliedMigration) error {
+	known := make(map[int]Migration, len(m.migrations))
+	for _, mg := range m.migrations {
+		known[mg.Version] = mg
+	}
+
+	for version, row := range applied {
+		mg, ok := known[version]
+		if !ok {
+			m.logger.Error().Int("version", version).Msg("applied migration is missing")
+			return ErrMigrationMissing
+		}
+		if mg.Checksum != row.checksum {
+			m.logger.Error().Int("version", version).Str("name", mg.Name).Msg("applied migration checksum mismatch")
+			return ErrMigrationChecksum
+		}
+	}
+
+	return nil
+}
+
+// 중간에 실패한 마이그레이션이 있으면 수동 정리 전까지 다른 마이그레이션을 실행하지 않는다
+func (m *Migrator) checkDirty(applied map[int]appliedMigration) error {
+	for version, row := range applied {
+		if row.dirty {
+			m.logger.Error().Int("version", version).Msg("schema migration is dirty, fix the schema manually and run migrate force")
+			return ErrMigrationDirty
+		}
+	}
+	return nil
+}
+
+// 전용 세션에서 마이그레이션 잠금을 획득한 뒤 fn을 실행한다.
+// 잠금은 세션 단위이므로 잠금과 마이그레이션이 같은 커넥션을 사용해야 한다.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.dbMgr.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	dialect := m.dbMgr.Dialect()
+	if err := dialect.Lock(ctx, conn, migrationLockName); err != nil {
+		m.logger.Error().Err(err).Msg("failed to acquire schema migration lock")
+		return ErrMigrationLock
+	}
+
+	defer func() {
+		// 요청 컨텍스트가 취소되었더라도 잠금은 해제한다
+		if err := dialect.Unlock(context.Background(), conn, migrationLockName); err != nil {
+			m.logger.Error().Err(err).Msg("failed to release schema migration lock")
+		}
+	}()
+
+	return fn(conn)
+}
+
+// 스크립트를 문장 단위로 나누어 실행한다. (드라이버 multiStatements 옵션 없이 동작)
+func execScript(ctx context.Context, conn *sql.Conn, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("%w: %s", err, firstLine(stmt))
+		}
+	}
+	return nil
+}
+
+// 줄 끝의 세미콜론을 기준으로 문장을 나누고 주석 줄은 제외한다.
+func splitStatements(script string) []string {
+	var stmts []string
+	var current strings.Builder
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+
+	return stmts
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// 디렉터리의 up/down 파일 쌍을 버전 순서로 읽는다.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		prefix, rest, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version: %s", name)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		mg, ok := byVersion[version]
+		if !ok {
+			mg = &Migration{Version: version, Name: strings.TrimSuffix(rest, "."+direction+".sql")}
+			byVersion[version] = mg
+		}
+
+		if direction == "up" {
+			sum := sha256.Sum256(content)
+			mg.Up = string(content)
+			mg.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			mg.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mg := range byVersion {
+		if mg.Up == "" || mg.Down == "" {
+			return nil, fmt.Errorf("migration %d must have both up and down files", mg.Version)
+		}
+		migrations = append(migrations, *mg)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
@@ -0,0 +1,244 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func testMigrations(t *testing.T, files fstest.MapFS) []Migration {
+	t.Helper()
+
+	migrations, err := loadMigrations(files, "m")
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	return migrations
+}
+
+var testMigrationFiles = fstest.MapFS{
+	"m/0001_create_items.up.sql":     {Data: []byte("CREATE TABLE items (ItemID INT NOT NULL, PRIMARY KEY (ItemID));")},
+	"m/0001_create_items.down.sql":   {Data: []byte("DROP TABLE items;")},
+	"m/0002_create_parts.up.sql":     {Data: []byte("CREATE TABLE parts (PartID INT NOT NULL, PRIMARY KEY (PartID));\nINSERT INTO parts (PartID) VALUES (1);")},
+	"m/0002_create_parts.down.sql":   {Data: []byte("DROP TABLE parts;")},
+	"m/0003_create_widgets.up.sql":   {Data: []byte("CREATE TABLE widgets (WidgetID INT NOT NULL, PRIMARY KEY (WidgetID));")},
+	"m/0003_create_widgets.down.sql": {Data: []byte("DROP TABLE widgets;")},
+}
+
+func newTestMigrator(t *testing.T, mgr DBManager, migrations []Migration) *Migrator {
+	t.Helper()
+
+	migrator, err := NewMigrator(testLogger(), mgr)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	migrator.migrations = migrations
+	return migrator
+}
+
+func appliedVersions(t *testing.T, migrator *Migrator) []int {
+	t.Helper()
+
+	statuses, err := migrator.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	var versions []int
+	for _, status := range statuses {
+		if status.Applied {
+			versions = append(versions, status.Version)
+		}
+	}
+	return versions
+}
+
+func tableExists(mgr DBManager, table string) bool {
+	rows, err := mgr.DB().QueryContext(context.Background(), "SELECT 1 FROM "+table+" WHERE 1 = 0")
+	if err != nil {
+		return false
+	}
+	rows.Close()
+	return true
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations := testMigrations(t, testMigrationFiles)
+	if len(migrations) != 3 {
+		t.Fatalf("loaded %d migrations, want 3", len(migrations))
+	}
+	for i, mg := range migrations {
+		if mg.Version != i+1 || mg.Up == "" || mg.Down == "" || len(mg.Checksum) != 64 {
+			t.Errorf("migration %d = %+v", i, mg)
+		}
+	}
+	if migrations[0].Name != "create_items" {
+		t.Errorf("Name = %q, want create_items", migrations[0].Name)
+	}
+
+	// up 또는 down 파일이 없으면 오류
+	if _, err := loadMigrations(fstest.MapFS{"m/0001_a.up.sql": {Data: []byte("SELECT 1;")}}, "m"); err == nil {
+		t.Error("loadMigrations without down file: expected error")
+	}
+
+	// 엔진별 임베드 마이그레이션은 모두 짝이 맞아야 한다
+	for _, dir := range []string{"migrations/sqlite", "migrations/mysql", "migrations/postgres"} {
+		if _, err := loadMigrations(migrationFiles, dir); err != nil {
+			t.Errorf("loadMigrations(%s): %v", dir, err)
+		}
+	}
+}
+
+func TestMigratorUpDown(t *testing.T) {
+	forEachEmptyBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		migrator := newTestMigrator(t, mgr, testMigrations(t, testMigrationFiles))
+		t.Cleanup(func() { migrator.Down(context.Background(), len(migrator.migrations)) })
+
+		if err := migrator.Up(ctx); err != nil {
+			t.Fatalf("Up: %v", err)
+		}
+		if got := appliedVersions(t, migrator); len(got) != 3 {
+			t.Fatalf("applied after Up = %v", got)
+		}
+		// 이미 적용된 마이그레이션은 다시 실행하지 않는다
+		if err := migrator.Up(ctx); err != nil {
+			t.Fatalf("second Up: %v", err)
+		}
+
+		if err := migrator.Down(ctx, 1); err != nil {
+			t.Fatalf("Down(1): %v", err)
+		}
+		if got := appliedVersions(t, migrator); len(got) != 2 || got[1] != 2 {
+			t.Errorf("applied after Down(1) = %v", got)
+		}
+		if tableExists(mgr, "widgets") || !tableExists(mgr, "parts") {
+			t.Error("Down(1) should drop only the last migration")
+		}
+
+		if err := migrator.Down(ctx, 10); err != nil {
+			t.Fatalf("Down(10): %v", err)
+		}
+		if got := appliedVersions(t, migrator); len(got) != 0 {
+			t.Errorf("applied after Down(10) = %v", got)
+		}
+		if tableExists(mgr, "items") {
+			t.Error("items table still exists after reverting all migrations")
+		}
+	})
+}
+
+func TestMigratorVerify(t *testing.T) {
+	forEachEmptyBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		migrations := testMigrations(t, testMigrationFiles)
+		migrator := newTestMigrator(t, mgr, migrations[:2])
+		t.Cleanup(func() {
+			migrator.migrations = migrations
+			migrator.Down(context.Background(), len(migrations))
+		})
+
+		if err := migrator.Up(ctx); err != nil {
+			t.Fatalf("Up: %v", err)
+		}
+
+		// 적용된 마이그레이션 파일이 바뀌면 새 마이그레이션을 적용하지 않는다
+		tampered := append([]Migration(nil), migrations...)
+		tampered[0].Checksum = "0000000000000000000000000000000000000000000000000000000000000000"
+		migrator.migrations = tampered
+		if err := migrator.Up(ctx); !errors.Is(err, ErrMigrationChecksum) {
+			t.Errorf("Up with modified migration: err = %v, want ErrMigrationChecksum", err)
+		}
+
+		// 적용된 마이그레이션 파일이 없어진 경우
+		migrator.migrations = migrations[1:]
+		if err := migrator.Up(ctx); !errors.Is(err, ErrMigrationMissing) {
+			t.Errorf("Up with missing migration: err = %v, want ErrMigrationMissing", err)
+		}
+
+		if tableExists(mgr, "widgets") {
+			t.Error("new migration was applied despite verification failure")
+		}
+	})
+}
+
+func TestMigratorFailedMigration(t *testing.T) {
+	forEachEmptyBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		files := fstest.MapFS{
+			"m/0001_create_items.up.sql":   testMigrationFiles["m/0001_create_items.up.sql"],
+			"m/0001_create_items.down.sql": testMigrationFiles["m/0001_create_items.down.sql"],
+			"m/0002_broken.up.sql":         {Data: []byte("CREATE TABLE broken (BrokenID INT NOT NULL);\nINSERT INTO missing_table (ID) VALUES (1);")},
+			"m/0002_broken.down.sql":       {Data: []byte("DROP TABLE broken;")},
+		}
+		migrator := newTestMigrator(t, mgr, testMigrations(t, files))
+		t.Cleanup(func() {
+			mgr.DB().ExecContext(context.Background(), "DROP TABLE IF EXISTS broken")
+			mgr.DB().ExecContext(context.Background(), "DELETE FROM schema_migrations WHERE Version = 2")
+			migrator.Down(context.Background(), 2)
+		})
+
+		err := migrator.Up(ctx)
+		if mgr.Dialect().TransactionalDDL() {
+			// 실패한 마이그레이션은 스크립트와 적용 기록이 모두 되돌려진다
+			if !errors.Is(err, ErrMigrationFailed) {
+				t.Fatalf("Up: err = %v, want ErrMigrationFailed", err)
+			}
+			if tableExists(mgr, "broken") {
+				t.Error("failed migration was not rolled back")
+			}
+			if got := appliedVersions(t, migrator); len(got) != 1 || got[0] != 1 {
+				t.Errorf("applied = %v, want [1]", got)
+			}
+			return
+		}
+
+		// DDL이 자동 커밋되는 엔진은 dirty로 남아 수동 정리 전까지 진행하지 않는다
+		if !errors.Is(err, ErrMigrationDirty) {
+			t.Fatalf("Up: err = %v, want ErrMigrationDirty", err)
+		}
+		if err := migrator.Up(ctx); !errors.Is(err, ErrMigrationDirty) {
+			t.Errorf("second Up: err = %v, want ErrMigrationDirty", err)
+		}
+		if err := migrator.Force(ctx, 2, false); err != nil {
+			t.Fatalf("Force: %v", err)
+		}
+		if got := appliedVersions(t, migrator); len(got) != 1 || got[0] != 1 {
+			t.Errorf("applied after Force = %v, want [1]", got)
+		}
+	})
+}
+
+func TestMigratorForce(t *testing.T) {
+	forEachEmptyBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		migrator := newTestMigrator(t, mgr, testMigrations(t, testMigrationFiles))
+		t.Cleanup(func() { migrator.Down(context.Background(), len(migrator.migrations)) })
+
+		if err := migrator.Up(ctx); err != nil {
+			t.Fatalf("Up: %v", err)
+		}
+		if err := migrator.Force(ctx, 3, true); !errors.Is(err, ErrMigrationNotDirty) {
+			t.Errorf("Force on clean migration: err = %v, want ErrMigrationNotDirty", err)
+		}
+
+		// 중간에 실패한 것으로 기록된 마이그레이션
+		if _, err := mgr.DB().ExecContext(ctx, "UPDATE schema_migrations SET Dirty = 1 WHERE Version = 3"); err != nil {
+			t.Fatal(err)
+		}
+		if err := migrator.Down(ctx, 1); !errors.Is(err, ErrMigrationDirty) {
+			t.Errorf("Down while dirty: err = %v, want ErrMigrationDirty", err)
+		}
+		statuses, _ := migrator.Status(ctx)
+		if !statuses[2].Dirty {
+			t.Errorf("Status = %+v, want version 3 dirty", statuses[2])
+		}
+
+		if err := migrator.Force(ctx, 3, true); err != nil {
+			t.Fatalf("Force: %v", err)
+		}
+		if err := migrator.Down(ctx, 1); err != nil {
+			t.Errorf("Down after Force: %v", err)
+		}
+	})
+}
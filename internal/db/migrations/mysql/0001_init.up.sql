@@ -0,0 +1,32 @@
+-- 디바이스 및 주기 보고 기본 테이블
+CREATE TABLE IF NOT EXISTS devices (
+    InternalID       BIGINT       NOT NULL AUTO_INCREMENT,
+    ProductNumber    VARCHAR(9)   NOT NULL,
+    MacAddress       VARCHAR(17)  NOT NULL,
+    ProductLine      VARCHAR(16)  NOT NULL DEFAULT '',
+    HardwareRevision VARCHAR(16)  NOT NULL DEFAULT '',
+    FirmwareVersion  VARCHAR(32)  NOT NULL DEFAULT '',
+    LastSeenAt       DATETIME(6)  NOT NULL,
+    CreatedAt        DATETIME(6)  NOT NULL,
+    ReTry            INT          NOT NULL DEFAULT 0,
+    UpdateCheck      INT          NOT NULL DEFAULT 0,
+    Status           VARCHAR(16)  NOT NULL,
+    PRIMARY KEY (InternalID),
+    UNIQUE KEY uq_devices_product_number (ProductNumber),
+    UNIQUE KEY uq_devices_mac_address (MacAddress)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS reports (
+    ReportID           BIGINT      NOT NULL AUTO_INCREMENT,
+    ProductNumber      VARCHAR(9)  NOT NULL,
+    BatteryPercent     INT         NOT NULL,
+    Lat                DOUBLE      NOT NULL,
+    Lon                DOUBLE      NOT NULL,
+    TemperatureCelsius DOUBLE      NOT NULL,
+    IP                 VARCHAR(45) NOT NULL,
+    ErrorCode          INT         NOT NULL DEFAULT 0,
+    ReportAt           DATETIME(6) NOT NULL,
+    ReportedStatus     VARCHAR(16) NOT NULL,
+    PRIMARY KEY (ReportID),
+    KEY idx_reports_product_report_at (ProductNumber, ReportAt)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS firmware_rollouts;
+DROP TABLE IF EXISTS firmware_campaigns;
+DROP TABLE IF EXISTS firmware_deltas;
+DROP TABLE IF EXISTS firmware_compatibility;
+DROP TABLE IF EXISTS firmwares;
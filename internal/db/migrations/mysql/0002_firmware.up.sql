@@ -0,0 +1,48 @@
+-- 펌웨어 저장소, 델타 패치, 배포 캠페인
+CREATE TABLE IF NOT EXISTS firmwares (
+    Version          VARCHAR(32)  NOT NULL,
+    Path             VARCHAR(512) NOT NULL,
+    Checksum         CHAR(64)     NOT NULL,
+    Size             BIGINT       NOT NULL,
+    MinSourceVersion VARCHAR(32)  NOT NULL DEFAULT '',
+    CreatedAt        DATETIME(6)  NOT NULL,
+    PRIMARY KEY (Version)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS firmware_compatibility (
+    Version          VARCHAR(32) NOT NULL,
+    ProductLine      VARCHAR(16) NOT NULL,
+    HardwareRevision VARCHAR(16) NOT NULL,
+    PRIMARY KEY (Version, ProductLine, HardwareRevision)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS firmware_deltas (
+    FromVersion VARCHAR(32)  NOT NULL,
+    ToVersion   VARCHAR(32)  NOT NULL,
+    Path        VARCHAR(512) NOT NULL,
+    Checksum    CHAR(64)     NOT NULL,
+    Size        BIGINT       NOT NULL,
+    CreatedAt   DATETIME(6)  NOT NULL,
+    PRIMARY KEY (FromVersion, ToVersion)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS firmware_campaigns (
+    CampaignID    BIGINT      NOT NULL AUTO_INCREMENT,
+    TargetVersion VARCHAR(32) NOT NULL DEFAULT '',
+    RollbackOf    BIGINT      NOT NULL DEFAULT 0,
+    Status        VARCHAR(16) NOT NULL,
+    CreatedAt     DATETIME(6) NOT NULL,
+    PRIMARY KEY (CampaignID)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS firmware_rollouts (
+    CampaignID    BIGINT      NOT NULL,
+    ProductNumber VARCHAR(9)  NOT NULL,
+    FromVersion   VARCHAR(32) NOT NULL,
+    TargetVersion VARCHAR(32) NOT NULL,
+    State         VARCHAR(16) NOT NULL,
+    UpdatedAt     DATETIME(6) NOT NULL,
+    PRIMARY KEY (CampaignID, ProductNumber),
+    KEY idx_rollouts_product (ProductNumber, CampaignID),
+    KEY idx_rollouts_campaign_state (CampaignID, State)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
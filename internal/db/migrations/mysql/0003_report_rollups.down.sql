@@ -0,0 +1,2 @@
+DROP INDEX idx_reports_report_at ON reports;
+DROP TABLE IF EXISTS report_rollups;
@@ -0,0 +1,23 @@
+-- 보존 기간이 지난 주기 보고의 시간/일 단위 요약
+-- 평균은 합계/건수로 계산하여 여러 번 나누어 집계해도 합칠 수 있도록 한다
+CREATE TABLE IF NOT EXISTS report_rollups (
+    ProductNumber  VARCHAR(9)  NOT NULL,
+    Granularity    VARCHAR(8)  NOT NULL,
+    BucketStart    DATETIME(6) NOT NULL,
+    Samples        BIGINT      NOT NULL,
+    BatteryMin     INT         NOT NULL,
+    BatteryMax     INT         NOT NULL,
+    BatterySum     BIGINT      NOT NULL,
+    TemperatureMin DOUBLE      NOT NULL,
+    TemperatureMax DOUBLE      NOT NULL,
+    TemperatureSum DOUBLE      NOT NULL,
+    ErrorCount     BIGINT      NOT NULL,
+    LastLat        DOUBLE      NOT NULL,
+    LastLon        DOUBLE      NOT NULL,
+    LastReportAt   DATETIME(6) NOT NULL,
+    PRIMARY KEY (ProductNumber, Granularity, BucketStart),
+    KEY idx_report_rollups_bucket (Granularity, BucketStart)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 보존 기간 기준 삭제 대상 조회용
+CREATE INDEX idx_reports_report_at ON reports (ReportAt);
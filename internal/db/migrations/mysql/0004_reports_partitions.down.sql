@@ -0,0 +1,2 @@
+ALTER TABLE reports REMOVE PARTITIONING;
+ALTER TABLE reports DROP PRIMARY KEY, ADD PRIMARY KEY (ReportID);
@@ -0,0 +1,8 @@
+-- 주기 보고를 ReportAt 기준 월 단위 RANGE 파티션으로 관리
+-- 파티션 키는 모든 unique key에 포함되어야 하므로 기본 키에 ReportAt을 추가한다
+ALTER TABLE reports DROP PRIMARY KEY, ADD PRIMARY KEY (ReportID, ReportAt);
+
+-- 월별 파티션(pYYYYMM)은 서버가 미리 생성하며(REORGANIZE pmax), 그 전까지는 pmax에 저장된다
+ALTER TABLE reports PARTITION BY RANGE COLUMNS (ReportAt) (
+    PARTITION pmax VALUES LESS THAN (MAXVALUE)
+);
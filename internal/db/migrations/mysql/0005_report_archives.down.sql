@@ -0,0 +1,2 @@
+ALTER TABLE reports DROP KEY idx_reports_restored_at, DROP COLUMN RestoredAt;
+DROP TABLE IF EXISTS report_archives;
@@ -0,0 +1,20 @@
+-- 보관 기간이 지난 보고를 디바이스/일 단위 압축 파일로 보관한 목록 (manifest)
+CREATE TABLE IF NOT EXISTS report_archives (
+    ArchiveID     BIGINT       NOT NULL AUTO_INCREMENT,
+    ProductNumber VARCHAR(9)   NOT NULL,
+    DayStart      DATETIME(6)  NOT NULL,
+    ObjectKey     VARCHAR(255) NOT NULL,
+    Format        VARCHAR(16)  NOT NULL,
+    Records       BIGINT       NOT NULL,
+    Bytes         BIGINT       NOT NULL,
+    Checksum      CHAR(64)     NOT NULL,
+    FirstReportAt DATETIME(6)  NOT NULL,
+    LastReportAt  DATETIME(6)  NOT NULL,
+    CreatedAt     DATETIME(6)  NOT NULL,
+    PRIMARY KEY (ArchiveID),
+    UNIQUE KEY uq_report_archives_object_key (ObjectKey),
+    KEY idx_report_archives_day (DayStart, ProductNumber)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 보관 파일에서 복원한 보고 표시 (보존 작업이 다시 요약/삭제하지 않도록 함)
+ALTER TABLE reports ADD COLUMN RestoredAt DATETIME(6) NULL, ADD KEY idx_reports_restored_at (RestoredAt);
@@ -0,0 +1,23 @@
+-- 디바이스별 마지막 보고 상태 (보고 저장 시 함께 갱신)
+-- 현재 배터리/위치/온도 조회 및 배터리 조건 목록 조회를 reports 검색 없이 처리한다
+CREATE TABLE IF NOT EXISTS device_latest_state (
+    ProductNumber      VARCHAR(9)  NOT NULL,
+    BatteryPercent     INT         NOT NULL,
+    Lat                DOUBLE      NOT NULL,
+    Lon                DOUBLE      NOT NULL,
+    TemperatureCelsius DOUBLE      NOT NULL,
+    IP                 VARCHAR(45) NOT NULL,
+    ErrorCode          INT         NOT NULL DEFAULT 0,
+    ReportedStatus     VARCHAR(16) NOT NULL,
+    ReportAt           DATETIME(6) NOT NULL,
+    PRIMARY KEY (ProductNumber),
+    KEY idx_device_latest_state_battery (BatteryPercent)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 기존 보고 중 디바이스별 마지막 보고로 채운다
+INSERT INTO device_latest_state (ProductNumber, BatteryPercent, Lat, Lon, TemperatureCelsius, IP, ErrorCode, ReportedStatus, ReportAt)
+SELECT r.ProductNumber, r.BatteryPercent, r.Lat, r.Lon, r.TemperatureCelsius, r.IP, r.ErrorCode, r.ReportedStatus, r.ReportAt
+FROM reports r
+WHERE r.ReportID = (
+    SELECT l.ReportID FROM reports l WHERE l.ProductNumber = r.ProductNumber ORDER BY l.ReportAt DESC, l.ReportID DESC LIMIT 1
+);
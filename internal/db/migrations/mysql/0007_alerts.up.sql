@@ -0,0 +1,40 @@
+-- 알림 규칙과 규칙별 디바이스 알림
+CREATE TABLE IF NOT EXISTS alert_rules (
+    RuleID      BIGINT       NOT NULL AUTO_INCREMENT,
+    Name        VARCHAR(64)  NOT NULL,
+    Kind        VARCHAR(32)  NOT NULL,
+    Severity    VARCHAR(16)  NOT NULL,
+    ProductLine VARCHAR(16)  NOT NULL DEFAULT '',
+    MinValue    DOUBLE       NULL,
+    MaxValue    DOUBLE       NULL,
+    ErrorCodes  VARCHAR(255) NOT NULL DEFAULT '',
+    Count       INT          NOT NULL DEFAULT 0,
+    Enabled     BOOLEAN      NOT NULL DEFAULT TRUE,
+    CreatedAt   DATETIME(6)  NOT NULL,
+    UpdatedAt   DATETIME(6)  NOT NULL,
+    PRIMARY KEY (RuleID)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- OpenKey는 열린(firing, acknowledged) 알림만 "RuleID:ProductNumber" 값을 가지며 해소되면 NULL로 바뀐다
+-- 같은 규칙/디바이스의 열린 알림이 하나만 유지되도록 unique 제약으로 중복을 막는다
+CREATE TABLE IF NOT EXISTS alerts (
+    AlertID        BIGINT       NOT NULL AUTO_INCREMENT,
+    RuleID         BIGINT       NOT NULL,
+    ProductNumber  VARCHAR(9)   NOT NULL,
+    Severity       VARCHAR(16)  NOT NULL,
+    State          VARCHAR(16)  NOT NULL,
+    Message        VARCHAR(255) NOT NULL,
+    ObservedValue  DOUBLE       NOT NULL DEFAULT 0,
+    Occurrences    BIGINT       NOT NULL DEFAULT 1,
+    FiredAt        DATETIME(6)  NOT NULL,
+    LastSeenAt     DATETIME(6)  NOT NULL,
+    AcknowledgedAt DATETIME(6)  NULL,
+    AcknowledgedBy VARCHAR(64)  NOT NULL DEFAULT '',
+    ResolvedAt     DATETIME(6)  NULL,
+    OpenKey        VARCHAR(64)  NULL,
+    PRIMARY KEY (AlertID),
+    UNIQUE KEY uq_alerts_open (OpenKey),
+    KEY idx_alerts_product (ProductNumber, AlertID),
+    KEY idx_alerts_state (State, AlertID),
+    KEY idx_alerts_rule (RuleID, AlertID)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
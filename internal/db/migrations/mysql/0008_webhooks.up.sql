@@ -0,0 +1,31 @@
+-- 웹훅 구독과 구독별 이벤트 전송 기록
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    SubscriptionID BIGINT       NOT NULL AUTO_INCREMENT,
+    URL            VARCHAR(512) NOT NULL,
+    EventTypes     VARCHAR(255) NOT NULL,
+    Secret         VARCHAR(128) NOT NULL,
+    Enabled        BOOLEAN      NOT NULL DEFAULT TRUE,
+    CreatedAt      DATETIME(6)  NOT NULL,
+    UpdatedAt      DATETIME(6)  NOT NULL,
+    PRIMARY KEY (SubscriptionID)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 전송 대기(pending) 중 NextAttemptAt이 지난 전송을 재시도하며, 최대 시도 횟수를 넘으면 dead로 남긴다
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    DeliveryID     BIGINT       NOT NULL AUTO_INCREMENT,
+    SubscriptionID BIGINT       NOT NULL,
+    EventID        VARCHAR(36)  NOT NULL,
+    EventType      VARCHAR(32)  NOT NULL,
+    Payload        MEDIUMTEXT   NOT NULL,
+    State          VARCHAR(16)  NOT NULL,
+    Attempts       INT          NOT NULL DEFAULT 0,
+    NextAttemptAt  DATETIME(6)  NOT NULL,
+    LastAttemptAt  DATETIME(6)  NULL,
+    LastStatusCode INT          NOT NULL DEFAULT 0,
+    LastError      VARCHAR(512) NOT NULL DEFAULT '',
+    CreatedAt      DATETIME(6)  NOT NULL,
+    DeliveredAt    DATETIME(6)  NULL,
+    PRIMARY KEY (DeliveryID),
+    KEY idx_webhook_deliveries_due (State, NextAttemptAt),
+    KEY idx_webhook_deliveries_subscription (SubscriptionID, DeliveryID)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,3 @@
+DROP INDEX uq_webhook_deliveries_event ON webhook_deliveries;
+DROP TABLE IF EXISTS outbox_leases;
+DROP TABLE IF EXISTS outbox;
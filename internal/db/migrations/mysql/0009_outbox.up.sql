@@ -0,0 +1,26 @@
+-- 저장소 변경과 같은 트랜잭션으로 기록하는 도메인 이벤트 (전달 후 삭제)
+CREATE TABLE IF NOT EXISTS outbox (
+    OutboxID      BIGINT       NOT NULL AUTO_INCREMENT,
+    EventID       VARCHAR(36)  NOT NULL,
+    EventType     VARCHAR(32)  NOT NULL,
+    ProductNumber VARCHAR(16)  NOT NULL DEFAULT '',
+    OccurredAt    DATETIME(6)  NOT NULL,
+    Payload       MEDIUMTEXT   NOT NULL,
+    Attempts      INT          NOT NULL DEFAULT 0,
+    LastError     VARCHAR(512) NOT NULL DEFAULT '',
+    CreatedAt     DATETIME(6)  NOT NULL,
+    PRIMARY KEY (OutboxID)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 이벤트 전달 작업을 한 인스턴스만 실행하기 위한 임대
+CREATE TABLE IF NOT EXISTS outbox_leases (
+    Name       VARCHAR(64) NOT NULL,
+    Owner      VARCHAR(64) NOT NULL DEFAULT '',
+    LeaseUntil DATETIME(6) NOT NULL,
+    PRIMARY KEY (Name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT INTO outbox_leases (Name, Owner, LeaseUntil) VALUES ('outbox_relay', '', '1970-01-01 00:00:01');
+
+-- 같은 이벤트가 다시 전달되어도 구독마다 전송을 한 번만 기록한다
+CREATE UNIQUE INDEX uq_webhook_deliveries_event ON webhook_deliveries (SubscriptionID, EventID);
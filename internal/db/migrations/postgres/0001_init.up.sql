@@ -0,0 +1,31 @@
+-- 디바이스 및 주기 보고 기본 테이블
+CREATE TABLE IF NOT EXISTS devices (
+    InternalID       BIGINT       GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    ProductNumber    VARCHAR(9)   NOT NULL,
+    MacAddress       VARCHAR(17)  NOT NULL,
+    ProductLine      VARCHAR(16)  NOT NULL DEFAULT '',
+    HardwareRevision VARCHAR(16)  NOT NULL DEFAULT '',
+    FirmwareVersion  VARCHAR(32)  NOT NULL DEFAULT '',
+    LastSeenAt       TIMESTAMPTZ  NOT NULL,
+    CreatedAt        TIMESTAMPTZ  NOT NULL,
+    ReTry            INTEGER      NOT NULL DEFAULT 0,
+    UpdateCheck      INTEGER      NOT NULL DEFAULT 0,
+    Status           VARCHAR(16)  NOT NULL,
+    CONSTRAINT uq_devices_product_number UNIQUE (ProductNumber),
+    CONSTRAINT uq_devices_mac_address UNIQUE (MacAddress)
+);
+
+CREATE TABLE IF NOT EXISTS reports (
+    ReportID           BIGINT           GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    ProductNumber      VARCHAR(9)       NOT NULL,
+    BatteryPercent     INTEGER          NOT NULL,
+    Lat                DOUBLE PRECISION NOT NULL,
+    Lon                DOUBLE PRECISION NOT NULL,
+    TemperatureCelsius DOUBLE PRECISION NOT NULL,
+    IP                 VARCHAR(45)      NOT NULL,
+    ErrorCode          INTEGER          NOT NULL DEFAULT 0,
+    ReportAt           TIMESTAMPTZ      NOT NULL,
+    ReportedStatus     VARCHAR(16)      NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_reports_product_report_at ON reports (ProductNumber, ReportAt);
@@ -0,0 +1,47 @@
+-- 펌웨어 저장소, 델타 패치, 배포 캠페인
+CREATE TABLE IF NOT EXISTS firmwares (
+    Version          VARCHAR(32)  NOT NULL PRIMARY KEY,
+    Path             VARCHAR(512) NOT NULL,
+    Checksum         CHAR(64)     NOT NULL,
+    Size             BIGINT       NOT NULL,
+    MinSourceVersion VARCHAR(32)  NOT NULL DEFAULT '',
+    CreatedAt        TIMESTAMPTZ  NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS firmware_compatibility (
+    Version          VARCHAR(32) NOT NULL,
+    ProductLine      VARCHAR(16) NOT NULL,
+    HardwareRevision VARCHAR(16) NOT NULL,
+    PRIMARY KEY (Version, ProductLine, HardwareRevision)
+);
+
+CREATE TABLE IF NOT EXISTS firmware_deltas (
+    FromVersion VARCHAR(32)  NOT NULL,
+    ToVersion   VARCHAR(32)  NOT NULL,
+    Path        VARCHAR(512) NOT NULL,
+    Checksum    CHAR(64)     NOT NULL,
+    Size        BIGINT       NOT NULL,
+    CreatedAt   TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (FromVersion, ToVersion)
+);
+
+CREATE TABLE IF NOT EXISTS firmware_campaigns (
+    CampaignID    BIGINT      GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    TargetVersion VARCHAR(32) NOT NULL DEFAULT '',
+    RollbackOf    BIGINT      NOT NULL DEFAULT 0,
+    Status        VARCHAR(16) NOT NULL,
+    CreatedAt     TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS firmware_rollouts (
+    CampaignID    BIGINT      NOT NULL,
+    ProductNumber VARCHAR(9)  NOT NULL,
+    FromVersion   VARCHAR(32) NOT NULL,
+    TargetVersion VARCHAR(32) NOT NULL,
+    State         VARCHAR(16) NOT NULL,
+    UpdatedAt     TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (CampaignID, ProductNumber)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rollouts_product ON firmware_rollouts (ProductNumber, CampaignID);
+CREATE INDEX IF NOT EXISTS idx_rollouts_campaign_state ON firmware_rollouts (CampaignID, State);
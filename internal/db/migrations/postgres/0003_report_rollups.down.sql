@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_reports_report_at;
+DROP TABLE IF EXISTS report_rollups;
@@ -0,0 +1,24 @@
+-- 보존 기간이 지난 주기 보고의 시간/일 단위 요약
+-- 평균은 합계/건수로 계산하여 여러 번 나누어 집계해도 합칠 수 있도록 한다
+CREATE TABLE IF NOT EXISTS report_rollups (
+    ProductNumber  VARCHAR(9)       NOT NULL,
+    Granularity    VARCHAR(8)       NOT NULL,
+    BucketStart    TIMESTAMPTZ      NOT NULL,
+    Samples        BIGINT           NOT NULL,
+    BatteryMin     INTEGER          NOT NULL,
+    BatteryMax     INTEGER          NOT NULL,
+    BatterySum     BIGINT           NOT NULL,
+    TemperatureMin DOUBLE PRECISION NOT NULL,
+    TemperatureMax DOUBLE PRECISION NOT NULL,
+    TemperatureSum DOUBLE PRECISION NOT NULL,
+    ErrorCount     BIGINT           NOT NULL,
+    LastLat        DOUBLE PRECISION NOT NULL,
+    LastLon        DOUBLE PRECISION NOT NULL,
+    LastReportAt   TIMESTAMPTZ      NOT NULL,
+    PRIMARY KEY (ProductNumber, Granularity, BucketStart)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_rollups_bucket ON report_rollups (Granularity, BucketStart);
+
+-- 보존 기간 기준 삭제 대상 조회용
+CREATE INDEX IF NOT EXISTS idx_reports_report_at ON reports (ReportAt);
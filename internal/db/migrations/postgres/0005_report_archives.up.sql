@@ -0,0 +1,21 @@
+-- 보관 기간이 지난 보고를 디바이스/일 단위 압축 파일로 보관한 목록 (manifest)
+CREATE TABLE IF NOT EXISTS report_archives (
+    ArchiveID     BIGINT       GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    ProductNumber VARCHAR(9)   NOT NULL,
+    DayStart      TIMESTAMPTZ  NOT NULL,
+    ObjectKey     VARCHAR(255) NOT NULL UNIQUE,
+    Format        VARCHAR(16)  NOT NULL,
+    Records       BIGINT       NOT NULL,
+    Bytes         BIGINT       NOT NULL,
+    Checksum      CHAR(64)     NOT NULL,
+    FirstReportAt TIMESTAMPTZ  NOT NULL,
+    LastReportAt  TIMESTAMPTZ  NOT NULL,
+    CreatedAt     TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_archives_day ON report_archives (DayStart, ProductNumber);
+
+-- 보관 파일에서 복원한 보고 표시 (보존 작업이 다시 요약/삭제하지 않도록 함)
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS RestoredAt TIMESTAMPTZ NULL;
+
+CREATE INDEX IF NOT EXISTS idx_reports_restored_at ON reports (RestoredAt);
@@ -0,0 +1,22 @@
+-- 디바이스별 마지막 보고 상태 (보고 저장 시 함께 갱신)
+-- 현재 배터리/위치/온도 조회 및 배터리 조건 목록 조회를 reports 검색 없이 처리한다
+CREATE TABLE IF NOT EXISTS device_latest_state (
+    ProductNumber      VARCHAR(9)       NOT NULL PRIMARY KEY,
+    BatteryPercent     INTEGER          NOT NULL,
+    Lat                DOUBLE PRECISION NOT NULL,
+    Lon                DOUBLE PRECISION NOT NULL,
+    TemperatureCelsius DOUBLE PRECISION NOT NULL,
+    IP                 VARCHAR(45)      NOT NULL,
+    ErrorCode          INTEGER          NOT NULL DEFAULT 0,
+    ReportedStatus     VARCHAR(16)      NOT NULL,
+    ReportAt           TIMESTAMPTZ      NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_device_latest_state_battery ON device_latest_state (BatteryPercent);
+
+-- 기존 보고 중 디바이스별 마지막 보고로 채운다
+INSERT INTO device_latest_state (ProductNumber, BatteryPercent, Lat, Lon, TemperatureCelsius, IP, ErrorCode, ReportedStatus, ReportAt)
+SELECT DISTINCT ON (ProductNumber) ProductNumber, BatteryPercent, Lat, Lon, TemperatureCelsius, IP, ErrorCode, ReportedStatus, ReportAt
+FROM reports
+ORDER BY ProductNumber, ReportAt DESC, ReportID DESC
+ON CONFLICT (ProductNumber) DO NOTHING;
@@ -0,0 +1,39 @@
+-- 알림 규칙과 규칙별 디바이스 알림
+CREATE TABLE IF NOT EXISTS alert_rules (
+    RuleID      BIGINT           GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    Name        VARCHAR(64)      NOT NULL,
+    Kind        VARCHAR(32)      NOT NULL,
+    Severity    VARCHAR(16)      NOT NULL,
+    ProductLine VARCHAR(16)      NOT NULL DEFAULT '',
+    MinValue    DOUBLE PRECISION NULL,
+    MaxValue    DOUBLE PRECISION NULL,
+    ErrorCodes  VARCHAR(255)     NOT NULL DEFAULT '',
+    Count       INTEGER          NOT NULL DEFAULT 0,
+    Enabled     BOOLEAN          NOT NULL DEFAULT TRUE,
+    CreatedAt   TIMESTAMPTZ      NOT NULL,
+    UpdatedAt   TIMESTAMPTZ      NOT NULL
+);
+
+-- OpenKey는 열린(firing, acknowledged) 알림만 "RuleID:ProductNumber" 값을 가지며 해소되면 NULL로 바뀐다
+-- 같은 규칙/디바이스의 열린 알림이 하나만 유지되도록 unique 제약으로 중복을 막는다
+CREATE TABLE IF NOT EXISTS alerts (
+    AlertID        BIGINT           GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    RuleID         BIGINT           NOT NULL,
+    ProductNumber  VARCHAR(9)       NOT NULL,
+    Severity       VARCHAR(16)      NOT NULL,
+    State          VARCHAR(16)      NOT NULL,
+    Message        VARCHAR(255)     NOT NULL,
+    ObservedValue  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    Occurrences    BIGINT           NOT NULL DEFAULT 1,
+    FiredAt        TIMESTAMPTZ      NOT NULL,
+    LastSeenAt     TIMESTAMPTZ      NOT NULL,
+    AcknowledgedAt TIMESTAMPTZ      NULL,
+    AcknowledgedBy VARCHAR(64)      NOT NULL DEFAULT '',
+    ResolvedAt     TIMESTAMPTZ      NULL,
+    OpenKey        VARCHAR(64)      NULL,
+    CONSTRAINT uq_alerts_open UNIQUE (OpenKey)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_product ON alerts (ProductNumber, AlertID);
+CREATE INDEX IF NOT EXISTS idx_alerts_state ON alerts (State, AlertID);
+CREATE INDEX IF NOT EXISTS idx_alerts_rule ON alerts (RuleID, AlertID);
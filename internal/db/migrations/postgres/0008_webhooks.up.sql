@@ -0,0 +1,30 @@
+-- 웹훅 구독과 구독별 이벤트 전송 기록
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    SubscriptionID BIGINT       GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    URL            VARCHAR(512) NOT NULL,
+    EventTypes     VARCHAR(255) NOT NULL,
+    Secret         VARCHAR(128) NOT NULL,
+    Enabled        BOOLEAN      NOT NULL DEFAULT TRUE,
+    CreatedAt      TIMESTAMPTZ  NOT NULL,
+    UpdatedAt      TIMESTAMPTZ  NOT NULL
+);
+
+-- 전송 대기(pending) 중 NextAttemptAt이 지난 전송을 재시도하며, 최대 시도 횟수를 넘으면 dead로 남긴다
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    DeliveryID     BIGINT       GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    SubscriptionID BIGINT       NOT NULL,
+    EventID        VARCHAR(36)  NOT NULL,
+    EventType      VARCHAR(32)  NOT NULL,
+    Payload        TEXT         NOT NULL,
+    State          VARCHAR(16)  NOT NULL,
+    Attempts       INTEGER      NOT NULL DEFAULT 0,
+    NextAttemptAt  TIMESTAMPTZ  NOT NULL,
+    LastAttemptAt  TIMESTAMPTZ  NULL,
+    LastStatusCode INTEGER      NOT NULL DEFAULT 0,
+    LastError      VARCHAR(512) NOT NULL DEFAULT '',
+    CreatedAt      TIMESTAMPTZ  NOT NULL,
+    DeliveredAt    TIMESTAMPTZ  NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (State, NextAttemptAt);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries (SubscriptionID, DeliveryID);
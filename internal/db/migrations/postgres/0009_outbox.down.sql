@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS uq_webhook_deliveries_event;
+DROP TABLE IF EXISTS outbox_leases;
+DROP TABLE IF EXISTS outbox;
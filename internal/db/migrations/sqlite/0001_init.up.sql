@@ -0,0 +1,29 @@
+-- 디바이스 및 주기 보고 기본 테이블
+CREATE TABLE IF NOT EXISTS devices (
+    InternalID       INTEGER      PRIMARY KEY AUTOINCREMENT,
+    ProductNumber    VARCHAR(9)   NOT NULL UNIQUE,
+    MacAddress       VARCHAR(17)  NOT NULL UNIQUE,
+    ProductLine      VARCHAR(16)  NOT NULL DEFAULT '',
+    HardwareRevision VARCHAR(16)  NOT NULL DEFAULT '',
+    FirmwareVersion  VARCHAR(32)  NOT NULL DEFAULT '',
+    LastSeenAt       DATETIME     NOT NULL,
+    CreatedAt        DATETIME     NOT NULL,
+    ReTry            INTEGER      NOT NULL DEFAULT 0,
+    UpdateCheck      INTEGER      NOT NULL DEFAULT 0,
+    Status           VARCHAR(16)  NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS reports (
+    ReportID           INTEGER     PRIMARY KEY AUTOINCREMENT,
+    ProductNumber      VARCHAR(9)  NOT NULL,
+    BatteryPercent     INTEGER     NOT NULL,
+    Lat                REAL        NOT NULL,
+    Lon                REAL        NOT NULL,
+    TemperatureCelsius REAL        NOT NULL,
+    IP                 VARCHAR(45) NOT NULL,
+    ErrorCode          INTEGER     NOT NULL DEFAULT 0,
+    ReportAt           DATETIME    NOT NULL,
+    ReportedStatus     VARCHAR(16) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_reports_product_report_at ON reports (ProductNumber, ReportAt);
@@ -0,0 +1,47 @@
+-- 펌웨어 저장소, 델타 패치, 배포 캠페인
+CREATE TABLE IF NOT EXISTS firmwares (
+    Version          VARCHAR(32)  NOT NULL PRIMARY KEY,
+    Path             VARCHAR(512) NOT NULL,
+    Checksum         CHAR(64)     NOT NULL,
+    Size             INTEGER      NOT NULL,
+    MinSourceVersion VARCHAR(32)  NOT NULL DEFAULT '',
+    CreatedAt        DATETIME     NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS firmware_compatibility (
+    Version          VARCHAR(32) NOT NULL,
+    ProductLine      VARCHAR(16) NOT NULL,
+    HardwareRevision VARCHAR(16) NOT NULL,
+    PRIMARY KEY (Version, ProductLine, HardwareRevision)
+);
+
+CREATE TABLE IF NOT EXISTS firmware_deltas (
+    FromVersion VARCHAR(32)  NOT NULL,
+    ToVersion   VARCHAR(32)  NOT NULL,
+    Path        VARCHAR(512) NOT NULL,
+    Checksum    CHAR(64)     NOT NULL,
+    Size        INTEGER      NOT NULL,
+    CreatedAt   DATETIME     NOT NULL,
+    PRIMARY KEY (FromVersion, ToVersion)
+);
+
+CREATE TABLE IF NOT EXISTS firmware_campaigns (
+    CampaignID    INTEGER     PRIMARY KEY AUTOINCREMENT,
+    TargetVersion VARCHAR(32) NOT NULL DEFAULT '',
+    RollbackOf    INTEGER     NOT NULL DEFAULT 0,
+    Status        VARCHAR(16) NOT NULL,
+    CreatedAt     DATETIME    NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS firmware_rollouts (
+    CampaignID    INTEGER     NOT NULL,
+    ProductNumber VARCHAR(9)  NOT NULL,
+    FromVersion   VARCHAR(32) NOT NULL,
+    TargetVersion VARCHAR(32) NOT NULL,
+    State         VARCHAR(16) NOT NULL,
+    UpdatedAt     DATETIME    NOT NULL,
+    PRIMARY KEY (CampaignID, ProductNumber)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rollouts_product ON firmware_rollouts (ProductNumber, CampaignID);
+CREATE INDEX IF NOT EXISTS idx_rollouts_campaign_state ON firmware_rollouts (CampaignID, State);
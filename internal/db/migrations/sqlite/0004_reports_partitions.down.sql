@@ -0,0 +1 @@
+-- 월 단위 파티션은 MariaDB에서만 사용한다 (버전 번호를 맞추기 위한 빈 마이그레이션)
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_reports_restored_at;
+ALTER TABLE reports DROP COLUMN RestoredAt;
+DROP TABLE IF EXISTS report_archives;
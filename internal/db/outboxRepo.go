@@ -0,0 +1,211 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 다중 row INSERT 한 번에 기록할 이벤트 수 (행당 6개 placeholder)
+const outboxBatch = 500
+
+// 오류 상수 선언
+var (
+	ErrInvalidOutboxRequired = errors.New("missing required inputs to create OutboxRepo")
+	ErrFailedToCreateOutbox  = errors.New("failed to create outbox event")
+	ErrFailedToSelectOutbox  = errors.New("failed to select outbox event")
+	ErrFailedToUpdateOutbox  = errors.New("failed to update outbox event")
+	ErrFailedToDeleteOutbox  = errors.New("failed to delete outbox event")
+	ErrFailedToAcquireLease  = errors.New("failed to acquire outbox lease")
+)
+
+// OutboxRepo를 통해 사용할 메서드를 제약하고 규정하기 위한 인터페이스
+type OutboxDataService interface {
+	Add(ctx context.Context, events []data.Event) error
+	ListPending(ctx context.Context, limit int) ([]data.OutboxEvent, error)
+	Delete(ctx context.Context, outboxIDs []int64) error
+	RecordFailure(ctx context.Context, outboxIDs []int64, lastError string) error
+	Backlog(ctx context.Context) (int64, error)
+	AcquireLease(ctx context.Context, name string, owner string, now time.Time, leaseUntil time.Time) (bool, error)
+	ReleaseLease(ctx context.Context, name string, owner string) error
+	WithTx(tx DBTX) OutboxDataService
+}
+
+// outbox, outbox_leases 테이블을 접근하기 위한 커넥션 관리
+type OutboxRepo struct {
+	connection DBTX
+	dialect    Dialect
+	logger     *logger.AppLogger
+}
+
+func NewOutboxRepo(lgr *logger.AppLogger, db DBTX, dialect Dialect) (*OutboxRepo, error) {
+	if lgr == nil || db == nil || dialect == nil {
+		return nil, ErrInvalidOutboxRequired
+	}
+	return &OutboxRepo{
+		connection: db,
+		dialect:    dialect,
+		logger:     lgr,
+	}, nil
+}
+
+// 트랜잭션에 바인딩된 OutboxRepo 반환 (저장소 변경과 같은 트랜잭션으로 이벤트 기록)
+func (r *OutboxRepo) WithTx(tx DBTX) OutboxDataService {
+	return &OutboxRepo{
+		connection: tx,
+		dialect:    r.dialect,
+		logger:     r.logger,
+	}
+}
+
+// Add - 이벤트를 전달 대기로 기록합니다. 이벤트는 기록된 순서(OutboxID)대로 전달됩니다.
+func (r *OutboxRepo) Add(ctx context.Context, events []data.Event) error {
+	now := time.Now()
+	for start := 0; start < len(events); start += outboxBatch {
+		batch := events[start:min(start+outboxBatch, len(events))]
+
+		values := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch)*6)
+		for i := range batch {
+			e := &batch[i]
+			values = append(values, "(?, ?, ?, ?, ?, ?)")
+			args = append(args, e.EventID, e.Type, e.ProductNumber, e.OccurredAt, string(e.Data), now)
+		}
+
+		query := "INSERT INTO outbox (EventID, EventType, ProductNumber, OccurredAt, Payload, CreatedAt) VALUES " +
+			strings.Join(values, ", ")
+
+		if _, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), args...); err != nil {
+			r.logger.Error().Err(err).Int("events", len(batch)).Msg("failed to create outbox events")
+			return r.wrap(ErrFailedToCreateOutbox, err)
+		}
+	}
+
+	return nil
+}
+
+// ListPending - 전달을 기다리는 이벤트를 기록된 순서로 limit건까지 반환합니다.
+func (r *OutboxRepo) ListPending(ctx context.Context, limit int) ([]data.OutboxEvent, error) {
+	query := "SELECT OutboxID, EventID, EventType, ProductNumber, OccurredAt, Payload, Attempts, LastError, CreatedAt " +
+		"FROM outbox ORDER BY OutboxID " + r.dialect.Limit(limit)
+
+	rows, err := r.connection.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to select outbox events")
+		return nil, r.wrap(ErrFailedToSelectOutbox, err)
+	}
+	defer rows.Close()
+
+	var events []data.OutboxEvent
+	for rows.Next() {
+		var o data.OutboxEvent
+		var payload string
+		if err := rows.Scan(&o.OutboxID, &o.Event.EventID, &o.Event.Type, &o.Event.ProductNumber, &o.Event.OccurredAt,
+			&payload, &o.Attempts, &o.LastError, &o.CreatedAt); err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan outbox event")
+			return nil, ErrFailedToSelectOutbox
+		}
+		o.Event.Data = []byte(payload)
+		events = append(events, o)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error().Err(err).Msg("failed to iterate outbox events")
+		return nil, r.wrap(ErrFailedToSelectOutbox, err)
+	}
+
+	return events, nil
+}
+
+// Delete - 전달한 이벤트를 삭제합니다.
+func (r *OutboxRepo) Delete(ctx context.Context, outboxIDs []int64) error {
+	for start := 0; start < len(outboxIDs); start += outboxBatch {
+		batch := outboxIDs[start:min(start+outboxBatch, len(outboxIDs))]
+
+		query := "DELETE FROM outbox WHERE OutboxID IN (?" + strings.Repeat(", ?", len(batch)-1) + ")"
+		if _, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), int64Args(batch)...); err != nil {
+			r.logger.Error().Err(err).Int("events", len(batch)).Msg("failed to delete outbox events")
+			return r.wrap(ErrFailedToDeleteOutbox, err)
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure - 전달하지 못한 이벤트의 시도 횟수를 늘리고 실패 사유를 기록합니다. (이벤트는 남아 다음 주기에 다시 전달)
+func (r *OutboxRepo) RecordFailure(ctx context.Context, outboxIDs []int64, lastError string) error {
+	for start := 0; start < len(outboxIDs); start += outboxBatch {
+		batch := outboxIDs[start:min(start+outboxBatch, len(outboxIDs))]
+
+		query := "UPDATE outbox SET Attempts = Attempts + 1, LastError = ? WHERE OutboxID IN (?" + strings.Repeat(", ?", len(batch)-1) + ")"
+		args := append([]interface{}{truncate(lastError, 512)}, int64Args(batch)...)
+		if _, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), args...); err != nil {
+			r.logger.Error().Err(err).Int("events", len(batch)).Msg("failed to record outbox failure")
+			return r.wrap(ErrFailedToUpdateOutbox, err)
+		}
+	}
+
+	return nil
+}
+
+// Backlog - 전달을 기다리는 이벤트 수를 반환합니다.
+func (r *OutboxRepo) Backlog(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.connection.QueryRowContext(ctx, "SELECT COUNT(*) FROM outbox").Scan(&count); err != nil {
+		r.logger.Error().Err(err).Msg("failed to count outbox events")
+		return 0, r.wrap(ErrFailedToSelectOutbox, err)
+	}
+
+	return count, nil
+}
+
+// AcquireLease - 임대가 비어 있거나 만료되었거나 이미 owner의 것이면 leaseUntil까지 owner의 임대로 갱신하고 true를 반환합니다.
+// 다른 인스턴스가 임대 중이면 false를 반환합니다.
+func (r *OutboxRepo) AcquireLease(ctx context.Context, name string, owner string, now time.Time, leaseUntil time.Time) (bool, error) {
+	query := "UPDATE outbox_leases SET Owner = ?, LeaseUntil = ? WHERE Name = ? AND (Owner = ? OR LeaseUntil < ?)"
+
+	result, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), owner, leaseUntil, name, owner, now)
+	if err != nil {
+		r.logger.Error().Err(err).Str("lease", name).Msg("failed to acquire outbox lease")
+		return false, r.wrap(ErrFailedToAcquireLease, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, ErrFailedToAcquireLease
+	}
+
+	return affected == 1, nil
+}
+
+// ReleaseLease - owner의 임대를 만료시켜 다른 인스턴스가 바로 가져갈 수 있도록 합니다.
+func (r *OutboxRepo) ReleaseLease(ctx context.Context, name string, owner string) error {
+	query := "UPDATE outbox_leases SET LeaseUntil = ? WHERE Name = ? AND Owner = ?"
+
+	if _, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), time.Unix(1, 0).UTC(), name, owner); err != nil {
+		r.logger.Error().Err(err).Str("lease", name).Msg("failed to release outbox lease")
+		return r.wrap(ErrFailedToAcquireLease, err)
+	}
+
+	return nil
+}
+
+// 데이터베이스에 연결할 수 없는 오류는 ErrUnavailable을 함께 감싼다
+func (r *OutboxRepo) wrap(sentinel error, err error) error {
+	if IsUnavailable(err) {
+		return fmt.Errorf("%w: %w", sentinel, ErrUnavailable)
+	}
+	return sentinel
+}
+
+func int64Args(values []int64) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
@@ -0,0 +1,233 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go-rest-example/internal/logger"
+)
+
+// 파티션 관리 기본값
+const (
+	defaultPartitionsAhead = 3 // 현재 달 이후 미리 만들어 둘 월 파티션 수
+
+	partitionMax        = "pmax"   // 월 파티션 범위를 벗어난 보고를 받는 파티션
+	partitionNameLayout = "200601" // 월 파티션 이름 pYYYYMM
+	partitionBoundFmt   = "2006-01-02 15:04:05"
+)
+
+var partitionNamePattern = regexp.MustCompile(`^p[0-9]{6}$`)
+
+var (
+	ErrInvalidPartitionRequired = errors.New("missing required inputs to create PartitionManager")
+	ErrPartitionsUnsupported    = errors.New("report partitions are supported only on MariaDB/MySQL")
+	ErrReportsNotPartitioned    = errors.New("reports table is not partitioned, apply schema migrations first")
+	ErrFailedToSelectPartitions = errors.New("failed to select report partitions")
+	ErrFailedToAlterPartitions  = errors.New("failed to alter report partitions")
+)
+
+type PartitionConfig struct {
+	Ahead     int           // 현재 달 이후 미리 만들어 둘 월 파티션 수 (기본값 3)
+	Retention time.Duration // 보고 보관 기간, 범위 전체가 지난 파티션을 제거 (0: 제거하지 않음)
+	DryRun    bool          // DDL을 실행하지 않고 로그로만 출력
+}
+
+// PartitionManager는 MariaDB reports 테이블의 ReportAt 기준 월 단위 RANGE 파티션을 관리합니다.
+// 앞으로 쓰일 달의 파티션을 미리 만들고(pmax 분할), 보관 기간이 지난 파티션을 제거합니다.
+// 보관 기간이 지난 보고는 보존 작업이 요약 후 삭제하므로, 비어 있지 않은 파티션은 요약이 끝날 때까지 제거하지 않습니다.
+// 파티션 경계는 UTC 기준입니다.
+type PartitionManager struct {
+	connection DBTX
+	cfg        PartitionConfig
+	logger     *logger.AppLogger
+}
+
+type reportPartition struct {
+	name  string
+	month time.Time // 파티션의 월 (UTC), 범위는 [month, 다음 달)
+}
+
+func NewPartitionManager(lgr *logger.AppLogger, db DBTX, dialect Dialect, cfg PartitionConfig) (*PartitionManager, error) {
+	if lgr == nil || db == nil || dialect == nil {
+		return nil, ErrInvalidPartitionRequired
+	}
+	if dialect.Name() != MySQLDialect.Name() {
+		return nil, ErrPartitionsUnsupported
+	}
+
+	cfg.Ahead = orDefault(cfg.Ahead, defaultPartitionsAhead)
+
+	return &PartitionManager{
+		connection: db,
+		cfg:        cfg,
+		logger:     lgr,
+	}, nil
+}
+
+// Plan - now 기준으로 실행해야 할 파티션 DDL을 반환합니다. 데이터베이스를 변경하지 않습니다.
+func (m *PartitionManager) Plan(ctx context.Context, now time.Time) ([]string, error) {
+	partitions, err := m.partitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ddl []string
+
+	// 1. 현재 달부터 Ahead개월 뒤까지 없는 월 파티션을 pmax에서 분할하여 생성
+	// 마지막 월 파티션 이후로만 추가할 수 있으며, 빠진 달이 있으면 함께 만든다
+	current := monthStart(now)
+	next := current
+	if n := len(partitions); n > 0 {
+		next = partitions[n-1].month.AddDate(0, 1, 0)
+	}
+
+	var defs []string
+	for month := next; !month.After(current.AddDate(0, m.cfg.Ahead, 0)); month = month.AddDate(0, 1, 0) {
+		defs = append(defs, fmt.Sprintf("PARTITION %s VALUES LESS THAN ('%s')",
+			partitionName(month), month.AddDate(0, 1, 0).Format(partitionBoundFmt)))
+	}
+	if len(defs) > 0 {
+		defs = append(defs, "PARTITION "+partitionMax+" VALUES LESS THAN (MAXVALUE)")
+		ddl = append(ddl, fmt.Sprintf("ALTER TABLE reports REORGANIZE PARTITION %s INTO (%s)", partitionMax, strings.Join(defs, ", ")))
+	}
+
+	// 2. 범위 전체가 보관 기간을 지난 파티션 제거 (비어 있는 파티션만)
+	if m.cfg.Retention > 0 {
+		cutoff := now.Add(-m.cfg.Retention)
+
+		var expired []string
+		for _, p := range partitions {
+			if p.month.AddDate(0, 1, 0).After(cutoff) {
+				break
+			}
+
+			empty, err := m.isEmpty(ctx, p.name)
+			if err != nil {
+				return nil, err
+			}
+			if !empty {
+				m.logger.Info().Str("partition", p.name).Msg("expired report partition still has rows, waiting for retention job to summarize them")
+				continue
+			}
+			expired = append(expired, p.name)
+		}
+		if len(expired) > 0 {
+			ddl = append(ddl, "ALTER TABLE reports DROP PARTITION "+strings.Join(expired, ", "))
+		}
+	}
+
+	return ddl, nil
+}
+
+// Apply - Plan의 DDL을 차례로 실행하고 실행한 DDL을 반환합니다.
+// DryRun이면 실행하지 않고 로그로만 출력합니다.
+func (m *PartitionManager) Apply(ctx context.Context, now time.Time) ([]string, error) {
+	ddl, err := m.Plan(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, stmt := range ddl {
+		if m.cfg.DryRun {
+			m.logger.Info().Str("ddl", stmt).Msg("report partition DDL (dry-run)")
+			continue
+		}
+
+		m.logger.Info().Str("ddl", stmt).Msg("altering report partitions")
+		if _, err := m.connection.ExecContext(ctx, stmt); err != nil {
+			m.logger.Error().Err(err).Str("ddl", stmt).Msg("failed to alter report partitions")
+			if IsUnavailable(err) {
+				return ddl[:i], fmt.Errorf("%w: %w", ErrFailedToAlterPartitions, ErrUnavailable)
+			}
+			return ddl[:i], ErrFailedToAlterPartitions
+		}
+	}
+
+	return ddl, nil
+}
+
+// 월 파티션을 오래된 순서로 반환한다 (pmax 제외)
+func (m *PartitionManager) partitions(ctx context.Context) ([]reportPartition, error) {
+	query := "SELECT PARTITION_NAME FROM information_schema.PARTITIONS " +
+		"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'reports' AND PARTITION_NAME IS NOT NULL"
+
+	rows, err := m.connection.QueryContext(ctx, query)
+	if err != nil {
+		m.logger.Error().Err(err).Msg("failed to select report partitions")
+		if IsUnavailable(err) {
+			return nil, fmt.Errorf("%w: %w", ErrFailedToSelectPartitions, ErrUnavailable)
+		}
+		return nil, ErrFailedToSelectPartitions
+	}
+	defer rows.Close()
+
+	var partitions []reportPartition
+	hasMax := false
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			m.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, err
+		}
+
+		switch {
+		case name == partitionMax:
+			hasMax = true
+		case partitionNamePattern.MatchString(name):
+			month, err := time.Parse(partitionNameLayout, name[1:])
+			if err != nil {
+				return nil, err
+			}
+			partitions = append(partitions, reportPartition{name: name, month: month})
+		default:
+			m.logger.Info().Str("partition", name).Msg("ignoring unmanaged report partition")
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// 마이그레이션이 만든 pmax가 없으면 관리할 수 없는 구성이다
+	if !hasMax {
+		return nil, ErrReportsNotPartitioned
+	}
+
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].month.Before(partitions[j].month) })
+	return partitions, nil
+}
+
+func (m *PartitionManager) isEmpty(ctx context.Context, name string) (bool, error) {
+	// 파티션 이름은 partitionNamePattern으로 검증된 값이다
+	query := "SELECT 1 FROM reports PARTITION (" + name + ") LIMIT 1"
+
+	var one int
+	err := m.connection.QueryRowContext(ctx, query).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return true, nil
+	}
+	if err != nil {
+		m.logger.Error().Err(err).Str("partition", name).Msg("failed to select report partition rows")
+		if IsUnavailable(err) {
+			return false, fmt.Errorf("%w: %w", ErrFailedToSelectPartitions, ErrUnavailable)
+		}
+		return false, ErrFailedToSelectPartitions
+	}
+	return false, nil
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func partitionName(month time.Time) string {
+	return "p" + month.Format(partitionNameLayout)
+}
@@ -0,0 +1,168 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go-rest-example/internal/logger"
+
+	_ "github.com/lib/pq" // PostgreSQL 드라이버 구현체 추가
+)
+
+type PostgresCredentials struct {
+	User     string
+	Password string
+	Host     string
+	Port     int
+	Database string
+	SSLMode  string   // disable | require | verify-ca | verify-full (기본값 disable)
+	Replicas []string // 조회 전용 replica 주소 목록 (host:port), 계정 및 DB 이름은 primary와 동일
+}
+
+type PostgresManager struct {
+	db     *sql.DB
+	reader *readRouter
+	logger *logger.AppLogger
+}
+
+// 컴파일 타임에 PostgresManager가 DBManager 인터페이스를 구현하는지 확인합니다.
+var _ DBManager = (*PostgresManager)(nil)
+
+func NewPostgresManager(creds *PostgresCredentials, lgr *logger.AppLogger) (DBManager, error) {
+	lgr.Info().Str("connURL", MaskPostgresDSN(creds)).Msg("connecting to PostgreSQL")
+
+	db, err := openPostgres(creds, creds.Host, creds.Port)
+	if err != nil {
+		lgr.Error().Err(err).Msg("failed to prepare DB connection")
+		return nil, ErrClientInit
+	}
+
+	mgr := &PostgresManager{
+		db:     db,
+		logger: lgr,
+	}
+
+	if err := mgr.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// replica 연결 준비 : 장애 상태의 replica는 상태 확인으로 복구될 때까지 사용하지 않는다
+	replicas := make(map[string]*sql.DB, len(creds.Replicas))
+	for _, addr := range creds.Replicas {
+		host, port, err := splitReplicaAddr(addr)
+		if err == nil {
+			replicas[addr], err = openPostgres(creds, host, port)
+		}
+		if err != nil {
+			lgr.Error().Err(err).Str("replica", addr).Msg("failed to prepare replica connection")
+			for _, r := range replicas {
+				r.Close()
+			}
+			db.Close()
+			return nil, ErrClientInit
+		}
+	}
+	mgr.reader = newReadRouter(db, replicas, lgr)
+
+	return mgr, nil
+}
+
+// 지정한 호스트로 커넥션 풀을 만든다. primary와 replica가 같은 설정을 사용한다.
+func openPostgres(creds *PostgresCredentials, host string, port int) (*sql.DB, error) {
+	sslMode := creds.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	// 비밀번호의 특수문자가 깨지지 않도록 URL 형식으로 조립
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(creds.User, creds.Password),
+		Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Path:     "/" + creds.Database,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+
+	db, err := sql.Open("postgres", dsn.String())
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetConnMaxLifetime(time.Minute * 3)
+	db.SetMaxOpenConns(10)
+	db.SetMaxIdleConns(10)
+
+	return db, nil
+}
+
+// DB - DBTX 인터페이스를 반환합니다.
+func (m *PostgresManager) DB() DBTX {
+	return m.db
+}
+
+// ReadDB - 조회 전용 DBTX를 반환합니다. 읽기는 replica로 분배되고 쓰기는 primary에서 실행됩니다.
+func (m *PostgresManager) ReadDB() DBTX {
+	return m.reader
+}
+
+// Stats - primary 및 replica 커넥션 풀 상태를 반환합니다.
+func (m *PostgresManager) Stats() PoolStats {
+	return PoolStats{
+		Primary:  m.db.Stats(),
+		Replicas: m.reader.Stats(),
+	}
+}
+
+// Conn - 풀에서 전용 커넥션을 하나 꺼내 반환합니다. 사용 후 반드시 Close 해야 합니다.
+func (m *PostgresManager) Conn(ctx context.Context) (*sql.Conn, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		m.logger.Error().Err(err).Msg("failed to get dedicated DB connection")
+		return nil, ErrConnectionEstablish
+	}
+	return conn, nil
+}
+
+// WithTx - 트랜잭션 안에서 fn을 실행하고, 데드락/직렬화 실패 시 트랜잭션 전체를 재시도합니다.
+func (m *PostgresManager) WithTx(ctx context.Context, fn func(tx DBTX) error) error {
+	return withTx(ctx, m.db, PostgresDialect, m.logger, fn)
+}
+
+// Dialect - PostgreSQL SQL 구문 차이를 반환합니다.
+func (m *PostgresManager) Dialect() Dialect {
+	return PostgresDialect
+}
+
+func (m *PostgresManager) Disconnect() error {
+	m.logger.Info().Msg("disconnecting from PostgreSQL")
+	if err := m.reader.Close(); err != nil {
+		m.logger.Error().Err(err).Msg("failed to close replica connections")
+	}
+	return m.db.Close()
+}
+
+func (m *PostgresManager) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.db.PingContext(ctx); err != nil {
+		m.logger.Error().Err(err).Msg("failed to ping DB")
+		return ErrPingDB
+	}
+	return nil
+}
+
+func MaskPostgresDSN(creds *PostgresCredentials) string {
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s",
+		"******",
+		"******",
+		creds.Host,
+		creds.Port,
+		creds.Database,
+	)
+}
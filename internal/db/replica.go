@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-rest-example/internal/logger"
+)
+
+// replica 상태 확인 주기 및 제한 시간
+const (
+	replicaCheckInterval = 10 * time.Second
+	replicaCheckTimeout  = 2 * time.Second
+)
+
+// PrimaryContextKey로 true가 설정된 컨텍스트의 읽기는 replica 대신 primary에서 수행한다.
+// 쓰기 직후 같은 요청에서 다시 읽는 경우(read-after-write) 복제 지연으로 이전 값을 읽지 않기 위해 사용한다.
+// gin.Context는 문자열 키를 c.Keys에서 찾으므로 c.Set(db.PrimaryContextKey, true)로도 지정할 수 있다.
+const PrimaryContextKey = "db.primary"
+
+var ErrInvalidReplicaAddr = errors.New("invalid replica address, expected host:port")
+
+// WithPrimary - 이후 읽기를 primary로 고정한 컨텍스트를 반환합니다.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, PrimaryContextKey, true)
+}
+
+// PrimaryRequested - 컨텍스트에 primary 읽기가 지정되었는지 확인합니다.
+func PrimaryRequested(ctx context.Context) bool {
+	forced, _ := ctx.Value(PrimaryContextKey).(bool)
+	return forced
+}
+
+// "host:port" 형식의 replica 주소를 분리한다.
+func splitReplicaAddr(addr string) (string, int, error) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0, ErrInvalidReplicaAddr
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || host == "" {
+		return "", 0, ErrInvalidReplicaAddr
+	}
+	return host, port, nil
+}
+
+type replica struct {
+	addr    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// readRouter는 읽기 쿼리를 정상 상태의 replica로 순환 분배하는 DBTX 구현체입니다.
+// replica가 없거나 모두 장애 상태이면, 또는 컨텍스트가 primary를 요구하면 primary를 사용합니다.
+// replica에서 쿼리가 실패하면 해당 replica를 장애로 표시하고 같은 쿼리를 primary에서 다시 실행합니다.
+// 쓰기(ExecContext)는 항상 primary에서 실행합니다.
+type readRouter struct {
+	primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+	logger   *logger.AppLogger
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// 컴파일 타임에 readRouter가 DBTX 인터페이스를 구현하는지 확인합니다.
+var _ DBTX = (*readRouter)(nil)
+
+// replica 커넥션 풀을 받아 라우터를 만들고 상태 확인을 시작한다.
+func newReadRouter(primary *sql.DB, replicas map[string]*sql.DB, lgr *logger.AppLogger) *readRouter {
+	r := &readRouter{
+		primary: primary,
+		logger:  lgr,
+		stop:    make(chan struct{}),
+	}
+
+	for addr, db := range replicas {
+		rep := &replica{addr: addr, db: db}
+		rep.healthy.Store(true)
+		r.replicas = append(r.replicas, rep)
+	}
+
+	if len(r.replicas) > 0 {
+		// 기동 시점의 상태를 먼저 반영한 뒤 주기적으로 확인
+		r.checkReplicas()
+		r.wg.Add(1)
+		go r.healthLoop()
+	}
+
+	return r
+}
+
+func (r *readRouter) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return r.primary.ExecContext(ctx, query, args...)
+}
+
+func (r *readRouter) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rep := r.pick(ctx)
+	if rep == nil {
+		return r.primary.QueryContext(ctx, query, args...)
+	}
+
+	rows, err := rep.db.QueryContext(ctx, query, args...)
+	if err != nil && ctx.Err() == nil {
+		r.markUnhealthy(rep, err)
+		return r.primary.QueryContext(ctx, query, args...)
+	}
+	return rows, err
+}
+
+func (r *readRouter) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	rep := r.pick(ctx)
+	if rep == nil {
+		return r.primary.QueryRowContext(ctx, query, args...)
+	}
+
+	// row.Err()는 쿼리 실행 오류만 반환하므로 결과 없음(ErrNoRows)은 폴백 대상이 아니다
+	row := rep.db.QueryRowContext(ctx, query, args...)
+	if err := row.Err(); err != nil && ctx.Err() == nil {
+		r.markUnhealthy(rep, err)
+		return r.primary.QueryRowContext(ctx, query, args...)
+	}
+	return row
+}
+
+// 정상 상태의 replica를 순환 선택한다. 사용할 replica가 없으면 nil.
+func (r *readRouter) pick(ctx context.Context) *replica {
+	if len(r.replicas) == 0 || PrimaryRequested(ctx) {
+		return nil
+	}
+
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		rep := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+func (r *readRouter) markUnhealthy(rep *replica, err error) {
+	if rep.healthy.CompareAndSwap(true, false) {
+		r.logger.Error().Err(err).Str("replica", rep.addr).Msg("replica query failed, falling back to primary")
+	}
+}
+
+// 주기적으로 replica에 Ping하여 장애 표시 및 복구를 반영한다.
+func (r *readRouter) healthLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(replicaCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.checkReplicas()
+		}
+	}
+}
+
+func (r *readRouter) checkReplicas() {
+	for _, rep := range r.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), replicaCheckTimeout)
+		err := rep.db.PingContext(ctx)
+		cancel()
+
+		switch {
+		case err != nil && rep.healthy.CompareAndSwap(true, false):
+			r.logger.Error().Err(err).Str("replica", rep.addr).Msg("replica health check failed")
+		case err == nil && rep.healthy.CompareAndSwap(false, true):
+			r.logger.Info().Str("replica", rep.addr).Msg("replica recovered")
+		}
+	}
+}
+
+// replica별 커넥션 풀 상태
+func (r *readRouter) Stats() map[string]sql.DBStats {
+	if len(r.replicas) == 0 {
+		return nil
+	}
+
+	stats := make(map[string]sql.DBStats, len(r.replicas))
+	for _, rep := range r.replicas {
+		stats[rep.addr] = rep.db.Stats()
+	}
+	return stats
+}
+
+// 상태 확인을 멈추고 replica 커넥션 풀을 닫는다. primary는 호출한 DBManager가 닫는다.
+func (r *readRouter) Close() error {
+	close(r.stop)
+	r.wg.Wait()
+
+	var errs []error
+	for _, rep := range r.replicas {
+		if err := rep.db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
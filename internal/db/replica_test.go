@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+// 저장된 값으로 구분할 수 있는 SQLite 데이터베이스를 연다
+func openNamedDB(t *testing.T, name string) *sql.DB {
+	t.Helper()
+
+	conn, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), name+".db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if _, err := conn.Exec("CREATE TABLE node (name TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Exec("INSERT INTO node (name) VALUES (?)", name); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+// 노드 이름을 읽는다. rows이면 QueryContext, 아니면 QueryRowContext를 사용한다.
+func queryNode(t *testing.T, ctx context.Context, conn DBTX, rows bool) string {
+	t.Helper()
+
+	var name string
+	if !rows {
+		if err := conn.QueryRowContext(ctx, "SELECT name FROM node").Scan(&name); err != nil {
+			t.Fatalf("QueryRowContext: %v", err)
+		}
+		return name
+	}
+
+	r, err := conn.QueryContext(ctx, "SELECT name FROM node")
+	if err != nil {
+		t.Fatalf("QueryContext: %v", err)
+	}
+	defer r.Close()
+	if !r.Next() {
+		t.Fatal("QueryContext: no rows")
+	}
+	if err := r.Scan(&name); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestReadRouter(t *testing.T) {
+	ctx := context.Background()
+	primary := openNamedDB(t, "primary")
+	replicaA, replicaB := openNamedDB(t, "replica-a"), openNamedDB(t, "replica-b")
+
+	router := newReadRouter(primary, map[string]*sql.DB{"a:5432": replicaA, "b:5432": replicaB}, testLogger())
+	t.Cleanup(func() { router.Close() })
+
+	// 읽기는 replica에 순환 분배한다
+	seen := make(map[string]int)
+	for i := 0; i < 10; i++ {
+		seen[queryNode(t, ctx, router, i%2 == 0)]++
+	}
+	if seen["replica-a"] == 0 || seen["replica-b"] == 0 || seen["primary"] != 0 {
+		t.Errorf("reads = %v, want both replicas and no primary", seen)
+	}
+
+	// primary가 지정된 읽기와 쓰기는 primary에서 실행한다
+	if got := queryNode(t, WithPrimary(ctx), router, false); got != "primary" {
+		t.Errorf("WithPrimary read from %s", got)
+	}
+	if _, err := router.ExecContext(ctx, "UPDATE node SET name = ?", "primary-written"); err != nil {
+		t.Fatalf("ExecContext: %v", err)
+	}
+	if got := queryNode(t, WithPrimary(ctx), router, true); got != "primary-written" {
+		t.Errorf("read after write from %s, want primary-written", got)
+	}
+
+	// 쿼리가 실패한 replica는 장애로 표시하고 primary에서 다시 실행한다
+	replicaA.Close()
+	seen = make(map[string]int)
+	for i := 0; i < 10; i++ {
+		seen[queryNode(t, ctx, router, i%2 == 0)]++
+	}
+	if seen["replica-a"] != 0 || seen["replica-b"] == 0 {
+		t.Errorf("reads after replica-a failed = %v", seen)
+	}
+
+	// 상태 확인에 실패한 replica는 복구될 때까지 사용하지 않고, 모두 장애이면 primary를 사용한다
+	replicaB.Close()
+	router.checkReplicas()
+	if got := queryNode(t, ctx, router, false); got != "primary-written" {
+		t.Errorf("read with all replicas down from %s, want primary", got)
+	}
+	if stats := router.Stats(); len(stats) != 2 {
+		t.Errorf("Stats = %v, want both replicas", stats)
+	}
+}
+
+func TestSplitReplicaAddr(t *testing.T) {
+	host, port, err := splitReplicaAddr("replica-1:5432")
+	if err != nil || host != "replica-1" || port != 5432 {
+		t.Errorf("splitReplicaAddr = %q, %d, %v", host, port, err)
+	}
+
+	for _, addr := range []string{"replica-1", ":5432", "replica-1:port", ""} {
+		if _, _, err := splitReplicaAddr(addr); !errors.Is(err, ErrInvalidReplicaAddr) {
+			t.Errorf("splitReplicaAddr(%q): err = %v, want ErrInvalidReplicaAddr", addr, err)
+		}
+	}
+}
@@ -0,0 +1,651 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/model/external"
+)
+
+// 모든 엔진에서 같은 값으로 비교할 수 있도록 초 단위 UTC 시각을 사용한다
+var testNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func newTestDevice(productNumber, firmwareVersion string) data.Device {
+	productLine, hardwareRevision := data.ParseProductNumber(productNumber)
+	return data.Device{
+		ProductNumber:    productNumber,
+		MacAddress:       "00:11:22:33:" + productNumber[5:7] + ":" + productNumber[7:9],
+		ProductLine:      productLine,
+		HardwareRevision: hardwareRevision,
+		FirmwareVersion:  firmwareVersion,
+		LastSeenAt:       testNow,
+		CreatedAt:        testNow,
+		Status:           data.StatusReady,
+	}
+}
+
+func createTestDevices(t *testing.T, repo DevicesDataService, devices ...data.Device) {
+	t.Helper()
+	for i := range devices {
+		if _, err := repo.Create(context.Background(), &devices[i]); err != nil {
+			t.Fatalf("create device %s: %v", devices[i].ProductNumber, err)
+		}
+	}
+}
+
+func newTestReport(productNumber string, battery int, reportAt time.Time) data.DeviceInfo {
+	return data.DeviceInfo{
+		ProductNumber:      productNumber,
+		BatteryPercent:     battery,
+		Lat:                37.5,
+		Lon:                127.0,
+		TemperatureCelsius: 21.5,
+		IP:                 "10.0.0.1",
+		ReportAt:           reportAt,
+		ReportedStatus:     data.ReportPowerOn,
+	}
+}
+
+func TestDevicesRepo(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		repo, err := NewDevicesRepo(testLogger(), mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		device := newTestDevice("ABC010001", "1.0.0")
+		createTestDevices(t, repo, device)
+
+		got, err := repo.GetByID(ctx, device.ProductNumber)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if got.MacAddress != device.MacAddress || got.ProductLine != "ABC" || got.HardwareRevision != "01" || got.FirmwareVersion != "1.0.0" {
+			t.Errorf("GetByID = %+v", got)
+		}
+		if !got.CreatedAt.Equal(testNow) {
+			t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, testNow)
+		}
+
+		// 같은 제품 번호는 다시 등록할 수 없다
+		if _, err := repo.Create(ctx, &device); err == nil {
+			t.Error("Create duplicate: expected error")
+		}
+
+		// 재시도 횟수는 저장된 값을 기준으로 증가하고, ReTry로 초기화한다
+		one, zero := 1, 0
+		version := "1.1.0"
+		for _, params := range []external.UpdateDeviceParams{
+			{ReTryAdd: &one, FirmwareVersion: &version},
+			{ReTryAdd: &one},
+		} {
+			if err := repo.Update(ctx, device.ProductNumber, &params); err != nil {
+				t.Fatalf("Update: %v", err)
+			}
+		}
+		if got, _ = repo.GetByID(ctx, device.ProductNumber); got.ReTry != 2 || got.FirmwareVersion != version {
+			t.Errorf("after ReTryAdd: ReTry = %d, FirmwareVersion = %q", got.ReTry, got.FirmwareVersion)
+		}
+		if err := repo.Update(ctx, device.ProductNumber, &external.UpdateDeviceParams{ReTry: &zero, ReTryAdd: &one}); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+		if got, _ = repo.GetByID(ctx, device.ProductNumber); got.ReTry != 0 {
+			t.Errorf("after ReTry reset: ReTry = %d", got.ReTry)
+		}
+
+		// 잠금 조회는 트랜잭션의 값을 읽고, 존재하지 않는 디바이스는 제외한다
+		err = mgr.WithTx(ctx, func(tx DBTX) error {
+			locked, err := repo.WithTx(tx).LockByIDs(ctx, []string{device.ProductNumber, "ABC019999"})
+			if err != nil {
+				return err
+			}
+			if len(locked) != 1 || locked[device.ProductNumber].FirmwareVersion != version {
+				t.Errorf("LockByIDs = %+v", locked)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("LockByIDs: %v", err)
+		}
+
+		if err := repo.Delete(ctx, device.ProductNumber); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := repo.GetByID(ctx, device.ProductNumber); !errors.Is(err, ErrDeviceNotFound) {
+			t.Errorf("GetByID after Delete: err = %v, want ErrDeviceNotFound", err)
+		}
+	})
+}
+
+func TestWithTxRollback(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		repo, err := NewDevicesRepo(testLogger(), mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		errAbort := errors.New("abort")
+		err = mgr.WithTx(ctx, func(tx DBTX) error {
+			device := newTestDevice("ABC010002", "1.0.0")
+			if _, err := repo.WithTx(tx).Create(ctx, &device); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("WithTx: err = %v, want errAbort", err)
+		}
+
+		if _, err := repo.GetByID(ctx, "ABC010002"); !errors.Is(err, ErrDeviceNotFound) {
+			t.Errorf("GetByID after rollback: err = %v, want ErrDeviceNotFound", err)
+		}
+	})
+}
+
+func TestReportsAndLatestState(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		lgr := testLogger()
+		devices, _ := NewDevicesRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+		reports, _ := NewReportsRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+		states, err := NewLatestStateRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		createTestDevices(t, devices, newTestDevice("ABC010001", "1.0.0"), newTestDevice("ABC010002", "1.0.0"), newTestDevice("ABC010003", "1.0.0"))
+
+		batch := []data.DeviceInfo{
+			newTestReport("ABC010001", 90, testNow),
+			newTestReport("ABC010001", 15, testNow.Add(time.Minute)),
+			newTestReport("ABC010002", 50, testNow),
+		}
+		if err := reports.CreateBatch(ctx, batch); err != nil {
+			t.Fatalf("CreateBatch: %v", err)
+		}
+		got, err := reports.GetByID(ctx, "ABC010001")
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if len(*got) != 2 {
+			t.Errorf("GetByID returned %d reports, want 2", len(*got))
+		}
+
+		// 순서와 관계없이 디바이스별 마지막 보고만 반영한다
+		if err := states.Upsert(ctx, []data.DeviceInfo{batch[1], batch[0], batch[2]}); err != nil {
+			t.Fatalf("Upsert: %v", err)
+		}
+		if err := states.Upsert(ctx, batch[:1]); err != nil {
+			t.Fatalf("Upsert older: %v", err)
+		}
+		state, err := states.GetByID(ctx, "ABC010001")
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if state.State == nil || state.State.BatteryPercent != 15 || !state.State.ReportAt.Equal(testNow.Add(time.Minute)) {
+			t.Errorf("latest state = %+v", state.State)
+		}
+
+		// 배터리 조건 및 페이지 이동
+		low, err := states.List(ctx, data.DeviceListQuery{BatteryBelow: 20})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(low) != 1 || low[0].Device.ProductNumber != "ABC010001" {
+			t.Errorf("List(BatteryBelow: 20) = %+v", low)
+		}
+		page, err := states.List(ctx, data.DeviceListQuery{After: "ABC010001", Limit: 1})
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(page) != 1 || page[0].Device.ProductNumber != "ABC010002" {
+			t.Errorf("List(After, Limit) = %+v", page)
+		}
+		if all, _ := states.List(ctx, data.DeviceListQuery{}); len(all) != 3 || all[2].State != nil {
+			t.Errorf("List() = %+v, want 3 devices with no state for ABC010003", all)
+		}
+	})
+}
+
+func TestAggregatesRepo(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		lgr := testLogger()
+		devices, _ := NewDevicesRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+		reports, _ := NewReportsRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+		aggregates, err := NewAggregatesRepo(lgr, mgr.ReadDB(), mgr.Dialect())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		createTestDevices(t, devices, newTestDevice("ABC010001", "1.0.0"))
+		base := testNow.Truncate(time.Hour)
+		err = reports.CreateBatch(ctx, []data.DeviceInfo{
+			newTestReport("ABC010001", 50, base.Add(5*time.Minute)),
+			newTestReport("ABC010001", 51, base.Add(20*time.Minute)),
+			newTestReport("ABC010001", 52, base.Add(40*time.Minute)),
+		})
+		if err != nil {
+			t.Fatalf("CreateBatch: %v", err)
+		}
+
+		got, err := aggregates.Aggregate(ctx, data.AggregateQuery{
+			ProductNumber: "ABC010001",
+			From:          base,
+			To:            base.Add(time.Hour),
+			Bucket:        30 * time.Minute,
+		})
+		if err != nil {
+			t.Fatalf("Aggregate: %v", err)
+		}
+		if len(got) != 2 {
+			t.Fatalf("Aggregate returned %d buckets, want 2: %+v", len(got), got)
+		}
+		if !got[0].BucketStart.Equal(base) || got[0].Samples != 2 || got[0].BatteryMin != 50 || got[0].BatteryMax != 51 {
+			t.Errorf("bucket 0 = %+v", got[0])
+		}
+		if !got[1].BucketStart.Equal(base.Add(30*time.Minute)) || got[1].Samples != 1 {
+			t.Errorf("bucket 1 = %+v", got[1])
+		}
+	})
+}
+
+func TestRollupsRepo(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		lgr := testLogger()
+		rollups, err := NewRollupsRepo(lgr, mgr.DB(), mgr.Dialect())
+		if err != nil {
+			t.Fatal(err)
+		}
+		aggregates, _ := NewAggregatesRepo(lgr, mgr.ReadDB(), mgr.Dialect())
+
+		base := testNow.Truncate(time.Hour)
+		rollup := func(bucketStart time.Time, reports ...data.DeviceInfo) data.ReportRollup {
+			r := data.ReportRollup{ProductNumber: "ABC010001", Granularity: data.RollupHourly, BucketStart: bucketStart}
+			for i := range reports {
+				r.Add(&reports[i])
+			}
+			return r
+		}
+		report := func(battery int, lat float64, at time.Time) data.DeviceInfo {
+			r := newTestReport("ABC010001", battery, at)
+			r.Lat = lat
+			return r
+		}
+
+		// 같은 구간의 요약은 합치고, 마지막 위치는 보고 시간이 더 늦은 쪽을 따른다
+		err = rollups.Merge(ctx, []data.ReportRollup{
+			rollup(base, report(40, 33.1, base.Add(10*time.Minute)), report(60, 33.2, base.Add(20*time.Minute))),
+			rollup(base.Add(time.Hour), report(70, 33.3, base.Add(70*time.Minute))),
+		})
+		if err != nil {
+			t.Fatalf("Merge: %v", err)
+		}
+		if err := rollups.Merge(ctx, []data.ReportRollup{rollup(base, report(30, 33.9, base.Add(5*time.Minute)))}); err != nil {
+			t.Fatalf("Merge existing bucket: %v", err)
+		}
+
+		var samples, batterySum int64
+		var batteryMin, batteryMax int
+		var lastLat float64
+		err = mgr.DB().QueryRowContext(ctx, mgr.Dialect().Rebind("SELECT Samples, BatteryMin, BatteryMax, BatterySum, LastLat FROM report_rollups "+
+			"WHERE ProductNumber = ? AND Granularity = ? AND BucketStart = ?"), "ABC010001", data.RollupHourly, base).
+			Scan(&samples, &batteryMin, &batteryMax, &batterySum, &lastLat)
+		if err != nil {
+			t.Fatalf("select rollup: %v", err)
+		}
+		if samples != 3 || batteryMin != 30 || batteryMax != 60 || batterySum != 130 || lastLat != 33.2 {
+			t.Errorf("merged rollup = samples %d, battery %d..%d sum %d, lastLat %v", samples, batteryMin, batteryMax, batterySum, lastLat)
+		}
+
+		// 요약 단위와 같은 구간 길이의 집계는 요약을 포함한다
+		got, err := aggregates.Aggregate(ctx, data.AggregateQuery{
+			ProductNumber: "ABC010001",
+			From:          base,
+			To:            base.Add(2 * time.Hour),
+			Bucket:        time.Hour,
+		})
+		if err != nil {
+			t.Fatalf("Aggregate: %v", err)
+		}
+		if len(got) != 2 || got[0].Samples != 3 || got[0].BatteryMin != 30 || got[1].Samples != 1 {
+			t.Errorf("Aggregate over rollups = %+v", got)
+		}
+
+		// 가장 오래된 구간부터 삭제
+		if oldest, err := rollups.OldestBucket(ctx, data.RollupHourly); err != nil || !oldest.Equal(base) {
+			t.Errorf("OldestBucket = %v, %v, want %v", oldest, err, base)
+		}
+		if oldest, err := rollups.OldestBucket(ctx, data.RollupDaily); err != nil || !oldest.IsZero() {
+			t.Errorf("OldestBucket(daily) = %v, %v, want zero time", oldest, err)
+		}
+		if deleted, err := rollups.DeleteRange(ctx, data.RollupHourly, base, base.Add(time.Hour)); err != nil || deleted != 1 {
+			t.Errorf("DeleteRange = %d, %v, want 1", deleted, err)
+		}
+		if oldest, _ := rollups.OldestBucket(ctx, data.RollupHourly); !oldest.Equal(base.Add(time.Hour)) {
+			t.Errorf("OldestBucket after DeleteRange = %v, want %v", oldest, base.Add(time.Hour))
+		}
+	})
+}
+
+func TestRolloutsRepo(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		lgr := testLogger()
+		devices, _ := NewDevicesRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+		rollouts, err := NewRolloutsRepo(lgr, mgr.DB(), mgr, mgr.Dialect())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		targets := []data.Device{newTestDevice("ABC010001", "1.0.0"), newTestDevice("ABC010002", "1.0.0")}
+		createTestDevices(t, devices, targets...)
+
+		campaign, total, err := rollouts.CreateCampaign(ctx, "2.0.0", targets)
+		if err != nil {
+			t.Fatalf("CreateCampaign: %v", err)
+		}
+		if total != 2 || campaign.Status != data.CampaignActive {
+			t.Errorf("CreateCampaign = %+v, %d", campaign, total)
+		}
+
+		progress, err := rollouts.GetProgress(ctx, campaign.CampaignID)
+		if err != nil {
+			t.Fatalf("GetProgress: %v", err)
+		}
+		if progress[data.RolloutEligible] != 2 {
+			t.Errorf("GetProgress = %v", progress)
+		}
+
+		if err := rollouts.UpdateState(ctx, campaign.CampaignID, "ABC010001", data.RolloutInstalled); err != nil {
+			t.Fatalf("UpdateState: %v", err)
+		}
+		active, err := rollouts.GetActiveByDevice(ctx, "ABC010001")
+		if err != nil {
+			t.Fatalf("GetActiveByDevice: %v", err)
+		}
+		if active.CampaignID != campaign.CampaignID || active.State != data.RolloutInstalled || active.FromVersion != "1.0.0" {
+			t.Errorf("GetActiveByDevice = %+v", active)
+		}
+
+		// 이전 버전이 펌웨어 목록에 없으면 롤백하지 않고 캠페인을 진행 중으로 둔다
+		if _, _, err := rollouts.Rollback(ctx, campaign.CampaignID); !errors.Is(err, ErrRollbackVersionMissing) {
+			t.Fatalf("Rollback without previous image: err = %v, want ErrRollbackVersionMissing", err)
+		}
+		if original, _ := rollouts.GetCampaign(ctx, campaign.CampaignID); original.Status != data.CampaignActive {
+			t.Errorf("campaign status after rejected rollback = %s", original.Status)
+		}
+		firmwares, _ := NewFirmwareRepo(lgr, mgr.DB(), mgr, mgr.Dialect())
+		previous := data.Firmware{Version: "1.0.0", Path: "/firmware/1.0.0.bin", Checksum: strings.Repeat("0", 64), Size: 1024, CreatedAt: testNow}
+		if err := firmwares.Create(ctx, &previous); err != nil {
+			t.Fatalf("Create firmware: %v", err)
+		}
+
+		// 설치된 디바이스만 이전 버전으로 되돌린다
+		rollback, affected, err := rollouts.Rollback(ctx, campaign.CampaignID)
+		if err != nil {
+			t.Fatalf("Rollback: %v", err)
+		}
+		if rollback.RollbackOf != campaign.CampaignID || affected != 1 {
+			t.Errorf("Rollback = %+v, %d", rollback, affected)
+		}
+		if original, _ := rollouts.GetCampaign(ctx, campaign.CampaignID); original.Status != data.CampaignRolledBack {
+			t.Errorf("original campaign status = %s", original.Status)
+		}
+		if active, _ := rollouts.GetActiveByDevice(ctx, "ABC010001"); active == nil || active.TargetVersion != "1.0.0" {
+			t.Errorf("rollback rollout = %+v", active)
+		}
+		if _, _, err := rollouts.Rollback(ctx, campaign.CampaignID); !errors.Is(err, ErrCampaignNotActive) {
+			t.Errorf("second Rollback: err = %v, want ErrCampaignNotActive", err)
+		}
+	})
+}
+
+func TestRolloutsRepoCreateCampaignAtomic(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		rollouts, err := NewRolloutsRepo(testLogger(), mgr.DB(), mgr, mgr.Dialect())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 같은 디바이스가 두 번 포함되어 배포 대상 등록이 실패하면 캠페인도 남지 않는다
+		device := newTestDevice("ABC010001", "1.0.0")
+		if _, _, err := rollouts.CreateCampaign(ctx, "2.0.0", []data.Device{device, device}); err == nil {
+			t.Fatal("CreateCampaign with duplicate devices: expected error")
+		}
+
+		var campaigns int
+		if err := mgr.DB().QueryRowContext(ctx, "SELECT COUNT(*) FROM firmware_campaigns").Scan(&campaigns); err != nil {
+			t.Fatal(err)
+		}
+		if campaigns != 0 {
+			t.Errorf("%d campaigns left after failed CreateCampaign", campaigns)
+		}
+	})
+}
+
+func TestFirmwareRepo(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		repo, err := NewFirmwareRepo(testLogger(), mgr.DB(), mgr, mgr.Dialect())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fw := data.Firmware{
+			Version:       "2.0.0",
+			Path:          "/firmware/2.0.0.bin",
+			Checksum:      "0000000000000000000000000000000000000000000000000000000000000000",
+			Size:          1024,
+			Compatibility: []data.FirmwareCompat{{ProductLine: "ABC", HardwareRevision: "01"}},
+			CreatedAt:     testNow,
+		}
+		if err := repo.Create(ctx, &fw); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if err := repo.Create(ctx, &fw); err == nil {
+			t.Error("Create duplicate: expected error")
+		}
+
+		got, err := repo.GetByVersion(ctx, "2.0.0")
+		if err != nil {
+			t.Fatalf("GetByVersion: %v", err)
+		}
+		if got.Size != 1024 || len(got.Compatibility) != 1 || got.Compatibility[0] != fw.Compatibility[0] {
+			t.Errorf("GetByVersion = %+v", got)
+		}
+		if _, err := repo.GetByVersion(ctx, "9.9.9"); !errors.Is(err, ErrFirmwareNotFound) {
+			t.Errorf("GetByVersion missing: err = %v, want ErrFirmwareNotFound", err)
+		}
+
+		delta := data.FirmwareDelta{FromVersion: "1.0.0", ToVersion: "2.0.0", Path: "/firmware/1.0.0-2.0.0.patch", Checksum: fw.Checksum, Size: 64, CreatedAt: testNow}
+		if err := repo.CreateDelta(ctx, &delta); err != nil {
+			t.Fatalf("CreateDelta: %v", err)
+		}
+		if got, err := repo.GetDelta(ctx, "1.0.0", "2.0.0"); err != nil || got.Size != 64 {
+			t.Errorf("GetDelta = %+v, %v", got, err)
+		}
+		if _, err := repo.GetDelta(ctx, "1.1.0", "2.0.0"); !errors.Is(err, ErrDeltaNotFound) {
+			t.Errorf("GetDelta missing: err = %v, want ErrDeltaNotFound", err)
+		}
+	})
+}
+
+func TestWebhooksRepo(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		repo, err := NewWebhooksRepo(testLogger(), mgr.DB(), mgr.Dialect())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sub := data.WebhookSubscription{
+			URL:        "https://example.com/hook",
+			EventTypes: []data.EventType{data.EventDeviceCreated},
+			Secret:     "0123456789abcdef",
+			Enabled:    true,
+			CreatedAt:  testNow,
+			UpdatedAt:  testNow,
+		}
+		subscriptionID, err := repo.CreateSubscription(ctx, &sub)
+		if err != nil {
+			t.Fatalf("CreateSubscription: %v", err)
+		}
+
+		sub.SubscriptionID = subscriptionID
+		sub.Enabled = false
+		if err := repo.UpdateSubscription(ctx, &sub); err != nil {
+			t.Fatalf("UpdateSubscription: %v", err)
+		}
+		if got, err := repo.GetSubscription(ctx, subscriptionID); err != nil || got.Enabled || got.URL != sub.URL {
+			t.Errorf("GetSubscription = %+v, %v", got, err)
+		}
+
+		// 비활성화된 구독의 전송은 전송 대상이 아니다
+		if err := repo.CreateDeliveries(ctx, []data.WebhookDelivery{{SubscriptionID: subscriptionID, EventID: "5f0c6a1e-0000-4000-8000-000000000000",
+			EventType: data.EventDeviceCreated, Payload: []byte(`{}`), State: data.DeliveryPending, NextAttemptAt: testNow, CreatedAt: testNow}}); err != nil {
+			t.Fatalf("CreateDeliveries: %v", err)
+		}
+		if due, err := repo.ListDue(ctx, testNow, 10); err != nil || len(due) != 0 {
+			t.Errorf("ListDue for disabled subscription = %d, %v", len(due), err)
+		}
+		sub.Enabled = true
+		if err := repo.UpdateSubscription(ctx, &sub); err != nil {
+			t.Fatalf("UpdateSubscription: %v", err)
+		}
+
+		// 같은 이벤트는 한 번만 기록한다
+		delivery := data.WebhookDelivery{
+			SubscriptionID: subscriptionID,
+			EventID:        "5f0c6a1e-0000-4000-8000-000000000001",
+			EventType:      data.EventDeviceCreated,
+			Payload:        []byte(`{"id":"1"}`),
+			State:          data.DeliveryPending,
+			NextAttemptAt:  testNow,
+			CreatedAt:      testNow,
+		}
+		for range 2 {
+			if err := repo.CreateDeliveries(ctx, []data.WebhookDelivery{delivery}); err != nil {
+				t.Fatalf("CreateDeliveries: %v", err)
+			}
+		}
+		due, err := repo.ListDue(ctx, testNow, 10)
+		if err != nil {
+			t.Fatalf("ListDue: %v", err)
+		}
+		if len(due) != 2 {
+			t.Fatalf("ListDue returned %d deliveries, want 2", len(due))
+		}
+		due = due[1:]
+
+		// 먼저 가져간 인스턴스만 전송한다
+		if ok, err := repo.Claim(ctx, due[0].DeliveryID, testNow, testNow.Add(time.Minute)); err != nil || !ok {
+			t.Fatalf("Claim = %v, %v", ok, err)
+		}
+		if ok, _ := repo.Claim(ctx, due[0].DeliveryID, testNow, testNow.Add(time.Minute)); ok {
+			t.Error("second Claim succeeded")
+		}
+
+		attemptedAt := testNow.Add(time.Second)
+		due[0].State = data.DeliveryDead
+		due[0].Attempts = 1
+		due[0].LastAttemptAt = &attemptedAt
+		due[0].LastStatusCode = 500
+		due[0].LastError = "unexpected response status 500"
+		if err := repo.RecordAttempt(ctx, &due[0]); err != nil {
+			t.Fatalf("RecordAttempt: %v", err)
+		}
+
+		requeued, err := repo.Requeue(ctx, subscriptionID, due[0].DeliveryID, testNow.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("Requeue: %v", err)
+		}
+		if requeued.State != data.DeliveryPending || requeued.Attempts != 0 {
+			t.Errorf("Requeue = %+v", requeued)
+		}
+		if _, err := repo.Requeue(ctx, subscriptionID, due[0].DeliveryID, testNow.Add(time.Hour)); !errors.Is(err, ErrDeliveryStateConflict) {
+			t.Errorf("Requeue pending: err = %v, want ErrDeliveryStateConflict", err)
+		}
+
+		if err := repo.DeleteSubscription(ctx, subscriptionID); err != nil {
+			t.Fatalf("DeleteSubscription: %v", err)
+		}
+		if _, err := repo.GetSubscription(ctx, subscriptionID); !errors.Is(err, ErrSubscriptionNotFound) {
+			t.Errorf("GetSubscription after delete: err = %v, want ErrSubscriptionNotFound", err)
+		}
+	})
+}
+
+func TestOutboxRepo(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, mgr DBManager) {
+		ctx := context.Background()
+		repo, err := NewOutboxRepo(testLogger(), mgr.DB(), mgr.Dialect())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var events []data.Event
+		for i := range 3 {
+			event, err := data.NewEvent(data.EventDeviceCreated, "ABC010001", testNow.Add(time.Duration(i)*time.Second), map[string]int{"seq": i})
+			if err != nil {
+				t.Fatal(err)
+			}
+			events = append(events, event)
+		}
+		if err := repo.Add(ctx, events); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+
+		pending, err := repo.ListPending(ctx, 10)
+		if err != nil {
+			t.Fatalf("ListPending: %v", err)
+		}
+		if len(pending) != 3 {
+			t.Fatalf("ListPending returned %d events, want 3", len(pending))
+		}
+		for i := range pending {
+			if pending[i].Event.EventID != events[i].EventID {
+				t.Errorf("pending[%d] = %s, want %s (recorded order)", i, pending[i].Event.EventID, events[i].EventID)
+			}
+		}
+
+		if err := repo.RecordFailure(ctx, []int64{pending[0].OutboxID}, "sink failed"); err != nil {
+			t.Fatalf("RecordFailure: %v", err)
+		}
+		if err := repo.Delete(ctx, []int64{pending[1].OutboxID, pending[2].OutboxID}); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if backlog, err := repo.Backlog(ctx); err != nil || backlog != 1 {
+			t.Errorf("Backlog = %d, %v", backlog, err)
+		}
+		if left, _ := repo.ListPending(ctx, 10); len(left) != 1 || left[0].Attempts != 1 || left[0].LastError != "sink failed" {
+			t.Errorf("ListPending after failure = %+v", left)
+		}
+
+		// 임대는 만료되거나 해제되기 전까지 한 인스턴스만 가진다
+		lease := "outbox_relay"
+		if ok, err := repo.AcquireLease(ctx, lease, "a", testNow, testNow.Add(time.Minute)); err != nil || !ok {
+			t.Fatalf("AcquireLease(a) = %v, %v", ok, err)
+		}
+		if ok, _ := repo.AcquireLease(ctx, lease, "b", testNow.Add(time.Second), testNow.Add(time.Minute)); ok {
+			t.Error("AcquireLease(b) succeeded while a holds the lease")
+		}
+		if ok, _ := repo.AcquireLease(ctx, lease, "b", testNow.Add(2*time.Minute), testNow.Add(3*time.Minute)); !ok {
+			t.Error("AcquireLease(b) failed after the lease expired")
+		}
+		if err := repo.ReleaseLease(ctx, lease, "b"); err != nil {
+			t.Fatalf("ReleaseLease: %v", err)
+		}
+		if ok, _ := repo.AcquireLease(ctx, lease, "a", testNow.Add(2*time.Minute), testNow.Add(3*time.Minute)); !ok {
+			t.Error("AcquireLease(a) failed after release")
+		}
+	})
+}
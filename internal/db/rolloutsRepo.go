@@ -0,0 +1,381 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 다중 row INSERT 한 번에 등록할 배포 대상 수
+const rolloutInsertBatch = 500
+
+// 오류 상수 선언
+var (
+	ErrInvalidRolloutRequired   = errors.New("missing required inputs to create RolloutsRepo")
+	ErrFailedToCreateCampaign   = errors.New("failed to create firmware campaign")
+	ErrFailedToSelectCampaign   = errors.New("failed to select firmware campaign")
+	ErrCampaignNotFound         = errors.New("firmware campaign not found")
+	ErrCampaignNotActive        = errors.New("firmware campaign is not active")
+	ErrFailedToRollbackCampaign = errors.New("failed to rollback firmware campaign")
+	ErrRollbackVersionMissing   = errors.New("previous firmware version is not registered")
+	ErrFailedToSelectRollout    = errors.New("failed to select firmware rollout")
+	ErrFailedToUpdateRollout    = errors.New("failed to update firmware rollout")
+	ErrRolloutNotFound          = errors.New("firmware rollout not found")
+)
+
+// RolloutsRepo를 통해 사용할 메서드를 제약하고 규정하기 위한 인터페이스
+type RolloutsDataService interface {
+	CreateCampaign(ctx context.Context, targetVersion string, devices []data.Device) (*data.FirmwareCampaign, int64, error)
+	GetCampaign(ctx context.Context, campaignID int64) (*data.FirmwareCampaign, error)
+	GetProgress(ctx context.Context, campaignID int64) (map[data.RolloutState]int, error)
+	GetActiveByDevice(ctx context.Context, productNumber string) (*data.FirmwareRollout, error)
+	UpdateState(ctx context.Context, campaignID int64, productNumber string, state data.RolloutState) error
+	Rollback(ctx context.Context, campaignID int64) (*data.FirmwareCampaign, int64, error)
+}
+
+// firmware_campaigns, firmware_rollouts 테이블을 접근하기 위한 커넥션 관리
+// 캠페인 생성과 롤백은 여러 테이블을 변경하므로 txMgr의 트랜잭션으로 처리한다.
+type RolloutsRepo struct {
+	connection DBTX
+	txMgr      TxManager
+	dialect    Dialect
+	logger     *logger.AppLogger
+}
+
+func NewRolloutsRepo(lgr *logger.AppLogger, db DBTX, txMgr TxManager, dialect Dialect) (*RolloutsRepo, error) {
+	if lgr == nil || db == nil || txMgr == nil || dialect == nil {
+		return nil, ErrInvalidRolloutRequired
+	}
+	return &RolloutsRepo{
+		connection: db,
+		txMgr:      txMgr,
+		dialect:    dialect,
+		logger:     lgr,
+	}, nil
+}
+
+// 트랜잭션에 바인딩된 RolloutsRepo 반환
+func (r *RolloutsRepo) withTx(tx DBTX) *RolloutsRepo {
+	return &RolloutsRepo{
+		connection: tx,
+		txMgr:      r.txMgr,
+		dialect:    r.dialect,
+		logger:     r.logger,
+	}
+}
+
+// 캠페인을 생성하고 전달된 디바이스를 eligible 상태로 등록한다.
+// 대상 디바이스 선정(호환성 검사 포함)은 호출하는 쪽의 책임이다.
+// 캠페인과 모든 배포 대상은 하나의 트랜잭션으로 등록되어, 일부 대상만 등록된 캠페인은 남지 않는다.
+func (r *RolloutsRepo) CreateCampaign(ctx context.Context, targetVersion string, devices []data.Device) (*data.FirmwareCampaign, int64, error) {
+	var campaign *data.FirmwareCampaign
+	var total int64
+	err := r.txMgr.WithTx(ctx, func(tx DBTX) error {
+		var err error
+		campaign, total, err = r.withTx(tx).createCampaign(ctx, targetVersion, devices)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return campaign, total, nil
+}
+
+func (r *RolloutsRepo) createCampaign(ctx context.Context, targetVersion string, devices []data.Device) (*data.FirmwareCampaign, int64, error) {
+	campaign := data.FirmwareCampaign{
+		TargetVersion: targetVersion,
+		Status:        data.CampaignActive,
+		CreatedAt:     time.Now(),
+	}
+
+	campaignID, err := r.insertCampaign(ctx, &campaign)
+	if err != nil {
+		return nil, 0, err
+	}
+	campaign.CampaignID = campaignID
+
+	// 플레이스홀더 수 제한을 넘지 않도록 나누어 다중 row INSERT
+	var total int64
+	for start := 0; start < len(devices); start += rolloutInsertBatch {
+		batch := devices[start:min(start+rolloutInsertBatch, len(devices))]
+
+		values := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch)*6)
+		for _, device := range batch {
+			values = append(values, "(?, ?, ?, ?, ?, ?)")
+			args = append(args, campaignID, device.ProductNumber, device.FirmwareVersion, targetVersion, data.RolloutEligible, campaign.CreatedAt)
+		}
+
+		query := "INSERT INTO firmware_rollouts (CampaignID, ProductNumber, FromVersion, TargetVersion, State, UpdatedAt) VALUES " +
+			strings.Join(values, ", ")
+
+		result, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), args...)
+		if err != nil {
+			r.logger.Error().Err(err).Int64("campaignID", campaignID).Msg("failed to create firmware rollouts")
+			return nil, 0, ErrFailedToCreateCampaign
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, 0, ErrFailedToCreateCampaign
+		}
+		total += affected
+	}
+
+	return &campaign, total, nil
+}
+
+func (r *RolloutsRepo) GetCampaign(ctx context.Context, campaignID int64) (*data.FirmwareCampaign, error) {
+	query := "SELECT CampaignID, TargetVersion, RollbackOf, Status, CreatedAt FROM firmware_campaigns WHERE CampaignID = ?"
+
+	var campaign data.FirmwareCampaign
+	err := r.connection.QueryRowContext(ctx, r.dialect.Rebind(query), campaignID).Scan(
+		&campaign.CampaignID,
+		&campaign.TargetVersion,
+		&campaign.RollbackOf,
+		&campaign.Status,
+		&campaign.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to select firmware campaign")
+		return nil, ErrFailedToSelectCampaign
+	}
+
+	return &campaign, nil
+}
+
+// 캠페인 내 상태별 디바이스 수 집계
+// 해당 상태의 디바이스가 없더라도 모든 상태 키를 포함하여 반환한다.
+func (r *RolloutsRepo) GetProgress(ctx context.Context, campaignID int64) (map[data.RolloutState]int, error) {
+	query := "SELECT State, COUNT(*) FROM firmware_rollouts WHERE CampaignID = ? GROUP BY State"
+
+	rows, err := r.connection.QueryContext(ctx, r.dialect.Rebind(query), campaignID)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to select firmware rollout progress")
+		return nil, ErrFailedToSelectRollout
+	}
+	defer rows.Close()
+
+	counts := make(map[data.RolloutState]int, len(data.RolloutStates))
+	for _, state := range data.RolloutStates {
+		counts[state] = 0
+	}
+
+	for rows.Next() {
+		var state data.RolloutState
+		var count int
+		if err := rows.Scan(&state, &count); err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, err
+		}
+		counts[state] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// 진행 중인 캠페인 중 디바이스에 가장 최근 할당된 배포 정보 획득
+func (r *RolloutsRepo) GetActiveByDevice(ctx context.Context, productNumber string) (*data.FirmwareRollout, error) {
+	query := "SELECT r.CampaignID, r.ProductNumber, r.FromVersion, r.TargetVersion, r.State, r.UpdatedAt " +
+		"FROM firmware_rollouts r JOIN firmware_campaigns c ON c.CampaignID = r.CampaignID " +
+		"WHERE r.ProductNumber = ? AND c.Status = ? ORDER BY r.CampaignID DESC " + r.dialect.Limit(1)
+
+	var rollout data.FirmwareRollout
+	err := r.connection.QueryRowContext(ctx, r.dialect.Rebind(query), productNumber, data.CampaignActive).Scan(
+		&rollout.CampaignID,
+		&rollout.ProductNumber,
+		&rollout.FromVersion,
+		&rollout.TargetVersion,
+		&rollout.State,
+		&rollout.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRolloutNotFound
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to select firmware rollout")
+		return nil, ErrFailedToSelectRollout
+	}
+
+	return &rollout, nil
+}
+
+func (r *RolloutsRepo) UpdateState(ctx context.Context, campaignID int64, productNumber string, state data.RolloutState) error {
+	query := "UPDATE firmware_rollouts SET State = ?, UpdatedAt = ? WHERE CampaignID = ? AND ProductNumber = ?"
+
+	result, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), state, time.Now(), campaignID, productNumber)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to update firmware rollout")
+		return ErrFailedToUpdateRollout
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return ErrFailedToUpdateRollout
+	}
+
+	if rowsAffected == 0 {
+		return ErrRolloutNotFound
+	}
+
+	return nil
+}
+
+// 캠페인을 중단하고, 이미 내려받았거나 설치한 디바이스를 이전 버전으로 되돌리는 롤백 캠페인을 생성한다.
+// 롤백 캠페인의 목표 버전은 디바이스별 FromVersion 이다.
+// 캠페인 중단과 롤백 캠페인 생성은 하나의 트랜잭션으로 처리되어, 실패 시 원본 캠페인은 진행 중으로 남아 다시 롤백할 수 있다.
+// 되돌릴 이전 버전이 펌웨어 목록에 없으면 디바이스가 내려받을 수 없으므로 ErrRollbackVersionMissing으로 거부한다.
+func (r *RolloutsRepo) Rollback(ctx context.Context, campaignID int64) (*data.FirmwareCampaign, int64, error) {
+	var rollback *data.FirmwareCampaign
+	var devices int64
+	err := r.txMgr.WithTx(ctx, func(tx DBTX) error {
+		var err error
+		rollback, devices, err = r.withTx(tx).rollback(ctx, campaignID)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return rollback, devices, nil
+}
+
+func (r *RolloutsRepo) rollback(ctx context.Context, campaignID int64) (*data.FirmwareCampaign, int64, error) {
+	original, err := r.GetCampaign(ctx, campaignID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if original.Status != data.CampaignActive {
+		return nil, 0, ErrCampaignNotActive
+	}
+
+	// 1. 되돌릴 이전 버전이 모두 등록되어 있는지 확인
+	missing, err := r.missingRollbackVersions(ctx, campaignID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(missing) > 0 {
+		return nil, 0, fmt.Errorf("%w: %s", ErrRollbackVersionMissing, strings.Join(missing, ", "))
+	}
+
+	// 2. 원본 캠페인 중단
+	query := "UPDATE firmware_campaigns SET Status = ? WHERE CampaignID = ? AND Status = ?"
+	result, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), data.CampaignRolledBack, campaignID, data.CampaignActive)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to update firmware campaign status")
+		return nil, 0, ErrFailedToRollbackCampaign
+	}
+
+	if rowsAffected, err := result.RowsAffected(); err != nil || rowsAffected == 0 {
+		return nil, 0, ErrCampaignNotActive
+	}
+
+	// 3. 롤백 캠페인 생성
+	rollback := data.FirmwareCampaign{
+		RollbackOf: campaignID,
+		Status:     data.CampaignActive,
+		CreatedAt:  time.Now(),
+	}
+
+	rollbackID, err := r.insertCampaign(ctx, &rollback)
+	if err != nil {
+		return nil, 0, ErrFailedToRollbackCampaign
+	}
+	rollback.CampaignID = rollbackID
+
+	// 4. 영향받은 디바이스를 이전 버전으로 재지정
+	// 안내만 받은(offered) 디바이스는 원본 캠페인 중단으로 더 이상 업데이트를 받지 않는다.
+	// 캠페인 ID와 시각은 SELECT 목록의 파라미터 타입 추론 차이(PostgreSQL)를 피하기 위해 롤백 캠페인 row에서 가져온다.
+	query = "INSERT INTO firmware_rollouts (CampaignID, ProductNumber, FromVersion, TargetVersion, State, UpdatedAt) " +
+		"SELECT c.CampaignID, r.ProductNumber, d.FirmwareVersion, r.FromVersion, ?, c.CreatedAt " +
+		"FROM firmware_campaigns c " +
+		"JOIN firmware_rollouts r ON r.CampaignID = c.RollbackOf " +
+		"JOIN devices d ON d.ProductNumber = r.ProductNumber " +
+		"WHERE c.CampaignID = ? AND r.State IN (?, ?, ?)"
+
+	result, err = r.connection.ExecContext(ctx, r.dialect.Rebind(query),
+		data.RolloutEligible,
+		rollbackID,
+		data.RolloutDownloading,
+		data.RolloutInstalled,
+		data.RolloutFailed,
+	)
+	if err != nil {
+		r.logger.Error().Err(err).Int64("campaignID", campaignID).Msg("failed to create rollback rollouts")
+		return nil, 0, ErrFailedToRollbackCampaign
+	}
+
+	devices, err := result.RowsAffected()
+	if err != nil {
+		return nil, 0, ErrFailedToRollbackCampaign
+	}
+
+	return &rollback, devices, nil
+}
+
+// 롤백 대상 디바이스의 이전 버전 중 펌웨어 목록에 없는 버전을 조회한다.
+func (r *RolloutsRepo) missingRollbackVersions(ctx context.Context, campaignID int64) ([]string, error) {
+	query := "SELECT DISTINCT r.FromVersion FROM firmware_rollouts r " +
+		"LEFT JOIN firmwares f ON f.Version = r.FromVersion " +
+		"WHERE r.CampaignID = ? AND r.State IN (?, ?, ?) AND f.Version IS NULL ORDER BY r.FromVersion"
+
+	rows, err := r.connection.QueryContext(ctx, r.dialect.Rebind(query),
+		campaignID,
+		data.RolloutDownloading,
+		data.RolloutInstalled,
+		data.RolloutFailed,
+	)
+	if err != nil {
+		r.logger.Error().Err(err).Int64("campaignID", campaignID).Msg("failed to select rollback versions")
+		return nil, ErrFailedToRollbackCampaign
+	}
+	defer rows.Close()
+
+	var missing []string
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, ErrFailedToRollbackCampaign
+		}
+		missing = append(missing, version)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, ErrFailedToRollbackCampaign
+	}
+
+	return missing, nil
+}
+
+func (r *RolloutsRepo) insertCampaign(ctx context.Context, campaign *data.FirmwareCampaign) (int64, error) {
+	query := "INSERT INTO firmware_campaigns (TargetVersion, RollbackOf, Status, CreatedAt) VALUES (?, ?, ?, ?)"
+
+	lastID, err := r.dialect.InsertID(ctx, r.connection, query, "CampaignID",
+		campaign.TargetVersion,
+		campaign.RollbackOf,
+		campaign.Status,
+		campaign.CreatedAt,
+	)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to create firmware campaign")
+		return 0, ErrFailedToCreateCampaign
+	}
+
+	return lastID, nil
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 다중 row upsert 한 번에 반영할 요약 수 (행당 14개 placeholder)
+const rollupMergeBatch = 500
+
+// 오류 상수 선언
+var (
+	ErrInvalidRollupRequired = errors.New("missing required inputs to create RollupsRepo")
+	ErrFailedToMergeRollup   = errors.New("failed to merge report rollup")
+	ErrFailedToSelectRollup  = errors.New("failed to select report rollup")
+	ErrFailedToDeleteRollup  = errors.New("failed to delete report rollup")
+)
+
+// RollupsRepo를 통해 사용할 메서드를 제약하고 규정하기 위한 인터페이스
+type RollupsDataService interface {
+	Merge(ctx context.Context, rollups []data.ReportRollup) error
+	OldestBucket(ctx context.Context, granularity data.RollupGranularity) (time.Time, error)
+	DeleteRange(ctx context.Context, granularity data.RollupGranularity, from, to time.Time) (int64, error)
+	WithTx(tx DBTX) RollupsDataService
+}
+
+// report_rollups 테이블을 접근하기 위한 커넥션 관리
+type RollupsRepo struct {
+	connection DBTX
+	dialect    Dialect
+	logger     *logger.AppLogger
+}
+
+func NewRollupsRepo(lgr *logger.AppLogger, db DBTX, dialect Dialect) (*RollupsRepo, error) {
+	if lgr == nil || db == nil || dialect == nil {
+		return nil, ErrInvalidRollupRequired
+	}
+	return &RollupsRepo{
+		connection: db,
+		dialect:    dialect,
+		logger:     lgr,
+	}, nil
+}
+
+// 트랜잭션에 바인딩된 RollupsRepo 반환
+func (r *RollupsRepo) WithTx(tx DBTX) RollupsDataService {
+	return &RollupsRepo{
+		connection: tx,
+		dialect:    r.dialect,
+		logger:     r.logger,
+	}
+}
+
+// Merge - 요약을 저장하고, 같은 구간의 요약이 이미 있으면 합칩니다.
+// 건수/합계는 더하고 최소/최대는 비교하며, 마지막 위치는 보고 시간이 더 늦은 쪽을 따릅니다.
+// 한 번에 전달하는 요약은 (ProductNumber, Granularity, BucketStart)가 중복되지 않아야 합니다.
+func (r *RollupsRepo) Merge(ctx context.Context, rollups []data.ReportRollup) error {
+	mergeClause := r.mergeClause()
+
+	for start := 0; start < len(rollups); start += rollupMergeBatch {
+		batch := rollups[start:min(start+rollupMergeBatch, len(rollups))]
+
+		values := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch)*14)
+		for i := range batch {
+			rollup := &batch[i]
+			values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+			args = append(args,
+				rollup.ProductNumber,
+				rollup.Granularity,
+				rollup.BucketStart.UTC(),
+				rollup.Samples,
+				rollup.BatteryMin,
+				rollup.BatteryMax,
+				rollup.BatterySum,
+				rollup.TemperatureMin,
+				rollup.TemperatureMax,
+				rollup.TemperatureSum,
+				rollup.ErrorCount,
+				rollup.LastLat,
+				rollup.LastLon,
+				rollup.LastReportAt.UTC())
+		}
+
+		query := "INSERT INTO report_rollups (ProductNumber, Granularity, BucketStart, Samples, BatteryMin, BatteryMax, BatterySum, " +
+			"TemperatureMin, TemperatureMax, TemperatureSum, ErrorCount, LastLat, LastLon, LastReportAt) VALUES " +
+			strings.Join(values, ", ") + " " + mergeClause
+
+		if _, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), args...); err != nil {
+			r.logger.Error().Err(err).Int("rows", len(batch)).Msg("failed to merge report rollups")
+			if IsUnavailable(err) {
+				return fmt.Errorf("%w: %w", ErrFailedToMergeRollup, ErrUnavailable)
+			}
+			return ErrFailedToMergeRollup
+		}
+	}
+
+	return nil
+}
+
+// OldestBucket - 가장 오래된 요약 구간의 시작 시간을 반환합니다. 요약이 없으면 zero time을 반환합니다.
+func (r *RollupsRepo) OldestBucket(ctx context.Context, granularity data.RollupGranularity) (time.Time, error) {
+	query := "SELECT BucketStart FROM report_rollups WHERE Granularity = ? ORDER BY BucketStart " + r.dialect.Limit(1)
+
+	var bucketStart time.Time
+	err := r.connection.QueryRowContext(ctx, r.dialect.Rebind(query), granularity).Scan(&bucketStart)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to select oldest report rollup")
+		if IsUnavailable(err) {
+			return time.Time{}, fmt.Errorf("%w: %w", ErrFailedToSelectRollup, ErrUnavailable)
+		}
+		return time.Time{}, ErrFailedToSelectRollup
+	}
+
+	return bucketStart, nil
+}
+
+// DeleteRange - 구간 시작 시간이 [from, to) 범위인 요약을 제거하고 제거된 row 수를 반환합니다.
+func (r *RollupsRepo) DeleteRange(ctx context.Context, granularity data.RollupGranularity, from, to time.Time) (int64, error) {
+	query := "DELETE FROM report_rollups WHERE Granularity = ? AND BucketStart >= ? AND BucketStart < ?"
+
+	result, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), granularity, from.UTC(), to.UTC())
+	if err != nil {
+		r.logger.Error().Err(err).Str("granularity", string(granularity)).Msg("failed to delete report rollups")
+		if IsUnavailable(err) {
+			return 0, fmt.Errorf("%w: %w", ErrFailedToDeleteRollup, ErrUnavailable)
+		}
+		return 0, ErrFailedToDeleteRollup
+	}
+
+	return result.RowsAffected()
+}
+
+// 같은 구간의 기존 요약과 합치는 upsert 절
+// 기존 값은 테이블 이름으로 한정해야 PostgreSQL에서 excluded와 구분된다.
+// MySQL은 갱신 절을 왼쪽부터 적용하므로 마지막 위치를 LastReportAt보다 먼저 갱신한다.
+func (r *RollupsRepo) mergeClause() string {
+	d := r.dialect
+	cur := func(col string) string { return "report_rollups." + col }
+	add := func(col string) string { return fmt.Sprintf("%s = %s + %s", col, cur(col), d.Excluded(col)) }
+	least := func(col string) string { return fmt.Sprintf("%s = %s", col, d.Least(cur(col), d.Excluded(col))) }
+	greatest := func(col string) string { return fmt.Sprintf("%s = %s", col, d.Greatest(cur(col), d.Excluded(col))) }
+	latest := func(col string) string {
+		return fmt.Sprintf("%s = CASE WHEN %s >= %s THEN %s ELSE %s END",
+			col, d.Excluded("LastReportAt"), cur("LastReportAt"), d.Excluded(col), cur(col))
+	}
+
+	sets := []string{
+		add("Samples"),
+		least("BatteryMin"),
+		greatest("BatteryMax"),
+		add("BatterySum"),
+		least("TemperatureMin"),
+		greatest("TemperatureMax"),
+		add("TemperatureSum"),
+		add("ErrorCount"),
+		latest("LastLat"),
+		latest("LastLon"),
+		greatest("LastReportAt"),
+	}
+
+	return d.OnConflict([]string{"ProductNumber", "Granularity", "BucketStart"}) + " " + strings.Join(sets, ", ")
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go-rest-example/internal/logger"
+
+	_ "modernc.org/sqlite" // 순수 Go SQLite 드라이버 (cgo 불필요)
+)
+
+// 잠금 대기 시간 (다른 커넥션이 쓰기 중일 때 SQLITE_BUSY 대신 대기)
+const sqliteBusyTimeoutMs = 5000
+
+var ErrInvalidSQLitePath = errors.New("sqlite database path is required")
+
+// SQLiteManager는 외부 데이터베이스 없이 개발/CI 환경에서 서비스를 실행하기 위한 DBManager 구현체입니다.
+// ":memory:" 경로를 지정하면 프로세스 메모리에만 저장합니다.
+type SQLiteManager struct {
+	db     *sql.DB
+	logger *logger.AppLogger
+}
+
+// 컴파일 타임에 SQLiteManager가 DBManager 인터페이스를 구현하는지 확인합니다.
+var _ DBManager = (*SQLiteManager)(nil)
+
+func NewSQLiteManager(path string, lgr *logger.AppLogger) (DBManager, error) {
+	if path == "" {
+		return nil, ErrInvalidSQLitePath
+	}
+
+	// 시간 값은 정렬 가능한 문자열로 저장하고, 외래 키 및 WAL 저널을 사용한다
+	params := url.Values{}
+	params.Add("_time_format", "sqlite")
+	params.Add("_pragma", "busy_timeout("+strconv.Itoa(sqliteBusyTimeoutMs)+")")
+	params.Add("_pragma", "foreign_keys(1)")
+	if path != ":memory:" {
+		params.Add("_pragma", "journal_mode(WAL)")
+	}
+	dsn := "file:" + path + "?" + params.Encode()
+
+	lgr.Info().Str("path", path).Msg("opening SQLite database")
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		lgr.Error().Err(err).Msg("failed to prepare DB connection")
+		return nil, ErrClientInit
+	}
+
+	// SQLite는 쓰기가 직렬화되며, 메모리 DB는 커넥션마다 별도 DB가 되므로 커넥션을 하나만 사용한다.
+	// 커넥션을 점유한 채 다른 쿼리를 실행하면 교착되므로 트랜잭션 안에서는 tx만 사용해야 한다.
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+
+	mgr := &SQLiteManager{
+		db:     db,
+		logger: lgr,
+	}
+
+	if err := mgr.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return mgr, nil
+}
+
+// DB - DBTX 인터페이스를 반환합니다.
+func (m *SQLiteManager) DB() DBTX {
+	return m.db
+}
+
+// ReadDB - SQLite는 replica가 없으므로 같은 DB를 반환합니다.
+func (m *SQLiteManager) ReadDB() DBTX {
+	return m.db
+}
+
+// Stats - 커넥션 풀 상태를 반환합니다.
+func (m *SQLiteManager) Stats() PoolStats {
+	return PoolStats{Primary: m.db.Stats()}
+}
+
+// Conn - 전용 커넥션을 반환합니다. 커넥션이 하나뿐이므로 사용 후 즉시 Close 해야 합니다.
+func (m *SQLiteManager) Conn(ctx context.Context) (*sql.Conn, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		m.logger.Error().Err(err).Msg("failed to get dedicated DB connection")
+		return nil, ErrConnectionEstablish
+	}
+	return conn, nil
+}
+
+// WithTx - 트랜잭션 안에서 fn을 실행하고, SQLITE_BUSY 등 잠금 오류 시 트랜잭션 전체를 재시도합니다.
+func (m *SQLiteManager) WithTx(ctx context.Context, fn func(tx DBTX) error) error {
+	return withTx(ctx, m.db, SQLiteDialect, m.logger, fn)
+}
+
+// Dialect - SQLite SQL 구문 차이를 반환합니다.
+func (m *SQLiteManager) Dialect() Dialect {
+	return SQLiteDialect
+}
+
+func (m *SQLiteManager) Disconnect() error {
+	m.logger.Info().Msg("closing SQLite database")
+	return m.db.Close()
+}
+
+func (m *SQLiteManager) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.db.PingContext(ctx); err != nil {
+		m.logger.Error().Err(err).Msg("failed to ping DB")
+		return ErrPingDB
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go-rest-example/internal/logger"
+)
+
+// 트랜잭션 재시도 설정
+const (
+	maxTxAttempts = 3
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+// 트랜잭션 안에서 fn을 실행하고, Dialect가 재시도 가능하다고 판단한 오류는 트랜잭션 전체를 재시도합니다.
+// DBManager 구현체들이 공통으로 사용합니다.
+func withTx(ctx context.Context, db *sql.DB, dialect Dialect, lgr *logger.AppLogger, fn func(tx DBTX) error) error {
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		var retryable bool
+		retryable, err = runTx(ctx, db, dialect, lgr, fn)
+		if err == nil || !retryable || attempt == maxTxAttempts {
+			break
+		}
+
+		lgr.Info().Err(err).Int("attempt", attempt).Msg("retrying DB transaction")
+
+		// 재시도 간격은 시도 횟수에 비례하여 늘린다
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * txRetryDelay):
+		}
+	}
+
+	return err
+}
+
+// 트랜잭션 1회 실행. 실패 시 재시도 가능 여부를 함께 반환합니다.
+func runTx(ctx context.Context, db *sql.DB, dialect Dialect, lgr *logger.AppLogger, fn func(tx DBTX) error) (retryable bool, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		lgr.Error().Err(err).Msg("failed to begin DB transaction")
+		return false, ErrTxBegin
+	}
+
+	rec := &recordingTx{tx: tx, dialect: dialect}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(rec); err != nil {
+		tx.Rollback()
+		return rec.retryable, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		lgr.Error().Err(err).Msg("failed to commit DB transaction")
+		return dialect.IsRetryable(err), ErrTxCommit
+	}
+
+	for _, hook := range rec.afterCommit {
+		hook()
+	}
+	return false, nil
+}
+
+// 커밋 후 실행할 작업을 등록할 수 있는 DBTX (TxManager가 fn에 전달하는 트랜잭션)
+type commitHooker interface {
+	onCommit(fn func())
+}
+
+// AfterCommit - tx가 커밋된 뒤 fn을 실행합니다. 롤백되거나 재시도로 버려진 트랜잭션에서는 실행하지 않습니다.
+// 트랜잭션이 아닌 DBTX이면 이미 반영된 것이므로 바로 실행합니다. (캐시 무효화 등 커밋된 값을 기준으로 해야 하는 작업용)
+func AfterCommit(tx DBTX, fn func()) {
+	if h, ok := tx.(commitHooker); ok {
+		h.onCommit(fn)
+		return
+	}
+	fn()
+}
+
+// recordingTx는 sql.Tx를 감싸 실행 중 발생한 재시도 가능 오류를 기록합니다.
+// Repository는 드라이버 오류를 자체 오류로 감싸 반환하므로, 원본 오류는 여기서 판별합니다.
+type recordingTx struct {
+	tx          *sql.Tx
+	dialect     Dialect
+	retryable   bool
+	afterCommit []func() // 커밋 후 실행할 작업
+}
+
+func (r *recordingTx) onCommit(fn func()) {
+	r.afterCommit = append(r.afterCommit, fn)
+}
+
+func (r *recordingTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	result, err := r.tx.ExecContext(ctx, query, args...)
+	r.record(err)
+	return result, err
+}
+
+func (r *recordingTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	rows, err := r.tx.QueryContext(ctx, query, args...)
+	r.record(err)
+	return rows, err
+}
+
+func (r *recordingTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	row := r.tx.QueryRowContext(ctx, query, args...)
+	r.record(row.Err())
+	return row
+}
+
+func (r *recordingTx) record(err error) {
+	if err != nil && r.dialect.IsRetryable(err) {
+		r.retryable = true
+	}
+}
@@ -0,0 +1,406 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 전송 기록 조회 및 다중 row 처리 기본/최대 수
+const (
+	defaultDeliveryListLimit = 100
+	maxDeliveryListLimit     = 1000
+	deliveryBatch            = 500 // 다중 row INSERT 한 번에 기록할 전송 수 (행당 8개 placeholder)
+)
+
+// 오류 상수 선언
+var (
+	ErrInvalidWebhookRequired     = errors.New("missing required inputs to create WebhooksRepo")
+	ErrFailedToCreateSubscription = errors.New("failed to create webhook subscription")
+	ErrFailedToSelectSubscription = errors.New("failed to select webhook subscription")
+	ErrFailedToUpdateSubscription = errors.New("failed to update webhook subscription")
+	ErrFailedToDeleteSubscription = errors.New("failed to delete webhook subscription")
+	ErrSubscriptionNotFound       = errors.New("webhook subscription not found")
+	ErrFailedToCreateDelivery     = errors.New("failed to create webhook delivery")
+	ErrFailedToSelectDelivery     = errors.New("failed to select webhook delivery")
+	ErrFailedToUpdateDelivery     = errors.New("failed to update webhook delivery")
+	ErrDeliveryNotFound           = errors.New("webhook delivery not found")
+	ErrDeliveryStateConflict      = errors.New("webhook delivery state does not allow this change")
+)
+
+// WebhooksRepo를 통해 사용할 메서드를 제약하고 규정하기 위한 인터페이스
+type WebhooksDataService interface {
+	CreateSubscription(ctx context.Context, sub *data.WebhookSubscription) (int64, error)
+	GetSubscription(ctx context.Context, subscriptionID int64) (*data.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]data.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, sub *data.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, subscriptionID int64) error
+
+	CreateDeliveries(ctx context.Context, deliveries []data.WebhookDelivery) error
+	ListDue(ctx context.Context, now time.Time, limit int) ([]data.WebhookDelivery, error)
+	Claim(ctx context.Context, deliveryID int64, now time.Time, leaseUntil time.Time) (bool, error)
+	RecordAttempt(ctx context.Context, delivery *data.WebhookDelivery) error
+	Requeue(ctx context.Context, subscriptionID int64, deliveryID int64, now time.Time) (*data.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) (*data.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, q data.DeliveryListQuery) ([]data.WebhookDelivery, error)
+	WithTx(tx DBTX) WebhooksDataService
+}
+
+// webhook_subscriptions, webhook_deliveries 테이블을 접근하기 위한 커넥션 관리
+type WebhooksRepo struct {
+	connection DBTX
+	dialect    Dialect
+	logger     *logger.AppLogger
+}
+
+func NewWebhooksRepo(lgr *logger.AppLogger, db DBTX, dialect Dialect) (*WebhooksRepo, error) {
+	if lgr == nil || db == nil || dialect == nil {
+		return nil, ErrInvalidWebhookRequired
+	}
+	return &WebhooksRepo{
+		connection: db,
+		dialect:    dialect,
+		logger:     lgr,
+	}, nil
+}
+
+// 트랜잭션에 바인딩된 WebhooksRepo 반환
+func (r *WebhooksRepo) WithTx(tx DBTX) WebhooksDataService {
+	return &WebhooksRepo{
+		connection: tx,
+		dialect:    r.dialect,
+		logger:     r.logger,
+	}
+}
+
+const subscriptionColumns = "SubscriptionID, URL, EventTypes, Secret, Enabled, CreatedAt, UpdatedAt"
+
+// CreateSubscription - 웹훅 구독을 등록하고 생성된 SubscriptionID를 반환합니다.
+func (r *WebhooksRepo) CreateSubscription(ctx context.Context, sub *data.WebhookSubscription) (int64, error) {
+	query := "INSERT INTO webhook_subscriptions (URL, EventTypes, Secret, Enabled, CreatedAt, UpdatedAt) VALUES (?, ?, ?, ?, ?, ?)"
+
+	subscriptionID, err := r.dialect.InsertID(ctx, r.connection, query, "SubscriptionID",
+		sub.URL, joinEventTypes(sub.EventTypes), sub.Secret, sub.Enabled, sub.CreatedAt, sub.UpdatedAt)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to create webhook subscription")
+		return 0, r.wrap(ErrFailedToCreateSubscription, err)
+	}
+
+	return subscriptionID, nil
+}
+
+func (r *WebhooksRepo) GetSubscription(ctx context.Context, subscriptionID int64) (*data.WebhookSubscription, error) {
+	query := "SELECT " + subscriptionColumns + " FROM webhook_subscriptions WHERE SubscriptionID = ?"
+
+	subs, err := r.selectSubscriptions(ctx, query, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(subs) == 0 {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	return &subs[0], nil
+}
+
+// ListSubscriptions - 모든 웹훅 구독을 SubscriptionID 순서로 반환합니다.
+func (r *WebhooksRepo) ListSubscriptions(ctx context.Context) ([]data.WebhookSubscription, error) {
+	return r.selectSubscriptions(ctx, "SELECT "+subscriptionColumns+" FROM webhook_subscriptions ORDER BY SubscriptionID")
+}
+
+// UpdateSubscription - 웹훅 구독의 URL, 이벤트 종류, 서명 키, 활성 여부를 변경합니다. (CreatedAt은 유지)
+func (r *WebhooksRepo) UpdateSubscription(ctx context.Context, sub *data.WebhookSubscription) error {
+	query := "UPDATE webhook_subscriptions SET URL = ?, EventTypes = ?, Secret = ?, Enabled = ?, UpdatedAt = ? WHERE SubscriptionID = ?"
+
+	result, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query),
+		sub.URL, joinEventTypes(sub.EventTypes), sub.Secret, sub.Enabled, sub.UpdatedAt, sub.SubscriptionID)
+	if err != nil {
+		r.logger.Error().Err(err).Int64("subscriptionID", sub.SubscriptionID).Msg("failed to update webhook subscription")
+		return r.wrap(ErrFailedToUpdateSubscription, err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrSubscriptionNotFound
+	}
+
+	return nil
+}
+
+// DeleteSubscription - 웹훅 구독과 구독의 전송 기록을 삭제합니다.
+func (r *WebhooksRepo) DeleteSubscription(ctx context.Context, subscriptionID int64) error {
+	result, err := r.connection.ExecContext(ctx, r.dialect.Rebind("DELETE FROM webhook_subscriptions WHERE SubscriptionID = ?"), subscriptionID)
+	if err != nil {
+		r.logger.Error().Err(err).Int64("subscriptionID", subscriptionID).Msg("failed to delete webhook subscription")
+		return r.wrap(ErrFailedToDeleteSubscription, err)
+	}
+
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrSubscriptionNotFound
+	}
+
+	_, err = r.connection.ExecContext(ctx, r.dialect.Rebind("DELETE FROM webhook_deliveries WHERE SubscriptionID = ?"), subscriptionID)
+	if err != nil {
+		r.logger.Error().Err(err).Int64("subscriptionID", subscriptionID).Msg("failed to delete webhook deliveries")
+		return r.wrap(ErrFailedToDeleteSubscription, err)
+	}
+
+	return nil
+}
+
+func (r *WebhooksRepo) selectSubscriptions(ctx context.Context, query string, args ...interface{}) ([]data.WebhookSubscription, error) {
+	rows, err := r.connection.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to select webhook subscription")
+		return nil, r.wrap(ErrFailedToSelectSubscription, err)
+	}
+	defer rows.Close()
+
+	subs := make([]data.WebhookSubscription, 0)
+	for rows.Next() {
+		var sub data.WebhookSubscription
+		var eventTypes string
+		err := rows.Scan(&sub.SubscriptionID, &sub.URL, &eventTypes, &sub.Secret, &sub.Enabled, &sub.CreatedAt, &sub.UpdatedAt)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, ErrFailedToSelectSubscription
+		}
+
+		sub.EventTypes = splitEventTypes(eventTypes)
+		subs = append(subs, sub)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, r.wrap(ErrFailedToSelectSubscription, err)
+	}
+
+	return subs, nil
+}
+
+// CreateDeliveries - 구독별 이벤트 전송을 pending 상태로 기록합니다.
+// 같은 구독에 이미 기록된 이벤트(EventID)는 다시 기록하지 않으므로 같은 이벤트를 여러 번 전달받아도 전송은 한 번입니다.
+func (r *WebhooksRepo) CreateDeliveries(ctx context.Context, deliveries []data.WebhookDelivery) error {
+	for start := 0; start < len(deliveries); start += deliveryBatch {
+		batch := deliveries[start:min(start+deliveryBatch, len(deliveries))]
+
+		values := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch)*8)
+		for i := range batch {
+			d := &batch[i]
+			values = append(values, "(?, ?, ?, ?, ?, ?, ?, ?)")
+			args = append(args, d.SubscriptionID, d.EventID, d.EventType, string(d.Payload), data.DeliveryPending, 0,
+				d.NextAttemptAt, d.CreatedAt)
+		}
+
+		query := "INSERT INTO webhook_deliveries (SubscriptionID, EventID, EventType, Payload, State, Attempts, NextAttemptAt, CreatedAt) VALUES " +
+			strings.Join(values, ", ") + " " + r.dialect.Upsert([]string{"SubscriptionID", "EventID"}, []string{"EventID"})
+
+		if _, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), args...); err != nil {
+			r.logger.Error().Err(err).Int("deliveries", len(batch)).Msg("failed to create webhook deliveries")
+			return r.wrap(ErrFailedToCreateDelivery, err)
+		}
+	}
+
+	return nil
+}
+
+// ListDue - 전송 시간이 된 pending 전송을 오래된 순서로 limit건까지 반환합니다.
+// 비활성화된 구독의 전송은 구독이 다시 활성화될 때까지 대기합니다.
+func (r *WebhooksRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]data.WebhookDelivery, error) {
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries WHERE State = ? AND NextAttemptAt <= ? " +
+		"AND SubscriptionID IN (SELECT SubscriptionID FROM webhook_subscriptions WHERE Enabled = ?) " +
+		"ORDER BY NextAttemptAt, DeliveryID " + r.dialect.Limit(limit)
+	return r.selectDeliveries(ctx, query, data.DeliveryPending, now, true)
+}
+
+// Claim - 전송 시간이 된 pending 전송의 다음 시도 시간을 leaseUntil로 미뤄 전송할 권한을 얻습니다.
+// 다른 인스턴스가 먼저 가져간 전송이면 false를 반환하며, 전송 결과를 기록하지 못하고 종료되면 leaseUntil 이후 다시 전송됩니다.
+func (r *WebhooksRepo) Claim(ctx context.Context, deliveryID int64, now time.Time, leaseUntil time.Time) (bool, error) {
+	query := "UPDATE webhook_deliveries SET NextAttemptAt = ? WHERE DeliveryID = ? AND State = ? AND NextAttemptAt <= ?"
+
+	affected, err := r.update(ctx, query, leaseUntil, deliveryID, data.DeliveryPending, now)
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// RecordAttempt - 전송 시도 결과(상태, 시도 횟수, 다음 시도 시간, 응답 코드, 실패 사유)를 기록합니다.
+func (r *WebhooksRepo) RecordAttempt(ctx context.Context, delivery *data.WebhookDelivery) error {
+	query := "UPDATE webhook_deliveries SET State = ?, Attempts = ?, NextAttemptAt = ?, LastAttemptAt = ?, LastStatusCode = ?, " +
+		"LastError = ?, DeliveredAt = ? WHERE DeliveryID = ?"
+
+	affected, err := r.update(ctx, query, delivery.State, delivery.Attempts, delivery.NextAttemptAt, nullTime(delivery.LastAttemptAt),
+		delivery.LastStatusCode, truncate(delivery.LastError, 512), nullTime(delivery.DeliveredAt), delivery.DeliveryID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+// Requeue - dead 상태의 전송을 시도 횟수를 초기화하여 바로 다시 전송하도록 합니다.
+// dead 상태가 아니면 ErrDeliveryStateConflict를 반환합니다.
+func (r *WebhooksRepo) Requeue(ctx context.Context, subscriptionID int64, deliveryID int64, now time.Time) (*data.WebhookDelivery, error) {
+	query := "UPDATE webhook_deliveries SET State = ?, Attempts = 0, NextAttemptAt = ? WHERE DeliveryID = ? AND SubscriptionID = ? AND State = ?"
+
+	affected, err := r.update(ctx, query, data.DeliveryPending, now, deliveryID, subscriptionID, data.DeliveryDead)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := r.GetDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return delivery, ErrDeliveryStateConflict
+	}
+
+	return delivery, nil
+}
+
+func (r *WebhooksRepo) update(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	result, err := r.connection.ExecContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to update webhook delivery")
+		return 0, r.wrap(ErrFailedToUpdateDelivery, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, ErrFailedToUpdateDelivery
+	}
+
+	return affected, nil
+}
+
+const deliveryColumns = "DeliveryID, SubscriptionID, EventID, EventType, Payload, State, Attempts, NextAttemptAt, LastAttemptAt, " +
+	"LastStatusCode, LastError, CreatedAt, DeliveredAt"
+
+func (r *WebhooksRepo) GetDelivery(ctx context.Context, subscriptionID int64, deliveryID int64) (*data.WebhookDelivery, error) {
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries WHERE DeliveryID = ? AND SubscriptionID = ?"
+
+	deliveries, err := r.selectDeliveries(ctx, query, deliveryID, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return nil, ErrDeliveryNotFound
+	}
+
+	return &deliveries[0], nil
+}
+
+// ListDeliveries - 구독의 전송 기록을 최신 순서로 반환합니다.
+func (r *WebhooksRepo) ListDeliveries(ctx context.Context, q data.DeliveryListQuery) ([]data.WebhookDelivery, error) {
+	limit := min(orDefault(q.Limit, defaultDeliveryListLimit), maxDeliveryListLimit)
+
+	conds := []string{"SubscriptionID = ?"}
+	args := []interface{}{q.SubscriptionID}
+	if q.State != "" {
+		conds = append(conds, "State = ?")
+		args = append(args, q.State)
+	}
+	if q.Before > 0 {
+		conds = append(conds, "DeliveryID < ?")
+		args = append(args, q.Before)
+	}
+
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries WHERE " + strings.Join(conds, " AND ") +
+		" ORDER BY DeliveryID DESC " + r.dialect.Limit(limit)
+
+	return r.selectDeliveries(ctx, query, args...)
+}
+
+func (r *WebhooksRepo) selectDeliveries(ctx context.Context, query string, args ...interface{}) ([]data.WebhookDelivery, error) {
+	rows, err := r.connection.QueryContext(ctx, r.dialect.Rebind(query), args...)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to select webhook delivery")
+		return nil, r.wrap(ErrFailedToSelectDelivery, err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]data.WebhookDelivery, 0)
+	for rows.Next() {
+		var d data.WebhookDelivery
+		var payload string
+		var lastAttemptAt, deliveredAt sql.NullTime
+		err := rows.Scan(&d.DeliveryID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload, &d.State, &d.Attempts,
+			&d.NextAttemptAt, &lastAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &deliveredAt)
+		if err != nil {
+			r.logger.Error().Err(err).Msg("failed to scan row")
+			return nil, ErrFailedToSelectDelivery
+		}
+
+		d.Payload = []byte(payload)
+		if lastAttemptAt.Valid {
+			d.LastAttemptAt = &lastAttemptAt.Time
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, r.wrap(ErrFailedToSelectDelivery, err)
+	}
+
+	return deliveries, nil
+}
+
+// 데이터베이스에 연결할 수 없는 오류는 ErrUnavailable을 함께 감싼다
+func (r *WebhooksRepo) wrap(sentinel error, err error) error {
+	if IsUnavailable(err) {
+		return fmt.Errorf("%w: %w", sentinel, ErrUnavailable)
+	}
+	return sentinel
+}
+
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// 실패 사유는 컬럼 크기에 맞춰 자른다
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
+
+// 이벤트 종류 목록은 쉼표로 구분하여 저장한다
+func joinEventTypes(eventTypes []data.EventType) string {
+	values := make([]string, len(eventTypes))
+	for i, t := range eventTypes {
+		values[i] = string(t)
+	}
+	return strings.Join(values, ",")
+}
+
+func splitEventTypes(s string) []data.EventType {
+	if s == "" {
+		return nil
+	}
+
+	var eventTypes []data.EventType
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			eventTypes = append(eventTypes, data.EventType(v))
+		}
+	}
+	return eventTypes
+}
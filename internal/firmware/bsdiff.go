@@ -0,0 +1,437 @@
+package firmware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// bsdiff 알고리즘(Colin Percival)을 순수 Go로 옮긴 구현
+// 원본은 bzip2로 블록을 압축하지만, 표준 라이브러리에 bzip2 writer가 없으므로 gzip을 사용한다.
+// http://www.daemonology.net/bsdiff/
+//
+// 패치 형식
+//   0  8  magic ("GODELTA1")
+//   8  8  ctrl 블록 압축 크기
+//   16 8  diff 블록 압축 크기
+//   24 8  결과 이미지 크기
+//   32 .. ctrl 블록 (varint 3개 단위: diff 길이, extra 길이, old 위치 이동량)
+//   .. .. diff 블록 (new - old 바이트 차이)
+//   .. .. extra 블록 (old에 없는 신규 바이트)
+
+const (
+	patchMagic      = "GODELTA1"
+	patchHeaderSize = 32
+)
+
+// 오류 타입 선언
+var (
+	ErrCorruptPatch = errors.New("corrupt delta patch")
+)
+
+// Diff는 oldData를 newData로 변환하는 패치를 생성한다.
+func Diff(oldData, newData []byte) ([]byte, error) {
+	var ctrl, diff, extra bytes.Buffer
+	varint := make([]byte, binary.MaxVarintLen64)
+
+	writeCtrl := func(v int) {
+		n := binary.PutVarint(varint, int64(v))
+		ctrl.Write(varint[:n])
+	}
+
+	I := qsufsort(oldData)
+	oldSize, newSize := len(oldData), len(newData)
+
+	var scan, pos, length int
+	var lastScan, lastPos, lastOffset int
+
+	for scan < newSize {
+		oldScore := 0
+
+		scan += length
+		for scsc := scan; scan < newSize; scan++ {
+			pos, length = search(I, oldData, newData[scan:], 0, oldSize)
+
+			for ; scsc < scan+length; scsc++ {
+				if scsc+lastOffset < oldSize && oldData[scsc+lastOffset] == newData[scsc] {
+					oldScore++
+				}
+			}
+
+			if (length == oldScore && length != 0) || length > oldScore+8 {
+				break
+			}
+
+			if scan+lastOffset < oldSize && oldData[scan+lastOffset] == newData[scan] {
+				oldScore--
+			}
+		}
+
+		if length == oldScore && scan != newSize {
+			continue
+		}
+
+		// 1. 앞쪽 확장 길이 계산
+		var s, sf, lenf int
+		for i := 0; lastScan+i < scan && lastPos+i < oldSize; {
+			if oldData[lastPos+i] == newData[lastScan+i] {
+				s++
+			}
+			i++
+			if s*2-i > sf*2-lenf {
+				sf = s
+				lenf = i
+			}
+		}
+
+		// 2. 뒤쪽 확장 길이 계산
+		lenb := 0
+		if scan < newSize {
+			var sb int
+			s = 0
+			for i := 1; scan >= lastScan+i && pos >= i; i++ {
+				if oldData[pos-i] == newData[scan-i] {
+					s++
+				}
+				if s*2-i > sb*2-lenb {
+					sb = s
+					lenb = i
+				}
+			}
+		}
+
+		// 3. 앞/뒤 확장 구간이 겹치면 최적 분할 지점 탐색
+		if lastScan+lenf > scan-lenb {
+			overlap := (lastScan + lenf) - (scan - lenb)
+			var ss, lens int
+			s = 0
+			for i := 0; i < overlap; i++ {
+				if newData[lastScan+lenf-overlap+i] == oldData[lastPos+lenf-overlap+i] {
+					s++
+				}
+				if newData[scan-lenb+i] == oldData[pos-lenb+i] {
+					s--
+				}
+				if s > ss {
+					ss = s
+					lens = i + 1
+				}
+			}
+			lenf += lens - overlap
+			lenb -= lens
+		}
+
+		// 4. diff, extra 블록 및 제어 정보 기록
+		for i := 0; i < lenf; i++ {
+			diff.WriteByte(newData[lastScan+i] - oldData[lastPos+i])
+		}
+
+		extraLen := (scan - lenb) - (lastScan + lenf)
+		extra.Write(newData[lastScan+lenf : lastScan+lenf+extraLen])
+
+		writeCtrl(lenf)
+		writeCtrl(extraLen)
+		writeCtrl((pos - lenb) - (lastPos + lenf))
+
+		lastScan = scan - lenb
+		lastPos = pos - lenb
+		lastOffset = pos - scan
+	}
+
+	// 5. 블록 압축 후 패치 조립
+	ctrlZ, err := compress(ctrl.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	diffZ, err := compress(diff.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	extraZ, err := compress(extra.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	patch := make([]byte, patchHeaderSize, patchHeaderSize+len(ctrlZ)+len(diffZ)+len(extraZ))
+	copy(patch, patchMagic)
+	binary.BigEndian.PutUint64(patch[8:], uint64(len(ctrlZ)))
+	binary.BigEndian.PutUint64(patch[16:], uint64(len(diffZ)))
+	binary.BigEndian.PutUint64(patch[24:], uint64(newSize))
+	patch = append(patch, ctrlZ...)
+	patch = append(patch, diffZ...)
+	patch = append(patch, extraZ...)
+
+	return patch, nil
+}
+
+// Patch는 oldData에 패치를 적용하여 새 이미지를 생성한다.
+func Patch(oldData, patch []byte) ([]byte, error) {
+	if len(patch) < patchHeaderSize || string(patch[:8]) != patchMagic {
+		return nil, ErrCorruptPatch
+	}
+
+	ctrlLen := binary.BigEndian.Uint64(patch[8:])
+	diffLen := binary.BigEndian.Uint64(patch[16:])
+	newSize := binary.BigEndian.Uint64(patch[24:])
+
+	body := uint64(len(patch) - patchHeaderSize)
+	if ctrlLen > body || diffLen > body-ctrlLen || newSize > maxImageSize {
+		return nil, ErrCorruptPatch
+	}
+
+	ctrlEnd := patchHeaderSize + ctrlLen
+	diffEnd := ctrlEnd + diffLen
+
+	ctrl, err := decompress(patch[patchHeaderSize:ctrlEnd])
+	if err != nil {
+		return nil, err
+	}
+	defer ctrl.Close()
+	diff, err := decompress(patch[ctrlEnd:diffEnd])
+	if err != nil {
+		return nil, err
+	}
+	defer diff.Close()
+	extra, err := decompress(patch[diffEnd:])
+	if err != nil {
+		return nil, err
+	}
+	defer extra.Close()
+
+	ctrlReader := bufio.NewReader(ctrl)
+	newData := make([]byte, newSize)
+	oldSize := int64(len(oldData))
+	var oldPos, newPos int64
+
+	for newPos < int64(newSize) {
+		var c [3]int64
+		for i := range c {
+			if c[i], err = binary.ReadVarint(ctrlReader); err != nil {
+				return nil, ErrCorruptPatch
+			}
+		}
+
+		// 1. diff 블록 적용
+		if c[0] < 0 || c[1] < 0 || newPos+c[0] > int64(newSize) {
+			return nil, ErrCorruptPatch
+		}
+		if _, err := io.ReadFull(diff, newData[newPos:newPos+c[0]]); err != nil {
+			return nil, ErrCorruptPatch
+		}
+		for i := int64(0); i < c[0]; i++ {
+			if oldPos+i >= 0 && oldPos+i < oldSize {
+				newData[newPos+i] += oldData[oldPos+i]
+			}
+		}
+		newPos += c[0]
+		oldPos += c[0]
+
+		// 2. extra 블록 복사
+		if newPos+c[1] > int64(newSize) {
+			return nil, ErrCorruptPatch
+		}
+		if _, err := io.ReadFull(extra, newData[newPos:newPos+c[1]]); err != nil {
+			return nil, ErrCorruptPatch
+		}
+		newPos += c[1]
+		oldPos += c[2]
+	}
+
+	return newData, nil
+}
+
+func compress(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(b); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(b []byte) (*gzip.Reader, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, ErrCorruptPatch
+	}
+	return zr, nil
+}
+
+// old 접미사 배열에서 newData와 가장 길게 일치하는 위치를 이진 탐색한다.
+func search(I []int, oldData, newData []byte, st, en int) (pos, n int) {
+	for en-st >= 2 {
+		x := st + (en-st)/2
+		cmpLen := min(len(oldData)-I[x], len(newData))
+		if bytes.Compare(oldData[I[x]:I[x]+cmpLen], newData[:cmpLen]) < 0 {
+			st = x
+		} else {
+			en = x
+		}
+	}
+
+	x := matchLen(oldData[I[st]:], newData)
+	y := matchLen(oldData[I[en]:], newData)
+	if x > y {
+		return I[st], x
+	}
+	return I[en], y
+}
+
+func matchLen(a, b []byte) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+// Larsson-Sadakane qsufsort 접미사 배열 생성
+func qsufsort(buf []byte) []int {
+	var buckets [256]int
+	n := len(buf)
+	I := make([]int, n+1)
+	V := make([]int, n+1)
+
+	for _, c := range buf {
+		buckets[c]++
+	}
+	for i := 1; i < 256; i++ {
+		buckets[i] += buckets[i-1]
+	}
+	for i := 255; i > 0; i-- {
+		buckets[i] = buckets[i-1]
+	}
+	buckets[0] = 0
+
+	for i, c := range buf {
+		buckets[c]++
+		I[buckets[c]] = i
+	}
+	I[0] = n
+
+	for i, c := range buf {
+		V[i] = buckets[c]
+	}
+	V[n] = 0
+
+	for i := 1; i < 256; i++ {
+		if buckets[i] == buckets[i-1]+1 {
+			I[buckets[i]] = -1
+		}
+	}
+	I[0] = -1
+
+	for h := 1; I[0] != -(n + 1); h += h {
+		length := 0
+		i := 0
+		for i < n+1 {
+			if I[i] < 0 {
+				length -= I[i]
+				i -= I[i]
+			} else {
+				if length != 0 {
+					I[i-length] = -length
+				}
+				length = V[I[i]] + 1 - i
+				split(I, V, i, length, h)
+				i += length
+				length = 0
+			}
+		}
+		if length != 0 {
+			I[i-length] = -length
+		}
+	}
+
+	for i := 0; i < n+1; i++ {
+		I[V[i]] = i
+	}
+
+	return I
+}
+
+func split(I, V []int, start, length, h int) {
+	if length < 16 {
+		for k := start; k < start+length; {
+			j := 1
+			x := V[I[k]+h]
+			for i := 1; k+i < start+length; i++ {
+				if V[I[k+i]+h] < x {
+					x = V[I[k+i]+h]
+					j = 0
+				}
+				if V[I[k+i]+h] == x {
+					I[k+i], I[k+j] = I[k+j], I[k+i]
+					j++
+				}
+			}
+			for i := 0; i < j; i++ {
+				V[I[k+i]] = k + j - 1
+			}
+			if j == 1 {
+				I[k] = -1
+			}
+			k += j
+		}
+		return
+	}
+
+	x := V[I[start+length/2]+h]
+	jj, kk := 0, 0
+	for i := start; i < start+length; i++ {
+		if V[I[i]+h] < x {
+			jj++
+		}
+		if V[I[i]+h] == x {
+			kk++
+		}
+	}
+	jj += start
+	kk += jj
+
+	i, j, k := start, 0, 0
+	for i < jj {
+		if V[I[i]+h] < x {
+			i++
+		} else if V[I[i]+h] == x {
+			I[i], I[jj+j] = I[jj+j], I[i]
+			j++
+		} else {
+			I[i], I[kk+k] = I[kk+k], I[i]
+			k++
+		}
+	}
+
+	for jj+j < kk {
+		if V[I[jj+j]+h] == x {
+			j++
+		} else {
+			I[jj+j], I[kk+k] = I[kk+k], I[jj+j]
+			k++
+		}
+	}
+
+	if jj > start {
+		split(I, V, start, jj-start, h)
+	}
+
+	for i := 0; i < kk-jj; i++ {
+		V[I[jj+i]] = kk - 1
+	}
+	if jj == kk-1 {
+		I[jj] = -1
+	}
+
+	if start+length > kk {
+		split(I, V, kk, start+length-kk, h)
+	}
+}
@@ -0,0 +1,135 @@
+package firmware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 일부 구간만 바뀐 새 버전 이미지를 만든다
+func similarImage(r *rand.Rand, old []byte) []byte {
+	image := append([]byte(nil), old...)
+	for i := 0; i < len(image); i += 4096 {
+		image[i] ^= 0xff
+	}
+	insert := make([]byte, 512)
+	r.Read(insert)
+	mid := len(image) / 2
+	return append(image[:mid], append(insert, image[mid:]...)...)
+}
+
+func TestDiffPatchRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := func(n int) []byte {
+		b := make([]byte, n)
+		r.Read(b)
+		return b
+	}
+	base := random(64 << 10)
+
+	tests := []struct {
+		name     string
+		old, new []byte
+	}{
+		{"empty", nil, nil},
+		{"from empty", nil, random(1000)},
+		{"to empty", random(1000), nil},
+		{"identical", base, base},
+		{"similar", base, similarImage(r, base)},
+		{"unrelated", random(10000), random(12000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := Diff(tt.old, tt.new)
+			if err != nil {
+				t.Fatalf("Diff: %v", err)
+			}
+			got, err := Patch(tt.old, patch)
+			if err != nil {
+				t.Fatalf("Patch: %v", err)
+			}
+			if !bytes.Equal(got, tt.new) {
+				t.Errorf("Patch result differs from new image (%d bytes, want %d)", len(got), len(tt.new))
+			}
+		})
+	}
+
+	// 비슷한 이미지의 패치는 전체 이미지보다 훨씬 작다
+	patch, _ := Diff(base, similarImage(r, base))
+	if len(patch) > len(base)/4 {
+		t.Errorf("patch for similar image is %d bytes, image is %d bytes", len(patch), len(base))
+	}
+}
+
+func TestPatchCorrupt(t *testing.T) {
+	old := bytes.Repeat([]byte("firmware"), 100)
+	patch, err := Diff(old, append(old, "v2"...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, corrupt := range map[string][]byte{
+		"empty":     nil,
+		"magic":     append([]byte("BADMAGIC"), patch[8:]...),
+		"truncated": patch[:patchHeaderSize+4],
+	} {
+		if _, err := Patch(old, corrupt); err == nil {
+			t.Errorf("Patch(%s): expected error", name)
+		}
+	}
+	if _, err := Patch(old, nil); !errors.Is(err, ErrCorruptPatch) {
+		t.Errorf("Patch(nil): err = %v, want ErrCorruptPatch", err)
+	}
+}
+
+func TestGenerateDelta(t *testing.T) {
+	dir := t.TempDir()
+	r := rand.New(rand.NewSource(2))
+	oldData := make([]byte, 32<<10)
+	r.Read(oldData)
+	newData := similarImage(r, oldData)
+
+	oldPath, newPath, outPath := filepath.Join(dir, "1.0.0.bin"), filepath.Join(dir, "2.0.0.bin"), filepath.Join(dir, "1.0.0-2.0.0.patch")
+	os.WriteFile(oldPath, oldData, 0o644)
+	os.WriteFile(newPath, newData, 0o644)
+	sum := sha256.Sum256(newData)
+	checksum := hex.EncodeToString(sum[:])
+
+	// 목표 이미지 체크섬이 다르면 패치를 저장하지 않는다
+	if _, err := GenerateDelta(oldPath, newPath, outPath, "00"+checksum[2:]); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("GenerateDelta with wrong checksum: err = %v, want ErrChecksumMismatch", err)
+	}
+	if _, err := os.Stat(outPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("patch file exists after failed GenerateDelta: %v", err)
+	}
+
+	delta, err := GenerateDelta(oldPath, newPath, outPath, checksum)
+	if err != nil {
+		t.Fatalf("GenerateDelta: %v", err)
+	}
+	if err := VerifyFile(delta.Path, delta.Checksum); err != nil {
+		t.Errorf("VerifyFile: %v", err)
+	}
+
+	patch, _ := os.ReadFile(outPath)
+	if int64(len(patch)) != delta.Size {
+		t.Errorf("Size = %d, file is %d bytes", delta.Size, len(patch))
+	}
+	if got, err := Patch(oldData, patch); err != nil || !bytes.Equal(got, newData) {
+		t.Errorf("stored patch does not reproduce the new image: %v", err)
+	}
+
+	// 임시 파일이 남지 않는다
+	if entries, _ := os.ReadDir(dir); len(entries) != 3 {
+		t.Errorf("%d files in output directory, want 3", len(entries))
+	}
+
+	if err := VerifyFile(delta.Path, checksum); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("VerifyFile with wrong checksum: err = %v, want ErrChecksumMismatch", err)
+	}
+}
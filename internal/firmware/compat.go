@@ -0,0 +1,100 @@
+package firmware
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-rest-example/internal/model/data"
+)
+
+// 오류 타입 선언
+var (
+	ErrIncompatibleHardware = errors.New("firmware does not support device hardware")
+	ErrSourceVersionTooOld  = errors.New("device firmware is older than the minimum source version")
+	ErrInvalidVersion       = errors.New("firmware version is not numeric")
+)
+
+// CheckCompatible은 펌웨어를 디바이스에 설치할 수 있는지 확인한다.
+// 호환 목록에 디바이스의 제품군/하드웨어 리비전이 있어야 하며,
+// 현재 버전이 최소 요구 버전 이상이어야 한다.
+func CheckCompatible(fw *data.Firmware, device *data.Device) error {
+	if !supportsHardware(fw.Compatibility, device.ProductLine, device.HardwareRevision) {
+		return ErrIncompatibleHardware
+	}
+
+	// 현재 버전을 알 수 없거나 해석할 수 없는 디바이스는 최소 요구 버전을 만족한다고 볼 수 없다
+	if fw.MinSourceVersion != "" {
+		if device.FirmwareVersion == "" {
+			return ErrSourceVersionTooOld
+		}
+		cmp, err := CompareVersions(device.FirmwareVersion, fw.MinSourceVersion)
+		if err != nil {
+			return err
+		}
+		if cmp < 0 {
+			return ErrSourceVersionTooOld
+		}
+	}
+
+	return nil
+}
+
+func supportsHardware(compat []data.FirmwareCompat, productLine, hardwareRevision string) bool {
+	for _, c := range compat {
+		if c.ProductLine != productLine {
+			continue
+		}
+		if c.HardwareRevision == data.AnyHardwareRevision || c.HardwareRevision == hardwareRevision {
+			return true
+		}
+	}
+	return false
+}
+
+// CompareVersions는 "1.02.03" 형식의 버전을 숫자 단위로 비교한다.
+// a < b 이면 -1, a == b 이면 0, a > b 이면 1을 반환한다.
+// 숫자가 아닌 자리가 있으면 비교하지 않고 ErrInvalidVersion을 반환한다.
+func CompareVersions(a, b string) (int, error) {
+	as, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	bs, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+
+		switch {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
+
+func parseVersion(v string) ([]int, error) {
+	parts := strings.Split(v, ".")
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidVersion, v)
+		}
+		nums[i] = n
+	}
+	return nums, nil
+}
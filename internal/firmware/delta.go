@@ -0,0 +1,114 @@
+package firmware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"go-rest-example/internal/util"
+)
+
+// 패치 생성 시 메모리에 올릴 수 있는 최대 이미지 크기
+// 접미사 정렬(qsufsort)이 원본 크기+1 길이의 []int 두 개(약 16배)를 사용하므로 16MiB 이미지도 약 256MiB를 사용한다.
+const maxImageSize = 16 << 20
+
+// 오류 타입 선언
+var (
+	ErrImageTooLarge    = errors.New("firmware image is too large for delta generation")
+	ErrChecksumMismatch = errors.New("firmware checksum mismatch")
+)
+
+// 생성된 델타 패치 파일 정보
+type DeltaFile struct {
+	Path     string // 패치 파일 경로
+	Checksum string // 패치 파일 sha256 (hex)
+	Size     int64  // 패치 파일 크기 (byte)
+}
+
+// GenerateDelta는 oldPath 이미지를 newPath 이미지로 변환하는 패치를 outPath에 생성한다.
+// 생성된 패치를 실제로 적용해 결과 이미지가 targetChecksum과 일치하는지 검증한 뒤에만 저장한다.
+func GenerateDelta(oldPath, newPath, outPath, targetChecksum string) (*DeltaFile, error) {
+	oldData, err := readImage(oldPath)
+	if err != nil {
+		return nil, err
+	}
+
+	newData, err := readImage(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 목표 이미지 무결성 확인
+	if sum := sha256.Sum256(newData); hex.EncodeToString(sum[:]) != targetChecksum {
+		return nil, ErrChecksumMismatch
+	}
+
+	// 2. 패치 생성
+	patch, err := Diff(oldData, newData)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 패치 적용 결과 검증
+	result, err := Patch(oldData, patch)
+	if err != nil {
+		return nil, err
+	}
+	if sum := sha256.Sum256(result); hex.EncodeToString(sum[:]) != targetChecksum {
+		return nil, ErrChecksumMismatch
+	}
+
+	// 4. 임시 파일에 기록 후 교체하여 불완전한 패치가 노출되지 않도록 한다
+	tmp, err := os.CreateTemp(filepath.Dir(outPath), ".delta-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(patch); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), outPath); err != nil {
+		return nil, err
+	}
+
+	patchSum := sha256.Sum256(patch)
+	return &DeltaFile{
+		Path:     outPath,
+		Checksum: hex.EncodeToString(patchSum[:]),
+		Size:     int64(len(patch)),
+	}, nil
+}
+
+// VerifyFile은 저장된 파일이 등록 당시의 체크썸과 일치하는지 확인한다.
+func VerifyFile(path, checksum string) error {
+	sum, err := util.CheckSum(path)
+	if err != nil {
+		return err
+	}
+	if sum != checksum {
+		return ErrChecksumMismatch
+	}
+	return nil
+}
+
+func readImage(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxImageSize {
+		return nil, ErrImageTooLarge
+	}
+	return os.ReadFile(path)
+}
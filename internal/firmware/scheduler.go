@@ -0,0 +1,158 @@
+package firmware
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// 다운로드 스케줄러 설정
+// 0 이하의 값은 해당 제한을 사용하지 않음을 의미한다.
+type SchedulerConfig struct {
+	MaxConcurrent int           // 전체 동시 다운로드 수
+	MaxPerGroup   int           // 디바이스 그룹별 동시 다운로드 수
+	BytesPerSec   int64         // 전체 다운로드 대역폭 (byte/s)
+	RetryBase     time.Duration // 재시도 안내 기본 대기 시간
+	RetryMax      time.Duration // 재시도 안내 최대 대기 시간
+}
+
+// DownloadScheduler는 펌웨어 다운로드의 동시 실행 수와 대역폭을 제한한다.
+// 배포가 수천 대에 동시에 시작되어도 서버와 업링크가 포화되지 않도록 한다.
+type DownloadScheduler struct {
+	cfg    SchedulerConfig
+	bucket *TokenBucket
+
+	mu     sync.Mutex
+	active int
+	groups map[string]int
+}
+
+func NewDownloadScheduler(cfg SchedulerConfig) *DownloadScheduler {
+	if cfg.RetryBase <= 0 {
+		cfg.RetryBase = 30 * time.Second
+	}
+	if cfg.RetryMax <= 0 {
+		cfg.RetryMax = 10 * time.Minute
+	}
+	if cfg.RetryMax < cfg.RetryBase {
+		cfg.RetryMax = cfg.RetryBase
+	}
+
+	var bucket *TokenBucket
+	if cfg.BytesPerSec > 0 {
+		bucket = NewTokenBucket(cfg.BytesPerSec, cfg.BytesPerSec)
+	}
+
+	return &DownloadScheduler{
+		cfg:    cfg,
+		bucket: bucket,
+		groups: make(map[string]int),
+	}
+}
+
+// Acquire는 그룹에 다운로드 슬롯을 할당한다.
+// 여유가 없으면 false를 반환하며, 성공 시 반환된 release 함수를 반드시 호출해야 한다.
+func (s *DownloadScheduler) Acquire(group string) (func(), bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cfg.MaxConcurrent > 0 && s.active >= s.cfg.MaxConcurrent {
+		return nil, false
+	}
+	if s.cfg.MaxPerGroup > 0 && s.groups[group] >= s.cfg.MaxPerGroup {
+		return nil, false
+	}
+
+	s.active++
+	s.groups[group]++
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			s.active--
+			if s.groups[group]--; s.groups[group] <= 0 {
+				delete(s.groups, group)
+			}
+		})
+	}
+
+	return release, true
+}
+
+// Bucket은 다운로드 응답에 적용할 전체 대역폭 토큰 버킷을 반환한다. (제한이 없으면 nil)
+func (s *DownloadScheduler) Bucket() *TokenBucket {
+	return s.bucket
+}
+
+// RetryAfter는 재시도 횟수(attempt)에 따른 지수 백오프에 지터를 더한 대기 시간을 반환한다.
+// 대기 시간은 [backoff/2, backoff) 범위에서 무작위로 선택되어 디바이스들의 재시도가 분산된다.
+func (s *DownloadScheduler) RetryAfter(attempt int) time.Duration {
+	backoff := s.cfg.RetryBase
+	for i := 0; i < attempt && backoff < s.cfg.RetryMax; i++ {
+		backoff *= 2
+	}
+	if backoff > s.cfg.RetryMax {
+		backoff = s.cfg.RetryMax
+	}
+
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// TokenBucket은 초당 rate 만큼 토큰이 채워지는 토큰 버킷이다.
+// 여러 다운로드가 하나의 버킷을 공유하여 전체 대역폭을 제한한다.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucket(rate, burst int64) *TokenBucket {
+	return &TokenBucket{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Burst는 한 번에 요청할 수 있는 최대 토큰 수를 반환한다.
+func (b *TokenBucket) Burst() int {
+	return int(b.burst)
+}
+
+// WaitN은 n개의 토큰을 확보할 때까지 대기한다. n은 Burst 이하여야 한다.
+func (b *TokenBucket) WaitN(ctx context.Context, n int) error {
+	for {
+		b.mu.Lock()
+		now := time.Now()
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+
+		if b.tokens >= float64(n) {
+			b.tokens -= float64(n)
+			b.mu.Unlock()
+			return nil
+		}
+
+		wait := time.Duration((float64(n) - b.tokens) / b.rate * float64(time.Second))
+		b.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package firmware
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDownloadSchedulerAcquire(t *testing.T) {
+	s := NewDownloadScheduler(SchedulerConfig{MaxConcurrent: 3, MaxPerGroup: 2})
+
+	// 그룹별 제한
+	releaseA1, ok := s.Acquire("ABC")
+	if !ok {
+		t.Fatal("first ABC download rejected")
+	}
+	if _, ok := s.Acquire("ABC"); !ok {
+		t.Fatal("second ABC download rejected")
+	}
+	if _, ok := s.Acquire("ABC"); ok {
+		t.Error("third ABC download accepted over MaxPerGroup")
+	}
+
+	// 전체 제한
+	if _, ok := s.Acquire("XYZ"); !ok {
+		t.Fatal("first XYZ download rejected")
+	}
+	if _, ok := s.Acquire("XYZ"); ok {
+		t.Error("download accepted over MaxConcurrent")
+	}
+
+	// release는 여러 번 호출해도 슬롯을 한 번만 반환한다
+	releaseA1()
+	releaseA1()
+	if _, ok := s.Acquire("XYZ"); !ok {
+		t.Error("download rejected after release")
+	}
+	if _, ok := s.Acquire("ABC"); ok {
+		t.Error("download accepted after a repeated release")
+	}
+
+	// 0 이하의 제한은 사용하지 않는다
+	unlimited := NewDownloadScheduler(SchedulerConfig{})
+	for i := 0; i < 100; i++ {
+		if _, ok := unlimited.Acquire("ABC"); !ok {
+			t.Fatalf("unlimited scheduler rejected download %d", i)
+		}
+	}
+	if unlimited.Bucket() != nil {
+		t.Error("Bucket() != nil without BytesPerSec")
+	}
+}
+
+func TestDownloadSchedulerRetryAfter(t *testing.T) {
+	s := NewDownloadScheduler(SchedulerConfig{RetryBase: time.Second, RetryMax: 8 * time.Second})
+
+	// 지수 백오프의 [backoff/2, backoff] 범위에서 선택하고, 최대 대기 시간을 넘지 않는다
+	for attempt, backoff := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second, 8 * time.Second} {
+		for i := 0; i < 50; i++ {
+			if got := s.RetryAfter(attempt); got < backoff/2 || got > backoff {
+				t.Fatalf("RetryAfter(%d) = %v, want within [%v, %v]", attempt, got, backoff/2, backoff)
+			}
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	b := NewTokenBucket(1000, 100)
+	if b.Burst() != 100 {
+		t.Errorf("Burst = %d, want 100", b.Burst())
+	}
+
+	// 가득 찬 버킷은 기다리지 않고, 비운 뒤에는 rate에 맞춰 채워질 때까지 기다린다
+	start := time.Now()
+	if err := b.WaitN(ctx, 100); err != nil {
+		t.Fatalf("WaitN: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("WaitN on a full bucket took %v", elapsed)
+	}
+
+	start = time.Now()
+	if err := b.WaitN(ctx, 50); err != nil {
+		t.Fatalf("WaitN: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("WaitN on an empty bucket took %v, want about 50ms", elapsed)
+	}
+
+	// 기다리는 중 ctx가 종료되면 ctx 오류를 반환한다
+	cancelCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := b.WaitN(cancelCtx, 100); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitN after deadline: err = %v, want DeadlineExceeded", err)
+	}
+}
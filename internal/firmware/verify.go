@@ -0,0 +1,57 @@
+package firmware
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// 검증을 마친 파일 정보
+type verifiedFile struct {
+	checksum string
+	size     int64
+	modTime  time.Time
+}
+
+// Verifier는 전송할 이미지와 패치 파일의 체크썸 검증 결과를 보관합니다.
+// 파일마다 처음 한 번만 전체를 읽어 검증하고, 이후에는 크기와 수정 시간이 그대로인지만 확인합니다.
+// 파일이 교체되거나 변경되면 다시 검증합니다.
+type Verifier struct {
+	mu       sync.Mutex
+	verified map[string]verifiedFile
+}
+
+func NewVerifier() *Verifier {
+	return &Verifier{verified: make(map[string]verifiedFile)}
+}
+
+// Verify - 파일이 등록 당시의 체크썸과 일치하는지 확인합니다. 일치하지 않으면 ErrChecksumMismatch를 반환합니다.
+func (v *Verifier) Verify(path, checksum string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	cached, ok := v.verified[path]
+	v.mu.Unlock()
+	if ok && cached.checksum == checksum && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return nil
+	}
+
+	if err := VerifyFile(path, checksum); err != nil {
+		v.forget(path)
+		return err
+	}
+
+	v.mu.Lock()
+	v.verified[path] = verifiedFile{checksum: checksum, size: info.Size(), modTime: info.ModTime()}
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *Verifier) forget(path string) {
+	v.mu.Lock()
+	delete(v.verified, path)
+	v.mu.Unlock()
+}
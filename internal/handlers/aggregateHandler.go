@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/external"
+)
+
+type AggregateHandler struct {
+	agRepo db.AggregatesDataService
+	dsRepo db.DevicesDataService
+	logger *logger.AppLogger
+}
+
+func NewAggregateHandler(lgr *logger.AppLogger, agRepo db.AggregatesDataService, dsRepo db.DevicesDataService) (*AggregateHandler, error) {
+	if lgr == nil || agRepo == nil || dsRepo == nil {
+		return nil, errors2.New("missing required parameters to create aggregate handler")
+	}
+
+	return &AggregateHandler{
+		agRepo: agRepo,
+		dsRepo: dsRepo,
+		logger: lgr,
+	}, nil
+}
+
+// Device handles GET /device/:ID/reports/aggregate?from=&to=&bucket=5m|1h|1d&fields=battery,temperature.
+// 디바이스 보고의 구간별 최소/최대/평균/건수를 반환한다.
+func (a *AggregateHandler) Device(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+	productNumber := c.Param("ID")
+
+	// 0. 쿼리 파라미터 획득 및 유효성 검사
+	var aggregateReq external.AggregateReq
+	if err := c.ShouldBindQuery(&aggregateReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid aggregate query", requestID, err)
+		return
+	}
+	if err := aggregateReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid aggregate query", requestID, err)
+		return
+	}
+
+	// 1. 디바이스 존재 여부 확인
+	if _, err := a.dsRepo.GetByID(c, productNumber); err != nil {
+		abortWithAPIError(c, lgr, deviceErrorStatus(err), "failed to find device", requestID, err)
+		return
+	}
+
+	a.aggregate(c, &aggregateReq, productNumber)
+}
+
+// Fleet handles GET /device/reports/aggregate?from=&to=&bucket=5m|1h|1d&fields=&groupBy=status|firmware|group.
+// 모든 디바이스 보고의 구간별 통계를 디바이스의 현재 상태, 펌웨어 버전 또는 그룹(제품군)별로 반환한다.
+func (a *AggregateHandler) Fleet(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+
+	// 0. 쿼리 파라미터 획득 및 유효성 검사
+	var aggregateReq external.AggregateReq
+	if err := c.ShouldBindQuery(&aggregateReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid aggregate query", requestID, err)
+		return
+	}
+	if err := aggregateReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid aggregate query", requestID, err)
+		return
+	}
+
+	a.aggregate(c, &aggregateReq, "")
+}
+
+func (a *AggregateHandler) aggregate(c *gin.Context, aggregateReq *external.AggregateReq, productNumber string) {
+	lgr, requestID := a.logger.WithReqID(c)
+
+	query := aggregateReq.Query(productNumber)
+	aggregates, err := a.agRepo.Aggregate(c, query)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if db.IsUnavailable(err) {
+			status = http.StatusServiceUnavailable
+		}
+		abortWithAPIError(c, lgr, status, "failed to aggregate reports", requestID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, external.NewAggregateRes(aggregateReq, &query, aggregates))
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-rest-example/internal/alert"
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/external"
+)
+
+type AlertHandler struct {
+	engine *alert.Engine
+	alRepo db.AlertsDataService
+	logger *logger.AppLogger
+}
+
+func NewAlertHandler(lgr *logger.AppLogger, engine *alert.Engine, alRepo db.AlertsDataService) (*AlertHandler, error) {
+	if lgr == nil || engine == nil || alRepo == nil {
+		return nil, errors2.New("missing required parameters to create alert handler")
+	}
+
+	return &AlertHandler{
+		engine: engine,
+		alRepo: alRepo,
+		logger: lgr,
+	}, nil
+}
+
+// CreateRule handles POST /alerts/rules.
+func (a *AlertHandler) CreateRule(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+
+	// 0. 요청 데이터 획득 및 유효성 검사
+	var ruleReq external.AlertRuleReq
+	if err := c.ShouldBindBodyWithJSON(&ruleReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid alert rule data", requestID, err)
+		return
+	}
+	if err := ruleReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid alert rule data", requestID, err)
+		return
+	}
+
+	// 1. 규칙 등록 후 평가 대상에 반영
+	rule := ruleReq.Rule(0)
+	created, err := a.engine.CreateRule(c, &rule)
+	if err != nil {
+		abortWithAPIError(c, lgr, alertErrorStatus(err), "failed to create alert rule", requestID, err)
+		return
+	}
+
+	lgr.Info().Int64("ruleID", created.RuleID).Str("kind", string(created.Kind)).Msg("alert rule created")
+	c.JSON(http.StatusCreated, external.NewAlertRuleRes(created))
+}
+
+// GetRules handles GET /alerts/rules.
+func (a *AlertHandler) GetRules(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+
+	rules, err := a.alRepo.ListRules(c)
+	if err != nil {
+		abortWithAPIError(c, lgr, alertErrorStatus(err), "failed to select alert rules", requestID, err)
+		return
+	}
+
+	res := make([]external.AlertRuleRes, len(rules))
+	for i := range rules {
+		res[i] = external.NewAlertRuleRes(&rules[i])
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// GetRule handles GET /alerts/rules/:ID.
+func (a *AlertHandler) GetRule(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+
+	ruleID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid rule ID", requestID, err)
+		return
+	}
+
+	rule, err := a.alRepo.GetRule(c, ruleID)
+	if err != nil {
+		abortWithAPIError(c, lgr, alertErrorStatus(err), "failed to find alert rule", requestID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, external.NewAlertRuleRes(rule))
+}
+
+// UpdateRule handles PUT /alerts/rules/:ID.
+// 규칙을 비활성화하거나 조건을 바꾸면 기존 조건으로 열린 알림은 해소된다.
+func (a *AlertHandler) UpdateRule(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+
+	ruleID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid rule ID", requestID, err)
+		return
+	}
+
+	var ruleReq external.AlertRuleReq
+	if err := c.ShouldBindBodyWithJSON(&ruleReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid alert rule data", requestID, err)
+		return
+	}
+	if err := ruleReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid alert rule data", requestID, err)
+		return
+	}
+
+	rule := ruleReq.Rule(ruleID)
+	updated, err := a.engine.UpdateRule(c, &rule)
+	if err != nil {
+		abortWithAPIError(c, lgr, alertErrorStatus(err), "failed to update alert rule", requestID, err)
+		return
+	}
+
+	lgr.Info().Int64("ruleID", ruleID).Bool("enabled", updated.Enabled).Msg("alert rule updated")
+	c.JSON(http.StatusOK, external.NewAlertRuleRes(updated))
+}
+
+// DeleteRule handles DELETE /alerts/rules/:ID.
+// 규칙의 열린 알림은 해소되며 알림 이력은 남는다.
+func (a *AlertHandler) DeleteRule(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+
+	ruleID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid rule ID", requestID, err)
+		return
+	}
+
+	if err := a.engine.DeleteRule(c, ruleID); err != nil {
+		abortWithAPIError(c, lgr, alertErrorStatus(err), "failed to delete alert rule", requestID, err)
+		return
+	}
+
+	lgr.Info().Int64("ruleID", ruleID).Msg("alert rule deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// GetAll handles GET /alerts?state=&productNumber=&ruleID=&before=&limit=.
+func (a *AlertHandler) GetAll(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+
+	var listReq external.AlertListReq
+	if err := c.ShouldBindQuery(&listReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid alert query", requestID, err)
+		return
+	}
+	if err := listReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid alert query", requestID, err)
+		return
+	}
+
+	alerts, err := a.alRepo.ListAlerts(c, listReq.Query())
+	if err != nil {
+		abortWithAPIError(c, lgr, alertErrorStatus(err), "failed to select alerts", requestID, err)
+		return
+	}
+
+	res := make([]external.AlertRes, len(alerts))
+	for i := range alerts {
+		res[i] = external.NewAlertRes(&alerts[i])
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// GetByID handles GET /alerts/:ID.
+func (a *AlertHandler) GetByID(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+
+	alertID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid alert ID", requestID, err)
+		return
+	}
+
+	found, err := a.alRepo.GetAlert(c, alertID)
+	if err != nil {
+		abortWithAPIError(c, lgr, alertErrorStatus(err), "failed to find alert", requestID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, external.NewAlertRes(found))
+}
+
+// Acknowledge handles POST /alerts/:ID/ack.
+// firing 상태의 알림만 확인할 수 있으며, 확인한 알림은 조건이 해소될 때까지 다시 발생해도 확인 상태를 유지한다.
+func (a *AlertHandler) Acknowledge(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+
+	alertID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid alert ID", requestID, err)
+		return
+	}
+
+	var ackReq external.AlertAckReq
+	if err := c.ShouldBindBodyWithJSON(&ackReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid acknowledge data", requestID, err)
+		return
+	}
+	if err := ackReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid acknowledge data", requestID, err)
+		return
+	}
+
+	acked, err := a.engine.Acknowledge(c, alertID, ackReq.By)
+	if err != nil {
+		abortWithAPIError(c, lgr, alertErrorStatus(err), "failed to acknowledge alert", requestID, err)
+		return
+	}
+
+	lgr.Info().Int64("alertID", alertID).Str("by", ackReq.By).Msg("alert acknowledged")
+	c.JSON(http.StatusOK, external.NewAlertRes(acked))
+}
+
+// Resolve handles POST /alerts/:ID/resolve.
+// 열린 알림을 직접 해소한다. 조건이 계속되면 다음 평가에서 새 알림이 발생한다.
+func (a *AlertHandler) Resolve(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+
+	alertID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid alert ID", requestID, err)
+		return
+	}
+
+	resolved, err := a.engine.Resolve(c, alertID)
+	if err != nil {
+		abortWithAPIError(c, lgr, alertErrorStatus(err), "failed to resolve alert", requestID, err)
+		return
+	}
+
+	lgr.Info().Int64("alertID", alertID).Msg("alert resolved")
+	c.JSON(http.StatusOK, external.NewAlertRes(resolved))
+}
+
+// EngineStats handles GET /internal/alerts/engine.
+// 알림 평가 대기열 및 발생/해소 현황을 반환한다.
+func (a *AlertHandler) EngineStats(c *gin.Context) {
+	c.JSON(http.StatusOK, a.engine.Stats())
+}
+
+// 존재하지 않는 규칙/알림은 404, 상태가 맞지 않으면 409, 데이터베이스 장애는 503으로 응답한다
+func alertErrorStatus(err error) int {
+	switch {
+	case errors2.Is(err, db.ErrRuleNotFound), errors2.Is(err, db.ErrAlertNotFound):
+		return http.StatusNotFound
+	case errors2.Is(err, db.ErrAlertStateConflict):
+		return http.StatusConflict
+	case db.IsUnavailable(err):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"go-rest-example/internal/model/external"
+)
+
+// 에러 발생 시 응답 생성 역할 수행
+// 모든 핸들러가 공용으로 사용하는 에러 응답 생성 함수
+func abortWithAPIError(
+	c *gin.Context,
+	lgr zerolog.Logger,
+	status int,
+	message, debugID string,
+	err error,
+) {
+	apiErr := &external.APIError{
+		HTTPStatusCode: status,
+		Message:        message,
+		DebugID:        debugID,
+	}
+
+	event := lgr.Error().Int("HttpStatusCode", status)
+	if err != nil {
+		event.Err(err)
+	}
+
+	event.Msg(message)
+	c.AbortWithStatusJSON(status, apiErr)
+}
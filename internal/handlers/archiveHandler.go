@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-rest-example/internal/archive"
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/external"
+)
+
+type ArchiveHandler struct {
+	restorer *archive.Restorer
+	logger   *logger.AppLogger
+}
+
+func NewArchiveHandler(lgr *logger.AppLogger, restorer *archive.Restorer) (*ArchiveHandler, error) {
+	if lgr == nil || restorer == nil {
+		return nil, errors2.New("missing required parameters to create archive handler")
+	}
+
+	return &ArchiveHandler{
+		restorer: restorer,
+		logger:   lgr,
+	}, nil
+}
+
+// List handles GET /internal/reports/archives?from=&to=&productNumber=.
+// 보고 시간 [from, to) 범위의 보고를 포함하는 보관 파일 목록을 반환한다.
+func (a *ArchiveHandler) List(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+	var rangeReq external.ArchiveRangeReq
+
+	// 0. 쿼리 파라미터 획득 및 유효성 검사
+	if err := c.ShouldBindQuery(&rangeReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid archive range", requestID, err)
+		return
+	}
+	if err := rangeReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid archive range", requestID, err)
+		return
+	}
+
+	// 1. 목록 조회
+	archives, err := a.restorer.List(c, rangeReq.From, rangeReq.To, rangeReq.ProductNumber)
+	if err != nil {
+		abortWithAPIError(c, lgr, archiveErrorStatus(err), "failed to select report archives", requestID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, archives)
+}
+
+// Restore handles POST /internal/reports/restore.
+// 보고 시간 [from, to) 범위의 보관된 보고를 조사용으로 reports 테이블에 복원한다.
+// 이미 있는 보고는 건너뛰므로 같은 범위를 다시 요청해도 중복되지 않는다.
+func (a *ArchiveHandler) Restore(c *gin.Context) {
+	lgr, requestID := a.logger.WithReqID(c)
+	var rangeReq external.ArchiveRangeReq
+
+	// 0. BODY -> JSON 직렬화
+	if err := c.ShouldBindBodyWithJSON(&rangeReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid restore request body", requestID, err)
+		return
+	}
+
+	// 1. 객체 유효성 검사
+	if err := rangeReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid restore request body", requestID, err)
+		return
+	}
+
+	// 2. 복원
+	result, err := a.restorer.Restore(c, rangeReq.From, rangeReq.To, rangeReq.ProductNumber)
+	if err != nil {
+		abortWithAPIError(c, lgr, archiveErrorStatus(err), "failed to restore archived reports", requestID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// 데이터베이스 장애는 503, 보관 파일 손상/누락은 500으로 응답한다
+func archiveErrorStatus(err error) int {
+	if db.IsUnavailable(err) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
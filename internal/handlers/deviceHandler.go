@@ -14,50 +14,68 @@ import (
 )
 
 type DevicesHandler struct {
+	txMgr  db.TxManager
 	dsRepo db.DevicesDataService
+	lsRepo db.LatestStateDataService
+	outbox db.OutboxDataService
+	cache  db.DeviceCacheStatsProvider
 	logger *logger.AppLogger
 }
 
-func NewDevicesHandler(lgr *logger.AppLogger,dsRepo db.DevicesDataService )(*DevicesHandler, error){
-	if lgr == nil || dsRepo == nil {
+// outbox는 디바이스 등록 이벤트를 기록하지 않으면 nil, cache는 디바이스 조회 캐시를 사용하지 않으면 nil입니다.
+func NewDevicesHandler(lgr *logger.AppLogger, txMgr db.TxManager, dsRepo db.DevicesDataService, lsRepo db.LatestStateDataService, outbox db.OutboxDataService, cache db.DeviceCacheStatsProvider)(*DevicesHandler, error){
+	if lgr == nil || txMgr == nil || dsRepo == nil || lsRepo == nil {
 		return nil, errors2.New("missing required parameters to create orders handler")
 	}
 
-	return &DevicesHandler{dsRepo: dsRepo, logger: lgr}, nil
+	return &DevicesHandler{txMgr: txMgr, dsRepo: dsRepo, lsRepo: lsRepo, outbox: outbox, cache: cache, logger: lgr}, nil
 }
 
 
 // Create handles POST /device.
 func(d *DevicesHandler) Create(c *gin.Context){
-	// lgr, requestID := d.logger.WithReqID(c)
+	lgr, requestID := d.logger.WithReqID(c)
 	var deviceReq external.DeviceReq
 
 	// 0. BODY -> JSON 직렬화
-	err := c.ShouldBindBodyWithJSON(&deviceReq) 
-	if err != nil {
-		// 커스텀 에러 선언 필요 
-		return 
+	if err := c.ShouldBindBodyWithJSON(&deviceReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid device data", requestID, err)
+		return
 	}
 
-	// 1. 객체 유효성 검사 
-	err = deviceReq.Validate()
-	if err != nil {
-		// 커스텀 에러 선언 필요 
-		return 
+	// 1. 객체 유효성 검사
+	if err := deviceReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid device data", requestID, err)
+		return
+	}
+
+	// 2. DB 중복 객체 존재 여부 확인 (존재하지 않는 경우에만 등록)
+	_, err := d.dsRepo.GetByID(c, deviceReq.ProductNumber)
+	if err == nil {
+		abortWithAPIError(c, lgr, http.StatusConflict, "device already exists", requestID, nil)
+		return
+	}
+	if !errors2.Is(err, db.ErrDeviceNotFound) {
+		abortWithAPIError(c, lgr, deviceErrorStatus(err), "failed to find device", requestID, err)
+		return
 	}
 
-	// 2. DB 중복 객체 존재 여부 확인
-	findDevice, err := d.dsRepo.GetByID(c, deviceReq.ProductNumber)
-	if err != nil || findDevice != nil {
-		// 커스텀 에러 선언 필요 
-		return 
+	// 3. 제품군 및 하드웨어 리비전 결정 : 지정되지 않은 경우 제품 번호에서 추출
+	productLine, hardwareRevision := data.ParseProductNumber(deviceReq.ProductNumber)
+	if deviceReq.ProductLine != "" {
+		productLine = deviceReq.ProductLine
+	}
+	if deviceReq.HardwareRevision != "" {
+		hardwareRevision = deviceReq.HardwareRevision
 	}
 
-	// 3. 객체 생성을 위한 도메인 엔티티 생성
+	// 4. 객체 생성을 위한 도메인 엔티티 생성
 	newDevice := data.Device{
 		InternalID 	  : 1, 
 		ProductNumber : deviceReq.ProductNumber,
 		MacAddress    : deviceReq.MacAddress,
+		ProductLine   : productLine,
+		HardwareRevision : hardwareRevision,
 		FirmwareVersion : deviceReq.FirmwareVersion,  
 		LastSeenAt    : time.Now(),
 		CreatedAt     : time.Now(),
@@ -66,35 +84,110 @@ func(d *DevicesHandler) Create(c *gin.Context){
 		Status        : data.StatusReady,
 	}
 
-	_, err = d.dsRepo.Create(c, &newDevice)
+	// 5. 디바이스 등록과 등록 이벤트 기록을 하나의 트랜잭션으로 처리
+	events := d.createdEvents(&newDevice)
+	err = d.txMgr.WithTx(c, func(tx db.DBTX) error {
+		if _, err := d.dsRepo.WithTx(tx).Create(c, &newDevice); err != nil {
+			return err
+		}
+		if d.outbox == nil || len(events) == 0 {
+			return nil
+		}
+		return d.outbox.WithTx(tx).Add(c, events)
+	})
 	if err != nil {
-		// 커스텀 에러 선언 필요 
-		return 
+		abortWithAPIError(c, lgr, deviceErrorStatus(err), "failed to create device", requestID, err)
+		return
 	}
 
+	lgr.Info().Str("productNumber", newDevice.ProductNumber).Msg("device created")
 	c.String(http.StatusCreated, "update is ok" )
 }
 
-// Select handles GET /device.
+func (d *DevicesHandler) createdEvents(device *data.Device) []data.Event {
+	if d.outbox == nil {
+		return nil
+	}
+
+	event, err := data.NewEvent(data.EventDeviceCreated, device.ProductNumber, device.CreatedAt,
+		external.NewDeviceRes(&data.DeviceWithState{Device: *device}, false))
+	if err != nil {
+		d.logger.Error().Err(err).Str("productNumber", device.ProductNumber).Msg("failed to create device event")
+		return nil
+	}
+	return []data.Event{event}
+}
+
+// Select handles GET /device?include=state&batteryBelow=&after=&limit=.
+// 디바이스 목록을 ProductNumber 순서로 반환한다. batteryBelow 지정 시 마지막 보고의 배터리가 그 값 미만인 디바이스만 반환한다.
 func(d *DevicesHandler) GetAll(c *gin.Context){
-	// 0. 데이터 레이어를 통한 정보 획득 
+	lgr, requestID := d.logger.WithReqID(c)
+	var listReq external.DeviceListReq
 
-	// 1. 정보 반환 
-	c.JSON(http.StatusCreated, nil)
+	// 0. 쿼리 파라미터 획득 및 유효성 검사
+	if err := c.ShouldBindQuery(&listReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid device list query", requestID, err)
+		return
+	}
+	if err := listReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid device list query", requestID, err)
+		return
+	}
+
+	// 1. 데이터 레이어를 통한 정보 획득 (마지막 보고 상태와 함께 조회)
+	devices, err := d.lsRepo.List(c, listReq.Query())
+	if err != nil {
+		abortWithAPIError(c, lgr, deviceErrorStatus(err), "failed to select devices", requestID, err)
+		return
+	}
+
+	// 2. 정보 반환
+	res := make([]external.DeviceRes, 0, len(devices))
+	for i := range devices {
+		res = append(res, external.NewDeviceRes(&devices[i], listReq.IncludeState()))
+	}
+	c.JSON(http.StatusOK, res)
 }
 
-// Select handles GET /device/ID=.
+// Select handles GET /device/:ID.
+// 디바이스 정보와 마지막 보고 상태(배터리, 위치, 온도 등)를 반환한다.
 func(d *DevicesHandler) GetByID(c *gin.Context){
-	// 0. 쿼리 파라미터 획득 
-	i := c.Query("ID") 
+	lgr, requestID := d.logger.WithReqID(c)
 
-	// 1. 데이터 레이어를 통한 정보 획득 
-	findDevice, err := d.dsRepo.GetByID(c, i)
+	// 0. 경로 파라미터 획득
+	i := c.Param("ID")
+
+	// 1. 데이터 레이어를 통한 정보 획득
+	findDevice, err := d.lsRepo.GetByID(c, i)
 	if err != nil {
-		// 커스텀 에러 선언 필요 
+		abortWithAPIError(c, lgr, deviceErrorStatus(err), "failed to find device", requestID, err)
 		return
 	}
 
 	// 2. 정보 반환
-	c.JSON(http.StatusCreated, findDevice)
-}
\ No newline at end of file
+	c.JSON(http.StatusOK, external.NewDeviceRes(findDevice, true))
+}
+
+// 존재하지 않는 디바이스는 404, 데이터베이스 장애는 503으로 응답한다
+func deviceErrorStatus(err error) int {
+	switch {
+	case errors2.Is(err, db.ErrDeviceNotFound):
+		return http.StatusNotFound
+	case db.IsUnavailable(err):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// CacheStats handles GET /internal/devices/cache.
+// 디바이스 조회 캐시의 크기 및 적중 현황을 반환한다. (캐시를 사용하지 않으면 404)
+func (d *DevicesHandler) CacheStats(c *gin.Context) {
+	lgr, requestID := d.logger.WithReqID(c)
+
+	if d.cache == nil {
+		abortWithAPIError(c, lgr, http.StatusNotFound, "device cache is not enabled", requestID, nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, d.cache.Stats())
+}
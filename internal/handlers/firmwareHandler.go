@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/firmware"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/model/external"
+	"go-rest-example/internal/util"
+)
+
+type FirmwareHandler struct {
+	fwRepo      db.FirmwareDataService
+	roRepo      db.RolloutsDataService
+	dsRepo      db.DevicesDataService
+	firmwareDir string
+	deltaSlot   chan struct{} // 델타 패치 생성은 메모리를 많이 사용하므로 한 번에 하나만 수행
+	logger      *logger.AppLogger
+}
+
+func NewFirmwareHandler(
+	lgr *logger.AppLogger,
+	fwRepo db.FirmwareDataService,
+	roRepo db.RolloutsDataService,
+	dsRepo db.DevicesDataService,
+	firmwareDir string,
+) (*FirmwareHandler, error) {
+	if lgr == nil || fwRepo == nil || roRepo == nil || dsRepo == nil || firmwareDir == "" {
+		return nil, errors2.New("missing required parameters to create firmware handler")
+	}
+
+	// 펌웨어 이미지 저장 경로 준비
+	if err := os.MkdirAll(firmwareDir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &FirmwareHandler{
+		fwRepo:      fwRepo,
+		roRepo:      roRepo,
+		dsRepo:      dsRepo,
+		firmwareDir: firmwareDir,
+		deltaSlot:   make(chan struct{}, 1),
+		logger:      lgr,
+	}, nil
+}
+
+// Register handles POST /firmware.
+// multipart: version, file, compatibility(반복, "제품군:리비전"), minSourceVersion(선택)
+func (f *FirmwareHandler) Register(c *gin.Context) {
+	lgr, requestID := f.logger.WithReqID(c)
+
+	// 0. 버전 형식 검사
+	version := c.PostForm("version")
+	if err := external.ValidateFirmwareVersion(version); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid firmware version", requestID, err)
+		return
+	}
+
+	// 1. 호환 하드웨어 및 최소 요구 버전 검사
+	compat, err := external.ParseFirmwareCompat(c.PostFormArray("compatibility"))
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid firmware compatibility", requestID, err)
+		return
+	}
+
+	minSourceVersion := c.PostForm("minSourceVersion")
+	if minSourceVersion != "" {
+		if err := external.ValidateFirmwareVersion(minSourceVersion); err != nil {
+			abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid minimum source version", requestID, err)
+			return
+		}
+	}
+
+	// 2. 중복 버전 확인
+	if _, err := f.fwRepo.GetByVersion(c, version); err == nil {
+		abortWithAPIError(c, lgr, http.StatusConflict, "firmware version already exists", requestID, nil)
+		return
+	}
+
+	// 3. 이미지 저장
+	file, err := c.FormFile("file")
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "firmware file is required", requestID, err)
+		return
+	}
+
+	path := filepath.Join(f.firmwareDir, version+".bin")
+	if err := c.SaveUploadedFile(file, path); err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, "failed to save firmware file", requestID, err)
+		return
+	}
+
+	// 4. 체크썸 계산 후 저장소 등록 (실패 시 저장한 이미지를 삭제하여 다시 등록할 수 있도록 한다)
+	checksum, err := util.CheckSum(path)
+	if err != nil {
+		f.removeFile(lgr, path)
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, "failed to calculate checksum", requestID, err)
+		return
+	}
+
+	fw := data.Firmware{
+		Version:          version,
+		Path:             path,
+		Checksum:         checksum,
+		Size:             file.Size,
+		MinSourceVersion: minSourceVersion,
+		Compatibility:    compat,
+		CreatedAt:        time.Now(),
+	}
+
+	if err := f.fwRepo.Create(c, &fw); err != nil {
+		f.removeFile(lgr, path)
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, "failed to register firmware", requestID, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, external.NewFirmwareRes(&fw))
+}
+
+func (f *FirmwareHandler) removeFile(lgr zerolog.Logger, path string) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		lgr.Error().Err(err).Str("path", path).Msg("failed to remove firmware file")
+	}
+}
+
+// GetAll handles GET /firmware.
+func (f *FirmwareHandler) GetAll(c *gin.Context) {
+	lgr, requestID := f.logger.WithReqID(c)
+
+	firmwares, err := f.fwRepo.GetAll(c)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, "failed to select firmwares", requestID, err)
+		return
+	}
+
+	res := make([]external.FirmwareRes, 0, len(*firmwares))
+	for _, fw := range *firmwares {
+		res = append(res, external.NewFirmwareRes(&fw))
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateDelta handles POST /firmware/deltas.
+// 두 버전 사이의 델타 패치를 생성하고, 적용 결과가 목표 이미지와 일치하는지 검증한 뒤 등록한다.
+func (f *FirmwareHandler) CreateDelta(c *gin.Context) {
+	lgr, requestID := f.logger.WithReqID(c)
+	var deltaReq external.DeltaReq
+
+	// 0. BODY -> JSON 직렬화
+	if err := c.ShouldBindBodyWithJSON(&deltaReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid delta request body", requestID, err)
+		return
+	}
+
+	// 1. 객체 유효성 검사
+	if err := deltaReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid delta request body", requestID, err)
+		return
+	}
+
+	// 2. 두 버전 모두 저장소에 등록되어 있는지 확인
+	from, err := f.fwRepo.GetByVersion(c, deltaReq.FromVersion)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusNotFound, "source firmware cannot be found", requestID, err)
+		return
+	}
+
+	to, err := f.fwRepo.GetByVersion(c, deltaReq.ToVersion)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusNotFound, "target firmware cannot be found", requestID, err)
+		return
+	}
+
+	// 3. 진행 중인 패치 생성이 끝날 때까지 대기 (요청이 취소되면 중단)
+	select {
+	case f.deltaSlot <- struct{}{}:
+		defer func() { <-f.deltaSlot }()
+	case <-c.Request.Context().Done():
+		abortWithAPIError(c, lgr, http.StatusServiceUnavailable, "delta generation was cancelled", requestID, c.Request.Context().Err())
+		return
+	}
+
+	// 4. 패치 생성 및 검증
+	path := filepath.Join(f.firmwareDir, from.Version+"_"+to.Version+".delta")
+	deltaFile, err := firmware.GenerateDelta(from.Path, to.Path, path, to.Checksum)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors2.Is(err, firmware.ErrImageTooLarge) {
+			status = http.StatusUnprocessableEntity
+		}
+		abortWithAPIError(c, lgr, status, "failed to generate delta", requestID, err)
+		return
+	}
+
+	// 5. 저장소 등록
+	delta := data.FirmwareDelta{
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Path:        deltaFile.Path,
+		Checksum:    deltaFile.Checksum,
+		Size:        deltaFile.Size,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := f.fwRepo.CreateDelta(c, &delta); err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, "failed to register delta", requestID, err)
+		return
+	}
+
+	lgr.Info().
+		Str("fromVersion", delta.FromVersion).
+		Str("toVersion", delta.ToVersion).
+		Int64("size", delta.Size).
+		Int64("fullSize", to.Size).
+		Msg("firmware delta generated")
+
+	c.JSON(http.StatusCreated, external.DeltaRes{
+		FromVersion: delta.FromVersion,
+		ToVersion:   delta.ToVersion,
+		Checksum:    delta.Checksum,
+		Size:        delta.Size,
+	})
+}
+
+// CreateCampaign handles POST /firmware/campaigns.
+func (f *FirmwareHandler) CreateCampaign(c *gin.Context) {
+	lgr, requestID := f.logger.WithReqID(c)
+	var campaignReq external.CampaignReq
+
+	// 0. BODY -> JSON 직렬화
+	if err := c.ShouldBindBodyWithJSON(&campaignReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid campaign request body", requestID, err)
+		return
+	}
+
+	// 1. 객체 유효성 검사
+	if err := campaignReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid campaign request body", requestID, err)
+		return
+	}
+
+	// 2. 목표 버전이 저장소에 등록되어 있는지 확인
+	fw, err := f.fwRepo.GetByVersion(c, campaignReq.TargetVersion)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusNotFound, "target firmware cannot be found", requestID, err)
+		return
+	}
+
+	// 3. 대상 디바이스 선정
+	// 목표 버전이 아니면서 하드웨어/현재 버전이 호환되는 디바이스만 포함한다.
+	devices, err := f.dsRepo.GetAll(c)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, "failed to select devices", requestID, err)
+		return
+	}
+
+	requested := make(map[string]bool, len(campaignReq.ProductNumbers))
+	for _, pn := range campaignReq.ProductNumbers {
+		requested[pn] = true
+	}
+
+	var targets []data.Device
+	incompatible := 0
+	for _, device := range *devices {
+		if len(requested) > 0 && !requested[device.ProductNumber] {
+			continue
+		}
+		if device.FirmwareVersion == fw.Version {
+			continue
+		}
+		if err := firmware.CheckCompatible(fw, &device); err != nil {
+			incompatible++
+			continue
+		}
+		targets = append(targets, device)
+	}
+
+	// 4. 캠페인 생성
+	campaign, count, err := f.roRepo.CreateCampaign(c, fw.Version, targets)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, "failed to create campaign", requestID, err)
+		return
+	}
+
+	lgr.Info().
+		Int64("campaignID", campaign.CampaignID).
+		Str("targetVersion", campaign.TargetVersion).
+		Int64("devices", count).
+		Int("incompatible", incompatible).
+		Msg("firmware campaign started")
+
+	c.JSON(http.StatusCreated, external.CampaignRes{
+		CampaignID:    campaign.CampaignID,
+		TargetVersion: campaign.TargetVersion,
+		Status:        campaign.Status,
+		Devices:       count,
+		Incompatible:  incompatible,
+	})
+}
+
+// Progress handles GET /firmware/campaigns/:ID/progress.
+func (f *FirmwareHandler) Progress(c *gin.Context) {
+	lgr, requestID := f.logger.WithReqID(c)
+
+	campaignID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid campaign ID", requestID, err)
+		return
+	}
+
+	campaign, err := f.roRepo.GetCampaign(c, campaignID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors2.Is(err, db.ErrCampaignNotFound) {
+			status = http.StatusNotFound
+		}
+		abortWithAPIError(c, lgr, status, "failed to find campaign", requestID, err)
+		return
+	}
+
+	counts, err := f.roRepo.GetProgress(c, campaignID)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, "failed to select campaign progress", requestID, err)
+		return
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+
+	c.JSON(http.StatusOK, external.CampaignProgress{
+		CampaignID:    campaign.CampaignID,
+		TargetVersion: campaign.TargetVersion,
+		Status:        campaign.Status,
+		Total:         total,
+		Counts:        counts,
+	})
+}
+
+// Rollback handles POST /firmware/campaigns/:ID/rollback.
+func (f *FirmwareHandler) Rollback(c *gin.Context) {
+	lgr, requestID := f.logger.WithReqID(c)
+
+	campaignID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid campaign ID", requestID, err)
+		return
+	}
+
+	rollback, devices, err := f.roRepo.Rollback(c, campaignID)
+	if err != nil {
+		status, message := http.StatusInternalServerError, "failed to rollback campaign"
+		switch {
+		case errors2.Is(err, db.ErrCampaignNotFound):
+			status = http.StatusNotFound
+		case errors2.Is(err, db.ErrCampaignNotActive):
+			status = http.StatusConflict
+		case errors2.Is(err, db.ErrRollbackVersionMissing):
+			// 이전 버전 이미지를 다시 등록한 뒤 롤백할 수 있다 (원본 캠페인은 진행 중으로 남는다)
+			status, message = http.StatusConflict, "previous firmware version is not registered"
+		}
+		abortWithAPIError(c, lgr, status, message, requestID, err)
+		return
+	}
+
+	lgr.Info().
+		Int64("campaignID", campaignID).
+		Int64("rollbackCampaignID", rollback.CampaignID).
+		Int64("devices", devices).
+		Msg("firmware campaign rolled back")
+
+	c.JSON(http.StatusCreated, external.CampaignRes{
+		CampaignID: rollback.CampaignID,
+		RollbackOf: rollback.RollbackOf,
+		Status:     rollback.Status,
+		Devices:    devices,
+	})
+}
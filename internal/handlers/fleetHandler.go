@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/external"
+)
+
+type FleetHandler struct {
+	flRepo db.FleetDataService
+	logger *logger.AppLogger
+}
+
+func NewFleetHandler(lgr *logger.AppLogger, flRepo db.FleetDataService) (*FleetHandler, error) {
+	if lgr == nil || flRepo == nil {
+		return nil, errors2.New("missing required parameters to create fleet handler")
+	}
+
+	return &FleetHandler{flRepo: flRepo, logger: lgr}, nil
+}
+
+// Overview handles GET /internal/fleet/overview.
+// 상태/펌웨어 버전별 디바이스 수, 오프라인, 배터리 부족, 에러 디바이스 수와 최근 1시간 분당 보고 수를 반환한다.
+// 집계 결과는 짧게 보관되므로 최대 보관 시간만큼 지난 값일 수 있다. (generatedAt 참고)
+func (f *FleetHandler) Overview(c *gin.Context) {
+	lgr, requestID := f.logger.WithReqID(c)
+
+	overview, err := f.flRepo.Overview(c, time.Now())
+	if err != nil {
+		status := http.StatusInternalServerError
+		if db.IsUnavailable(err) {
+			status = http.StatusServiceUnavailable
+		}
+		abortWithAPIError(c, lgr, status, "failed to select fleet overview", requestID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, external.NewFleetOverviewRes(overview))
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/outbox"
+)
+
+type OutboxHandler struct {
+	relay  *outbox.Relay
+	logger *logger.AppLogger
+}
+
+func NewOutboxHandler(lgr *logger.AppLogger, relay *outbox.Relay) (*OutboxHandler, error) {
+	if lgr == nil || relay == nil {
+		return nil, errors2.New("missing required parameters to create outbox handler")
+	}
+
+	return &OutboxHandler{relay: relay, logger: lgr}, nil
+}
+
+// RelayStats handles GET /internal/outbox/relay.
+// 이 인스턴스의 전달 임대 여부, 전달을 기다리는 이벤트 수와 전달/실패 현황을 반환한다.
+func (o *OutboxHandler) RelayStats(c *gin.Context) {
+	c.JSON(http.StatusOK, o.relay.Stats())
+}
@@ -2,34 +2,57 @@ package handlers
 
 import (
 	errors2 "errors"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 
 	"go-rest-example/internal/db"
+	"go-rest-example/internal/firmware"
+	"go-rest-example/internal/ingest"
 	"go-rest-example/internal/logger"
 	"go-rest-example/internal/model/data"
 	"go-rest-example/internal/model/external"
+	"go-rest-example/internal/retention"
 	"go-rest-example/internal/util"
 )
 
 type ReportsHandler struct {
-	rsRepo db.ReportsDataService
+	writer   *ingest.BatchWriter
+	buffer   *ingest.Buffer
+	retention *retention.Job
 	dsRepo db.DevicesDataService
+	roRepo db.RolloutsDataService
+	fwRepo db.FirmwareDataService
+	verifier *firmware.Verifier // 전송할 이미지/패치 체크썸 검증 결과 (파일마다 한 번만 전체 검증)
 	logger *logger.AppLogger
 }
 
 // 오류 코드와 메서드 타입 사용하여 동작의 의미를 명확히 할 것 
-func NewReportsHandler(lgr *logger.AppLogger, rsRepo db.ReportsDataService, dsRepo db.DevicesDataService) (*ReportsHandler, error) {
-	if lgr == nil || rsRepo == nil || dsRepo == nil {
+func NewReportsHandler(
+	lgr *logger.AppLogger,
+	writer *ingest.BatchWriter,
+	buffer *ingest.Buffer,
+	retention *retention.Job,
+	dsRepo db.DevicesDataService,
+	roRepo db.RolloutsDataService,
+	fwRepo db.FirmwareDataService,
+) (*ReportsHandler, error) {
+	if lgr == nil || writer == nil || buffer == nil || retention == nil || dsRepo == nil || roRepo == nil || fwRepo == nil {
 		return nil, errors2.New("missing required parameters to create reports handler")
 	}
 
 	return &ReportsHandler{
-		rsRepo: rsRepo, 
+		writer:   writer,
+		buffer:   buffer,
+		retention: retention,
 		dsRepo: dsRepo,
+		roRepo: roRepo,
+		fwRepo: fwRepo,
+		verifier: firmware.NewVerifier(),
 		logger: lgr,
 	}, nil
 }
@@ -41,25 +64,36 @@ func(d *ReportsHandler) Report(c *gin.Context){
 
 	// 0. BODY -> JSON 직렬화 
 	if err := c.ShouldBindBodyWithJSON(&reportReq); err != nil {
-		d.abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid report request body", requestID, err)
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid report request body", requestID, err)
 		return
 	}
 
 	// 1. 객체 유효성 검사 
 	err := reportReq.Validate()
 	if err != nil {
-		d.abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid report request body", requestID, err)
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid report request body", requestID, err)
 		return 
 	}
 
 	// 2. 디바이스 존재 여부 검증 : 선언 필요
 	findDevice, err := d.dsRepo.GetByID(c, reportReq.ProductNumber)
 	if err != nil {
+		// 데이터베이스 장애 시 보고를 버퍼에 보관 
+		if db.IsUnavailable(err) {
+			d.bufferReport(c, lgr, requestID, &reportReq)
+			return
+		}
 		// 404 에러 반환 
-		d.abortWithAPIError(c, lgr, http.StatusNotFound, "faild to find product", requestID, err)
+		abortWithAPIError(c, lgr, http.StatusNotFound, "faild to find product", requestID, err)
 		return 
 	}
 
+	// 버퍼에 재전송되지 않은 보고가 남아있으면 보고 순서를 지키기 위해 이어서 버퍼에 기록
+	if d.buffer.Pending() {
+		d.bufferReport(c, lgr, requestID, &reportReq)
+		return
+	}
+
 	// 3. 정보 업데이트 객체 준비 
 	report := data.DeviceInfo{	
 		ReportID           : 1,
@@ -74,10 +108,16 @@ func(d *ReportsHandler) Report(c *gin.Context){
 		ReportedStatus     : reportReq. ReportedStatus,
 	}
 
-	// 4. repo 호출을 통한 업데이트 진행 
-	_, err = d.rsRepo.Create(c, &report)
+	// 4. 보고 저장과 디바이스 갱신은 배치 기록기에서 처리 
+	// 대기열이 가득 차면 디바이스가 잠시 후 다시 보고하도록 429 반환 
+	err = d.writer.Enqueue(&report, reportReq.FirmwareVersion)
+	if errors2.Is(err, ingest.ErrQueueFull) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(d.writer.RetryAfter().Seconds()))))
+		abortWithAPIError(c, lgr, http.StatusTooManyRequests, "report queue is full, retry later", requestID, err)
+		return
+	}
 	if err != nil {
-		d.abortWithAPIError(c, lgr, http.StatusBadRequest, "faild to Create report", requestID, err)
+		abortWithAPIError(c, lgr, http.StatusServiceUnavailable, "faild to Create report", requestID, err)
 		return 
 	}
 
@@ -93,15 +133,70 @@ func(d *ReportsHandler) Report(c *gin.Context){
 		reboot = 1
 	}
 
+	// 펌웨어 배포 진행 상태 반영 및 업데이트 안내 
+	updateVersion := d.trackRollout(c, lgr, findDevice, &reportReq)
+
 	// 주기 보고 시간 할당 
 	reportRes := external.DeviceUpdate{
-		ReportCycleSec : 100,
+		ReportCycleSec : int(data.ReportCycle / time.Second),
 		PowerOff       : power,
 		Reboot         : reboot,
+		UpdateVersion  : updateVersion,
 	} 
 
-	// 5. 응답 진행
-	c.JSON(http.StatusCreated, reportRes)
+	// 5. 응답 진행 (저장은 비동기로 진행되므로 202 응답)
+	c.JSON(http.StatusAccepted, reportRes)
+}
+
+// 데이터베이스에 저장할 수 없는 보고를 버퍼(WAL)에 기록하고 디바이스에 응답한다.
+// 디바이스 상태를 읽을 수 없으므로 전원 차단 및 업데이트 안내 없이, 오류 보고에 대한 재부팅만 안내한다.
+func (d *ReportsHandler) bufferReport(c *gin.Context, lgr zerolog.Logger, requestID string, reportReq *external.ReportReq) {
+	report := data.DeviceInfo{
+		ProductNumber      : reportReq.ProductNumber,
+		BatteryPercent     : reportReq.BatteryPercent,
+		Lat                : reportReq.Lat,
+		Lon                : reportReq.Lon,
+		TemperatureCelsius : reportReq.TemperatureCelsius,
+		IP                 : reportReq.IP,
+		ErrorCode          : reportReq.ErrorCode,
+		ReportAt           : time.Now(),
+		ReportedStatus     : reportReq.ReportedStatus,
+	}
+
+	if err := d.buffer.Add(&report, reportReq.FirmwareVersion); err != nil {
+		abortWithAPIError(c, lgr, http.StatusServiceUnavailable, "failed to buffer report", requestID, err)
+		return
+	}
+	lgr.Info().Str("productNumber", report.ProductNumber).Msg("report buffered for replay")
+
+	reboot := 0
+	if reportReq.ErrorCode != 0 {
+		reboot = 1
+	}
+
+	// 저장이 완료되지 않았으므로 202 응답
+	c.JSON(http.StatusAccepted, external.DeviceUpdate{
+		ReportCycleSec : int(data.ReportCycle / time.Second),
+		Reboot         : reboot,
+	})
+}
+
+// Backlog handles GET /internal/reports/backlog.
+// 장애 버퍼(WAL)에 남아있는 보고 수, 크기 및 재전송 현황을 반환한다.
+func (d *ReportsHandler) Backlog(c *gin.Context) {
+	c.JSON(http.StatusOK, d.buffer.Stats())
+}
+
+// WriterStats handles GET /internal/reports/writer.
+// 배치 기록 대기열 크기 및 기록 현황을 반환한다.
+func (d *ReportsHandler) WriterStats(c *gin.Context) {
+	c.JSON(http.StatusOK, d.writer.Stats())
+}
+
+// RetentionStats handles GET /internal/reports/retention.
+// 보고 보존 기간 요약/정리 작업의 실행 현황을 반환한다.
+func (d *ReportsHandler) RetentionStats(c *gin.Context) {
+	c.JSON(http.StatusOK, d.retention.Stats())
 }
 
 // Select handles GET /report/update
@@ -115,59 +210,152 @@ func(d *ReportsHandler) Update(c *gin.Context){
 	findDevice, err := d.dsRepo.GetByID(c,i)
 	if err != nil {
 		// 500 오류 코드 반환 
-		d.abortWithAPIError(c, lgr, http.StatusBadRequest, "faild to find Prucut",requestID, err)
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "faild to find Prucut",requestID, err)
 		return
 	}
 
 	// 1. UpdateCheck가 허용이면서 FirmwareVersion 버전이 최신이 아닌경우 
 	if findDevice.FirmwareVersion != "" && findDevice.UpdateCheck != 0 {
 		// 404 오류 코드 반환 
-		d.abortWithAPIError(c, lgr, http.StatusNotFound, "update has not been approved",requestID, err)
+		abortWithAPIError(c, lgr, http.StatusNotFound, "update has not been approved",requestID, err)
 		return 
 	}
 
-	// 2. 펌웨어 정보 획득
-	path := "test"
+	// 2. 배포 중인 펌웨어 정보 획득
+	rollout, err := d.roRepo.GetActiveByDevice(c, findDevice.ProductNumber)
+	if err != nil || rollout.State == data.RolloutInstalled || rollout.State == data.RolloutFailed {
+		// 404 오류 코드 반환 
+		abortWithAPIError(c, lgr, http.StatusNotFound, "no firmware update offered",requestID, err)
+		return 
+	}
 
-	// 3. 유틸 메서드 경로 유효성 검사
+	fw, err := d.fwRepo.GetByVersion(c, rollout.TargetVersion)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusNotFound, "firmware cannot be found",requestID, err)
+		return 
+	}
+
+	// 3. 하드웨어 호환성 및 최소 요구 버전 확인 
+	// 호환되지 않는 이미지는 절대 전송하지 않으며, 배포 상태를 실패로 기록하여 다시 안내하지 않는다.
+	if err := firmware.CheckCompatible(fw, findDevice); err != nil {
+		if stateErr := d.roRepo.UpdateState(c, rollout.CampaignID, rollout.ProductNumber, data.RolloutFailed); stateErr != nil {
+			lgr.Error().Err(stateErr).Str("productNumber", rollout.ProductNumber).Msg("failed to record incompatible firmware")
+		}
+		abortWithAPIError(c, lgr, http.StatusConflict, "firmware is not compatible with device",requestID, err)
+		return 
+	}
+
+	// 4. 유틸 메서드 경로 유효성 검사
 	// 내부에서 파일 존재 여부도 검사 
-	err = util.PathValid(path)
+	err = util.PathValid(fw.Path)
 	if err != nil {
 		// 404 오류 코드 반환 
-		d.abortWithAPIError(c, lgr, http.StatusNotFound, "file cannot be found",requestID, err)
+		abortWithAPIError(c, lgr, http.StatusNotFound, "file cannot be found",requestID, err)
+		return 
+	}
+
+	// 5. 다운로드 시작 기록 
+	if rollout.State != data.RolloutDownloading {
+		if err := d.roRepo.UpdateState(c, rollout.CampaignID, rollout.ProductNumber, data.RolloutDownloading); err != nil {
+			lgr.Error().Err(err).Str("productNumber", rollout.ProductNumber).Msg("failed to record firmware download")
+		}
+	}
+
+	// 6. 체크썸 전달 
+	// 등록 시 계산된 sha256 값을 헤더로 전달하여 디바이스가 검증하도록 한다.
+	c.Header("X-Firmware-Version", fw.Version)
+	c.Header("X-Firmware-Checksum", fw.Checksum)
+
+	// 7. 델타 패치 우선 전송 
+	// 디바이스가 전체 이미지를 요청(full=true)하지 않았고 현재 버전 기준 패치가 있으면 패치를 전송한다.
+	if c.Query("full") != "true" {
+		if delta := d.findDelta(c, lgr, findDevice.FirmwareVersion, fw.Version); delta != nil {
+			c.Header("X-Firmware-Delta-From", delta.FromVersion)
+			c.Header("X-Firmware-Delta-Checksum", delta.Checksum)
+			c.File(delta.Path)
+			return
+		}
+	}
+
+	// 8. 체크썸 및 파일 정보 전송 
+	// 저장된 이미지가 등록 당시와 다르면 손상된 이미지를 보내지 않는다.
+	if err := d.verifier.Verify(fw.Path, fw.Checksum); err != nil {
+		abortWithAPIError(c, lgr, http.StatusInternalServerError, "firmware image failed verification",requestID, err)
 		return 
 	}
+	c.File(fw.Path)
+}
+
+// 현재 버전에서 목표 버전으로 가는 델타 패치를 찾는다.
+// 패치가 없거나 저장된 파일이 손상된 경우 nil을 반환하여 전체 이미지로 대체한다.
+func (d *ReportsHandler) findDelta(c *gin.Context, lgr zerolog.Logger, fromVersion, toVersion string) *data.FirmwareDelta {
+	if fromVersion == "" {
+		return nil
+	}
+
+	delta, err := d.fwRepo.GetDelta(c, fromVersion, toVersion)
+	if err != nil {
+		if !errors2.Is(err, db.ErrDeltaNotFound) {
+			lgr.Error().Err(err).Msg("failed to find firmware delta")
+		}
+		return nil
+	}
 
-	// 5. 체크썸 계산 (옵션)
-	// 내부에서 파일을 읽어 체크썸 생성 - 추가 보안 필요시 사용할 것 
-	// https://stackoverflow.com/questions/15879136/how-to-calculate-sha256-file-checksum-in-go
-	// util.CheckSum(path)
+	if err := d.verifier.Verify(delta.Path, delta.Checksum); err != nil {
+		lgr.Error().Err(err).
+			Str("fromVersion", fromVersion).
+			Str("toVersion", toVersion).
+			Msg("firmware delta failed verification, falling back to full image")
+		return nil
+	}
 
-	// 6. 체크썸 및 파일 정보 전송 
-	// golang gin file fileattach 차이 인지 필요 스트리밍으로 구현할 것인가? 
-	c.File(path)
+	return delta
 }
 
-// 에러 발생 시 응답 생성 역할 수행 
-// 공용 에러 핸들러로 전환 필요 
-func(d *ReportsHandler) abortWithAPIError(
-	c *gin.Context,
-	lgr zerolog.Logger,
-	status int,
-	message, debugID string,
-	err error,
-){
-	apiErr := &external.APIError{
-		HTTPStatusCode : status,
-		Message : message,
-		DebugID: debugID,
-	}
-
-	event := lgr.Error().Int("HttpStatusCode",status)
+// 보고된 펌웨어 버전을 바탕으로 배포 상태를 갱신하고, 설치해야 할 버전을 반환한다.
+// 배포 대상이 아니거나 더 이상 안내가 필요 없으면 빈 문자열을 반환한다.
+// 주기 보고마다 배포 정보를 조회하지 않도록 보고된 버전이 저장된 버전과 다를 때만 조회하며,
+// 그 외의 디바이스는 업데이트 확인(/report/update) 시 배포 정보를 받는다.
+// 배포 추적 실패가 주기 보고 자체를 실패시키지 않도록 오류는 로그로만 남긴다.
+func (d *ReportsHandler) trackRollout(c *gin.Context, lgr zerolog.Logger, device *data.Device, reportReq *external.ReportReq) string {
+	reported := reportReq.FirmwareVersion
+
+	// 디바이스 펌웨어 버전 동기화는 보고 저장 트랜잭션에서 처리된다 
+	// 0. 펌웨어 버전이 바뀌지 않았으면 배포 상태도 바뀌지 않는다 
+	if reported == "" || reported == device.FirmwareVersion {
+		return ""
+	}
+
+	// 1. 진행 중인 배포 확인 
+	rollout, err := d.roRepo.GetActiveByDevice(c, device.ProductNumber)
 	if err != nil {
-		event.Err(err)
+		if !errors2.Is(err, db.ErrRolloutNotFound) {
+			lgr.Error().Err(err).Str("productNumber", device.ProductNumber).Msg("failed to find firmware rollout")
+		}
+		return ""
+	}
+
+	// 2. 상태 전이 결정 
+	next := rollout.State
+	switch {
+	case reported != "" && reported == rollout.TargetVersion:
+		next = data.RolloutInstalled
+	case rollout.State == data.RolloutDownloading && reportReq.ReportedStatus == data.ReportError:
+		next = data.RolloutFailed
+	case rollout.State == data.RolloutEligible:
+		next = data.RolloutOffered
+	}
+
+	if next != rollout.State {
+		if err := d.roRepo.UpdateState(c, rollout.CampaignID, rollout.ProductNumber, next); err != nil {
+			lgr.Error().Err(err).Str("productNumber", device.ProductNumber).Msg("failed to update firmware rollout")
+		}
+	}
+
+	// 3. 설치 완료 혹은 실패한 디바이스에는 더 이상 안내하지 않는다 
+	if next == data.RolloutInstalled || next == data.RolloutFailed {
+		return ""
 	}
 
-	event.Msg(message)
-	c.AbortWithStatusJSON(status, apiErr)
-}
\ No newline at end of file
+	return rollout.TargetVersion
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/ingest"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/model/external"
+	"go-rest-example/internal/wal"
+)
+
+// fakeRolloutsRepo는 배포 조회 횟수와 상태 변경을 기록하는 RolloutsDataService입니다.
+type fakeRolloutsRepo struct {
+	db.RolloutsDataService
+	rollout data.FirmwareRollout
+	lookups int
+}
+
+func (f *fakeRolloutsRepo) GetActiveByDevice(context.Context, string) (*data.FirmwareRollout, error) {
+	f.lookups++
+	rollout := f.rollout
+	return &rollout, nil
+}
+
+func (f *fakeRolloutsRepo) UpdateState(_ context.Context, _ int64, _ string, state data.RolloutState) error {
+	f.rollout.State = state
+	return nil
+}
+
+func TestTrackRollout(t *testing.T) {
+	roRepo := &fakeRolloutsRepo{rollout: data.FirmwareRollout{
+		CampaignID:    1,
+		ProductNumber: "ABC010001",
+		FromVersion:   "1.0.0",
+		TargetVersion: "2.0.0",
+		State:         data.RolloutDownloading,
+	}}
+	handler := &ReportsHandler{roRepo: roRepo}
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	device := &data.Device{ProductNumber: "ABC010001", FirmwareVersion: "1.0.0"}
+
+	// 펌웨어 버전이 바뀌지 않은 주기 보고는 배포 정보를 조회하지 않는다
+	for _, version := range []string{"", "1.0.0"} {
+		report := &external.ReportReq{ProductNumber: "ABC010001", FirmwareVersion: version, ReportedStatus: data.ReportPowerOn}
+		if got := handler.trackRollout(c, zerolog.Nop(), device, report); got != "" {
+			t.Errorf("trackRollout(%q) = %q, want empty", version, got)
+		}
+	}
+	if roRepo.lookups != 0 {
+		t.Errorf("rollout lookups = %d for unchanged firmware, want 0", roRepo.lookups)
+	}
+
+	// 목표 버전으로 바뀐 보고는 설치 완료로 기록한다
+	report := &external.ReportReq{ProductNumber: "ABC010001", FirmwareVersion: "2.0.0", ReportedStatus: data.ReportPowerOn}
+	if got := handler.trackRollout(c, zerolog.Nop(), device, report); got != "" {
+		t.Errorf("trackRollout after install = %q, want empty", got)
+	}
+	if roRepo.lookups != 1 || roRepo.rollout.State != data.RolloutInstalled {
+		t.Errorf("lookups = %d, state = %s, want 1 lookup and installed", roRepo.lookups, roRepo.rollout.State)
+	}
+}
+
+// fakeDevicesRepo는 등록된 디바이스만 조회되는 DevicesDataService입니다.
+type fakeDevicesRepo struct {
+	db.DevicesDataService
+	devices map[string]data.Device
+}
+
+func (f *fakeDevicesRepo) GetByID(_ context.Context, productNumber string) (*data.Device, error) {
+	device, ok := f.devices[productNumber]
+	if !ok {
+		return nil, fmt.Errorf("%w: %w", db.ErrFailedToSelectDevice, db.ErrDeviceNotFound)
+	}
+	return &device, nil
+}
+
+func TestReportWhileBufferPending(t *testing.T) {
+	lgr := logger.Setup("error", "test")
+	dsRepo := &fakeDevicesRepo{devices: map[string]data.Device{
+		"ABC010001": {ProductNumber: "ABC010001", FirmwareVersion: "1.0.0", Status: data.StatusReady},
+	}}
+
+	// 재전송하지 않으므로 Recorder의 저장소는 호출되지 않는다
+	recorder, err := ingest.NewRecorder(lgr, struct{ db.TxManager }{}, struct{ db.ReportsDataService }{}, dsRepo, struct{ db.LatestStateDataService }{}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log, err := wal.Open(t.TempDir(), 0, lgr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { log.Close() })
+	buffer, err := ingest.NewBuffer(lgr, log, recorder, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := log.Append([]byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	handler := &ReportsHandler{buffer: buffer, dsRepo: dsRepo, logger: lgr}
+
+	report := func(productNumber string) int {
+		body := `{"productNumber":"` + productNumber + `","batteryPercent":80,"lat":33.5,"lon":127.0,"temperatureCelsius":21.5,"ip":"10.0.0.1","reportedStatus":"PowerOn"}`
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/report", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler.Report(c)
+		return w.Code
+	}
+
+	// 버퍼링 중에도 등록되지 않은 디바이스의 보고는 버퍼에 넣지 않고 404
+	if code := report("ABC019999"); code != http.StatusNotFound {
+		t.Errorf("unknown device: status = %d, want 404", code)
+	}
+	if backlog := log.Backlog(); backlog != 1 {
+		t.Errorf("backlog after unknown device = %d, want 1", backlog)
+	}
+
+	// 등록된 디바이스의 보고는 순서를 지키기 위해 버퍼에 이어서 기록
+	if code := report("ABC010001"); code != http.StatusAccepted {
+		t.Errorf("known device: status = %d, want 202", code)
+	}
+	if backlog := log.Backlog(); backlog != 2 {
+		t.Errorf("backlog after known device = %d, want 2", backlog)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"errors"
 	"go-rest-example/internal/db"
 	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/external"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,33 +22,55 @@ var (
 
 
 type StatusHandler struct {
-	dbMgr db.DBManager
-	lgr   *logger.AppLogger
+	dbMgr   db.DBManager
+	breaker *db.CircuitBreaker
+	lgr     *logger.AppLogger
 }
 
-func NewStatusHandler(l *logger.AppLogger, d db.DBManager) (*StatusHandler, error) {
+func NewStatusHandler(l *logger.AppLogger, d db.DBManager, cb *db.CircuitBreaker) (*StatusHandler, error) {
 
-	if l == nil || d == nil {
+	if l == nil || d == nil || cb == nil {
 		return nil, errors.New("missing required inputs to create status handler")
 	}
 	return &StatusHandler{
 		dbMgr: d,
+		breaker: cb,
 		lgr: l,
 	}, nil
 }
 
 func (s *StatusHandler) CheckStatus(c *gin.Context) {
+		res := external.HealthRes{
+			Database: "up",
+			Breaker:  string(s.breaker.State()),
+		}
+
+		// 브레이커가 열려 있으면 데이터베이스 응답을 기다리지 않는다
+		if retryAfter := s.breaker.RetryAfter(); retryAfter > 0 {
+			res.Database = "down"
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.JSON(http.StatusServiceUnavailable, &res)
+			return
+		}
+
 		var code int
 
 		if err := s.dbMgr.Ping(); err == nil {
 			// 성공 시 2** 코드 
-			code = http.StatusNoContent
+			code = http.StatusOK
 		} else {
 			// 오류 발생시 5** 코드
 			s.lgr.Error().Msg("failed to ping DB")
+			res.Database = "down"
 			code = http.StatusFailedDependency
 		}
 
 		// 상태 반환 
-		c.JSON(code, nil)
-}
\ No newline at end of file
+		c.JSON(code, &res)
+}
+
+// DBStats handles GET /internal/db/stats.
+// primary 및 replica 커넥션 풀의 현재 상태(sql.DBStats)를 반환한다.
+func (s *StatusHandler) DBStats(c *gin.Context) {
+	c.JSON(http.StatusOK, s.dbMgr.Stats())
+}
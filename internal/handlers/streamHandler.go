@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/external"
+	"go-rest-example/internal/stream"
+)
+
+// 스트림 연결 유지 기본값
+const defaultStreamHeartbeat = 15 * time.Second
+
+// 스트림 이벤트 이름 (데이터 이벤트는 이벤트 종류를 이름으로 사용)
+const (
+	streamEventPing  = "ping"  // 연결 유지 확인
+	streamEventClose = "close" // 서버가 스트림을 끊음 (느린 수신, 서버 종료)
+)
+
+type StreamHandler struct {
+	hub       *stream.Hub
+	dsRepo    db.DevicesDataService
+	heartbeat time.Duration
+	logger    *logger.AppLogger
+}
+
+// heartbeat는 이벤트가 없을 때 연결 유지 확인(ping)을 보내는 주기입니다. (기본값 15초)
+func NewStreamHandler(lgr *logger.AppLogger, hub *stream.Hub, dsRepo db.DevicesDataService, heartbeat time.Duration) (*StreamHandler, error) {
+	if lgr == nil || hub == nil || dsRepo == nil {
+		return nil, errors2.New("missing required parameters to create stream handler")
+	}
+	if heartbeat <= 0 {
+		heartbeat = defaultStreamHeartbeat
+	}
+
+	return &StreamHandler{hub: hub, dsRepo: dsRepo, heartbeat: heartbeat, logger: lgr}, nil
+}
+
+// Device handles GET /device/:ID/stream?types=.
+// 디바이스에 새로 저장된 보고와 상태 변경, 펌웨어 설치를 Server-Sent Events로 전달한다.
+func (s *StreamHandler) Device(c *gin.Context) {
+	lgr, requestID := s.logger.WithReqID(c)
+
+	var streamReq external.StreamReq
+	if err := c.ShouldBindQuery(&streamReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid stream query", requestID, err)
+		return
+	}
+	if err := streamReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid stream query", requestID, err)
+		return
+	}
+
+	device, err := s.dsRepo.GetByID(c, c.Param("ID"))
+	if err != nil {
+		abortWithAPIError(c, lgr, deviceErrorStatus(err), "failed to find device", requestID, err)
+		return
+	}
+
+	s.serve(c, lgr, requestID, stream.Filter{
+		ProductNumbers: []string{device.ProductNumber},
+		EventTypes:     streamReq.EventTypes(),
+	})
+}
+
+// Fleet handles GET /device/stream?types=&productNumbers=&productLine=.
+// 조건에 해당하는 디바이스에 새로 저장된 보고와 상태 변경, 펌웨어 설치를 Server-Sent Events로 전달한다.
+func (s *StreamHandler) Fleet(c *gin.Context) {
+	lgr, requestID := s.logger.WithReqID(c)
+
+	var streamReq external.StreamReq
+	if err := c.ShouldBindQuery(&streamReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid stream query", requestID, err)
+		return
+	}
+	if err := streamReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid stream query", requestID, err)
+		return
+	}
+
+	s.serve(c, lgr, requestID, stream.Filter{
+		ProductNumbers: streamReq.ProductNumberList(),
+		ProductLine:    streamReq.ProductLine,
+		EventTypes:     streamReq.EventTypes(),
+	})
+}
+
+// HubStats handles GET /internal/stream/hub.
+// 실시간 스트림 구독 수와 전달/끊김 현황을 반환한다.
+func (s *StreamHandler) HubStats(c *gin.Context) {
+	c.JSON(http.StatusOK, s.hub.Stats())
+}
+
+// 구독한 이벤트를 클라이언트가 연결을 끊거나 구독이 끊길 때까지 전달한다.
+// 이벤트는 id(EventID), event(이벤트 종류), data(웹훅과 같은 이벤트 JSON)로 보내며,
+// 수신이 느려 구독이 끊기면 close 이벤트를 보내고 응답을 마친다. (클라이언트는 다시 연결해야 함)
+func (s *StreamHandler) serve(c *gin.Context, lgr zerolog.Logger, requestID string, filter stream.Filter) {
+	// 1. 구독
+	sub, err := s.hub.Subscribe(filter)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusServiceUnavailable, "failed to subscribe stream", requestID, err)
+		return
+	}
+	defer sub.Close()
+
+	// 2. 스트림 응답 시작 (프록시가 응답을 모아 보내지 않도록 지정)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	s.send(c, sse.Event{Event: streamEventPing, Data: time.Now()})
+
+	ticker := time.NewTicker(s.heartbeat)
+	defer ticker.Stop()
+
+	// 3. 이벤트 전달
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-sub.Done():
+			reason := "closed"
+			if err := sub.Err(); err != nil {
+				reason = err.Error()
+			}
+			lgr.Info().Str("reason", reason).Msg("stream closed by server")
+			s.send(c, sse.Event{Event: streamEventClose, Data: external.StreamCloseRes{Reason: reason}})
+			return
+		case event := <-sub.Events():
+			s.send(c, sse.Event{Id: event.EventID, Event: string(event.Type), Data: external.NewEventRes(&event)})
+		case now := <-ticker.C:
+			s.send(c, sse.Event{Event: streamEventPing, Data: now})
+		}
+	}
+}
+
+func (s *StreamHandler) send(c *gin.Context, event sse.Event) {
+	c.Render(-1, event)
+	c.Writer.Flush()
+}
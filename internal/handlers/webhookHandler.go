@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	errors2 "errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/external"
+	"go-rest-example/internal/webhook"
+)
+
+type WebhookHandler struct {
+	dispatcher *webhook.Dispatcher
+	whRepo     db.WebhooksDataService
+	logger     *logger.AppLogger
+}
+
+func NewWebhookHandler(lgr *logger.AppLogger, dispatcher *webhook.Dispatcher, whRepo db.WebhooksDataService) (*WebhookHandler, error) {
+	if lgr == nil || dispatcher == nil || whRepo == nil {
+		return nil, errors2.New("missing required parameters to create webhook handler")
+	}
+
+	return &WebhookHandler{
+		dispatcher: dispatcher,
+		whRepo:     whRepo,
+		logger:     lgr,
+	}, nil
+}
+
+// Create handles POST /webhooks.
+// 서명 키를 지정하지 않으면 서버가 만들며, 서명 키는 이 응답에서만 반환한다.
+func (w *WebhookHandler) Create(c *gin.Context) {
+	lgr, requestID := w.logger.WithReqID(c)
+
+	// 0. 요청 데이터 획득 및 유효성 검사
+	var webhookReq external.WebhookReq
+	if err := c.ShouldBindBodyWithJSON(&webhookReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid webhook data", requestID, err)
+		return
+	}
+	if err := webhookReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid webhook data", requestID, err)
+		return
+	}
+	if err := w.dispatcher.CheckTarget(c, webhookReq.URL); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "webhook target is not allowed", requestID, err)
+		return
+	}
+
+	// 1. 서명 키 결정
+	sub := webhookReq.Subscription(0)
+	if sub.Secret == "" {
+		secret, err := webhook.NewSecret()
+		if err != nil {
+			abortWithAPIError(c, lgr, http.StatusInternalServerError, "failed to create webhook secret", requestID, err)
+			return
+		}
+		sub.Secret = secret
+	}
+
+	// 2. 구독 등록
+	now := time.Now()
+	sub.CreatedAt, sub.UpdatedAt = now, now
+	subscriptionID, err := w.whRepo.CreateSubscription(c, &sub)
+	if err != nil {
+		abortWithAPIError(c, lgr, webhookErrorStatus(err), "failed to create webhook", requestID, err)
+		return
+	}
+	sub.SubscriptionID = subscriptionID
+
+	lgr.Info().Int64("subscriptionID", subscriptionID).Str("url", sub.URL).Msg("webhook created")
+	c.JSON(http.StatusCreated, external.NewWebhookRes(&sub, true))
+}
+
+// GetAll handles GET /webhooks.
+func (w *WebhookHandler) GetAll(c *gin.Context) {
+	lgr, requestID := w.logger.WithReqID(c)
+
+	subs, err := w.whRepo.ListSubscriptions(c)
+	if err != nil {
+		abortWithAPIError(c, lgr, webhookErrorStatus(err), "failed to select webhooks", requestID, err)
+		return
+	}
+
+	res := make([]external.WebhookRes, len(subs))
+	for i := range subs {
+		res[i] = external.NewWebhookRes(&subs[i], false)
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// GetByID handles GET /webhooks/:ID.
+func (w *WebhookHandler) GetByID(c *gin.Context) {
+	lgr, requestID := w.logger.WithReqID(c)
+
+	subscriptionID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid webhook ID", requestID, err)
+		return
+	}
+
+	sub, err := w.whRepo.GetSubscription(c, subscriptionID)
+	if err != nil {
+		abortWithAPIError(c, lgr, webhookErrorStatus(err), "failed to find webhook", requestID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, external.NewWebhookRes(sub, false))
+}
+
+// Update handles PUT /webhooks/:ID.
+// 서명 키를 지정하지 않으면 기존 서명 키를 유지한다. 비활성화된 구독의 전송은 다시 활성화될 때까지 대기한다.
+func (w *WebhookHandler) Update(c *gin.Context) {
+	lgr, requestID := w.logger.WithReqID(c)
+
+	subscriptionID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid webhook ID", requestID, err)
+		return
+	}
+
+	var webhookReq external.WebhookReq
+	if err := c.ShouldBindBodyWithJSON(&webhookReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid webhook data", requestID, err)
+		return
+	}
+	if err := webhookReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid webhook data", requestID, err)
+		return
+	}
+	if err := w.dispatcher.CheckTarget(c, webhookReq.URL); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "webhook target is not allowed", requestID, err)
+		return
+	}
+
+	// 1. 기존 구독 조회 (생성 시간, 서명 키 유지)
+	current, err := w.whRepo.GetSubscription(c, subscriptionID)
+	if err != nil {
+		abortWithAPIError(c, lgr, webhookErrorStatus(err), "failed to find webhook", requestID, err)
+		return
+	}
+
+	// 2. 구독 변경
+	sub := webhookReq.Subscription(subscriptionID)
+	if sub.Secret == "" {
+		sub.Secret = current.Secret
+	}
+	sub.CreatedAt, sub.UpdatedAt = current.CreatedAt, time.Now()
+	if err := w.whRepo.UpdateSubscription(c, &sub); err != nil {
+		abortWithAPIError(c, lgr, webhookErrorStatus(err), "failed to update webhook", requestID, err)
+		return
+	}
+
+	lgr.Info().Int64("subscriptionID", subscriptionID).Bool("enabled", sub.Enabled).Msg("webhook updated")
+	c.JSON(http.StatusOK, external.NewWebhookRes(&sub, false))
+}
+
+// Delete handles DELETE /webhooks/:ID.
+// 구독의 전송 기록도 함께 삭제되며, 보내지 않은 전송은 더 이상 보내지 않는다.
+func (w *WebhookHandler) Delete(c *gin.Context) {
+	lgr, requestID := w.logger.WithReqID(c)
+
+	subscriptionID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid webhook ID", requestID, err)
+		return
+	}
+
+	if err := w.dispatcher.DeleteSubscription(c, subscriptionID); err != nil {
+		abortWithAPIError(c, lgr, webhookErrorStatus(err), "failed to delete webhook", requestID, err)
+		return
+	}
+
+	lgr.Info().Int64("subscriptionID", subscriptionID).Msg("webhook deleted")
+	c.Status(http.StatusNoContent)
+}
+
+// Test handles POST /webhooks/:ID/test.
+// 구독 URL로 webhook.ping 이벤트를 바로 보내고 수신 결과를 반환한다. (전송 기록은 남기지 않음)
+func (w *WebhookHandler) Test(c *gin.Context) {
+	lgr, requestID := w.logger.WithReqID(c)
+
+	subscriptionID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid webhook ID", requestID, err)
+		return
+	}
+
+	sub, err := w.whRepo.GetSubscription(c, subscriptionID)
+	if err != nil {
+		abortWithAPIError(c, lgr, webhookErrorStatus(err), "failed to find webhook", requestID, err)
+		return
+	}
+
+	res := w.dispatcher.Ping(c, sub)
+	lgr.Info().Int64("subscriptionID", subscriptionID).Bool("delivered", res.Delivered).Int("statusCode", res.StatusCode).Msg("webhook tested")
+	c.JSON(http.StatusOK, res)
+}
+
+// GetDeliveries handles GET /webhooks/:ID/deliveries?state=&before=&limit=.
+// 구독의 전송 기록을 최신 순서로 반환한다.
+func (w *WebhookHandler) GetDeliveries(c *gin.Context) {
+	lgr, requestID := w.logger.WithReqID(c)
+
+	subscriptionID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid webhook ID", requestID, err)
+		return
+	}
+
+	var listReq external.DeliveryListReq
+	if err := c.ShouldBindQuery(&listReq); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid delivery query", requestID, err)
+		return
+	}
+	if err := listReq.Validate(); err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "Invalid delivery query", requestID, err)
+		return
+	}
+
+	if _, err := w.whRepo.GetSubscription(c, subscriptionID); err != nil {
+		abortWithAPIError(c, lgr, webhookErrorStatus(err), "failed to find webhook", requestID, err)
+		return
+	}
+
+	deliveries, err := w.whRepo.ListDeliveries(c, listReq.Query(subscriptionID))
+	if err != nil {
+		abortWithAPIError(c, lgr, webhookErrorStatus(err), "failed to select webhook deliveries", requestID, err)
+		return
+	}
+
+	res := make([]external.DeliveryRes, len(deliveries))
+	for i := range deliveries {
+		res[i] = external.NewDeliveryRes(&deliveries[i])
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// Retry handles POST /webhooks/:ID/deliveries/:deliveryID/retry.
+// dead로 남은 전송을 시도 횟수를 초기화하여 바로 다시 보낸다.
+func (w *WebhookHandler) Retry(c *gin.Context) {
+	lgr, requestID := w.logger.WithReqID(c)
+
+	subscriptionID, err := strconv.ParseInt(c.Param("ID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid webhook ID", requestID, err)
+		return
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("deliveryID"), 10, 64)
+	if err != nil {
+		abortWithAPIError(c, lgr, http.StatusBadRequest, "invalid delivery ID", requestID, err)
+		return
+	}
+
+	delivery, err := w.dispatcher.Requeue(c, subscriptionID, deliveryID)
+	if err != nil {
+		abortWithAPIError(c, lgr, webhookErrorStatus(err), "failed to retry webhook delivery", requestID, err)
+		return
+	}
+
+	lgr.Info().Int64("subscriptionID", subscriptionID).Int64("deliveryID", deliveryID).Msg("webhook delivery requeued")
+	c.JSON(http.StatusOK, external.NewDeliveryRes(delivery))
+}
+
+// DispatcherStats handles GET /internal/webhooks/dispatcher.
+// 웹훅 전송 기록 및 전송/재시도/dead 현황을 반환한다.
+func (w *WebhookHandler) DispatcherStats(c *gin.Context) {
+	c.JSON(http.StatusOK, w.dispatcher.Stats())
+}
+
+// 존재하지 않는 구독/전송은 404, 상태가 맞지 않으면 409, 데이터베이스 장애는 503으로 응답한다
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors2.Is(err, db.ErrSubscriptionNotFound), errors2.Is(err, db.ErrDeliveryNotFound):
+		return http.StatusNotFound
+	case errors2.Is(err, db.ErrDeliveryStateConflict):
+		return http.StatusConflict
+	case db.IsUnavailable(err):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,197 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/wal"
+)
+
+// 재전송 기본 주기와 한 트랜잭션으로 재전송할 보고 수
+const (
+	defaultReplayInterval = 5 * time.Second
+	replayBatchSize       = defaultBatchSize
+)
+
+var ErrInvalidBufferRequired = errors.New("missing required inputs to create report Buffer")
+
+// BufferedReport는 데이터베이스 장애 중 WAL에 기록되는 보고 한 건입니다.
+type BufferedReport struct {
+	Report          data.DeviceInfo `json:"report"`
+	FirmwareVersion string          `json:"firmwareVersion,omitempty"`
+}
+
+// BufferStats는 장애 버퍼의 backlog 및 재전송 현황입니다.
+type BufferStats struct {
+	wal.Stats
+	Replayed     int64     `json:"replayed"`               // 재전송 완료 건수 (기동 이후)
+	Dropped      int64     `json:"dropped"`                // 디바이스 삭제 등으로 저장할 수 없어 버린 건수 (기동 이후)
+	LastReplayAt time.Time `json:"lastReplayAt,omitempty"` // 마지막 재전송 완료 시간
+}
+
+// Buffer는 데이터베이스에 연결할 수 없을 때 보고를 로컬 WAL에 보관하고,
+// 연결이 복구되면 기록 순서대로 Recorder를 통해 데이터베이스에 재전송합니다.
+type Buffer struct {
+	log      *wal.Log
+	recorder *Recorder
+	interval time.Duration
+	logger   *logger.AppLogger
+
+	replayed atomic.Int64
+	dropped  atomic.Int64
+
+	mu           sync.Mutex
+	lastReplayAt time.Time
+}
+
+func NewBuffer(lgr *logger.AppLogger, log *wal.Log, recorder *Recorder, interval time.Duration) (*Buffer, error) {
+	if lgr == nil || log == nil || recorder == nil {
+		return nil, ErrInvalidBufferRequired
+	}
+	if interval <= 0 {
+		interval = defaultReplayInterval
+	}
+
+	return &Buffer{
+		log:      log,
+		recorder: recorder,
+		interval: interval,
+		logger:   lgr,
+	}, nil
+}
+
+// Add - 보고를 WAL에 기록합니다. 반환 시점에 디스크에 fsync 되어 있습니다.
+func (b *Buffer) Add(report *data.DeviceInfo, firmwareVersion string) error {
+	payload, err := json.Marshal(BufferedReport{Report: *report, FirmwareVersion: firmwareVersion})
+	if err != nil {
+		return err
+	}
+	return b.log.Append(payload)
+}
+
+// Pending - 재전송되지 않은 보고가 남아있는지 확인합니다.
+// 남아있는 동안 새 보고도 버퍼에 기록해야 보고 순서가 유지됩니다.
+func (b *Buffer) Pending() bool {
+	return b.log.Backlog() > 0
+}
+
+// Stats - backlog 크기와 재전송 현황을 반환합니다.
+func (b *Buffer) Stats() BufferStats {
+	b.mu.Lock()
+	lastReplayAt := b.lastReplayAt
+	b.mu.Unlock()
+
+	return BufferStats{
+		Stats:        b.log.Stats(),
+		Replayed:     b.replayed.Load(),
+		Dropped:      b.dropped.Load(),
+		LastReplayAt: lastReplayAt,
+	}
+}
+
+// Run - ctx가 종료될 때까지 주기적으로 backlog를 재전송합니다.
+func (b *Buffer) Run(ctx context.Context) {
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		b.Replay(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Replay - backlog를 기록 순서대로 묶어 배치 기록과 같이 한 트랜잭션에 저장합니다.
+// (재전송하는 동안 들어온 보고도 버퍼에 이어서 기록되므로 보고 단위로 저장하면 backlog가 줄지 않을 수 있다)
+// 데이터베이스에 여전히 연결할 수 없으면 중단하고 다음 주기에 같은 보고부터 다시 시도합니다.
+func (b *Buffer) Replay(ctx context.Context) {
+	backlog := b.log.Backlog()
+	if backlog == 0 {
+		return
+	}
+
+	b.logger.Info().Int64("backlog", backlog).Msg("replaying buffered reports")
+
+	err := b.log.ReplayBatch(replayBatchSize, func(payloads [][]byte) (int, error) {
+		return b.replayBatch(ctx, payloads)
+	})
+	if err != nil {
+		b.logger.Error().Err(err).Int64("backlog", b.log.Backlog()).Msg("buffered report replay stopped")
+		return
+	}
+
+	b.mu.Lock()
+	b.lastReplayAt = time.Now()
+	b.mu.Unlock()
+
+	b.logger.Info().Int64("replayed", b.replayed.Load()).Int64("dropped", b.dropped.Load()).Msg("buffered reports replayed")
+}
+
+// 보고 묶음을 저장하고 처리를 마친 보고 수를 반환한다.
+// 장애가 아닌 오류는 배치 중 일부 보고의 오류일 수 있으므로 보고 단위로 다시 저장한다.
+func (b *Buffer) replayBatch(ctx context.Context, payloads [][]byte) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	// 1. 읽을 수 없는 보고는 다시 시도해도 같으므로 버린다
+	batch := make([]pendingReport, 0, len(payloads))
+	indexes := make([]int, 0, len(payloads)) // batch의 보고별 payloads 위치
+	for i, payload := range payloads {
+		var buffered BufferedReport
+		if err := json.Unmarshal(payload, &buffered); err != nil {
+			b.logger.Error().Err(err).Msg("dropping undecodable buffered report")
+			b.dropped.Add(1)
+			continue
+		}
+		batch = append(batch, pendingReport{report: buffered.Report, firmwareVersion: buffered.FirmwareVersion})
+		indexes = append(indexes, i)
+	}
+	if len(batch) == 0 {
+		return len(payloads), nil
+	}
+
+	// 2. 보고 저장 : 커밋 실패는 반영 여부를 알 수 없으므로 유실 대신 중복 저장을 감수하고 다시 시도한다
+	dropped, err := b.recorder.recordBatch(ctx, batch)
+	switch {
+	case err == nil:
+		if dropped > 0 {
+			b.logger.Error().Int("reports", dropped).Msg("dropping buffered reports for unknown devices")
+		}
+		b.replayed.Add(int64(len(batch) - dropped))
+		b.dropped.Add(int64(dropped))
+		return len(payloads), nil
+	case db.IsUnavailable(err) || errors.Is(err, db.ErrTxCommit):
+		return 0, err
+	}
+
+	// 3. 보고 단위로 다시 저장
+	b.logger.Error().Err(err).Int("reports", len(batch)).Msg("failed to replay buffered report batch, retrying one by one")
+	for i := range batch {
+		item := &batch[i]
+
+		err := b.recorder.Record(ctx, &item.report, item.firmwareVersion)
+		if db.IsUnavailable(err) || errors.Is(err, db.ErrTxCommit) {
+			return indexes[i], err
+		}
+		if err != nil {
+			// 장애가 아닌 오류(디바이스 삭제 등)는 다시 시도해도 같으므로 버린다
+			b.logger.Error().Err(err).Str("productNumber", item.report.ProductNumber).Msg("dropping buffered report")
+			b.dropped.Add(1)
+			continue
+		}
+		b.replayed.Add(1)
+	}
+	return len(payloads), nil
+}
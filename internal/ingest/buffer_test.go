@@ -0,0 +1,71 @@
+package ingest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/wal"
+)
+
+func TestBufferReplay(t *testing.T) {
+	ctx := context.Background()
+	lgr := logger.Setup("error", "test")
+	recorder, dsRepo, publisher := newTestRecorder(t, newTestDevice("ABC010001", "1.0.0"))
+
+	log, err := wal.Open(t.TempDir(), 0, lgr)
+	if err != nil {
+		t.Fatalf("wal.Open: %v", err)
+	}
+	t.Cleanup(func() { log.Close() })
+	buffer, err := NewBuffer(lgr, log, recorder, time.Second)
+	if err != nil {
+		t.Fatalf("NewBuffer: %v", err)
+	}
+
+	// 한 배치보다 많은 보고와, 삭제된 디바이스의 보고, 읽을 수 없는 보고
+	total := replayBatchSize + 5
+	for i := 0; i < total; i++ {
+		report := newTestReport("ABC010001", data.ReportPowerOn, 0, testNow.Add(time.Duration(i)*time.Second))
+		if err := buffer.Add(&report, ""); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	unknown := newTestReport("ABC019999", data.ReportPowerOn, 0, testNow)
+	if err := buffer.Add(&unknown, ""); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := log.Append([]byte("not json")); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if !buffer.Pending() {
+		t.Fatal("Pending = false after Add")
+	}
+
+	buffer.Replay(ctx)
+
+	stats := buffer.Stats()
+	if buffer.Pending() || stats.Replayed != int64(total) || stats.Dropped != 2 || stats.LastReplayAt.IsZero() {
+		t.Errorf("Stats after Replay = %+v", stats)
+	}
+	received := 0
+	for _, event := range publisher.take() {
+		if event.Type == data.EventReportReceived {
+			received++
+		}
+	}
+	if received != total {
+		t.Errorf("published %d report events, want %d", received, total)
+	}
+
+	// 마지막 보고 시간은 마지막으로 재전송한 보고를 따른다
+	device, err := dsRepo.GetByID(ctx, "ABC010001")
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if want := testNow.Add(time.Duration(total-1) * time.Second); !device.LastSeenAt.Equal(want) {
+		t.Errorf("LastSeenAt = %v, want %v", device.LastSeenAt, want)
+	}
+}
@@ -0,0 +1,251 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/model/external"
+)
+
+var ErrInvalidRecorderRequired = errors.New("missing required inputs to create report Recorder")
+
+// Observer는 저장(커밋)된 보고를 전달받는 후속 처리입니다. (알림 평가 등)
+// 보고 저장을 지연시키지 않도록 기다리지 않고 반환해야 하며, 전달받은 reports를 변경하지 않아야 합니다.
+type Observer interface {
+	Observe(reports []data.DeviceInfo)
+}
+
+// Publisher는 커밋된 보고 저장, 디바이스 상태 변경, 펌웨어 설치 이벤트를 바로 전달받는 후속 처리입니다. (실시간 스트림 등)
+// 보고 저장을 지연시키지 않도록 기다리지 않고 반환해야 하며, 전달받은 events를 변경하지 않아야 합니다.
+type Publisher interface {
+	Publish(events []data.Event)
+}
+
+// Recorder는 주기 보고 저장과 디바이스 상태 갱신을 하나의 트랜잭션으로 처리합니다.
+// 보고 API와 WAL 재전송이 같은 저장 로직을 사용합니다.
+// 보고 저장, 디바이스 상태 변경, 펌웨어 설치 이벤트는 같은 트랜잭션으로 outbox에 기록하고,
+// 커밋된 보고는 Observer에, 커밋된 이벤트는 Publisher에 전달합니다. (배치 기록기가 기록한 보고 포함)
+type Recorder struct {
+	txMgr     db.TxManager
+	rsRepo    db.ReportsDataService
+	dsRepo    db.DevicesDataService
+	lsRepo    db.LatestStateDataService
+	outbox    db.OutboxDataService
+	observer  Observer
+	publisher Publisher
+	logger    *logger.AppLogger
+}
+
+// outbox는 이벤트를 기록하지 않으면, observer와 publisher는 전달받을 후속 처리가 없으면 nil입니다.
+func NewRecorder(
+	lgr *logger.AppLogger,
+	txMgr db.TxManager,
+	rsRepo db.ReportsDataService,
+	dsRepo db.DevicesDataService,
+	lsRepo db.LatestStateDataService,
+	outbox db.OutboxDataService,
+	observer Observer,
+	publisher Publisher,
+) (*Recorder, error) {
+	if lgr == nil || txMgr == nil || rsRepo == nil || dsRepo == nil || lsRepo == nil {
+		return nil, ErrInvalidRecorderRequired
+	}
+
+	return &Recorder{
+		txMgr:     txMgr,
+		rsRepo:    rsRepo,
+		dsRepo:    dsRepo,
+		lsRepo:    lsRepo,
+		outbox:    outbox,
+		observer:  observer,
+		publisher: publisher,
+		logger:    lgr,
+	}, nil
+}
+
+// Record - 보고를 저장하고 보고 내용으로 디바이스의 마지막 보고 시간, 재시도 횟수, 상태, 펌웨어 버전과 마지막 보고 상태를 갱신합니다.
+// 디바이스가 존재하지 않으면 저장하지 않고 db.ErrDeviceNotFound를 반환합니다.
+func (r *Recorder) Record(ctx context.Context, report *data.DeviceInfo, firmwareVersion string) error {
+	dropped, err := r.recordBatch(ctx, []pendingReport{{report: *report, firmwareVersion: firmwareVersion}})
+	if err != nil {
+		return err
+	}
+	if dropped > 0 {
+		return fmt.Errorf("%w: %w", db.ErrFailedToSelectDevice, db.ErrDeviceNotFound)
+	}
+	return nil
+}
+
+// 보고 묶음의 저장, 디바이스 및 마지막 보고 상태 갱신, 이벤트 기록을 하나의 트랜잭션으로 처리한다.
+// 디바이스 갱신 정보는 트랜잭션 안에서 잠그고 읽은 현재 값에 보고를 순서대로 반영하여 만든다.
+// (보고를 받을 때 읽은 값은 캐시되었거나 앞선 배치가 반영되기 전의 값일 수 있다)
+// 그 사이 삭제된 디바이스의 보고는 저장하지 않고 버린 건수로 반환한다.
+func (r *Recorder) recordBatch(ctx context.Context, batch []pendingReport) (dropped int, err error) {
+	productNumbers := make([]string, 0, len(batch))
+	seen := make(map[string]bool, len(batch))
+	for i := range batch {
+		if pn := batch[i].report.ProductNumber; !seen[pn] {
+			seen[pn] = true
+			productNumbers = append(productNumbers, pn)
+		}
+	}
+
+	var reports []data.DeviceInfo
+	var events []data.Event
+	err = r.txMgr.WithTx(ctx, func(tx db.DBTX) error {
+		// 1. 디바이스의 현재 값을 잠그고 읽는다
+		dsRepo := r.dsRepo.WithTx(tx)
+		devices, err := dsRepo.LockByIDs(ctx, productNumbers)
+		if err != nil {
+			return err
+		}
+
+		kept := make([]pendingReport, 0, len(batch))
+		reports = make([]data.DeviceInfo, 0, len(batch))
+		for i := range batch {
+			if _, ok := devices[batch[i].report.ProductNumber]; ok {
+				kept = append(kept, batch[i])
+				reports = append(reports, batch[i].report)
+			}
+		}
+		dropped = len(batch) - len(kept)
+		if len(kept) == 0 {
+			events = nil
+			return nil
+		}
+
+		// 2. 디바이스 갱신 정보 및 이벤트 준비
+		updates := foldDeviceUpdates(kept, devices)
+		events = r.events(reports, updates)
+
+		// 3. 보고 저장, 디바이스 갱신, 이벤트 기록
+		if err := r.rsRepo.WithTx(tx).CreateBatch(ctx, reports); err != nil {
+			return err
+		}
+		if err := r.lsRepo.WithTx(tx).Upsert(ctx, reports); err != nil {
+			return err
+		}
+		for _, u := range updates {
+			if err := dsRepo.Update(ctx, u.productNumber, &u.params); err != nil {
+				return err
+			}
+		}
+		return r.record(ctx, tx, events)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// 4. 커밋된 보고와 이벤트를 후속 처리에 전달
+	if len(reports) > 0 {
+		r.observe(reports)
+		r.publish(events)
+	}
+	return dropped, nil
+}
+
+func (r *Recorder) observe(reports []data.DeviceInfo) {
+	if r.observer != nil {
+		r.observer.Observe(reports)
+	}
+}
+
+// 보고 저장 이벤트와, 갱신 전과 달라진 디바이스의 상태 변경 및 펌웨어 설치 이벤트를 만든다.
+// 같은 디바이스의 이벤트는 보고 순서대로, 상태 변경과 펌웨어 설치는 그 디바이스의 보고 뒤에 둔다.
+func (r *Recorder) events(reports []data.DeviceInfo, updates []deviceUpdateParams) []data.Event {
+	if r.outbox == nil && r.publisher == nil {
+		return nil
+	}
+
+	events := make([]data.Event, 0, len(reports)+len(updates))
+	for i := range reports {
+		report := &reports[i]
+		events = r.appendEvent(events, data.EventReportReceived, report.ProductNumber, report.ReportAt, external.NewReportRes(report))
+	}
+
+	for i := range updates {
+		u := &updates[i]
+		at := *u.params.LastSeenAt
+
+		if u.params.Status != nil && *u.params.Status != u.previous {
+			events = r.appendEvent(events, data.EventDeviceStatusChanged, u.productNumber, at, external.DeviceStatusChangedRes{
+				ProductNumber:  u.productNumber,
+				PreviousStatus: u.previous,
+				Status:         *u.params.Status,
+				ChangedAt:      at,
+			})
+		}
+		if u.params.FirmwareVersion != nil && *u.params.FirmwareVersion != u.previousFirmware {
+			events = r.appendEvent(events, data.EventFirmwareInstalled, u.productNumber, at, external.FirmwareInstalledRes{
+				ProductNumber:   u.productNumber,
+				PreviousVersion: u.previousFirmware,
+				Version:         *u.params.FirmwareVersion,
+				InstalledAt:     at,
+			})
+		}
+	}
+
+	return events
+}
+
+func (r *Recorder) appendEvent(events []data.Event, eventType data.EventType, productNumber string, at time.Time, payload any) []data.Event {
+	event, err := data.NewEvent(eventType, productNumber, at, payload)
+	if err != nil {
+		r.logger.Error().Err(err).Str("productNumber", productNumber).Str("eventType", string(eventType)).Msg("failed to create report event")
+		return events
+	}
+	return append(events, event)
+}
+
+func (r *Recorder) publish(events []data.Event) {
+	if r.publisher != nil && len(events) > 0 {
+		r.publisher.Publish(events)
+	}
+}
+
+// 보고 저장과 같은 트랜잭션으로 이벤트를 outbox에 기록한다
+func (r *Recorder) record(ctx context.Context, tx db.DBTX, events []data.Event) error {
+	if r.outbox == nil || len(events) == 0 {
+		return nil
+	}
+	return r.outbox.WithTx(tx).Add(ctx, events)
+}
+
+// 보고 한 건으로 바뀌는 디바이스 정보를 만든다.
+// 에러 코드가 보고되면 재시도(재부팅) 횟수를 증가시키고, 정상 보고 시 초기화한다.
+// 재시도 횟수는 보고 API의 단건 기록 등과 함께 갱신될 수 있으므로 저장된 값에서 증가시킨다.
+func deviceUpdate(device *data.Device, report *data.DeviceInfo, firmwareVersion string) external.UpdateDeviceParams {
+	lastSeenAt := report.ReportAt
+	status := DeviceStatusFromReport(report.ReportedStatus)
+
+	params := external.UpdateDeviceParams{
+		LastSeenAt: &lastSeenAt,
+		Status:     &status,
+	}
+	if report.ErrorCode != 0 {
+		one := 1
+		params.ReTryAdd = &one
+	} else {
+		zero := 0
+		params.ReTry = &zero
+	}
+	if firmwareVersion != "" && firmwareVersion != device.FirmwareVersion {
+		params.FirmwareVersion = &firmwareVersion
+	}
+	return params
+}
+
+// DeviceStatusFromReport - 디바이스가 보고한 상태로부터 서버가 판단하는 최종 상태를 결정합니다.
+func DeviceStatusFromReport(reported data.DeviceStatus) data.DeviceStatus {
+	switch reported {
+	case data.ReportError, data.ReportPowerOff:
+		return reported
+	default:
+		return data.StatusReady
+	}
+}
@@ -0,0 +1,168 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/model/external"
+)
+
+var testNow = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// capturePublisher는 전달받은 이벤트를 모아두는 Publisher입니다.
+type capturePublisher struct {
+	mu     sync.Mutex
+	events []data.Event
+}
+
+func (p *capturePublisher) Publish(events []data.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, events...)
+}
+
+// 이벤트를 꺼내고 비운다
+func (p *capturePublisher) take() []data.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	events := p.events
+	p.events = nil
+	return events
+}
+
+// SQLite 데이터베이스로 Recorder를 구성하고 디바이스를 등록한다
+func newTestRecorder(t *testing.T, devices ...data.Device) (*Recorder, db.DevicesDataService, *capturePublisher) {
+	t.Helper()
+
+	ctx := context.Background()
+	lgr := logger.Setup("error", "test")
+	mgr, err := db.NewSQLiteManager(filepath.Join(t.TempDir(), "test.db"), lgr)
+	if err != nil {
+		t.Fatalf("NewSQLiteManager: %v", err)
+	}
+	t.Cleanup(func() { mgr.Disconnect() })
+
+	migrator, err := db.NewMigrator(lgr, mgr)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	rsRepo, _ := db.NewReportsRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+	dsRepo, _ := db.NewDevicesRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+	lsRepo, _ := db.NewLatestStateRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+	for i := range devices {
+		if _, err := dsRepo.Create(ctx, &devices[i]); err != nil {
+			t.Fatalf("create device %s: %v", devices[i].ProductNumber, err)
+		}
+	}
+
+	publisher := &capturePublisher{}
+	recorder, err := NewRecorder(lgr, mgr, rsRepo, dsRepo, lsRepo, nil, nil, publisher)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	return recorder, dsRepo, publisher
+}
+
+func newTestDevice(productNumber, firmwareVersion string) data.Device {
+	productLine, hardwareRevision := data.ParseProductNumber(productNumber)
+	return data.Device{
+		ProductNumber:    productNumber,
+		MacAddress:       "00:11:22:33:" + productNumber[5:7] + ":" + productNumber[7:9],
+		ProductLine:      productLine,
+		HardwareRevision: hardwareRevision,
+		FirmwareVersion:  firmwareVersion,
+		LastSeenAt:       testNow,
+		CreatedAt:        testNow,
+		Status:           data.StatusReady,
+	}
+}
+
+func newTestReport(productNumber string, status data.DeviceStatus, errorCode int, reportAt time.Time) data.DeviceInfo {
+	return data.DeviceInfo{
+		ProductNumber:  productNumber,
+		BatteryPercent: 80,
+		IP:             "10.0.0.1",
+		ErrorCode:      errorCode,
+		ReportAt:       reportAt,
+		ReportedStatus: status,
+	}
+}
+
+func TestRecordBatchFoldsLockedState(t *testing.T) {
+	ctx := context.Background()
+	recorder, dsRepo, publisher := newTestRecorder(t, newTestDevice("ABC010001", "1.0.0"))
+
+	// 1. 첫 배치 : 펌웨어 설치와 오류 보고가 반영된다
+	batch := []pendingReport{
+		{report: newTestReport("ABC010001", data.ReportPowerOn, 0, testNow.Add(time.Minute)), firmwareVersion: "2.0.0"},
+		{report: newTestReport("ABC010001", data.ReportError, 7, testNow.Add(2*time.Minute))},
+		{report: newTestReport("ABC019999", data.ReportPowerOn, 0, testNow.Add(2*time.Minute))},
+	}
+	dropped, err := recorder.recordBatch(ctx, batch)
+	if err != nil {
+		t.Fatalf("recordBatch: %v", err)
+	}
+	if dropped != 1 {
+		t.Errorf("dropped = %d, want 1 (unknown device)", dropped)
+	}
+	if got := eventTypes(publisher.take()); got != "report.received,report.received,device.status_changed,firmware.installed" {
+		t.Errorf("first batch events = %s", got)
+	}
+
+	// 2. 다음 배치는 앞선 배치가 커밋한 상태에 반영한다 (같은 펌웨어는 다시 설치 이벤트를 만들지 않는다)
+	batch = []pendingReport{
+		{report: newTestReport("ABC010001", data.ReportError, 7, testNow.Add(3*time.Minute)), firmwareVersion: "2.0.0"},
+		{report: newTestReport("ABC010001", data.ReportPowerOn, 0, testNow.Add(4*time.Minute)), firmwareVersion: "2.0.0"},
+	}
+	if _, err := recorder.recordBatch(ctx, batch); err != nil {
+		t.Fatalf("recordBatch: %v", err)
+	}
+	events := publisher.take()
+	if got := eventTypes(events); got != "report.received,report.received,device.status_changed" {
+		t.Fatalf("second batch events = %s", got)
+	}
+	var changed external.DeviceStatusChangedRes
+	if err := json.Unmarshal(events[2].Data, &changed); err != nil {
+		t.Fatal(err)
+	}
+	if changed.PreviousStatus != data.ReportError || changed.Status != data.StatusReady {
+		t.Errorf("status change = %s -> %s, want %s -> %s", changed.PreviousStatus, changed.Status, data.ReportError, data.StatusReady)
+	}
+
+	device, err := dsRepo.GetByID(ctx, "ABC010001")
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if device.FirmwareVersion != "2.0.0" || device.Status != data.StatusReady || device.ReTry != 0 {
+		t.Errorf("device = %+v", device)
+	}
+
+	// 존재하지 않는 디바이스의 단건 보고는 ErrDeviceNotFound
+	report := newTestReport("ABC019999", data.ReportPowerOn, 0, testNow)
+	if err := recorder.Record(ctx, &report, ""); !errors.Is(err, db.ErrDeviceNotFound) {
+		t.Errorf("Record unknown device: err = %v, want ErrDeviceNotFound", err)
+	}
+}
+
+func eventTypes(events []data.Event) string {
+	var s string
+	for i, event := range events {
+		if i > 0 {
+			s += ","
+		}
+		s += string(event.Type)
+	}
+	return s
+}
@@ -0,0 +1,313 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/model/external"
+)
+
+// 배치 기록 기본값
+const (
+	defaultBatchSize     = 200
+	defaultFlushInterval = time.Second
+	defaultQueueSize     = 10000
+	maxBatchSize         = 1000 // 행당 9개 placeholder, 드라이버 placeholder 수 제한 이내
+	flushTimeout         = 30 * time.Second
+)
+
+var (
+	ErrInvalidWriterRequired = errors.New("missing required inputs to create report BatchWriter")
+	ErrQueueFull             = errors.New("report write queue is full")
+	ErrWriterClosed          = errors.New("report writer is closed")
+)
+
+type WriterConfig struct {
+	BatchSize     int           // 한 번에 기록할 최대 보고 수 (기본값 200, 최대 1000)
+	FlushInterval time.Duration // 배치가 차지 않아도 기록하는 주기 (기본값 1초)
+	QueueSize     int           // 기록 대기열 크기, 가득 차면 ErrQueueFull (기본값 10000)
+}
+
+// WriterStats는 배치 기록 현황입니다.
+type WriterStats struct {
+	Queued   int   `json:"queued"`   // 기록 대기 중인 보고 수
+	Capacity int   `json:"capacity"` // 대기열 크기
+	Batches  int64 `json:"batches"`  // 기록한 배치 수 (기동 이후)
+	Written  int64 `json:"written"`  // 기록한 보고 수 (기동 이후)
+	Buffered int64 `json:"buffered"` // 데이터베이스 장애로 WAL에 넘긴 보고 수 (기동 이후)
+	Dropped  int64 `json:"dropped"`  // 저장하지 못하고 버린 보고 수 (기동 이후)
+	Rejected int64 `json:"rejected"` // 대기열이 가득 차 거부한 보고 수 (기동 이후)
+}
+
+type pendingReport struct {
+	report          data.DeviceInfo
+	firmwareVersion string
+}
+
+// BatchWriter는 보고를 대기열에 모아 크기 또는 주기에 따라 multi-row INSERT로 기록합니다.
+// 배치의 보고 저장, 디바이스 갱신, 이벤트 기록은 하나의 트랜잭션으로 처리하며,
+// 데이터베이스에 연결할 수 없으면 배치를 장애 버퍼(WAL)로 넘기고,
+// 그 외 오류는 보고 단위로 다시 기록하여 문제가 있는 보고만 제외합니다.
+type BatchWriter struct {
+	cfg      WriterConfig
+	recorder *Recorder
+	buffer   *Buffer
+	logger   *logger.AppLogger
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan pendingReport
+	done   chan struct{}
+
+	batches  atomic.Int64
+	written  atomic.Int64
+	buffered atomic.Int64
+	dropped  atomic.Int64
+	rejected atomic.Int64
+}
+
+// NewBatchWriter - 배치 기록을 시작합니다. 종료 시 Close로 남은 보고를 기록해야 합니다.
+func NewBatchWriter(
+	lgr *logger.AppLogger,
+	recorder *Recorder,
+	buffer *Buffer,
+	cfg WriterConfig,
+) (*BatchWriter, error) {
+	if lgr == nil || recorder == nil || buffer == nil {
+		return nil, ErrInvalidWriterRequired
+	}
+
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	cfg.BatchSize = min(cfg.BatchSize, maxBatchSize)
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultQueueSize
+	}
+
+	w := &BatchWriter{
+		cfg:      cfg,
+		recorder: recorder,
+		buffer:   buffer,
+		logger:   lgr,
+		queue:    make(chan pendingReport, cfg.QueueSize),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+
+	return w, nil
+}
+
+// Enqueue - 보고를 기록 대기열에 넣습니다. 대기열이 가득 차면 기다리지 않고 ErrQueueFull을 반환합니다.
+func (w *BatchWriter) Enqueue(report *data.DeviceInfo, firmwareVersion string) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return ErrWriterClosed
+	}
+
+	select {
+	case w.queue <- pendingReport{report: *report, firmwareVersion: firmwareVersion}:
+		return nil
+	default:
+		w.rejected.Add(1)
+		return ErrQueueFull
+	}
+}
+
+// RetryAfter - 대기열이 가득 찼을 때 디바이스에 안내할 재시도 대기 시간
+func (w *BatchWriter) RetryAfter() time.Duration {
+	return w.cfg.FlushInterval
+}
+
+// Stats - 대기열 및 기록 현황을 반환합니다.
+func (w *BatchWriter) Stats() WriterStats {
+	return WriterStats{
+		Queued:   len(w.queue),
+		Capacity: cap(w.queue),
+		Batches:  w.batches.Load(),
+		Written:  w.written.Load(),
+		Buffered: w.buffered.Load(),
+		Dropped:  w.dropped.Load(),
+		Rejected: w.rejected.Load(),
+	}
+}
+
+// Close - 새 보고를 받지 않고 대기열에 남은 보고를 모두 기록한 뒤 반환합니다.
+// ctx가 먼저 종료되면 기록을 기다리지 않고 ctx 오류를 반환합니다.
+func (w *BatchWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.logger.Error().Int("queued", len(w.queue)).Msg("report writer did not flush before shutdown deadline")
+		return ctx.Err()
+	}
+}
+
+func (w *BatchWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]pendingReport, 0, w.cfg.BatchSize)
+	for {
+		select {
+		case item, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, item)
+			if len(batch) < w.cfg.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+
+		w.flush(batch)
+		batch = batch[:0]
+	}
+}
+
+func (w *BatchWriter) flush(batch []pendingReport) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+
+	// 1. 버퍼에 재전송되지 않은 보고가 남아있으면 보고 순서를 지키기 위해 이어서 버퍼에 기록
+	if w.buffer.Pending() {
+		w.bufferAll(batch)
+		return
+	}
+
+	// 2. 보고 저장과 디바이스 및 마지막 보고 상태 갱신, 이벤트 기록을 하나의 트랜잭션으로 처리
+	dropped, err := w.recorder.recordBatch(ctx, batch)
+	switch {
+	case err == nil:
+		w.batches.Add(1)
+		w.written.Add(int64(len(batch) - dropped))
+		w.dropped.Add(int64(dropped))
+	case db.IsUnavailable(err):
+		w.logger.Error().Err(err).Int("reports", len(batch)).Msg("database unavailable, buffering report batch")
+		w.bufferAll(batch)
+	default:
+		// 3. 배치 중 일부 보고의 오류일 수 있으므로 보고 단위로 다시 기록
+		w.logger.Error().Err(err).Int("reports", len(batch)).Msg("failed to write report batch, retrying one by one")
+		w.writeEach(ctx, batch)
+	}
+}
+
+func (w *BatchWriter) writeEach(ctx context.Context, batch []pendingReport) {
+	for i := range batch {
+		item := &batch[i]
+
+		err := w.recorder.Record(ctx, &item.report, item.firmwareVersion)
+		switch {
+		case err == nil:
+			w.written.Add(1)
+		case db.IsUnavailable(err):
+			w.bufferAll(batch[i:])
+			return
+		default:
+			w.logger.Error().Err(err).Str("productNumber", item.report.ProductNumber).Msg("dropping report")
+			w.dropped.Add(1)
+		}
+	}
+}
+
+func (w *BatchWriter) bufferAll(batch []pendingReport) {
+	for i := range batch {
+		item := &batch[i]
+		if err := w.buffer.Add(&item.report, item.firmwareVersion); err != nil {
+			w.logger.Error().Err(err).Str("productNumber", item.report.ProductNumber).Msg("failed to buffer report, dropping")
+			w.dropped.Add(1)
+			continue
+		}
+		w.buffered.Add(1)
+	}
+}
+
+type deviceUpdateParams struct {
+	productNumber    string
+	previous         data.DeviceStatus // 갱신 전 디바이스 상태
+	previousFirmware string            // 갱신 전 펌웨어 버전
+	params           external.UpdateDeviceParams
+}
+
+// 트랜잭션 안에서 읽은 디바이스의 현재 값(devices)에 배치 안의 보고를 순서대로 반영하여 디바이스별 최종 갱신 정보를 만든다.
+// 모든 보고의 디바이스가 devices에 있어야 한다. 같은 디바이스의 보고가 여러 건이면 재시도 횟수는 누적하고, 마지막 보고 시간과 상태는 마지막 보고를 따른다.
+// 재시도 횟수는 정상 보고가 있으면 마지막 정상 보고 이후의 오류 보고 수로 정하고, 없으면 저장된 값에 오류 보고 수를 더한다.
+func foldDeviceUpdates(batch []pendingReport, devices map[string]data.Device) []deviceUpdateParams {
+	var updates []deviceUpdateParams
+	index := make(map[string]int)
+	states := make(map[string]data.Device)
+
+	for i := range batch {
+		item := &batch[i]
+		pn := item.report.ProductNumber
+
+		state, ok := states[pn]
+		if !ok {
+			state = devices[pn]
+		}
+
+		params := deviceUpdate(&state, &item.report, item.firmwareVersion)
+		if params.FirmwareVersion != nil {
+			state.FirmwareVersion = *params.FirmwareVersion
+		}
+		states[pn] = state
+
+		j, ok := index[pn]
+		if !ok {
+			j = len(updates)
+			index[pn] = j
+			updates = append(updates, deviceUpdateParams{productNumber: pn, previous: devices[pn].Status, previousFirmware: devices[pn].FirmwareVersion})
+		}
+
+		// 펌웨어 버전은 배치 중 앞선 보고에서만 바뀌었어도 반영되어야 한다
+		prev := updates[j].params
+		if params.FirmwareVersion == nil {
+			params.FirmwareVersion = prev.FirmwareVersion
+		}
+
+		// 오류 보고는 앞선 보고의 재시도 횟수(초기화 또는 증가분)에 더한다
+		if params.ReTryAdd != nil {
+			switch {
+			case prev.ReTry != nil:
+				reTry := *prev.ReTry + *params.ReTryAdd
+				params.ReTry, params.ReTryAdd = &reTry, nil
+			case prev.ReTryAdd != nil:
+				add := *prev.ReTryAdd + *params.ReTryAdd
+				params.ReTryAdd = &add
+			}
+		}
+		updates[j].params = params
+	}
+
+	return updates
+}
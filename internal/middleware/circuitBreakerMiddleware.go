@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/model/external"
+	"go-rest-example/internal/util"
+)
+
+// 데이터베이스 서킷 브레이커가 열려 있으면 핸들러를 실행하지 않고 즉시 503을 응답하는 미들웨어
+// Retry-After는 브레이커가 half-open으로 바뀔 때까지 남은 시간이다.
+func CircuitBreakerMiddleware(cb *db.CircuitBreaker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		retryAfter := cb.RetryAfter()
+		if retryAfter <= 0 {
+			c.Next()
+			return
+		}
+
+		requestID := c.Writer.Header().Get(util.RequestIdentifier)
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, &external.APIError{
+			HTTPStatusCode: http.StatusServiceUnavailable,
+			Message:        "database is unavailable, retry later",
+			DebugID:        requestID,
+		})
+	}
+}
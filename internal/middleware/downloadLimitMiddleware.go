@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-rest-example/internal/firmware"
+	"go-rest-example/internal/model/external"
+	"go-rest-example/internal/util"
+)
+
+// 펌웨어 다운로드 경로의 동시 실행 수와 대역폭을 제한하는 미들웨어
+// 디바이스 그룹은 ProductNumber 앞부분(groupPrefix 글자)으로 구분한다.
+// 여유가 없으면 503과 함께 지터가 적용된 Retry-After를 응답한다.
+func DownloadLimitMiddleware(sched *firmware.DownloadScheduler, groupPrefix int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		group := c.Query("ProductNumber")
+		if groupPrefix > 0 && len(group) > groupPrefix {
+			group = group[:groupPrefix]
+		}
+
+		release, ok := sched.Acquire(group)
+		if !ok {
+			// 디바이스가 전달한 재시도 횟수에 따라 대기 시간을 늘린다
+			attempt, _ := strconv.Atoi(c.Query("attempt"))
+			retryAfter := sched.RetryAfter(attempt)
+			seconds := int(math.Ceil(retryAfter.Seconds()))
+
+			requestID := c.Writer.Header().Get(util.RequestIdentifier)
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, &external.APIError{
+				HTTPStatusCode: http.StatusServiceUnavailable,
+				Message:        "firmware download capacity exhausted, retry later",
+				DebugID:        requestID,
+			})
+			return
+		}
+		defer release()
+
+		// 응답 전송 속도 제한
+		if bucket := sched.Bucket(); bucket != nil {
+			c.Writer = &throttledWriter{ResponseWriter: c.Writer, bucket: bucket, c: c}
+		}
+
+		c.Next()
+	}
+}
+
+// 토큰 버킷으로 전송 속도를 제한하는 ResponseWriter
+type throttledWriter struct {
+	gin.ResponseWriter
+	bucket *firmware.TokenBucket
+	c      *gin.Context
+}
+
+func (w *throttledWriter) Write(b []byte) (int, error) {
+	written := 0
+	chunk := w.bucket.Burst()
+
+	for written < len(b) {
+		n := min(chunk, len(b)-written)
+		if err := w.bucket.WaitN(w.c.Request.Context(), n); err != nil {
+			return written, err
+		}
+
+		m, err := w.ResponseWriter.Write(b[written : written+n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, nil
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-rest-example/internal/model/external"
+	"go-rest-example/internal/util"
+)
+
+// 운영자 인증 헤더 값의 접두사 (Authorization: Bearer <token>)
+const bearerPrefix = "Bearer "
+
+// 운영자 전용 API(내부 현황, 펌웨어 및 배포 캠페인, 알림 규칙, 웹훅 구독)의 인증을 담당하는 미들웨어
+// Authorization 헤더의 Bearer 토큰이 설정된 관리자 토큰과 일치해야 하며, 관리자 토큰이 설정되지 않았으면 모든 요청을 거부한다.
+func InternalAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got, ok := strings.CutPrefix(c.GetHeader("Authorization"), bearerPrefix)
+		if ok && token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+			c.Next()
+			return
+		}
+
+		requestID := c.Writer.Header().Get(util.RequestIdentifier)
+		c.Header("WWW-Authenticate", "Bearer")
+		c.AbortWithStatusJSON(http.StatusUnauthorized, &external.APIError{
+			HTTPStatusCode: http.StatusUnauthorized,
+			Message:        "authentication required",
+			DebugID:        requestID,
+		})
+	}
+}
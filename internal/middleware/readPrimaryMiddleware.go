@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-rest-example/internal/db"
+)
+
+// ReadPrimaryMiddleware는 데이터를 변경하는 요청의 조회를 primary로 고정한다.
+// 같은 요청 안에서 쓰기 전후로 읽는 값이 복제 지연 때문에 어긋나지 않도록 하기 위함이다.
+func ReadPrimaryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			c.Set(db.PrimaryContextKey, true)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,91 @@
+package data
+
+import (
+	"time"
+)
+
+type AggregateGroup string
+
+// 플릿 집계 그룹 기준 (디바이스의 현재 값 기준)
+const (
+	AggregateByStatus   AggregateGroup = "status"   // 서버가 판단한 디바이스 상태
+	AggregateByFirmware AggregateGroup = "firmware" // 펌웨어 버전
+	AggregateByGroup    AggregateGroup = "group"    // 디바이스 그룹 (제품군)
+)
+
+// AggregateQuery는 보고 집계 조건이다.
+type AggregateQuery struct {
+	ProductNumber string         // 빈 값이면 모든 디바이스 (플릿 집계)
+	GroupBy       AggregateGroup // 플릿 집계의 그룹 기준, 빈 값이면 전체를 하나로 집계
+	From          time.Time
+	To            time.Time
+	Bucket        time.Duration // 집계 구간 길이 (하루를 나누어 떨어지게 하는 값)
+}
+
+// Range는 [From, To)를 구간 경계(UTC)로 내림/올림한 조회 범위이다.
+func (q *AggregateQuery) Range() (time.Time, time.Time) {
+	from := q.From.UTC().Truncate(q.Bucket)
+	to := q.To.UTC().Truncate(q.Bucket)
+	if to.Before(q.To) {
+		to = to.Add(q.Bucket)
+	}
+	return from, to
+}
+
+// Rollup은 구간 길이와 같은 요약 단위를 반환한다. 없으면 false.
+func (q *AggregateQuery) Rollup() (RollupGranularity, bool) {
+	switch q.Bucket {
+	case RollupHourly.Duration():
+		return RollupHourly, true
+	case RollupDaily.Duration():
+		return RollupDaily, true
+	}
+	return "", false
+}
+
+// ReportAggregate는 집계 구간별 보고 통계이다.
+// 원본 보고와 요약을 합쳐 계산하므로 요약과 같이 평균을 합계와 건수로 보관한다.
+type ReportAggregate struct {
+	Group          string    // 플릿 집계의 그룹 값 (그룹 기준이 없으면 빈 값)
+	BucketStart    time.Time // 구간 시작 시간 (UTC)
+	Samples        int64     // 보고 건수
+	BatteryMin     int
+	BatteryMax     int
+	BatterySum     int64
+	TemperatureMin float64
+	TemperatureMax float64
+	TemperatureSum float64
+}
+
+func (a *ReportAggregate) BatteryAvg() float64 {
+	if a.Samples == 0 {
+		return 0
+	}
+	return float64(a.BatterySum) / float64(a.Samples)
+}
+
+func (a *ReportAggregate) TemperatureAvg() float64 {
+	if a.Samples == 0 {
+		return 0
+	}
+	return a.TemperatureSum / float64(a.Samples)
+}
+
+// Merge는 같은 그룹/구간의 다른 통계를 합친다.
+func (a *ReportAggregate) Merge(o *ReportAggregate) {
+	if o.Samples == 0 {
+		return
+	}
+	if a.Samples == 0 {
+		*a = *o
+		return
+	}
+
+	a.BatteryMin = min(a.BatteryMin, o.BatteryMin)
+	a.BatteryMax = max(a.BatteryMax, o.BatteryMax)
+	a.TemperatureMin = min(a.TemperatureMin, o.TemperatureMin)
+	a.TemperatureMax = max(a.TemperatureMax, o.TemperatureMax)
+	a.Samples += o.Samples
+	a.BatterySum += o.BatterySum
+	a.TemperatureSum += o.TemperatureSum
+}
@@ -0,0 +1,150 @@
+package data
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type AlertRuleKind string
+
+// 알림 규칙 종류
+const (
+	RuleBatteryBelow     AlertRuleKind = "battery_below"     // 배터리가 MinValue 미만
+	RuleTemperatureRange AlertRuleKind = "temperature_range" // 온도가 [MinValue, MaxValue] 범위 밖
+	RuleErrorCode        AlertRuleKind = "error_code"        // 에러 코드가 ErrorCodes 중 하나 (비어 있으면 0이 아닌 모든 코드)
+	RuleErrorRepeated    AlertRuleKind = "error_repeated"    // 마지막 Count번의 보고 상태가 모두 ERROR
+	RuleOffline          AlertRuleKind = "offline"           // 보고 주기 Count번 동안 보고 없음 (주기 점검에서 평가)
+)
+
+type AlertSeverity string
+
+// 알림 심각도
+const (
+	SeverityInfo     AlertSeverity = "info"
+	SeverityWarning  AlertSeverity = "warning"
+	SeverityCritical AlertSeverity = "critical"
+)
+
+type AlertState string
+
+// 알림 상태 : firing -> acknowledged -> resolved (조건이 해소되면 acknowledged를 거치지 않고 resolved)
+const (
+	AlertFiring       AlertState = "firing"
+	AlertAcknowledged AlertState = "acknowledged"
+	AlertResolved     AlertState = "resolved"
+)
+
+// 알림 규칙 (DB에 저장되는 모델)
+type AlertRule struct {
+	RuleID      int64
+	Name        string
+	Kind        AlertRuleKind
+	Severity    AlertSeverity
+	ProductLine string   // 적용 제품군 (빈 값: 모든 디바이스)
+	MinValue    *float64 // battery_below의 기준값, temperature_range의 하한
+	MaxValue    *float64 // temperature_range의 상한
+	ErrorCodes  []int    // error_code 대상 코드
+	Count       int      // error_repeated의 반복 횟수, offline의 보고 주기 수
+	Enabled     bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Applies는 규칙이 디바이스에 적용되는지 여부이다.
+func (r *AlertRule) Applies(productNumber string) bool {
+	if !r.Enabled {
+		return false
+	}
+	if r.ProductLine == "" {
+		return true
+	}
+	productLine, _ := ParseProductNumber(productNumber)
+	return productLine == r.ProductLine
+}
+
+// OfflineAfter는 offline 규칙의 판단 시간이다.
+func (r *AlertRule) OfflineAfter() time.Duration {
+	return time.Duration(r.Count) * ReportCycle
+}
+
+// Evaluate는 보고 한 건이 규칙 조건에 해당하는지와 알림에 기록할 관측값을 반환한다.
+// error_repeated, offline은 보고 한 건으로 판단할 수 없으므로 평가하지 않는다.
+func (r *AlertRule) Evaluate(report *DeviceInfo) (bool, float64) {
+	switch r.Kind {
+	case RuleBatteryBelow:
+		value := float64(report.BatteryPercent)
+		return r.MinValue != nil && value < *r.MinValue, value
+	case RuleTemperatureRange:
+		value := report.TemperatureCelsius
+		return (r.MinValue != nil && value < *r.MinValue) || (r.MaxValue != nil && value > *r.MaxValue), value
+	case RuleErrorCode:
+		value := float64(report.ErrorCode)
+		if report.ErrorCode == 0 {
+			return false, value
+		}
+		if len(r.ErrorCodes) == 0 {
+			return true, value
+		}
+		for _, code := range r.ErrorCodes {
+			if code == report.ErrorCode {
+				return true, value
+			}
+		}
+		return false, value
+	}
+	return false, 0
+}
+
+// Message는 규칙 조건에 해당할 때 알림에 남기는 설명이다.
+func (r *AlertRule) Message(value float64) string {
+	switch r.Kind {
+	case RuleBatteryBelow:
+		return fmt.Sprintf("battery %s%% is below %s%%", formatValue(value), formatValue(*r.MinValue))
+	case RuleTemperatureRange:
+		return fmt.Sprintf("temperature %s is outside the allowed range", formatValue(value))
+	case RuleErrorCode:
+		return fmt.Sprintf("error code %s reported", formatValue(value))
+	case RuleErrorRepeated:
+		return fmt.Sprintf("status ERROR reported %d times in a row", r.Count)
+	case RuleOffline:
+		return fmt.Sprintf("no report for %s", time.Duration(value*float64(time.Second)).Truncate(time.Second))
+	}
+	return string(r.Kind)
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// 알림 (DB에 저장되는 모델)
+// 같은 규칙/디바이스의 열린(firing, acknowledged) 알림은 하나만 유지하며, 다시 조건에 해당하면 Occurrences를 늘린다.
+type Alert struct {
+	AlertID        int64
+	RuleID         int64
+	ProductNumber  string
+	Severity       AlertSeverity
+	State          AlertState
+	Message        string
+	ObservedValue  float64 // 마지막으로 조건에 해당한 관측값 (offline은 보고가 없던 시간(초))
+	Occurrences    int64   // 조건에 해당한 횟수
+	FiredAt        time.Time
+	LastSeenAt     time.Time // 마지막으로 조건에 해당한 시간
+	AcknowledgedAt *time.Time
+	AcknowledgedBy string
+	ResolvedAt     *time.Time
+}
+
+// AlertOpenKey는 열린 알림의 중복 방지 키이다. (해소된 알림은 NULL)
+func AlertOpenKey(ruleID int64, productNumber string) string {
+	return strconv.FormatInt(ruleID, 10) + ":" + productNumber
+}
+
+// AlertListQuery는 알림 목록 조회 조건이다.
+type AlertListQuery struct {
+	State         AlertState // 빈 값이면 모든 상태
+	ProductNumber string
+	RuleID        int64
+	Before        int64 // 이 AlertID 이전부터 조회 (최신 순 페이지 이동)
+	Limit         int
+}
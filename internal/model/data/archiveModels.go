@@ -0,0 +1,24 @@
+package data
+
+import (
+	"time"
+)
+
+// 보관 파일 형식
+const ArchiveFormatNDJSONGzip = "ndjson.gz" // 보고 한 건당 JSON 한 줄, gzip 압축
+
+// ReportArchive는 보관 기간이 지나 파일로 보관된 디바이스 하루치 보고의 목록 항목(manifest)이다.
+// 보존 작업이 나누어 처리하므로 같은 디바이스/일에 파일이 여러 개일 수 있다.
+type ReportArchive struct {
+	ArchiveID     int64
+	ProductNumber string
+	DayStart      time.Time // 보고 일자 시작 시간 (UTC)
+	ObjectKey     string    // 저장소 안의 파일 경로
+	Format        string
+	Records       int64  // 보고 수
+	Bytes         int64  // 압축된 파일 크기
+	Checksum      string // 압축된 파일의 SHA-256 (hex)
+	FirstReportAt time.Time
+	LastReportAt  time.Time
+	CreatedAt     time.Time
+}
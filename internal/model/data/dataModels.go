@@ -23,6 +23,8 @@ type Device struct {
 	InternalID 	  int64 // DB에서 사용할 내부 ID auto increments 
 	ProductNumber string     // 사용자가 식별하는 제품 번호 (Unique Key)
 	MacAddress    string        // 디바이스의 MAC 주소 (Unique Key)
+	ProductLine   string        // 제품군 (ProductNumber 앞 3자리 혹은 등록 시 지정)
+	HardwareRevision string     // 하드웨어 리비전 (ProductNumber 4~5번째 자리 혹은 등록 시 지정)
 	FirmwareVersion string  
 	LastSeenAt    time.Time     // 마지막으로 보고를 받은 시간
 	CreatedAt     time.Time 
@@ -45,3 +47,20 @@ type DeviceInfo struct {
 	ReportedStatus     DeviceStatus    // 디바이스가 보고하는 현재 상태 (예: PowerOn)
 }
 
+// ProductNumber 구성 : 제품군(3) + 하드웨어 리비전(2) + 일련번호(4)
+const (
+	productLineLen      = 3
+	hardwareRevisionLen = 2
+)
+
+// ParseProductNumber는 제품 번호에서 제품군과 하드웨어 리비전을 추출한다.
+// 규격보다 짧은 제품 번호는 추출 가능한 부분만 반환한다.
+func ParseProductNumber(productNumber string) (productLine, hardwareRevision string) {
+	if len(productNumber) <= productLineLen {
+		return productNumber, ""
+	}
+
+	productLine = productNumber[:productLineLen]
+	hardwareRevision = productNumber[productLineLen:min(len(productNumber), productLineLen+hardwareRevisionLen)]
+	return productLine, hardwareRevision
+}
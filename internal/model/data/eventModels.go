@@ -0,0 +1,67 @@
+package data
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+// 외부 시스템에 알리는 이벤트 종류
+const (
+	EventAlertFired          EventType = "alert.fired"           // 새 알림 발생 (같은 알림의 반복 발생은 제외)
+	EventAlertAcknowledged   EventType = "alert.acknowledged"    // 알림 확인
+	EventAlertResolved       EventType = "alert.resolved"        // 알림 해소 (조건 해소, 직접 해소, 규칙 변경/삭제)
+	EventDeviceCreated       EventType = "device.created"        // 디바이스 등록
+	EventDeviceStatusChanged EventType = "device.status_changed" // 서버가 판단하는 디바이스 상태 변경
+	EventReportReceived      EventType = "report.received"       // 주기 보고 저장
+	EventFirmwareInstalled   EventType = "firmware.installed"    // 디바이스가 보고한 펌웨어 버전 변경
+	EventWebhookPing         EventType = "webhook.ping"          // 웹훅 수신 확인용 (구독한 이벤트 종류와 관계없이 전송)
+)
+
+// EventTypes는 구독할 수 있는 이벤트 종류이다.
+var EventTypes = []EventType{
+	EventAlertFired,
+	EventAlertAcknowledged,
+	EventAlertResolved,
+	EventDeviceCreated,
+	EventDeviceStatusChanged,
+	EventReportReceived,
+	EventFirmwareInstalled,
+}
+
+// 외부 시스템에 알리는 이벤트
+type Event struct {
+	EventID       string
+	Type          EventType
+	ProductNumber string // 관련 디바이스 (없으면 빈 값)
+	OccurredAt    time.Time
+	Data          json.RawMessage // 이벤트 내용 (JSON)
+}
+
+// NewEvent는 payload를 JSON으로 변환하여 새 이벤트를 만든다.
+func NewEvent(eventType EventType, productNumber string, occurredAt time.Time, payload any) (Event, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		EventID:       uuid.New().String(),
+		Type:          eventType,
+		ProductNumber: productNumber,
+		OccurredAt:    occurredAt,
+		Data:          body,
+	}, nil
+}
+
+// 저장소 변경과 같은 트랜잭션으로 기록되어 전달을 기다리는 이벤트
+type OutboxEvent struct {
+	OutboxID  int64 // 기록 순서
+	Event     Event
+	Attempts  int    // 실패한 전달 시도 횟수
+	LastError string // 마지막 전달 실패 사유
+	CreatedAt time.Time
+}
@@ -0,0 +1,82 @@
+package data
+
+import (
+	"time"
+)
+
+type RolloutState string
+
+// 펌웨어 배포 시 디바이스별 진행 상태
+const (
+	RolloutEligible    RolloutState = "eligible"    // 배포 대상으로 선정됨
+	RolloutOffered     RolloutState = "offered"     // 주기 보고 응답으로 업데이트를 안내함
+	RolloutDownloading RolloutState = "downloading" // 디바이스가 이미지를 내려받기 시작함
+	RolloutInstalled   RolloutState = "installed"   // 이후 보고에서 목표 버전이 확인됨
+	RolloutFailed      RolloutState = "failed"      // 다운로드 이후 오류가 보고됨
+)
+
+// RolloutStates는 진행률 집계 시 사용하는 상태 목록이다.
+var RolloutStates = []RolloutState{
+	RolloutEligible,
+	RolloutOffered,
+	RolloutDownloading,
+	RolloutInstalled,
+	RolloutFailed,
+}
+
+type CampaignStatus string
+
+// 배포 캠페인 상태
+const (
+	CampaignActive     CampaignStatus = "Active"     // 진행 중
+	CampaignRolledBack CampaignStatus = "RolledBack" // 롤백 명령으로 중단됨
+)
+
+// 모든 하드웨어 리비전을 지원함을 나타내는 값
+const AnyHardwareRevision = "*"
+
+// 펌웨어가 지원하는 제품군/하드웨어 리비전 조합
+type FirmwareCompat struct {
+	ProductLine      string
+	HardwareRevision string // AnyHardwareRevision 이면 제품군 전체
+}
+
+// 펌웨어 저장소에 등록된 이미지 정보 (DB에 저장되는 모델)
+type Firmware struct {
+	Version          string           // 펌웨어 버전 (Unique Key)
+	Path             string           // 서버 내 이미지 파일 경로
+	Checksum         string           // 이미지 sha256 (hex)
+	Size             int64            // 이미지 크기 (byte)
+	MinSourceVersion string           // 업데이트 가능한 최소 현재 버전 (빈 값: 제한 없음)
+	Compatibility    []FirmwareCompat // 지원 하드웨어 목록 (비어 있으면 어떤 디바이스에도 배포하지 않음)
+	CreatedAt        time.Time
+}
+
+// 두 펌웨어 버전 사이의 델타 패치 정보 (DB에 저장되는 모델)
+type FirmwareDelta struct {
+	FromVersion string // 패치를 적용할 원본 버전
+	ToVersion   string // 패치 적용 결과 버전
+	Path        string // 서버 내 패치 파일 경로
+	Checksum    string // 패치 파일 sha256 (hex)
+	Size        int64  // 패치 파일 크기 (byte)
+	CreatedAt   time.Time
+}
+
+// 펌웨어 배포 캠페인
+type FirmwareCampaign struct {
+	CampaignID    int64  // DB에서 사용할 내부 ID auto increments
+	TargetVersion string // 배포 목표 버전 (롤백 캠페인은 디바이스별로 목표가 다르므로 비어 있음)
+	RollbackOf    int64  // 롤백 캠페인인 경우 원본 캠페인 ID (0: 일반 캠페인)
+	Status        CampaignStatus
+	CreatedAt     time.Time
+}
+
+// 캠페인 내 디바이스별 배포 상태
+type FirmwareRollout struct {
+	CampaignID    int64
+	ProductNumber string
+	FromVersion   string // 캠페인 시작 시점의 디바이스 버전 (롤백 대상 버전)
+	TargetVersion string // 디바이스가 설치해야 하는 버전
+	State         RolloutState
+	UpdatedAt     time.Time
+}
@@ -0,0 +1,22 @@
+package data
+
+import (
+	"time"
+)
+
+// 디바이스에 안내하는 보고 주기 (DeviceUpdate.ReportCycleSec)
+const ReportCycle = 100 * time.Second
+
+// FleetOverview는 관제 화면용 전체 디바이스 현황이다.
+// 배터리/에러 현황은 오프라인이 아닌 디바이스의 마지막 보고 기준이다.
+type FleetOverview struct {
+	Devices          int64                  // 전체 디바이스 수
+	ByStatus         map[DeviceStatus]int64 // 서버가 판단한 상태별 디바이스 수
+	ByFirmware       map[string]int64       // 펌웨어 버전별 디바이스 수
+	Offline          int64                  // 마지막 보고 후 N 주기 이상 보고가 없는 디바이스 수
+	LowBattery       int64                  // 마지막 보고의 배터리가 기준 이하인 디바이스 수
+	Errors           int64                  // 마지막 보고의 에러 코드가 0이 아닌 디바이스 수
+	ByErrorCode      map[int]int64          // 에러 코드별 디바이스 수
+	ReportsPerMinute []int64                // 최근 1시간 분당 보고 수 (오래된 순, 현재 분 제외)
+	GeneratedAt      time.Time              // 집계 시간
+}
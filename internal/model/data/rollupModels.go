@@ -0,0 +1,87 @@
+package data
+
+import (
+	"time"
+)
+
+type RollupGranularity string
+
+// 주기 보고 요약 단위
+const (
+	RollupHourly RollupGranularity = "hour"
+	RollupDaily  RollupGranularity = "day"
+)
+
+// Duration은 요약 구간의 길이이다.
+func (g RollupGranularity) Duration() time.Duration {
+	if g == RollupDaily {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// BucketStart는 t가 속한 요약 구간의 시작 시간(UTC)이다.
+func (g RollupGranularity) BucketStart(t time.Time) time.Time {
+	return t.UTC().Truncate(g.Duration())
+}
+
+// ReportRollup은 디바이스별 주기 보고의 시간/일 단위 요약이다.
+// 평균은 합계와 건수로 보관하여 나누어 집계한 결과를 합칠 수 있다.
+type ReportRollup struct {
+	ProductNumber  string
+	Granularity    RollupGranularity
+	BucketStart    time.Time // 구간 시작 시간 (UTC)
+	Samples        int64     // 보고 건수
+	BatteryMin     int
+	BatteryMax     int
+	BatterySum     int64
+	TemperatureMin float64
+	TemperatureMax float64
+	TemperatureSum float64
+	ErrorCount     int64   // 에러 코드가 0이 아닌 보고 건수
+	LastLat        float64 // 구간의 마지막 보고 위치
+	LastLon        float64
+	LastReportAt   time.Time // 구간의 마지막 보고 시간
+}
+
+func (r *ReportRollup) BatteryAvg() float64 {
+	if r.Samples == 0 {
+		return 0
+	}
+	return float64(r.BatterySum) / float64(r.Samples)
+}
+
+func (r *ReportRollup) TemperatureAvg() float64 {
+	if r.Samples == 0 {
+		return 0
+	}
+	return r.TemperatureSum / float64(r.Samples)
+}
+
+// Add는 보고 한 건을 요약에 반영한다.
+func (r *ReportRollup) Add(report *DeviceInfo) {
+	if r.Samples == 0 || report.BatteryPercent < r.BatteryMin {
+		r.BatteryMin = report.BatteryPercent
+	}
+	if r.Samples == 0 || report.BatteryPercent > r.BatteryMax {
+		r.BatteryMax = report.BatteryPercent
+	}
+	if r.Samples == 0 || report.TemperatureCelsius < r.TemperatureMin {
+		r.TemperatureMin = report.TemperatureCelsius
+	}
+	if r.Samples == 0 || report.TemperatureCelsius > r.TemperatureMax {
+		r.TemperatureMax = report.TemperatureCelsius
+	}
+	if r.Samples == 0 || !report.ReportAt.Before(r.LastReportAt) {
+		r.LastLat = report.Lat
+		r.LastLon = report.Lon
+		r.LastReportAt = report.ReportAt
+	}
+	if report.ErrorCode != 0 {
+		r.ErrorCount++
+	}
+
+	r.Samples++
+	r.BatterySum += int64(report.BatteryPercent)
+	r.TemperatureSum += report.TemperatureCelsius
+}
@@ -0,0 +1,47 @@
+package data
+
+import (
+	"time"
+)
+
+// DeviceLatestState는 디바이스의 마지막 보고 상태이다. (device_latest_state)
+// 보고 저장 시 함께 갱신하며, 늦게 저장된 이전 보고(WAL 재전송 등)로는 바뀌지 않는다.
+type DeviceLatestState struct {
+	ProductNumber      string
+	BatteryPercent     int
+	Lat                float64
+	Lon                float64
+	TemperatureCelsius float64
+	IP                 string
+	ErrorCode          int
+	ReportedStatus     DeviceStatus
+	ReportAt           time.Time // 마지막 보고 시간
+}
+
+// DeviceWithState는 디바이스와 마지막 보고 상태이다. 보고가 없으면 State는 nil이다.
+type DeviceWithState struct {
+	Device Device
+	State  *DeviceLatestState
+}
+
+// DeviceListQuery는 디바이스 목록 조회 조건이다.
+type DeviceListQuery struct {
+	BatteryBelow int    // 0보다 크면 마지막 보고의 배터리가 이 값 미만인 디바이스만 조회
+	After        string // 이 ProductNumber 다음부터 조회 (페이지 이동)
+	Limit        int
+}
+
+// NewLatestState는 보고 한 건으로 마지막 보고 상태를 만든다.
+func NewLatestState(report *DeviceInfo) DeviceLatestState {
+	return DeviceLatestState{
+		ProductNumber:      report.ProductNumber,
+		BatteryPercent:     report.BatteryPercent,
+		Lat:                report.Lat,
+		Lon:                report.Lon,
+		TemperatureCelsius: report.TemperatureCelsius,
+		IP:                 report.IP,
+		ErrorCode:          report.ErrorCode,
+		ReportedStatus:     report.ReportedStatus,
+		ReportAt:           report.ReportAt,
+	}
+}
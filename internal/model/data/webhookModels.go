@@ -0,0 +1,56 @@
+package data
+
+import (
+	"slices"
+	"time"
+)
+
+// 웹훅 구독 (DB에 저장되는 모델)
+type WebhookSubscription struct {
+	SubscriptionID int64
+	URL            string
+	EventTypes     []EventType // 전송할 이벤트 종류
+	Secret         string      // 서명(HMAC-SHA256) 키
+	Enabled        bool
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// Matches는 구독이 이벤트를 받아야 하는지 여부이다.
+func (s *WebhookSubscription) Matches(eventType EventType) bool {
+	return s.Enabled && slices.Contains(s.EventTypes, eventType)
+}
+
+type DeliveryState string
+
+// 웹훅 전송 상태 : pending -> delivered 또는 최대 시도 횟수를 넘으면 dead
+const (
+	DeliveryPending   DeliveryState = "pending"
+	DeliveryDelivered DeliveryState = "delivered"
+	DeliveryDead      DeliveryState = "dead"
+)
+
+// 웹훅 전송 (DB에 저장되는 모델, 구독별 이벤트 한 건)
+type WebhookDelivery struct {
+	DeliveryID     int64
+	SubscriptionID int64
+	EventID        string
+	EventType      EventType
+	Payload        []byte // 전송할 본문 (재시도 시 같은 본문 전송)
+	State          DeliveryState
+	Attempts       int
+	NextAttemptAt  time.Time // 다음 전송 시도 시간 (pending)
+	LastAttemptAt  *time.Time
+	LastStatusCode int    // 마지막 응답 상태 코드 (응답이 없으면 0)
+	LastError      string // 마지막 실패 사유
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// DeliveryListQuery는 웹훅 전송 기록 조회 조건이다.
+type DeliveryListQuery struct {
+	SubscriptionID int64
+	State          DeliveryState // 빈 값이면 모든 상태
+	Before         int64         // 이 DeliveryID 이전부터 조회 (최신 순 페이지 이동)
+	Limit          int
+}
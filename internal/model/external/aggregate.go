@@ -0,0 +1,168 @@
+package external
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go-rest-example/internal/model/data"
+)
+
+// 오류 타입 선언
+var (
+	errInvalidAggregateRange = errors.New("to must be after from")
+	errInvalidBucket         = errors.New("bucket must be one of 5m, 1h, 1d")
+	errTooManyBuckets        = errors.New("too many buckets in range, use a larger bucket")
+	errInvalidAggregateField = errors.New("fields must be battery or temperature")
+	errInvalidGroupBy        = errors.New("groupBy must be one of status, firmware, group")
+)
+
+// 집계 필드
+const (
+	AggregateFieldBattery     = "battery"
+	AggregateFieldTemperature = "temperature"
+)
+
+// 한 번에 조회 가능한 최대 구간 수
+const maxAggregateBuckets = 1000
+
+// 집계 구간 길이
+var aggregateBuckets = map[string]time.Duration{
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// 보고 집계 조회 요청 DTO (보고 시간 [from, to), RFC3339)
+// Fields는 쉼표로 구분하며 비어 있으면 모든 필드를 집계한다. GroupBy는 플릿 집계에만 사용한다.
+type AggregateReq struct {
+	From    time.Time `form:"from" binding:"required"`
+	To      time.Time `form:"to" binding:"required"`
+	Bucket  string    `form:"bucket" binding:"required"`
+	Fields  string    `form:"fields"`
+	GroupBy string    `form:"groupBy"`
+}
+
+func (r *AggregateReq) Validate() error {
+	if !r.To.After(r.From) {
+		return errInvalidAggregateRange
+	}
+
+	bucket, ok := aggregateBuckets[r.Bucket]
+	if !ok {
+		return errInvalidBucket
+	}
+	if r.To.Sub(r.From)/bucket > maxAggregateBuckets {
+		return errTooManyBuckets
+	}
+
+	for _, field := range r.fields() {
+		if field != AggregateFieldBattery && field != AggregateFieldTemperature {
+			return errInvalidAggregateField
+		}
+	}
+
+	switch data.AggregateGroup(r.GroupBy) {
+	case "", data.AggregateByStatus, data.AggregateByFirmware, data.AggregateByGroup:
+	default:
+		return errInvalidGroupBy
+	}
+
+	return nil
+}
+
+// Query는 집계 조건을 만든다. productNumber가 비어 있으면 플릿 집계이다.
+func (r *AggregateReq) Query(productNumber string) data.AggregateQuery {
+	q := data.AggregateQuery{
+		ProductNumber: productNumber,
+		From:          r.From,
+		To:            r.To,
+		Bucket:        aggregateBuckets[r.Bucket],
+	}
+	if productNumber == "" {
+		q.GroupBy = data.AggregateGroup(r.GroupBy)
+	}
+	return q
+}
+
+func (r *AggregateReq) fields() []string {
+	if r.Fields == "" {
+		return []string{AggregateFieldBattery, AggregateFieldTemperature}
+	}
+
+	fields := strings.Split(r.Fields, ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+// 필드별 구간 통계 DTO
+type AggregateStatRes struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+	Avg float64 `json:"avg"`
+}
+
+// 구간 통계 DTO (요청하지 않은 필드는 생략)
+type AggregateBucketRes struct {
+	BucketStart time.Time         `json:"bucketStart"`
+	Count       int64             `json:"count"`
+	Battery     *AggregateStatRes `json:"battery,omitempty"`
+	Temperature *AggregateStatRes `json:"temperature,omitempty"`
+}
+
+// 그룹별 구간 통계 DTO (그룹 기준이 없으면 하나의 그룹)
+type AggregateSeriesRes struct {
+	Group   string               `json:"group,omitempty"`
+	Buckets []AggregateBucketRes `json:"buckets"`
+}
+
+// 보고 집계 응답 DTO
+type AggregateRes struct {
+	ProductNumber string               `json:"productNumber,omitempty"`
+	GroupBy       string               `json:"groupBy,omitempty"`
+	Bucket        string               `json:"bucket"`
+	From          time.Time            `json:"from"`
+	To            time.Time            `json:"to"`
+	Series        []AggregateSeriesRes `json:"series"`
+}
+
+// NewAggregateRes는 그룹, 구간 순서로 정렬된 집계 결과를 요청한 필드만 포함한 응답 DTO로 변환한다.
+func NewAggregateRes(req *AggregateReq, q *data.AggregateQuery, aggregates []data.ReportAggregate) AggregateRes {
+	from, to := q.Range()
+	res := AggregateRes{
+		ProductNumber: q.ProductNumber,
+		GroupBy:       string(q.GroupBy),
+		Bucket:        req.Bucket,
+		From:          from,
+		To:            to,
+		Series:        []AggregateSeriesRes{},
+	}
+
+	battery, temperature := false, false
+	for _, field := range req.fields() {
+		battery = battery || field == AggregateFieldBattery
+		temperature = temperature || field == AggregateFieldTemperature
+	}
+
+	for i := range aggregates {
+		a := &aggregates[i]
+		if len(res.Series) == 0 || res.Series[len(res.Series)-1].Group != a.Group {
+			res.Series = append(res.Series, AggregateSeriesRes{Group: a.Group})
+		}
+
+		bucket := AggregateBucketRes{BucketStart: a.BucketStart, Count: a.Samples}
+		if battery {
+			bucket.Battery = &AggregateStatRes{Min: float64(a.BatteryMin), Max: float64(a.BatteryMax), Avg: a.BatteryAvg()}
+		}
+		if temperature {
+			bucket.Temperature = &AggregateStatRes{Min: a.TemperatureMin, Max: a.TemperatureMax, Avg: a.TemperatureAvg()}
+		}
+
+		series := &res.Series[len(res.Series)-1]
+		series.Buckets = append(series.Buckets, bucket)
+	}
+
+	return res
+}
@@ -0,0 +1,242 @@
+package external
+
+import (
+	"errors"
+	"time"
+
+	"go-rest-example/internal/model/data"
+)
+
+// 오류 타입 선언
+var (
+	errInvalidRuleName     = errors.New("name must be 1 to 64 characters")
+	errInvalidRuleKind     = errors.New("kind must be one of battery_below, temperature_range, error_code, error_repeated, offline")
+	errInvalidSeverity     = errors.New("severity must be one of info, warning, critical")
+	errInvalidProductLine  = errors.New("productLine must be at most 16 characters")
+	errInvalidBatteryMin   = errors.New("battery_below requires minValue between 1 and 101")
+	errInvalidTempRange    = errors.New("temperature_range requires minValue or maxValue, and minValue must not exceed maxValue")
+	errInvalidErrorCodes   = errors.New("errorCodes must have at most 32 non-zero codes")
+	errInvalidRepeatCount  = errors.New("error_repeated requires count between 1 and 100")
+	errInvalidOfflineCount = errors.New("offline requires count between 1 and 1000")
+	errInvalidAlertState   = errors.New("state must be one of firing, acknowledged, resolved")
+	errInvalidAckBy        = errors.New("by must be 1 to 64 characters")
+)
+
+// 규칙 종류별 제한
+const (
+	maxRuleErrorCodes   = 32
+	maxRuleRepeatCount  = 100
+	maxRuleOfflineCount = 1000
+)
+
+// 알림 규칙 생성/변경 요청 DTO
+// 규칙 종류에 필요한 항목만 저장한다.
+//   - battery_below     : minValue (배터리 % 미만)
+//   - temperature_range : minValue, maxValue (하나만 지정 가능)
+//   - error_code        : errorCodes (비어 있으면 0이 아닌 모든 에러 코드)
+//   - error_repeated    : count (연속 ERROR 보고 수)
+//   - offline           : count (보고 없이 지난 보고 주기 수)
+type AlertRuleReq struct {
+	Name        string             `json:"name" binding:"required"`
+	Kind        data.AlertRuleKind `json:"kind" binding:"required"`
+	Severity    data.AlertSeverity `json:"severity"`    // 기본값 warning
+	ProductLine string             `json:"productLine"` // 적용 제품군 (빈 값: 모든 디바이스)
+	MinValue    *float64           `json:"minValue"`
+	MaxValue    *float64           `json:"maxValue"`
+	ErrorCodes  []int              `json:"errorCodes"`
+	Count       int                `json:"count"`
+	Enabled     *bool              `json:"enabled"` // 기본값 true
+}
+
+func (r *AlertRuleReq) Validate() error {
+	if len(r.Name) == 0 || len(r.Name) > 64 {
+		return errInvalidRuleName
+	}
+
+	switch r.Severity {
+	case "", data.SeverityInfo, data.SeverityWarning, data.SeverityCritical:
+	default:
+		return errInvalidSeverity
+	}
+
+	if len(r.ProductLine) > 16 {
+		return errInvalidProductLine
+	}
+
+	switch r.Kind {
+	case data.RuleBatteryBelow:
+		if r.MinValue == nil || *r.MinValue < 1 || *r.MinValue > 101 {
+			return errInvalidBatteryMin
+		}
+	case data.RuleTemperatureRange:
+		if (r.MinValue == nil && r.MaxValue == nil) || (r.MinValue != nil && r.MaxValue != nil && *r.MinValue > *r.MaxValue) {
+			return errInvalidTempRange
+		}
+	case data.RuleErrorCode:
+		if len(r.ErrorCodes) > maxRuleErrorCodes {
+			return errInvalidErrorCodes
+		}
+		for _, code := range r.ErrorCodes {
+			if code == 0 {
+				return errInvalidErrorCodes
+			}
+		}
+	case data.RuleErrorRepeated:
+		if r.Count < 1 || r.Count > maxRuleRepeatCount {
+			return errInvalidRepeatCount
+		}
+	case data.RuleOffline:
+		if r.Count < 1 || r.Count > maxRuleOfflineCount {
+			return errInvalidOfflineCount
+		}
+	default:
+		return errInvalidRuleKind
+	}
+
+	return nil
+}
+
+// Rule은 요청을 규칙 모델로 변환한다. (규칙 종류에 쓰이지 않는 항목은 버림)
+func (r *AlertRuleReq) Rule(ruleID int64) data.AlertRule {
+	rule := data.AlertRule{
+		RuleID:      ruleID,
+		Name:        r.Name,
+		Kind:        r.Kind,
+		Severity:    r.Severity,
+		ProductLine: r.ProductLine,
+		Enabled:     r.Enabled == nil || *r.Enabled,
+	}
+	if rule.Severity == "" {
+		rule.Severity = data.SeverityWarning
+	}
+
+	switch r.Kind {
+	case data.RuleBatteryBelow:
+		rule.MinValue = r.MinValue
+	case data.RuleTemperatureRange:
+		rule.MinValue, rule.MaxValue = r.MinValue, r.MaxValue
+	case data.RuleErrorCode:
+		rule.ErrorCodes = r.ErrorCodes
+	case data.RuleErrorRepeated, data.RuleOffline:
+		rule.Count = r.Count
+	}
+
+	return rule
+}
+
+// 알림 규칙 응답 DTO
+type AlertRuleRes struct {
+	RuleID      int64              `json:"ruleID"`
+	Name        string             `json:"name"`
+	Kind        data.AlertRuleKind `json:"kind"`
+	Severity    data.AlertSeverity `json:"severity"`
+	ProductLine string             `json:"productLine,omitempty"`
+	MinValue    *float64           `json:"minValue,omitempty"`
+	MaxValue    *float64           `json:"maxValue,omitempty"`
+	ErrorCodes  []int              `json:"errorCodes,omitempty"`
+	Count       int                `json:"count,omitempty"`
+	Enabled     bool               `json:"enabled"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+}
+
+func NewAlertRuleRes(rule *data.AlertRule) AlertRuleRes {
+	return AlertRuleRes{
+		RuleID:      rule.RuleID,
+		Name:        rule.Name,
+		Kind:        rule.Kind,
+		Severity:    rule.Severity,
+		ProductLine: rule.ProductLine,
+		MinValue:    rule.MinValue,
+		MaxValue:    rule.MaxValue,
+		ErrorCodes:  rule.ErrorCodes,
+		Count:       rule.Count,
+		Enabled:     rule.Enabled,
+		CreatedAt:   rule.CreatedAt,
+		UpdatedAt:   rule.UpdatedAt,
+	}
+}
+
+// 알림 목록 조회 요청 DTO (최신 순서, before로 다음 페이지 조회)
+type AlertListReq struct {
+	State         data.AlertState `form:"state"`
+	ProductNumber string          `form:"productNumber"`
+	RuleID        int64           `form:"ruleID"`
+	Before        int64           `form:"before"` // 이전 페이지의 마지막 alertID
+	Limit         int             `form:"limit"`  // 기본값 100, 최대 1000
+}
+
+func (r *AlertListReq) Validate() error {
+	switch r.State {
+	case "", data.AlertFiring, data.AlertAcknowledged, data.AlertResolved:
+	default:
+		return errInvalidAlertState
+	}
+
+	if len(r.ProductNumber) > 9 {
+		return errInvalidProductNumber
+	}
+
+	if r.Limit < 0 || r.Limit > 1000 {
+		return errInvalidListLimit
+	}
+
+	return nil
+}
+
+func (r *AlertListReq) Query() data.AlertListQuery {
+	return data.AlertListQuery{
+		State:         r.State,
+		ProductNumber: r.ProductNumber,
+		RuleID:        r.RuleID,
+		Before:        r.Before,
+		Limit:         r.Limit,
+	}
+}
+
+// 알림 확인 요청 DTO
+type AlertAckReq struct {
+	By string `json:"by" binding:"required"` // 확인한 담당자
+}
+
+func (r *AlertAckReq) Validate() error {
+	if len(r.By) == 0 || len(r.By) > 64 {
+		return errInvalidAckBy
+	}
+	return nil
+}
+
+// 알림 응답 DTO
+type AlertRes struct {
+	AlertID        int64              `json:"alertID"`
+	RuleID         int64              `json:"ruleID"`
+	ProductNumber  string             `json:"productNumber"`
+	Severity       data.AlertSeverity `json:"severity"`
+	State          data.AlertState    `json:"state"`
+	Message        string             `json:"message"`
+	ObservedValue  float64            `json:"observedValue"`
+	Occurrences    int64              `json:"occurrences"`
+	FiredAt        time.Time          `json:"firedAt"`
+	LastSeenAt     time.Time          `json:"lastSeenAt"`
+	AcknowledgedAt *time.Time         `json:"acknowledgedAt,omitempty"`
+	AcknowledgedBy string             `json:"acknowledgedBy,omitempty"`
+	ResolvedAt     *time.Time         `json:"resolvedAt,omitempty"`
+}
+
+func NewAlertRes(a *data.Alert) AlertRes {
+	return AlertRes{
+		AlertID:        a.AlertID,
+		RuleID:         a.RuleID,
+		ProductNumber:  a.ProductNumber,
+		Severity:       a.Severity,
+		State:          a.State,
+		Message:        a.Message,
+		ObservedValue:  a.ObservedValue,
+		Occurrences:    a.Occurrences,
+		FiredAt:        a.FiredAt,
+		LastSeenAt:     a.LastSeenAt,
+		AcknowledgedAt: a.AcknowledgedAt,
+		AcknowledgedBy: a.AcknowledgedBy,
+		ResolvedAt:     a.ResolvedAt,
+	}
+}
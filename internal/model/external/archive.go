@@ -0,0 +1,40 @@
+package external
+
+import (
+	"errors"
+	"time"
+)
+
+// 오류 타입 선언
+var (
+	errInvalidArchiveRange  = errors.New("to must be after from")
+	errArchiveRangeTooLarge = errors.New("archive range must not exceed 31 days")
+	errInvalidProductNumber = errors.New("invalid productNumber")
+)
+
+// 한 번에 조회/복원 가능한 최대 기간
+const maxArchiveRange = 31 * 24 * time.Hour
+
+// 보관 파일 조회 및 복원 요청 DTO (보고 시간 [from, to), RFC3339)
+// ProductNumber가 비어 있으면 모든 디바이스가 대상이 된다.
+type ArchiveRangeReq struct {
+	From          time.Time `json:"from" form:"from" binding:"required"`
+	To            time.Time `json:"to" form:"to" binding:"required"`
+	ProductNumber string    `json:"productNumber" form:"productNumber"`
+}
+
+func (r *ArchiveRangeReq) Validate() error {
+	if !r.To.After(r.From) {
+		return errInvalidArchiveRange
+	}
+
+	if r.To.Sub(r.From) > maxArchiveRange {
+		return errArchiveRangeTooLarge
+	}
+
+	if len(r.ProductNumber) > 9 {
+		return errInvalidProductNumber
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package external
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"go-rest-example/internal/model/data"
+)
+
+// 오류 타입 선언
+var (
+	errInvalidBatteryBelow = errors.New("batteryBelow must be between 1 and 101")
+	errInvalidListLimit    = errors.New("limit must be between 1 and 1000")
+	errInvalidInclude      = errors.New("include must be state")
+)
+
+// 디바이스 목록에 포함할 수 있는 선택 항목
+const DeviceIncludeState = "state"
+
+// 디바이스 목록 조회 요청 DTO
+// BatteryBelow를 지정하면 마지막 보고의 배터리가 그 값 미만인 디바이스만 조회하며, 마지막 보고 상태를 함께 반환한다.
+type DeviceListReq struct {
+	Include      string `form:"include"`      // state : 마지막 보고 상태 포함
+	BatteryBelow int    `form:"batteryBelow"` // 배터리 조건 (%)
+	After        string `form:"after"`        // 이전 페이지의 마지막 ProductNumber
+	Limit        int    `form:"limit"`        // 기본값 100, 최대 1000
+}
+
+func (r *DeviceListReq) Validate() error {
+	if r.Include != "" && strings.TrimSpace(r.Include) != DeviceIncludeState {
+		return errInvalidInclude
+	}
+
+	if r.BatteryBelow < 0 || r.BatteryBelow > 101 {
+		return errInvalidBatteryBelow
+	}
+
+	if r.Limit < 0 || r.Limit > 1000 {
+		return errInvalidListLimit
+	}
+
+	return nil
+}
+
+// IncludeState는 응답에 마지막 보고 상태를 포함할지 여부이다.
+func (r *DeviceListReq) IncludeState() bool {
+	return r.Include != "" || r.BatteryBelow > 0
+}
+
+func (r *DeviceListReq) Query() data.DeviceListQuery {
+	return data.DeviceListQuery{
+		BatteryBelow: r.BatteryBelow,
+		After:        r.After,
+		Limit:        r.Limit,
+	}
+}
+
+// 디바이스 마지막 보고 상태 DTO
+type LatestStateRes struct {
+	BatteryPercent     int               `json:"batteryPercent"`
+	Lat                float64           `json:"lat"`
+	Lon                float64           `json:"lon"`
+	TemperatureCelsius float64           `json:"temperatureCelsius"`
+	IP                 string            `json:"ip"`
+	ErrorCode          int               `json:"errorCode"`
+	ReportedStatus     data.DeviceStatus `json:"reportedStatus"`
+	ReportAt           time.Time         `json:"reportAt"`
+}
+
+// 디바이스 조회 응답 DTO (보고가 없거나 요청하지 않으면 latestState 생략)
+type DeviceRes struct {
+	ProductNumber    string            `json:"productNumber"`
+	MacAddress       string            `json:"macAddress"`
+	ProductLine      string            `json:"productLine"`
+	HardwareRevision string            `json:"hardwareRevision"`
+	FirmwareVersion  string            `json:"firmwareVersion"`
+	Status           data.DeviceStatus `json:"status"`
+	LastSeenAt       time.Time         `json:"lastSeenAt"`
+	CreatedAt        time.Time         `json:"createdAt"`
+	LatestState      *LatestStateRes   `json:"latestState,omitempty"`
+}
+
+// NewDeviceRes는 디바이스와 마지막 보고 상태를 응답 DTO로 변환한다.
+func NewDeviceRes(ds *data.DeviceWithState, includeState bool) DeviceRes {
+	d := &ds.Device
+	res := DeviceRes{
+		ProductNumber:    d.ProductNumber,
+		MacAddress:       d.MacAddress,
+		ProductLine:      d.ProductLine,
+		HardwareRevision: d.HardwareRevision,
+		FirmwareVersion:  d.FirmwareVersion,
+		Status:           d.Status,
+		LastSeenAt:       d.LastSeenAt,
+		CreatedAt:        d.CreatedAt,
+	}
+
+	if includeState && ds.State != nil {
+		s := ds.State
+		res.LatestState = &LatestStateRes{
+			BatteryPercent:     s.BatteryPercent,
+			Lat:                s.Lat,
+			Lon:                s.Lon,
+			TemperatureCelsius: s.TemperatureCelsius,
+			IP:                 s.IP,
+			ErrorCode:          s.ErrorCode,
+			ReportedStatus:     s.ReportedStatus,
+			ReportAt:           s.ReportAt,
+		}
+	}
+
+	return res
+}
@@ -23,11 +23,18 @@ type APIError struct {
 	ErrorCode      string  `json:"errorCode"`
 }
 
+// 상태 확인 응답 DTO
+type HealthRes struct {
+	Database string `json:"database"` // up | down
+	Breaker  string `json:"breaker"`  // 데이터베이스 서킷 브레이커 상태 (closed | open | half-open)
+}
+
 // DeviceUpdate는 서버가 디바이스에 응답으로 보내는 제어 정보 (DTO)
 type DeviceUpdate struct {
 	ReportCycleSec int  // 보고 주기 (초 단위)
 	PowerOff       int // 원격 종료 명령
 	Reboot         int // 원격 재부팅 명령
+	UpdateVersion  string // 설치해야 하는 펌웨어 버전 (배포 대상이 아니면 빈 값)
 }
 
 // 요청 DTO 
@@ -35,6 +42,8 @@ type DeviceReq struct {
 	ProductNumber string     // 사용자가 식별하는 제품 번호 (Unique Key)
 	MacAddress    string     // 디바이스의 MAC 주소 (Unique Key)
 	FirmwareVersion string  
+	ProductLine   string     // 제품군 (선택, 없으면 ProductNumber에서 추출)
+	HardwareRevision string  // 하드웨어 리비전 (선택, 없으면 ProductNumber에서 추출)
 }
 
 func (d *DeviceReq)Validate() error{
@@ -73,6 +82,7 @@ type ReportReq struct {
 	IP                 string            `json:"ip" binding:"required,ip"`
 	ErrorCode          int               `json:"errorCode"` 
 	ReportedStatus     data.DeviceStatus `json:"reportedStatus" binding:"required"`
+	FirmwareVersion    string            `json:"firmwareVersion"` // 현재 동작 중인 펌웨어 버전 (배포 진행 추적용)
 }
 
 // 복합 조건 검증 수행 
@@ -98,6 +108,13 @@ func(r *ReportReq)Validate() error {
 		return errors.New("invalide to Name lange")
 	}
 
+	// 펌웨어 버전은 선택 항목이므로 입력된 경우에만 형식 검사 
+	if r.FirmwareVersion != "" {
+		if err := ValidateFirmwareVersion(r.FirmwareVersion); err != nil {
+			return err
+		}
+	}
+
 	return nil
 
 }
@@ -107,6 +124,7 @@ type UpdateDeviceParams struct {
     FirmwareVersion *string
     LastSeenAt      *time.Time
     ReTry           *int
+    ReTryAdd        *int // 저장된 ReTry에 더할 값 (ReTry와 함께 지정하면 ReTry가 우선)
     UpdateCheck     *int
     Status          *data.DeviceStatus
 }
\ No newline at end of file
@@ -0,0 +1,149 @@
+package external
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"go-rest-example/internal/model/data"
+)
+
+// 오류 타입 선언
+var (
+	errInvalidFirmwareVersion = errors.New("invalid firmware version format")
+	errTooManyProducts        = errors.New("too many productNumbers in campaign request")
+	errSameDeltaVersion       = errors.New("fromVersion and toVersion must differ")
+	errCompatRequired         = errors.New("at least one compatibility entry is required")
+	errInvalidCompat          = errors.New("compatibility must be in PRODUCTLINE:REVISION format")
+)
+
+// 캠페인 요청 당 지정 가능한 최대 디바이스 수
+const maxCampaignProducts = 1000
+
+var firmwareVersionPattern = regexp.MustCompile(`^[0-9]+\.\d\d\.\d\d$`)
+
+// ValidateFirmwareVersion은 펌웨어 버전 문자열 형식(예: 1.02.03)을 검사한다.
+func ValidateFirmwareVersion(v string) error {
+	if !firmwareVersionPattern.MatchString(v) {
+		return errInvalidFirmwareVersion
+	}
+	return nil
+}
+
+// ParseFirmwareCompat은 "제품군:리비전" 형식의 호환 목록을 해석한다.
+// 리비전 자리에 "*"를 지정하면 제품군의 모든 하드웨어 리비전을 지원한다.
+func ParseFirmwareCompat(values []string) ([]data.FirmwareCompat, error) {
+	if len(values) == 0 {
+		return nil, errCompatRequired
+	}
+
+	compat := make([]data.FirmwareCompat, 0, len(values))
+	for _, v := range values {
+		productLine, hardwareRevision, ok := strings.Cut(strings.TrimSpace(v), ":")
+		if !ok || productLine == "" || hardwareRevision == "" {
+			return nil, errInvalidCompat
+		}
+		compat = append(compat, data.FirmwareCompat{
+			ProductLine:      productLine,
+			HardwareRevision: hardwareRevision,
+		})
+	}
+
+	return compat, nil
+}
+
+// 펌웨어 배포 캠페인 생성 요청 DTO
+// ProductNumbers가 비어 있으면 목표 버전이 아닌 모든 디바이스가 대상이 된다.
+type CampaignReq struct {
+	TargetVersion  string   `json:"targetVersion" binding:"required"`
+	ProductNumbers []string `json:"productNumbers"`
+}
+
+func (r *CampaignReq) Validate() error {
+	if err := ValidateFirmwareVersion(r.TargetVersion); err != nil {
+		return err
+	}
+
+	if len(r.ProductNumbers) > maxCampaignProducts {
+		return errTooManyProducts
+	}
+
+	return nil
+}
+
+// 델타 패치 생성 요청 DTO
+type DeltaReq struct {
+	FromVersion string `json:"fromVersion" binding:"required"`
+	ToVersion   string `json:"toVersion" binding:"required"`
+}
+
+func (r *DeltaReq) Validate() error {
+	if err := ValidateFirmwareVersion(r.FromVersion); err != nil {
+		return err
+	}
+	if err := ValidateFirmwareVersion(r.ToVersion); err != nil {
+		return err
+	}
+	if r.FromVersion == r.ToVersion {
+		return errSameDeltaVersion
+	}
+	return nil
+}
+
+// 델타 패치 응답 DTO
+type DeltaRes struct {
+	FromVersion string `json:"fromVersion"`
+	ToVersion   string `json:"toVersion"`
+	Checksum    string `json:"checksum"`
+	Size        int64  `json:"size"`
+}
+
+// 펌웨어 호환 하드웨어 DTO
+type FirmwareCompatRes struct {
+	ProductLine      string `json:"productLine"`
+	HardwareRevision string `json:"hardwareRevision"`
+}
+
+// 펌웨어 등록 응답 DTO
+type FirmwareRes struct {
+	Version          string              `json:"version"`
+	Checksum         string              `json:"checksum"`
+	Size             int64               `json:"size"`
+	MinSourceVersion string              `json:"minSourceVersion,omitempty"`
+	Compatibility    []FirmwareCompatRes `json:"compatibility"`
+}
+
+// NewFirmwareRes는 저장소 모델을 응답 DTO로 변환한다.
+func NewFirmwareRes(fw *data.Firmware) FirmwareRes {
+	compat := make([]FirmwareCompatRes, 0, len(fw.Compatibility))
+	for _, c := range fw.Compatibility {
+		compat = append(compat, FirmwareCompatRes{ProductLine: c.ProductLine, HardwareRevision: c.HardwareRevision})
+	}
+
+	return FirmwareRes{
+		Version:          fw.Version,
+		Checksum:         fw.Checksum,
+		Size:             fw.Size,
+		MinSourceVersion: fw.MinSourceVersion,
+		Compatibility:    compat,
+	}
+}
+
+// 캠페인 생성 및 롤백 응답 DTO
+type CampaignRes struct {
+	CampaignID    int64               `json:"campaignId"`
+	TargetVersion string              `json:"targetVersion"`
+	RollbackOf    int64               `json:"rollbackOf,omitempty"`
+	Status        data.CampaignStatus `json:"status"`
+	Devices       int64               `json:"devices"`                // 배포 대상으로 선정된 디바이스 수
+	Incompatible  int                 `json:"incompatible,omitempty"` // 호환되지 않아 제외된 디바이스 수
+}
+
+// 캠페인 진행률 응답 DTO
+type CampaignProgress struct {
+	CampaignID    int64                     `json:"campaignId"`
+	TargetVersion string                    `json:"targetVersion"`
+	Status        data.CampaignStatus       `json:"status"`
+	Total         int                       `json:"total"`
+	Counts        map[data.RolloutState]int `json:"counts"`
+}
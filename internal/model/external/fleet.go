@@ -0,0 +1,47 @@
+package external
+
+import (
+	"time"
+
+	"go-rest-example/internal/model/data"
+)
+
+// 전체 디바이스 현황 응답 DTO
+type FleetOverviewRes struct {
+	Devices          int64                       `json:"devices"`
+	ByStatus         map[data.DeviceStatus]int64 `json:"byStatus"`
+	ByFirmware       map[string]int64            `json:"byFirmware"`
+	Offline          int64                       `json:"offline"`
+	LowBattery       int64                       `json:"lowBattery"`
+	Errors           int64                       `json:"errors"`
+	ByErrorCode      map[int]int64               `json:"byErrorCode"`
+	ReportsPerMinute []int64                     `json:"reportsPerMinute"` // 최근 1시간, 오래된 순
+	ReportsPerMinAvg float64                     `json:"reportsPerMinuteAvg"`
+	GeneratedAt      time.Time                   `json:"generatedAt"`
+}
+
+// NewFleetOverviewRes는 집계 결과를 응답 DTO로 변환한다.
+func NewFleetOverviewRes(o *data.FleetOverview) FleetOverviewRes {
+	var total int64
+	for _, count := range o.ReportsPerMinute {
+		total += count
+	}
+
+	avg := 0.0
+	if len(o.ReportsPerMinute) > 0 {
+		avg = float64(total) / float64(len(o.ReportsPerMinute))
+	}
+
+	return FleetOverviewRes{
+		Devices:          o.Devices,
+		ByStatus:         o.ByStatus,
+		ByFirmware:       o.ByFirmware,
+		Offline:          o.Offline,
+		LowBattery:       o.LowBattery,
+		Errors:           o.Errors,
+		ByErrorCode:      o.ByErrorCode,
+		ReportsPerMinute: o.ReportsPerMinute,
+		ReportsPerMinAvg: avg,
+		GeneratedAt:      o.GeneratedAt,
+	}
+}
@@ -0,0 +1,86 @@
+package external
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"go-rest-example/internal/model/data"
+)
+
+// 오류 타입 선언
+var (
+	errInvalidStreamTypes    = errors.New("types must be report.received, device.status_changed or firmware.installed")
+	errTooManyStreamProducts = errors.New("productNumbers must have at most 100 product numbers")
+)
+
+// 한 스트림에서 지정할 수 있는 최대 디바이스 수
+const maxStreamProductNumbers = 100
+
+// StreamEventTypes는 실시간 스트림으로 전달하는 이벤트 종류이다.
+var StreamEventTypes = []data.EventType{
+	data.EventReportReceived,
+	data.EventDeviceStatusChanged,
+	data.EventFirmwareInstalled,
+}
+
+// 실시간 스트림 요청 DTO
+// Types, ProductNumbers는 쉼표로 구분하며 비어 있으면 모든 이벤트 종류/디바이스를 전달한다.
+// ProductNumbers와 ProductLine은 전체 디바이스 스트림에만 사용한다.
+type StreamReq struct {
+	Types          string `form:"types"`
+	ProductNumbers string `form:"productNumbers"`
+	ProductLine    string `form:"productLine"`
+}
+
+func (r *StreamReq) Validate() error {
+	for _, t := range r.EventTypes() {
+		if !slices.Contains(StreamEventTypes, t) {
+			return errInvalidStreamTypes
+		}
+	}
+
+	if len(r.ProductNumberList()) > maxStreamProductNumbers {
+		return errTooManyStreamProducts
+	}
+
+	if len(r.ProductLine) > 16 {
+		return errInvalidProductLine
+	}
+
+	return nil
+}
+
+// EventTypes는 전달할 이벤트 종류이다. 지정하지 않으면 실시간 스트림의 모든 이벤트 종류이다.
+func (r *StreamReq) EventTypes() []data.EventType {
+	values := splitList(r.Types)
+	if len(values) == 0 {
+		return StreamEventTypes
+	}
+
+	eventTypes := make([]data.EventType, len(values))
+	for i, v := range values {
+		eventTypes[i] = data.EventType(v)
+	}
+	return eventTypes
+}
+
+func (r *StreamReq) ProductNumberList() []string {
+	return splitList(r.ProductNumbers)
+}
+
+// 쉼표로 구분된 값 목록 (빈 값 제외)
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// 스트림 종료 안내 DTO
+type StreamCloseRes struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,236 @@
+package external
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"time"
+
+	"go-rest-example/internal/model/data"
+)
+
+// 오류 타입 선언
+var (
+	errInvalidWebhookURL    = errors.New("url must be an absolute http or https URL of at most 512 characters")
+	errInvalidEventTypes    = errors.New("eventTypes must have 1 or more of alert.fired, alert.acknowledged, alert.resolved, device.created, device.status_changed, report.received, firmware.installed")
+	errInvalidWebhookSecret = errors.New("secret must be 16 to 128 characters")
+	errInvalidDeliveryState = errors.New("state must be one of pending, delivered, dead")
+)
+
+// 웹훅 구독 제한
+const (
+	maxWebhookURLLength    = 512
+	minWebhookSecretLength = 16
+	maxWebhookSecretLength = 128
+)
+
+// 웹훅 구독 생성/변경 요청 DTO
+// secret을 지정하지 않으면 생성 시 서버가 만들고, 변경 시 기존 값을 유지한다.
+type WebhookReq struct {
+	URL        string           `json:"url" binding:"required"`
+	EventTypes []data.EventType `json:"eventTypes" binding:"required"`
+	Secret     string           `json:"secret"`
+	Enabled    *bool            `json:"enabled"` // 기본값 true
+}
+
+func (r *WebhookReq) Validate() error {
+	u, err := url.Parse(r.URL)
+	if err != nil || len(r.URL) > maxWebhookURLLength || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errInvalidWebhookURL
+	}
+
+	if len(r.EventTypes) == 0 {
+		return errInvalidEventTypes
+	}
+	for _, t := range r.EventTypes {
+		if !slices.Contains(data.EventTypes, t) {
+			return errInvalidEventTypes
+		}
+	}
+
+	if r.Secret != "" && (len(r.Secret) < minWebhookSecretLength || len(r.Secret) > maxWebhookSecretLength) {
+		return errInvalidWebhookSecret
+	}
+
+	return nil
+}
+
+// Subscription은 요청을 구독 모델로 변환한다. (중복된 이벤트 종류는 제거)
+func (r *WebhookReq) Subscription(subscriptionID int64) data.WebhookSubscription {
+	eventTypes := slices.Clone(r.EventTypes)
+	slices.Sort(eventTypes)
+
+	return data.WebhookSubscription{
+		SubscriptionID: subscriptionID,
+		URL:            r.URL,
+		EventTypes:     slices.Compact(eventTypes),
+		Secret:         r.Secret,
+		Enabled:        r.Enabled == nil || *r.Enabled,
+	}
+}
+
+// 웹훅 구독 응답 DTO (서명 키는 생성 응답에서만 반환)
+type WebhookRes struct {
+	SubscriptionID int64            `json:"subscriptionID"`
+	URL            string           `json:"url"`
+	EventTypes     []data.EventType `json:"eventTypes"`
+	Secret         string           `json:"secret,omitempty"`
+	Enabled        bool             `json:"enabled"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	UpdatedAt      time.Time        `json:"updatedAt"`
+}
+
+func NewWebhookRes(sub *data.WebhookSubscription, includeSecret bool) WebhookRes {
+	res := WebhookRes{
+		SubscriptionID: sub.SubscriptionID,
+		URL:            sub.URL,
+		EventTypes:     sub.EventTypes,
+		Enabled:        sub.Enabled,
+		CreatedAt:      sub.CreatedAt,
+		UpdatedAt:      sub.UpdatedAt,
+	}
+	if includeSecret {
+		res.Secret = sub.Secret
+	}
+	return res
+}
+
+// 웹훅 전송 기록 조회 요청 DTO (최신 순서, before로 다음 페이지 조회)
+type DeliveryListReq struct {
+	State  data.DeliveryState `form:"state"`
+	Before int64              `form:"before"` // 이전 페이지의 마지막 deliveryID
+	Limit  int                `form:"limit"`  // 기본값 100, 최대 1000
+}
+
+func (r *DeliveryListReq) Validate() error {
+	switch r.State {
+	case "", data.DeliveryPending, data.DeliveryDelivered, data.DeliveryDead:
+	default:
+		return errInvalidDeliveryState
+	}
+
+	if r.Limit < 0 || r.Limit > 1000 {
+		return errInvalidListLimit
+	}
+
+	return nil
+}
+
+func (r *DeliveryListReq) Query(subscriptionID int64) data.DeliveryListQuery {
+	return data.DeliveryListQuery{
+		SubscriptionID: subscriptionID,
+		State:          r.State,
+		Before:         r.Before,
+		Limit:          r.Limit,
+	}
+}
+
+// 웹훅 전송 기록 응답 DTO
+type DeliveryRes struct {
+	DeliveryID     int64              `json:"deliveryID"`
+	SubscriptionID int64              `json:"subscriptionID"`
+	EventID        string             `json:"eventID"`
+	EventType      data.EventType     `json:"eventType"`
+	State          data.DeliveryState `json:"state"`
+	Attempts       int                `json:"attempts"`
+	NextAttemptAt  *time.Time         `json:"nextAttemptAt,omitempty"` // pending 상태에서만 반환
+	LastAttemptAt  *time.Time         `json:"lastAttemptAt,omitempty"`
+	LastStatusCode int                `json:"lastStatusCode,omitempty"`
+	LastError      string             `json:"lastError,omitempty"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	DeliveredAt    *time.Time         `json:"deliveredAt,omitempty"`
+	Payload        json.RawMessage    `json:"payload"`
+}
+
+func NewDeliveryRes(d *data.WebhookDelivery) DeliveryRes {
+	res := DeliveryRes{
+		DeliveryID:     d.DeliveryID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		State:          d.State,
+		Attempts:       d.Attempts,
+		LastAttemptAt:  d.LastAttemptAt,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+		Payload:        d.Payload,
+	}
+	if d.State == data.DeliveryPending {
+		next := d.NextAttemptAt
+		res.NextAttemptAt = &next
+	}
+	return res
+}
+
+// 웹훅 수신 확인 응답 DTO
+type WebhookTestRes struct {
+	Delivered  bool   `json:"delivered"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Duration   string `json:"duration"`
+}
+
+// 외부 시스템에 전송하는 이벤트 본문
+type EventRes struct {
+	EventID       string          `json:"id"`
+	Type          data.EventType  `json:"type"`
+	ProductNumber string          `json:"productNumber,omitempty"`
+	OccurredAt    time.Time       `json:"occurredAt"`
+	Data          json.RawMessage `json:"data"`
+}
+
+func NewEventRes(e *data.Event) EventRes {
+	return EventRes{
+		EventID:       e.EventID,
+		Type:          e.Type,
+		ProductNumber: e.ProductNumber,
+		OccurredAt:    e.OccurredAt,
+		Data:          e.Data,
+	}
+}
+
+// device.status_changed 이벤트 내용
+type DeviceStatusChangedRes struct {
+	ProductNumber  string            `json:"productNumber"`
+	PreviousStatus data.DeviceStatus `json:"previousStatus"`
+	Status         data.DeviceStatus `json:"status"`
+	ChangedAt      time.Time         `json:"changedAt"` // 상태를 바꾼 보고의 보고 시간
+}
+
+// report.received 이벤트 내용
+type ReportRes struct {
+	ProductNumber      string            `json:"productNumber"`
+	BatteryPercent     int               `json:"batteryPercent"`
+	Lat                float64           `json:"lat"`
+	Lon                float64           `json:"lon"`
+	TemperatureCelsius float64           `json:"temperatureCelsius"`
+	IP                 string            `json:"ip"`
+	ErrorCode          int               `json:"errorCode"`
+	ReportedStatus     data.DeviceStatus `json:"reportedStatus"`
+	ReportAt           time.Time         `json:"reportAt"`
+}
+
+func NewReportRes(r *data.DeviceInfo) ReportRes {
+	return ReportRes{
+		ProductNumber:      r.ProductNumber,
+		BatteryPercent:     r.BatteryPercent,
+		Lat:                r.Lat,
+		Lon:                r.Lon,
+		TemperatureCelsius: r.TemperatureCelsius,
+		IP:                 r.IP,
+		ErrorCode:          r.ErrorCode,
+		ReportedStatus:     r.ReportedStatus,
+		ReportAt:           r.ReportAt,
+	}
+}
+
+// firmware.installed 이벤트 내용
+type FirmwareInstalledRes struct {
+	ProductNumber   string    `json:"productNumber"`
+	PreviousVersion string    `json:"previousVersion,omitempty"` // 이전에 보고한 버전이 없으면 생략
+	Version         string    `json:"version"`
+	InstalledAt     time.Time `json:"installedAt"` // 새 버전을 보고한 보고의 보고 시간
+}
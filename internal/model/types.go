@@ -1,12 +1,80 @@
 package model
 
+import "time"
+
 type ServiceEnv struct {
 	Name string   // 서비스 환경 이름
 	Host string   // 호스트 정보  
 	User string    // 유저 정보
 	Password string // 로그인 정보 
+	AdminToken string // 운영자 API 인증 토큰 (Authorization: Bearer <token>, 비어 있으면 운영자 API 요청을 모두 거부)
 	Port string   // 포트 번호
+	DBDriver string // 데이터베이스 종류 (mariadb | postgres | sqlite)
+	SQLitePath string // SQLite 파일 경로 (DBDriver가 sqlite인 경우)
 	DBPort string 
+	DBSSLMode string // PostgreSQL SSL 모드 (disable | require | verify-ca | verify-full)
+	DBReplicas []string // 조회 전용 replica 주소 목록 (host:port)
+	DBMaxOpenConns int // 최대 커넥션 수
+	DBMaxIdleConns int // 최대 유휴 커넥션 수
+	DBConnMaxLifetime time.Duration // 커넥션 최대 사용 시간
+	DBConnMaxIdleTime time.Duration // 커넥션 최대 유휴 시간 (0: 제한 없음)
+	DBDialTimeout time.Duration // 접속 제한 시간 (0: 제한 없음)
+	DBReadTimeout time.Duration // 읽기 제한 시간 (0: 제한 없음)
+	DBWriteTimeout time.Duration // 쓰기 제한 시간 (0: 제한 없음)
+	DBTLS string // MariaDB TLS 모드 (false | true | skip-verify | preferred)
+	DBTLSCAFile string // MariaDB 서버 인증서 검증용 CA 인증서 경로
+	DBCharset string // MariaDB 문자셋
+	DBCollation string // MariaDB 정렬 규칙
+	DBLoc string // MariaDB time.Time 변환 시간대 (IANA 이름)
+	DBConnectAttempts int // 기동 시 DB 접속 최대 시도 횟수
+	DBConnectBackoff time.Duration // 기동 시 DB 접속 재시도 첫 대기 시간
+	DBConnectMaxBackoff time.Duration // 기동 시 DB 접속 재시도 최대 대기 시간
+	DBBreakerFailures int // 서킷 브레이커를 여는 연속 DB 장애 횟수
+	DBBreakerOpenTimeout time.Duration // 서킷 브레이커 open 유지 시간
 	DBname string // 데이터베이스 이름
 	LogLevel string // 로깅 레벨
+	MigrateOnStart bool // 기동 시 스키마 마이그레이션 적용 여부
+	FirmwareDir string // 펌웨어 이미지 저장 경로
+	DownloadMaxConcurrent int // 펌웨어 전체 동시 다운로드 수 (0: 제한 없음)
+	DownloadMaxPerGroup int // 디바이스 그룹별 동시 다운로드 수 (0: 제한 없음)
+	DownloadBytesPerSec int64 // 펌웨어 전체 다운로드 대역폭 byte/s (0: 제한 없음)
+	DownloadGroupPrefix int // 디바이스 그룹을 구분하는 ProductNumber 앞자리 수
+	ReportWALDir string // DB 장애 중 보고를 보관하는 WAL 디렉터리
+	ReportWALSegmentBytes int64 // WAL 세그먼트 파일 최대 크기 (byte)
+	ReportReplayInterval time.Duration // WAL에 보관된 보고의 재전송 시도 주기
+	ReportBatchSize int // 한 번에 기록할 최대 보고 수
+	ReportFlushInterval time.Duration // 배치가 차지 않아도 보고를 기록하는 주기
+	ReportQueueSize int // 보고 기록 대기열 크기 (가득 차면 429 응답)
+	DeviceCacheSize int // 디바이스 조회 캐시 최대 보관 수
+	DeviceCacheTTL time.Duration // 디바이스 조회 결과 보관 시간
+	DeviceCacheNegativeTTL time.Duration // 존재하지 않는 디바이스 조회 결과 보관 시간
+	FleetOfflineCycles int // 보고 주기의 몇 배 동안 보고가 없으면 오프라인으로 볼지
+	FleetLowBattery int // 배터리 부족 기준 (%, 이하)
+	FleetOverviewCacheTTL time.Duration // 전체 디바이스 현황 집계 결과 보관 시간
+	AlertSweepInterval time.Duration // 오프라인 알림 점검 및 알림 규칙 재조회 주기
+	AlertQueueSize int // 알림 평가 대기열 크기 (보고 묶음 수, 가득 차면 평가하지 않음)
+	WebhookMaxAttempts int // 웹훅 최대 전송 시도 횟수 (넘으면 dead로 남김)
+	WebhookBackoff time.Duration // 웹훅 첫 재시도 대기 시간 (실패할 때마다 두 배)
+	WebhookMaxBackoff time.Duration // 웹훅 재시도 대기 시간 상한
+	WebhookTimeout time.Duration // 웹훅 전송 요청 제한 시간
+	WebhookPollInterval time.Duration // 전송 시간이 된 웹훅 전송 조회 주기
+	WebhookAllowPrivateTargets bool // 루프백/사설/링크 로컬 등 내부 주소로의 웹훅 전송 허용 (개발 및 시험용)
+	OutboxPollInterval time.Duration // outbox 이벤트 조회 및 전달 주기
+	OutboxBatchSize int // 한 번에 전달할 최대 outbox 이벤트 수
+	OutboxLeaseTTL time.Duration // outbox 전달 임대 유지 시간 (갱신하지 못하면 다른 인스턴스가 이어서 전달)
+	OutboxSinks []string // outbox 이벤트 전달 대상 (webhook, log, broker)
+	OutboxBrokers []string // 메시지 브로커(Kafka) 주소 목록 (host:port)
+	OutboxBrokerTopic string // 메시지 브로커로 이벤트를 보낼 topic
+	StreamBufferSize int // 실시간 스트림 구독별 전달 대기 이벤트 수 (가득 차면 구독을 끊음)
+	StreamMaxSubscribers int // 동시에 유지할 수 있는 실시간 스트림 구독 수
+	StreamHeartbeat time.Duration // 실시간 스트림 연결 유지 확인(ping) 주기
+	ReportRetention time.Duration // 원본 보고 보관 기간, 지나면 시간/일 단위로 요약 후 삭제 (0: 계속 보관)
+	RollupHourlyRetention time.Duration // 시간 단위 요약 보관 기간 (0: 계속 보관, 일 단위 요약은 계속 보관)
+	RetentionInterval time.Duration // 보고 요약/정리 작업 실행 주기
+	RetentionChunkSize int // 보고 요약/정리 시 한 트랜잭션에서 처리할 보고 수
+	ReportArchiveDir string // 보관 기간이 지난 보고를 삭제 전에 저장할 보관 파일 디렉터리 (빈 값: 보관하지 않음)
+	ReportRestoreTTL time.Duration // 보관 파일에서 복원한 보고의 보관 시간 (0: 삭제하지 않음)
+	ReportPartitionsAhead int // MariaDB 보고 월 파티션을 현재 달 이후 미리 만들어 둘 개월 수
+	ReportPartitionDryRun bool // 파티션 DDL을 실행하지 않고 로그로만 출력
+	ShutdownTimeout time.Duration // 종료 시 진행 중인 요청 및 대기열 기록을 기다리는 최대 시간
 }
\ No newline at end of file
@@ -0,0 +1,41 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// 브로커 요청 한 건의 제한 시간
+const kafkaTimeout = 10 * time.Second
+
+// KafkaProducer는 Kafka 클러스터에 메시지를 보내는 Producer입니다.
+// 같은 key의 메시지는 같은 파티션(key hash)으로 보내고, 모든 복제본이 받은 뒤 반환합니다.
+// BrokerSink가 메시지를 하나씩 순서대로 보내므로 메시지를 모으지 않고 바로 보냅니다.
+type KafkaProducer struct {
+	writer *kafka.Writer
+}
+
+func NewKafkaProducer(brokers []string) (*KafkaProducer, error) {
+	if len(brokers) == 0 {
+		return nil, ErrInvalidSinkRequired
+	}
+	return &KafkaProducer{writer: &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		BatchSize:    1,
+		ReadTimeout:  kafkaTimeout,
+		WriteTimeout: kafkaTimeout,
+	}}, nil
+}
+
+func (p *KafkaProducer) Produce(ctx context.Context, topic string, key string, value []byte) error {
+	return p.writer.WriteMessages(ctx, kafka.Message{Topic: topic, Key: []byte(key), Value: value})
+}
+
+// Close - 브로커 연결을 닫습니다.
+func (p *KafkaProducer) Close() error {
+	return p.writer.Close()
+}
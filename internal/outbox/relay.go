@@ -0,0 +1,304 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 이벤트 전달 기본값
+const (
+	defaultPollInterval = time.Second
+	defaultBatchSize    = 500
+	defaultLeaseTTL     = 30 * time.Second
+	maxBatchSize        = 5000
+	relayTimeout        = 30 * time.Second
+	leaseName           = "outbox_relay"
+)
+
+var (
+	ErrInvalidRelayRequired = errors.New("missing required inputs to create outbox Relay")
+	ErrNoSinks              = errors.New("outbox Relay requires at least one sink")
+)
+
+type Config struct {
+	PollInterval time.Duration // 전달할 이벤트 조회 주기 (기본값 1초)
+	BatchSize    int           // 한 번에 조회하여 전달할 최대 이벤트 수 (기본값 500, 최대 5000)
+	LeaseTTL     time.Duration // 전달 임대 유지 시간, 갱신하지 못하면 다른 인스턴스가 이어서 전달 (기본값 30초)
+}
+
+// Stats는 이벤트 전달 현황입니다.
+type Stats struct {
+	Leader     bool      `json:"leader"`               // 이 인스턴스가 전달 중인지 여부
+	Backlog    int64     `json:"backlog"`              // 전달을 기다리는 이벤트 수 (마지막 조회 기준)
+	Relayed    int64     `json:"relayed"`              // 모든 대상에 전달하고 삭제한 이벤트 수 (기동 이후)
+	Failed     int64     `json:"failed"`               // 전달하지 못해 남겨둔 이벤트 수 (기동 이후, 재시도 포함)
+	Sinks      []string  `json:"sinks"`                // 전달 대상
+	LastPollAt time.Time `json:"lastPollAt,omitempty"` // 마지막 조회 시간
+	LastError  string    `json:"lastError,omitempty"`  // 마지막 전달/조회 오류
+}
+
+// Relay는 저장소 변경과 같은 트랜잭션으로 outbox에 기록된 이벤트를 전달 대상(Sink)에 전달합니다.
+// 모든 대상에 전달한 이벤트만 삭제하므로 프로세스가 중간에 종료되어도 이벤트를 잃지 않으며(at-least-once),
+// 같은 이벤트가 다시 전달될 수 있으므로 대상은 EventID로 중복을 걸러야 합니다.
+// 같은 디바이스의 이벤트는 기록된 순서대로 전달하고, 전달하지 못한 디바이스의 이벤트는 이후 이벤트와 함께 남겨 다음 주기에 다시 전달합니다.
+// 전달하지 못한 이벤트가 BatchSize만큼 쌓이면 다른 디바이스의 전달도 멈추므로 Stats의 Backlog와 LastError를 확인해야 합니다.
+// 여러 인스턴스 중 임대(lease)를 가진 한 인스턴스만 전달합니다.
+type Relay struct {
+	cfg    Config
+	repo   db.OutboxDataService
+	sinks  []Sink
+	owner  string
+	logger *logger.AppLogger
+
+	mu         sync.Mutex
+	leader     bool
+	backlog    int64
+	lastPollAt time.Time
+	lastError  string
+
+	relayed atomic.Int64
+	failed  atomic.Int64
+}
+
+func NewRelay(lgr *logger.AppLogger, repo db.OutboxDataService, sinks []Sink, cfg Config) (*Relay, error) {
+	if lgr == nil || repo == nil {
+		return nil, ErrInvalidRelayRequired
+	}
+	if len(sinks) == 0 {
+		return nil, ErrNoSinks
+	}
+
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	cfg.BatchSize = min(cfg.BatchSize, maxBatchSize)
+	if cfg.LeaseTTL <= 0 {
+		cfg.LeaseTTL = defaultLeaseTTL
+	}
+
+	return &Relay{
+		cfg:    cfg,
+		repo:   repo,
+		sinks:  sinks,
+		owner:  uuid.New().String(),
+		logger: lgr,
+	}, nil
+}
+
+// Stats - 전달 현황을 반환합니다.
+func (r *Relay) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sinks := make([]string, len(r.sinks))
+	for i, sink := range r.sinks {
+		sinks[i] = sink.Name()
+	}
+
+	return Stats{
+		Leader:     r.leader,
+		Backlog:    r.backlog,
+		Relayed:    r.relayed.Load(),
+		Failed:     r.failed.Load(),
+		Sinks:      sinks,
+		LastPollAt: r.lastPollAt,
+		LastError:  r.lastError,
+	}
+}
+
+// Run - ctx가 종료될 때까지 PollInterval마다 outbox의 이벤트를 전달합니다. 종료 시 임대를 반납합니다.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.RelayPending(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			r.release()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending - 임대를 얻으면 outbox의 이벤트를 모두 전달하고 전달한 이벤트 수를 반환합니다.
+// 전달하지 못한 이벤트가 남으면 다음 주기까지 기다립니다.
+func (r *Relay) RelayPending(ctx context.Context, now time.Time) int {
+	r.mu.Lock()
+	r.lastPollAt = now
+	r.mu.Unlock()
+
+	relayed := 0
+	for ctx.Err() == nil {
+		// 1. 전달 임대 획득 또는 연장
+		ok, err := r.repo.AcquireLease(ctx, leaseName, r.owner, time.Now(), time.Now().Add(r.cfg.LeaseTTL))
+		r.setLeader(ok && err == nil)
+		if err != nil {
+			r.fail(err, "failed to acquire outbox lease")
+			return relayed
+		}
+		if !ok {
+			return relayed
+		}
+
+		// 2. 기록된 순서대로 전달
+		n, done := r.relayBatch(ctx)
+		relayed += n
+		if done {
+			return relayed
+		}
+	}
+
+	return relayed
+}
+
+// 한 묶음을 전달하고 전달한 이벤트 수와 이번 주기를 마칠지 여부를 반환한다
+func (r *Relay) relayBatch(ctx context.Context) (int, bool) {
+	ctx, cancel := context.WithTimeout(ctx, relayTimeout)
+	defer cancel()
+
+	pending, err := r.repo.ListPending(ctx, r.cfg.BatchSize)
+	if err != nil {
+		r.fail(err, "failed to select outbox events")
+		return 0, true
+	}
+	if len(pending) == 0 {
+		r.setBacklog(0)
+		return 0, true
+	}
+
+	// 1. 묶음 전체를 전달
+	events := make([]data.Event, len(pending))
+	ids := make([]int64, len(pending))
+	for i := range pending {
+		events[i] = pending[i].Event
+		ids[i] = pending[i].OutboxID
+	}
+	err = r.publish(ctx, events)
+	if err == nil {
+		if err := r.repo.Delete(ctx, ids); err != nil {
+			r.fail(err, "failed to delete relayed outbox events")
+			return 0, true
+		}
+		r.relayed.Add(int64(len(ids)))
+		r.updateBacklog(ctx)
+		return len(ids), len(pending) < r.cfg.BatchSize
+	}
+	r.fail(err, "failed to relay outbox events, retrying by device")
+
+	// 2. 일부 디바이스의 이벤트 문제일 수 있으므로 디바이스 단위로 다시 전달하여 문제가 있는 디바이스의 이벤트만 남긴다
+	relayed := r.relayEach(ctx, pending)
+	r.updateBacklog(ctx)
+	return relayed, true
+}
+
+func (r *Relay) relayEach(ctx context.Context, pending []data.OutboxEvent) int {
+	var order []string
+	byDevice := make(map[string][]*data.OutboxEvent)
+	for i := range pending {
+		pn := pending[i].Event.ProductNumber
+		if _, ok := byDevice[pn]; !ok {
+			order = append(order, pn)
+		}
+		byDevice[pn] = append(byDevice[pn], &pending[i])
+	}
+
+	relayed := 0
+	for _, pn := range order {
+		group := byDevice[pn]
+		events := make([]data.Event, len(group))
+		ids := make([]int64, len(group))
+		for i, o := range group {
+			events[i] = o.Event
+			ids[i] = o.OutboxID
+		}
+
+		if err := r.publish(ctx, events); err != nil {
+			r.failed.Add(int64(len(ids)))
+			r.logger.Error().Err(err).Str("productNumber", pn).Int("events", len(ids)).Msg("failed to relay device outbox events")
+			if err := r.repo.RecordFailure(ctx, ids, err.Error()); err != nil {
+				r.fail(err, "failed to record outbox failure")
+			}
+			continue
+		}
+
+		if err := r.repo.Delete(ctx, ids); err != nil {
+			r.fail(err, "failed to delete relayed outbox events")
+			continue
+		}
+		r.relayed.Add(int64(len(ids)))
+		relayed += len(ids)
+	}
+
+	return relayed
+}
+
+// 모든 대상에 순서대로 전달한다. 앞의 대상에 이미 전달했어도 뒤의 대상이 실패하면 다시 전달한다.
+func (r *Relay) publish(ctx context.Context, events []data.Event) error {
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, events); err != nil {
+			return &SinkError{Sink: sink.Name(), Err: err}
+		}
+	}
+	return nil
+}
+
+func (r *Relay) updateBacklog(ctx context.Context) {
+	backlog, err := r.repo.Backlog(ctx)
+	if err != nil {
+		r.fail(err, "failed to count outbox events")
+		return
+	}
+	r.setBacklog(backlog)
+}
+
+func (r *Relay) setBacklog(backlog int64) {
+	r.mu.Lock()
+	r.backlog = backlog
+	r.mu.Unlock()
+}
+
+func (r *Relay) setLeader(leader bool) {
+	r.mu.Lock()
+	r.leader = leader
+	r.mu.Unlock()
+}
+
+// 종료 시 임대를 반납하여 다른 인스턴스가 임대 만료를 기다리지 않고 이어서 전달하도록 한다
+func (r *Relay) release() {
+	r.mu.Lock()
+	leader := r.leader
+	r.leader = false
+	r.mu.Unlock()
+	if !leader {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), relayTimeout)
+	defer cancel()
+	if err := r.repo.ReleaseLease(ctx, leaseName, r.owner); err != nil {
+		r.logger.Error().Err(err).Msg("failed to release outbox lease")
+	}
+}
+
+func (r *Relay) fail(err error, msg string) {
+	r.logger.Error().Err(err).Msg(msg)
+
+	r.mu.Lock()
+	r.lastError = err.Error()
+	r.mu.Unlock()
+}
@@ -0,0 +1,190 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+var errSinkDown = errors.New("sink down")
+
+// 전달받은 이벤트를 기록하고 failing에 포함된 디바이스의 이벤트가 있으면 실패하는 Sink
+type recordingSink struct {
+	mu      sync.Mutex
+	events  []data.Event
+	failing map[string]bool
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Publish(_ context.Context, events []data.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range events {
+		if s.failing[events[i].ProductNumber] {
+			return errSinkDown
+		}
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+// 전달받은 디바이스 순서 (예: "A1 B1 A2")
+func (s *recordingSink) received() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, len(s.events))
+	for i := range s.events {
+		names[i] = string(s.events[i].Data)
+	}
+	return strings.Join(names, " ")
+}
+
+func newTestRepo(t *testing.T) *db.OutboxRepo {
+	t.Helper()
+
+	ctx := context.Background()
+	lgr := logger.Setup("error", "test")
+	mgr, err := db.NewSQLiteManager(filepath.Join(t.TempDir(), "test.db"), lgr)
+	if err != nil {
+		t.Fatalf("NewSQLiteManager: %v", err)
+	}
+	t.Cleanup(func() { mgr.Disconnect() })
+	migrator, err := db.NewMigrator(lgr, mgr)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	repo, err := db.NewOutboxRepo(lgr, mgr.DB(), mgr.Dialect())
+	if err != nil {
+		t.Fatalf("NewOutboxRepo: %v", err)
+	}
+	return repo
+}
+
+// 디바이스와 순번으로 구분할 수 있는 이벤트를 기록한다 (예: "A1"은 디바이스 A의 이벤트 1)
+func addEvents(t *testing.T, repo db.OutboxDataService, names ...string) {
+	t.Helper()
+	events := make([]data.Event, len(names))
+	for i, name := range names {
+		e, err := data.NewEvent(data.EventReportReceived, name[:1], time.Now(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e.Data = []byte(name)
+		events[i] = e
+	}
+	if err := repo.Add(context.Background(), events); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+}
+
+func TestRelayPending(t *testing.T) {
+	ctx := context.Background()
+	lgr := logger.Setup("error", "test")
+	repo := newTestRepo(t)
+	sink := &recordingSink{failing: map[string]bool{}}
+
+	relay, err := NewRelay(lgr, repo, []Sink{sink}, Config{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("NewRelay: %v", err)
+	}
+	standby, _ := NewRelay(lgr, repo, []Sink{sink}, Config{BatchSize: 2})
+
+	// 1. BatchSize씩 나누어 기록된 순서대로 모두 전달하고 삭제한다
+	addEvents(t, repo, "A1", "B1", "A2", "C1", "B2")
+	if n := relay.RelayPending(ctx, time.Now()); n != 5 {
+		t.Errorf("RelayPending = %d, want 5", n)
+	}
+	if got := sink.received(); got != "A1 B1 A2 C1 B2" {
+		t.Errorf("received %q", got)
+	}
+	if stats := relay.Stats(); !stats.Leader || stats.Relayed != 5 || stats.Backlog != 0 || stats.Sinks[0] != "recording" {
+		t.Errorf("Stats = %+v", stats)
+	}
+
+	// 2. 임대를 가진 인스턴스가 있으면 다른 인스턴스는 전달하지 않는다
+	addEvents(t, repo, "A3")
+	if n := standby.RelayPending(ctx, time.Now()); n != 0 || standby.Stats().Leader {
+		t.Errorf("standby RelayPending = %d, leader %v, want nothing relayed", n, standby.Stats().Leader)
+	}
+
+	// 3. 전달하지 못한 디바이스의 이벤트만 이후 이벤트와 함께 남기고 다른 디바이스의 이벤트는 전달한다
+	sink.failing["B"] = true
+	addEvents(t, repo, "B3", "B4")
+	if n := relay.RelayPending(ctx, time.Now()); n != 1 {
+		t.Errorf("RelayPending with failing device = %d, want 1", n)
+	}
+	pending, err := repo.ListPending(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListPending: %v", err)
+	}
+	if len(pending) != 2 || pending[0].Attempts != 1 || !strings.Contains(pending[0].LastError, errSinkDown.Error()) {
+		t.Fatalf("pending = %+v, want B3 and B4 with a recorded failure", pending)
+	}
+	if stats := relay.Stats(); stats.Failed != 1 || stats.Backlog != 2 || !strings.Contains(stats.LastError, "recording") {
+		t.Errorf("Stats after failure = %+v", stats)
+	}
+
+	// 4. 임대를 반납하면 다른 인스턴스가 바로 이어서 전달한다
+	relay.release()
+	sink.failing["B"] = false
+	if n := standby.RelayPending(ctx, time.Now()); n != 2 || !standby.Stats().Leader {
+		t.Errorf("standby RelayPending after release = %d, want 2", n)
+	}
+	if got := sink.received(); got != "A1 B1 A2 C1 B2 A3 B3 B4" {
+		t.Errorf("received %q", got)
+	}
+}
+
+type recordingProducer struct {
+	messages []string
+	err      error
+}
+
+func (p *recordingProducer) Produce(_ context.Context, topic string, key string, value []byte) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.messages = append(p.messages, topic+"/"+key)
+	return nil
+}
+
+func TestBrokerSink(t *testing.T) {
+	ctx := context.Background()
+	producer := &recordingProducer{}
+	sink, err := NewBrokerSink(producer, "device-events")
+	if err != nil {
+		t.Fatalf("NewBrokerSink: %v", err)
+	}
+
+	// 디바이스를 key로 하나씩 순서대로 보낸다
+	e1, _ := data.NewEvent(data.EventReportReceived, "ABC010001", time.Now(), map[string]int{"battery": 80})
+	e2, _ := data.NewEvent(data.EventDeviceCreated, "ABC010002", time.Now(), map[string]string{})
+	if err := sink.Publish(ctx, []data.Event{e1, e2}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if got := strings.Join(producer.messages, " "); got != "device-events/ABC010001 device-events/ABC010002" {
+		t.Errorf("messages = %q", got)
+	}
+
+	producer.err = errSinkDown
+	if err := sink.Publish(ctx, []data.Event{e1}); !errors.Is(err, errSinkDown) {
+		t.Errorf("Publish with failing producer: err = %v", err)
+	}
+	if _, err := NewBrokerSink(producer, ""); !errors.Is(err, ErrInvalidSinkRequired) {
+		t.Errorf("NewBrokerSink without topic: err = %v", err)
+	}
+}
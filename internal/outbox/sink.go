@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/model/external"
+)
+
+var ErrInvalidSinkRequired = errors.New("missing required inputs to create outbox Sink")
+
+// Sink는 outbox의 이벤트를 전달받는 대상입니다. (웹훅, 로그, 메시지 브로커 등)
+// events는 기록된 순서이며 같은 디바이스의 이벤트 순서를 유지해야 합니다.
+// 오류를 반환하면 이벤트가 다시 전달되므로, 이미 받은 이벤트를 다시 받아도 문제가 없어야 합니다. (EventID로 중복 제거)
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, events []data.Event) error
+}
+
+// SinkError는 전달에 실패한 대상과 원인입니다.
+type SinkError struct {
+	Sink string
+	Err  error
+}
+
+func (e *SinkError) Error() string {
+	return "outbox sink " + e.Sink + ": " + e.Err.Error()
+}
+
+func (e *SinkError) Unwrap() error {
+	return e.Err
+}
+
+// LogSink는 이벤트를 애플리케이션 로그로 남깁니다. (로그 수집기를 통한 연동 및 확인용)
+type LogSink struct {
+	logger *logger.AppLogger
+}
+
+func NewLogSink(lgr *logger.AppLogger) (*LogSink, error) {
+	if lgr == nil {
+		return nil, ErrInvalidSinkRequired
+	}
+	return &LogSink{logger: lgr}, nil
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Publish(_ context.Context, events []data.Event) error {
+	for i := range events {
+		e := &events[i]
+		s.logger.Info().Str("eventID", e.EventID).Str("eventType", string(e.Type)).Str("productNumber", e.ProductNumber).
+			Time("occurredAt", e.OccurredAt).RawJSON("data", e.Data).Msg("outbox event")
+	}
+	return nil
+}
+
+// Producer는 메시지 브로커(Kafka 등) 클라이언트가 구현하는 메시지 전송입니다.
+// 같은 key의 메시지는 같은 파티션에 보내 순서를 유지해야 하며, 브로커가 메시지를 받은 뒤 반환해야 합니다.
+type Producer interface {
+	Produce(ctx context.Context, topic string, key string, value []byte) error
+}
+
+// BrokerSink는 이벤트를 디바이스(ProductNumber)를 key로 메시지 브로커의 topic에 보냅니다.
+// 메시지 본문은 웹훅 본문과 같은 이벤트 JSON입니다.
+type BrokerSink struct {
+	producer Producer
+	topic    string
+}
+
+func NewBrokerSink(producer Producer, topic string) (*BrokerSink, error) {
+	if producer == nil || topic == "" {
+		return nil, ErrInvalidSinkRequired
+	}
+	return &BrokerSink{producer: producer, topic: topic}, nil
+}
+
+func (s *BrokerSink) Name() string {
+	return "broker"
+}
+
+// 이벤트를 하나씩 순서대로 보내며, 실패하면 이후 이벤트는 보내지 않는다
+func (s *BrokerSink) Publish(ctx context.Context, events []data.Event) error {
+	for i := range events {
+		value, err := json.Marshal(external.NewEventRes(&events[i]))
+		if err != nil {
+			return err
+		}
+		if err := s.producer.Produce(ctx, s.topic, events[i].ProductNumber, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+// 보존 작업 기본값
+const (
+	defaultInterval  = time.Hour
+	defaultChunkSize = 1000
+	maxChunkSize     = 5000 // ReportID IN (...) placeholder 수 제한 이내
+	chunkPause       = 50 * time.Millisecond
+)
+
+var (
+	ErrInvalidJobRequired = errors.New("missing required inputs to create retention Job")
+	ErrPurgeConflict      = errors.New("expired reports were changed during purge")
+)
+
+// Archiver는 원본 보고를 삭제하기 전에 보관 파일로 저장하고 tx로 목록을 등록합니다.
+type Archiver interface {
+	Archive(ctx context.Context, tx db.DBTX, reports []data.DeviceInfo) ([]data.ReportArchive, error)
+}
+
+// PartitionMaintainer는 보존 작업 후 실행하는 보고 파티션 관리 작업입니다. (MariaDB 전용)
+type PartitionMaintainer interface {
+	Apply(ctx context.Context, now time.Time) ([]string, error)
+}
+
+type Config struct {
+	Retention       time.Duration // 원본 보고 보관 기간, 0이면 정리하지 않음
+	HourlyRetention time.Duration // 시간 단위 요약 보관 기간, 0이면 정리하지 않음 (일 단위 요약은 계속 보관)
+	Interval        time.Duration // 작업 실행 주기 (기본값 1시간)
+	ChunkSize       int           // 한 트랜잭션에서 요약/삭제할 보고 수 (기본값 1000, 최대 5000)
+	RestoreTTL      time.Duration // 보관 파일에서 복원한 보고 보관 시간, 0이면 삭제하지 않음
+}
+
+// Stats는 보존 작업 실행 현황입니다.
+type Stats struct {
+	Enabled        bool      `json:"enabled"`
+	Runs           int64     `json:"runs"`                   // 실행 횟수 (기동 이후)
+	PurgedReports  int64     `json:"purgedReports"`          // 요약 후 삭제한 원본 보고 수 (기동 이후)
+	PurgedRollups  int64     `json:"purgedRollups"`          // 삭제한 시간 단위 요약 수 (기동 이후)
+	ArchivedFiles  int64     `json:"archivedFiles"`          // 삭제 전에 저장한 보관 파일 수 (기동 이후)
+	PurgedRestored int64     `json:"purgedRestored"`         // 보관 시간이 지나 삭제한 복원 보고 수 (기동 이후)
+	LastRunAt      time.Time `json:"lastRunAt,omitempty"`    // 마지막 실행 완료 시간
+	LastDuration   string    `json:"lastDuration,omitempty"` // 마지막 실행 소요 시간
+	LastError      string    `json:"lastError,omitempty"`    // 마지막 실행 오류
+	PartitionDDL   []string  `json:"partitionDDL,omitempty"` // 마지막 실행에서 적용(dry-run 시 출력)한 파티션 DDL
+}
+
+// Job은 보관 기간이 지난 원본 보고를 시간/일 단위 요약에 합친 뒤 삭제합니다.
+// 오래 잠금을 잡지 않도록 ChunkSize 건씩 나누어 트랜잭션마다 조회, 요약, 삭제를 함께 처리하므로
+// 중간에 실패해도 요약되지 않은 보고가 삭제되거나 같은 보고가 두 번 요약되지 않습니다.
+// 여러 인스턴스가 동시에 실행하면 같은 보고를 먼저 삭제한 쪽만 커밋되고 나머지는 롤백됩니다.
+// 보관 작업이 있으면 삭제 전에 보고를 보관 파일로 저장합니다.
+// 파티션 관리 작업이 있으면 보고 정리 후 파티션을 미리 만들고, 비워진 파티션을 제거합니다.
+type Job struct {
+	cfg        Config
+	txMgr      db.TxManager
+	rsRepo     db.ReportsDataService
+	rlRepo     db.RollupsDataService
+	archiver   Archiver
+	partitions PartitionMaintainer
+	logger     *logger.AppLogger
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// archiver는 보관 파일을 남기지 않으면, partitions는 파티션을 사용하지 않으면 nil입니다.
+func NewJob(
+	lgr *logger.AppLogger,
+	txMgr db.TxManager,
+	rsRepo db.ReportsDataService,
+	rlRepo db.RollupsDataService,
+	archiver Archiver,
+	partitions PartitionMaintainer,
+	cfg Config,
+) (*Job, error) {
+	if lgr == nil || txMgr == nil || rsRepo == nil || rlRepo == nil {
+		return nil, ErrInvalidJobRequired
+	}
+
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultInterval
+	}
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = defaultChunkSize
+	}
+	cfg.ChunkSize = min(cfg.ChunkSize, maxChunkSize)
+
+	return &Job{
+		cfg:        cfg,
+		txMgr:      txMgr,
+		rsRepo:     rsRepo,
+		rlRepo:     rlRepo,
+		archiver:   archiver,
+		partitions: partitions,
+		logger:     lgr,
+		stats:      Stats{Enabled: cfg.Retention > 0},
+	}, nil
+}
+
+// Enabled - 원본 보고 보관 기간이 설정되어 있는지 확인합니다.
+func (j *Job) Enabled() bool {
+	return j.cfg.Retention > 0
+}
+
+// Stats - 실행 현황을 반환합니다.
+func (j *Job) Stats() Stats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.stats
+}
+
+// Run - ctx가 종료될 때까지 주기적으로 작업을 실행합니다. 실행할 작업이 없으면 바로 반환합니다.
+func (j *Job) Run(ctx context.Context) {
+	if !j.Enabled() && j.cfg.RestoreTTL <= 0 && j.partitions == nil {
+		return
+	}
+
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		j.RunOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce - 보관 기간이 지난 원본 보고를 요약/보관 후 삭제하고, 보관 기간이 지난 시간 단위 요약과 복원 보고를 삭제한 뒤 파티션을 관리합니다.
+func (j *Job) RunOnce(ctx context.Context) error {
+	started := time.Now()
+
+	// 1. 원본 보고 요약 및 삭제
+	var purgedReports, archivedFiles int64
+	var err error
+	if j.Enabled() {
+		purgedReports, archivedFiles, err = j.purgeReports(ctx, started.Add(-j.cfg.Retention))
+	}
+
+	// 2. 시간 단위 요약 삭제 (일 단위 요약은 계속 보관)
+	var purgedRollups int64
+	if err == nil && j.Enabled() && j.cfg.HourlyRetention > 0 {
+		cutoff := data.RollupHourly.BucketStart(started.Add(-j.cfg.HourlyRetention))
+		purgedRollups, err = j.purgeRollups(ctx, data.RollupHourly, cutoff)
+	}
+
+	// 3. 보관 시간이 지난 복원 보고 삭제
+	var purgedRestored int64
+	if err == nil && j.cfg.RestoreTTL > 0 {
+		purgedRestored, err = j.purgeRestored(ctx, started.Add(-j.cfg.RestoreTTL))
+	}
+
+	// 4. 파티션 생성 및 제거 : 보고 정리가 실패해도 앞으로 쓰일 파티션은 만들어야 한다
+	var partitionDDL []string
+	if j.partitions != nil {
+		var partitionErr error
+		partitionDDL, partitionErr = j.partitions.Apply(ctx, started)
+		err = errors.Join(err, partitionErr)
+	}
+
+	j.mu.Lock()
+	j.stats.Runs++
+	j.stats.PurgedReports += purgedReports
+	j.stats.PurgedRollups += purgedRollups
+	j.stats.ArchivedFiles += archivedFiles
+	j.stats.PurgedRestored += purgedRestored
+	j.stats.LastRunAt = time.Now()
+	j.stats.LastDuration = time.Since(started).String()
+	j.stats.LastError = ""
+	j.stats.PartitionDDL = partitionDDL
+	if err != nil {
+		j.stats.LastError = err.Error()
+	}
+	j.mu.Unlock()
+
+	if err != nil {
+		j.logger.Error().Err(err).Int64("purgedReports", purgedReports).Int64("purgedRollups", purgedRollups).Msg("report retention job failed")
+		return err
+	}
+	j.logger.Info().Int64("purgedReports", purgedReports).Int64("purgedRollups", purgedRollups).Str("duration", time.Since(started).String()).Msg("report retention job completed")
+	return nil
+}
+
+// 조회한 보고 수가 ChunkSize보다 작아질 때까지 나누어 처리한다
+func (j *Job) purgeReports(ctx context.Context, cutoff time.Time) (purged, archived int64, err error) {
+	for {
+		n, files, err := j.purgeChunk(ctx, cutoff)
+		purged += int64(n)
+		archived += int64(files)
+		if err != nil {
+			return purged, archived, err
+		}
+		if n < j.cfg.ChunkSize {
+			return purged, archived, nil
+		}
+
+		// 트랜잭션 사이에 다른 쓰기가 잠금을 얻을 수 있도록 잠시 쉰다
+		select {
+		case <-ctx.Done():
+			return purged, archived, ctx.Err()
+		case <-time.After(chunkPause):
+		}
+	}
+}
+
+func (j *Job) purgeChunk(ctx context.Context, cutoff time.Time) (purged, archived int, err error) {
+
+	err = j.txMgr.WithTx(ctx, func(tx db.DBTX) error {
+		rsRepo := j.rsRepo.WithTx(tx)
+
+		// 1. 오래된 순서로 보관 기간이 지난 보고 조회
+		reports, err := rsRepo.GetBefore(ctx, cutoff, j.cfg.ChunkSize)
+		if err != nil || len(reports) == 0 {
+			return err
+		}
+
+		// 2. 시간/일 단위 요약을 기존 요약에 합침
+		if err := j.rlRepo.WithTx(tx).Merge(ctx, Summarize(reports)); err != nil {
+			return err
+		}
+
+		// 3. 보관 파일 저장 및 목록 등록
+		archived = 0
+		if j.archiver != nil {
+			archives, err := j.archiver.Archive(ctx, tx, reports)
+			if err != nil {
+				return err
+			}
+			archived = len(archives)
+		}
+
+		// 4. 요약한 보고 삭제 : 다른 인스턴스가 먼저 삭제했으면 중복 요약되지 않도록 롤백
+		// 오래된 순서로 조회했으므로 첫 보고와 마지막 보고가 보고 시간 범위이다
+		ids := make([]int64, len(reports))
+		for i := range reports {
+			ids[i] = reports[i].ReportID
+		}
+		deleted, err := rsRepo.DeleteByIDs(ctx, ids, reports[0].ReportAt, reports[len(reports)-1].ReportAt)
+		if err != nil {
+			return err
+		}
+		if deleted != int64(len(ids)) {
+			return fmt.Errorf("%w: selected %d, deleted %d", ErrPurgeConflict, len(ids), deleted)
+		}
+
+		purged = len(reports)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return purged, archived, nil
+}
+
+// 복원한 보고는 이미 요약/보관되었으므로 ChunkSize 건씩 삭제만 한다
+func (j *Job) purgeRestored(ctx context.Context, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		deleted, err := j.rsRepo.DeleteRestored(ctx, cutoff, j.cfg.ChunkSize)
+		total += deleted
+		if err != nil || deleted < int64(j.cfg.ChunkSize) {
+			return total, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(chunkPause):
+		}
+	}
+}
+
+// 가장 오래된 구간부터 한 구간씩 삭제한다
+func (j *Job) purgeRollups(ctx context.Context, granularity data.RollupGranularity, cutoff time.Time) (int64, error) {
+	var total int64
+	for {
+		oldest, err := j.rlRepo.OldestBucket(ctx, granularity)
+		if err != nil {
+			return total, err
+		}
+		if oldest.IsZero() || !oldest.Before(cutoff) {
+			return total, nil
+		}
+
+		deleted, err := j.rlRepo.DeleteRange(ctx, granularity, oldest, oldest.Add(granularity.Duration()))
+		total += deleted
+		if err != nil || deleted == 0 {
+			return total, err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+}
+
+// Summarize - 보고를 디바이스별 시간/일 단위 요약으로 묶습니다.
+// 구간은 UTC 기준이며, 결과는 (ProductNumber, Granularity, BucketStart)가 중복되지 않습니다.
+func Summarize(reports []data.DeviceInfo) []data.ReportRollup {
+	type key struct {
+		productNumber string
+		granularity   data.RollupGranularity
+		bucketStart   time.Time
+	}
+
+	var rollups []data.ReportRollup
+	index := make(map[key]int)
+
+	for i := range reports {
+		report := &reports[i]
+		for _, granularity := range []data.RollupGranularity{data.RollupHourly, data.RollupDaily} {
+			k := key{report.ProductNumber, granularity, granularity.BucketStart(report.ReportAt)}
+
+			j, ok := index[k]
+			if !ok {
+				j = len(rollups)
+				index[k] = j
+				rollups = append(rollups, data.ReportRollup{
+					ProductNumber: k.productNumber,
+					Granularity:   k.granularity,
+					BucketStart:   k.bucketStart,
+				})
+			}
+			rollups[j].Add(report)
+		}
+	}
+
+	return rollups
+}
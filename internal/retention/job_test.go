@@ -0,0 +1,153 @@
+package retention
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+func newTestReport(productNumber string, battery int, errorCode int, reportAt time.Time) data.DeviceInfo {
+	return data.DeviceInfo{
+		ProductNumber:      productNumber,
+		BatteryPercent:     battery,
+		Lat:                33.5,
+		Lon:                127.0,
+		TemperatureCelsius: float64(battery) / 2,
+		IP:                 "10.0.0.1",
+		ErrorCode:          errorCode,
+		ReportAt:           reportAt,
+		ReportedStatus:     data.ReportPowerOn,
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	day := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	reports := []data.DeviceInfo{
+		newTestReport("ABC010001", 80, 0, day.Add(10*time.Minute)),
+		newTestReport("ABC010001", 60, 3, day.Add(50*time.Minute)),
+		newTestReport("ABC010001", 70, 0, day.Add(90*time.Minute)),
+		newTestReport("ABC010002", 50, 0, day.Add(20*time.Minute)),
+	}
+	reports[1].Lat = 33.9
+
+	rollups := Summarize(reports)
+
+	// 디바이스별 시간 단위 구간 2개 + 1개, 일 단위 구간 1개 + 1개
+	if len(rollups) != 5 {
+		t.Fatalf("Summarize returned %d rollups, want 5: %+v", len(rollups), rollups)
+	}
+	find := func(productNumber string, granularity data.RollupGranularity, bucketStart time.Time) *data.ReportRollup {
+		for i := range rollups {
+			r := &rollups[i]
+			if r.ProductNumber == productNumber && r.Granularity == granularity && r.BucketStart.Equal(bucketStart) {
+				return r
+			}
+		}
+		t.Fatalf("no %s rollup for %s at %v", granularity, productNumber, bucketStart)
+		return nil
+	}
+
+	hour := find("ABC010001", data.RollupHourly, day)
+	if hour.Samples != 2 || hour.BatteryMin != 60 || hour.BatteryMax != 80 || hour.BatteryAvg() != 70 || hour.ErrorCount != 1 {
+		t.Errorf("first hourly rollup = %+v", hour)
+	}
+	if hour.LastLat != 33.9 || !hour.LastReportAt.Equal(day.Add(50*time.Minute)) {
+		t.Errorf("first hourly rollup last position = %v at %v", hour.LastLat, hour.LastReportAt)
+	}
+	if daily := find("ABC010001", data.RollupDaily, day); daily.Samples != 3 || daily.BatterySum != 210 || daily.TemperatureAvg() != 35 {
+		t.Errorf("daily rollup = %+v", daily)
+	}
+	if other := find("ABC010002", data.RollupHourly, day); other.Samples != 1 {
+		t.Errorf("ABC010002 hourly rollup = %+v", other)
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	ctx := context.Background()
+	lgr := logger.Setup("error", "test")
+	mgr, err := db.NewSQLiteManager(filepath.Join(t.TempDir(), "test.db"), lgr)
+	if err != nil {
+		t.Fatalf("NewSQLiteManager: %v", err)
+	}
+	t.Cleanup(func() { mgr.Disconnect() })
+	migrator, err := db.NewMigrator(lgr, mgr)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	dsRepo, _ := db.NewDevicesRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+	rsRepo, _ := db.NewReportsRepo(lgr, mgr.DB(), mgr.ReadDB(), mgr.Dialect())
+	rlRepo, _ := db.NewRollupsRepo(lgr, mgr.DB(), mgr.Dialect())
+	device := data.Device{ProductNumber: "ABC010001", MacAddress: "00:11:22:33:01:01", ProductLine: "ABC", HardwareRevision: "01", FirmwareVersion: "1.0.0", Status: data.StatusReady}
+	if _, err := dsRepo.Create(ctx, &device); err != nil {
+		t.Fatalf("create device: %v", err)
+	}
+
+	// 보관 기간(48시간)이 지난 보고 5건과 최근 보고 1건
+	old := data.RollupHourly.BucketStart(time.Now().Add(-72 * time.Hour))
+	err = rsRepo.CreateBatch(ctx, []data.DeviceInfo{
+		newTestReport("ABC010001", 80, 0, old.Add(time.Minute)),
+		newTestReport("ABC010001", 70, 0, old.Add(2*time.Minute)),
+		newTestReport("ABC010001", 60, 1, old.Add(3*time.Minute)),
+		newTestReport("ABC010001", 50, 0, old.Add(time.Hour+time.Minute)),
+		newTestReport("ABC010001", 40, 0, old.Add(time.Hour+2*time.Minute)),
+		newTestReport("ABC010001", 30, 0, time.Now().Add(-time.Hour)),
+	})
+	if err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+
+	job, err := NewJob(lgr, mgr, rsRepo, rlRepo, nil, nil, Config{
+		Retention:       48 * time.Hour,
+		HourlyRetention: 24 * time.Hour,
+		ChunkSize:       2,
+	})
+	if err != nil {
+		t.Fatalf("NewJob: %v", err)
+	}
+	if err := job.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	// 지난 보고는 나누어 요약 후 삭제하고, 시간 단위 요약은 보관 기간이 지나 삭제한다
+	stats := job.Stats()
+	if stats.Runs != 1 || stats.PurgedReports != 5 || stats.PurgedRollups != 2 || stats.LastError != "" {
+		t.Errorf("Stats = %+v", stats)
+	}
+	remaining, err := rsRepo.GetByID(ctx, "ABC010001")
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if len(*remaining) != 1 || (*remaining)[0].BatteryPercent != 30 {
+		t.Errorf("remaining reports = %+v, want only the recent one", *remaining)
+	}
+
+	var samples, batterySum, errorCount int64
+	err = mgr.DB().QueryRowContext(ctx, "SELECT SUM(Samples), SUM(BatterySum), SUM(ErrorCount) FROM report_rollups WHERE Granularity = ?", data.RollupDaily).
+		Scan(&samples, &batterySum, &errorCount)
+	if err != nil {
+		t.Fatalf("select daily rollups: %v", err)
+	}
+	if samples != 5 || batterySum != 300 || errorCount != 1 {
+		t.Errorf("daily rollups = samples %d, battery sum %d, errors %d", samples, batterySum, errorCount)
+	}
+	if oldest, _ := rlRepo.OldestBucket(ctx, data.RollupHourly); !oldest.IsZero() {
+		t.Errorf("hourly rollup remains at %v", oldest)
+	}
+
+	// 다시 실행해도 같은 보고를 두 번 요약하지 않는다
+	if err := job.RunOnce(ctx); err != nil {
+		t.Fatalf("second RunOnce: %v", err)
+	}
+	if stats := job.Stats(); stats.PurgedReports != 5 {
+		t.Errorf("PurgedReports after second run = %d, want 5", stats.PurgedReports)
+	}
+}
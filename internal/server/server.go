@@ -1,40 +1,85 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"sync"
 
 	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 
+	"go-rest-example/internal/alert"
+	"go-rest-example/internal/archive"
 	"go-rest-example/internal/db"
+	"go-rest-example/internal/firmware"
 	"go-rest-example/internal/handlers"
+	"go-rest-example/internal/ingest"
 	"go-rest-example/internal/logger"
 	"go-rest-example/internal/middleware"
 	"go-rest-example/internal/model"
+	"go-rest-example/internal/outbox"
+	"go-rest-example/internal/retention"
+	"go-rest-example/internal/stream"
 	"go-rest-example/internal/util"
+	"go-rest-example/internal/wal"
+	"go-rest-example/internal/webhook"
 )
 
 // 서버 시작 시 한번만 동작하는 것을 보장하기 위해 사용
 var startOnce sync.Once
 
 
-func Start(svcEnv *model.ServiceEnv, lgr *logger.AppLogger, dbMgr db.DBManager) error {
+func Start(ctx context.Context, svcEnv *model.ServiceEnv, lgr *logger.AppLogger, dbMgr db.DBManager) error {
 
 	var err error
-	var r *gin.Engine
 
 	// 초기화 로직을 한번만 실행하기 위해 사용
 	startOnce.Do(func() {
-		r, err = WebRouter(svcEnv, lgr, dbMgr)
+		var r *gin.Engine
+		var shutdown func(context.Context) error
+
+		r, shutdown, err = WebRouter(ctx, svcEnv, lgr, dbMgr)
+		if err != nil {
+			return
+		}
 		lgr.Info().Msg("Registered routes")
 		for _, item := range r.Routes() {
 			lgr.Info().Str("method", item.Method).Str("path", item.Path).Send()
 		}
-		if err != nil {
-			return
+
+		srv := &http.Server{
+			Addr:    ":" + svcEnv.Port,
+			Handler: r,
+		}
+
+		serveErr := make(chan error, 1)
+		go func() {
+			serveErr <- srv.ListenAndServe()
+		}()
+
+		// 종료 신호 또는 서버 오류 대기
+		select {
+		case <-ctx.Done():
+		case err = <-serveErr:
+		}
+
+		// 진행 중인 요청을 마친 뒤 대기열에 남은 보고를 기록하고 종료한다
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), svcEnv.ShutdownTimeout)
+		defer cancel()
+
+		if shutdownErr := srv.Shutdown(shutdownCtx); shutdownErr != nil {
+			lgr.Error().Err(shutdownErr).Msg("failed to shut down HTTP server gracefully")
+		}
+		if shutdownErr := shutdown(shutdownCtx); shutdownErr != nil {
+			lgr.Error().Err(shutdownErr).Msg("failed to stop background workers gracefully")
+		}
+
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
 		}
-		err = r.Run(":" + svcEnv.Port)
 	})
 
 	return err
@@ -42,7 +87,8 @@ func Start(svcEnv *model.ServiceEnv, lgr *logger.AppLogger, dbMgr db.DBManager)
 
 
 // 경로 정보를 지정하고, 의존성을 주입하는 역할을 수행한다.
-func WebRouter(svcEnv *model.ServiceEnv, lgr *logger.AppLogger, dbMgr db.DBManager) (*gin.Engine, error ){
+// 반환된 shutdown 함수는 HTTP 서버 종료 후 호출하여 백그라운드 작업을 정리한다.
+func WebRouter(ctx context.Context, svcEnv *model.ServiceEnv, lgr *logger.AppLogger, dbMgr db.DBManager) (*gin.Engine, func(context.Context) error, error ){
 
 
 	// 1. 환경 변수에 따라서 콘솔에 변화를 준다
@@ -60,61 +106,432 @@ func WebRouter(svcEnv *model.ServiceEnv, lgr *logger.AppLogger, dbMgr db.DBManag
 	router := gin.New();
 	
 	router.Use(gin.Recovery())
-	router.Use(gzip.Gzip(gzip.DefaultCompression))
+	// 실시간 스트림(SSE)은 이벤트마다 바로 전달해야 하므로 압축하지 않는다 (압축 버퍼에 머물러 전달되지 않음)
+	router.Use(gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPathsRegexs([]string{`^/device/([^/]+/)?stream/?$`})))
 	router.Use(middleware.ReqIDMiddleware())
 	router.Use(middleware.ResponseHeadersMiddleware())
 	router.Use(middleware.RequestLogMiddleware(lgr))
+	router.Use(middleware.ReadPrimaryMiddleware())
+
 
+	// 데이터베이스 장애 시 요청을 빠르게 실패시키기 위한 서킷 브레이커
+	breaker := db.NewCircuitBreaker(lgr, db.BreakerConfig{
+		FailureThreshold: svcEnv.DBBreakerFailures,
+		OpenTimeout:      svcEnv.DBBreakerOpenTimeout,
+	})
 
 	// Health Check 도메인
-	status, sHandlerErr := handlers.NewStatusHandler(lgr, dbMgr)
+	status, sHandlerErr := handlers.NewStatusHandler(lgr, dbMgr, breaker)
 	if sHandlerErr != nil {
-		return nil, sHandlerErr
+		return nil, nil, sHandlerErr
 	}
 	router.GET("/healthz", status.CheckStatus)
 
+	// 운영자 API 인증 토큰이 없으면 운영자 API 요청은 모두 거부된다
+	if svcEnv.AdminToken == "" {
+		lgr.Error().Msg("adminToken is not set, operator APIs will reject every request")
+	}
+
 	// 성능 모니터링
-	// internalAPIGrp := router.Group("/internal")
-	// internalAPIGrp.Use(middleware.InternalAuthMiddleware()) // use special auth middleware to handle internal employees
+	internalAPIGrp := router.Group("/internal")
+	internalAPIGrp.Use(middleware.InternalAuthMiddleware(svcEnv.AdminToken)) // 운영자 인증
 	// 프로메테우스와의 연동 계획
 	// pprof.RouteRegister(internalAPIGrp, "pprof")
 	// router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	internalAPIGrp.GET("/db/stats", status.DBStats)
 
 
 	// 0. 데이터 레이어 획득 
-	d := dbMgr.DB()
-	rpRepo, reportRepoErr := db.NewReportsRepo(lgr, d) 
+	d := breaker.Wrap(dbMgr.DB())
+	reader := breaker.Wrap(dbMgr.ReadDB())
+	rpRepo, reportRepoErr := db.NewReportsRepo(lgr, d, reader, dbMgr.Dialect())
 	if reportRepoErr != nil {
-		return nil, reportRepoErr
+		return nil, nil, reportRepoErr
 	}
 
-	dvRepo, deviceRepoErr := db.NewDevicesRepo(lgr, d)
+	deviceRepo, deviceRepoErr := db.NewDevicesRepo(lgr, d, reader, dbMgr.Dialect())
 	if deviceRepoErr != nil {
-		return nil, deviceRepoErr
+		return nil, nil, deviceRepoErr
+	}
+
+	// 보고마다 반복되는 디바이스 조회를 줄이기 위한 캐시
+	dvRepo, deviceCacheErr := db.NewDeviceCache(deviceRepo, db.DeviceCacheConfig{
+		Size:        svcEnv.DeviceCacheSize,
+		TTL:         svcEnv.DeviceCacheTTL,
+		NegativeTTL: svcEnv.DeviceCacheNegativeTTL,
+	})
+	if deviceCacheErr != nil {
+		return nil, nil, deviceCacheErr
+	}
+
+	// 디바이스별 마지막 보고 상태 (보고 저장 시 함께 갱신)
+	lsRepo, latestStateRepoErr := db.NewLatestStateRepo(lgr, d, reader, dbMgr.Dialect())
+	if latestStateRepoErr != nil {
+		return nil, nil, latestStateRepoErr
+	}
+
+	roRepo, rolloutRepoErr := db.NewRolloutsRepo(lgr, d, breaker.WrapTx(dbMgr), dbMgr.Dialect())
+	if rolloutRepoErr != nil {
+		return nil, nil, rolloutRepoErr
+	}
+
+	fwRepo, firmwareRepoErr := db.NewFirmwareRepo(lgr, d, breaker.WrapTx(dbMgr), dbMgr.Dialect())
+	if firmwareRepoErr != nil {
+		return nil, nil, firmwareRepoErr
 	}
 
-	deviceHandler, deviceHandlerErr := handlers.NewDevicesHandler(lgr, dvRepo)
+	// 저장소 변경과 같은 트랜잭션으로 기록하는 이벤트 (보고 저장, 디바이스 등록/상태 변경, 펌웨어 설치, 알림)
+	obRepo, outboxRepoErr := db.NewOutboxRepo(lgr, d, dbMgr.Dialect())
+	if outboxRepoErr != nil {
+		return nil, nil, outboxRepoErr
+	}
+
+	// outbox의 이벤트를 구독한 외부 시스템에 전송하는 웹훅
+	webhookRepo, webhookRepoErr := db.NewWebhooksRepo(lgr, d, dbMgr.Dialect())
+	if webhookRepoErr != nil {
+		return nil, nil, webhookRepoErr
+	}
+
+	webhookDispatcher, webhookDispatcherErr := webhook.NewDispatcher(lgr, breaker.WrapTx(dbMgr), webhookRepo, webhook.Config{
+		MaxAttempts:         svcEnv.WebhookMaxAttempts,
+		Backoff:             svcEnv.WebhookBackoff,
+		MaxBackoff:          svcEnv.WebhookMaxBackoff,
+		Timeout:             svcEnv.WebhookTimeout,
+		PollInterval:        svcEnv.WebhookPollInterval,
+		AllowPrivateTargets: svcEnv.WebhookAllowPrivateTargets,
+	})
+	if webhookDispatcherErr != nil {
+		return nil, nil, webhookDispatcherErr
+	}
+
+	// outbox의 이벤트를 전달 대상에 전달하는 작업 (임대를 가진 한 인스턴스만 전달)
+	sinks, closeSinks, sinksErr := outboxSinks(lgr, svcEnv, webhookDispatcher)
+	if sinksErr != nil {
+		return nil, nil, sinksErr
+	}
+
+	outboxRelay, outboxRelayErr := outbox.NewRelay(lgr, obRepo, sinks, outbox.Config{
+		PollInterval: svcEnv.OutboxPollInterval,
+		BatchSize:    svcEnv.OutboxBatchSize,
+		LeaseTTL:     svcEnv.OutboxLeaseTTL,
+	})
+	if outboxRelayErr != nil {
+		return nil, nil, outboxRelayErr
+	}
+
+	outboxHandler, outboxHandlerErr := handlers.NewOutboxHandler(lgr, outboxRelay)
+	if outboxHandlerErr != nil {
+		return nil, nil, outboxHandlerErr
+	}
+	internalAPIGrp.GET("/outbox/relay", outboxHandler.RelayStats)
+
+	deviceHandler, deviceHandlerErr := handlers.NewDevicesHandler(lgr, breaker.WrapTx(dbMgr), dvRepo, lsRepo, obRepo, dvRepo)
 	if deviceHandlerErr != nil {
-		return nil, deviceHandlerErr
+		return nil, nil, deviceHandlerErr
 	}
+	internalAPIGrp.GET("/devices/cache", deviceHandler.CacheStats)
+
+	// 관제 화면용 전체 디바이스 현황 (짧게 보관하여 자주 조회해도 집계는 보관 시간마다 한 번)
+	fleetRepo, fleetRepoErr := db.NewFleetRepo(lgr, reader, dbMgr.Dialect(), db.FleetConfig{
+		OfflineCycles: svcEnv.FleetOfflineCycles,
+		LowBattery:    svcEnv.FleetLowBattery,
+	})
+	if fleetRepoErr != nil {
+		return nil, nil, fleetRepoErr
+	}
+
+	fleetCache, fleetCacheErr := db.NewFleetCache(fleetRepo, svcEnv.FleetOverviewCacheTTL)
+	if fleetCacheErr != nil {
+		return nil, nil, fleetCacheErr
+	}
+
+	fleetHandler, fleetHandlerErr := handlers.NewFleetHandler(lgr, fleetCache)
+	if fleetHandlerErr != nil {
+		return nil, nil, fleetHandlerErr
+	}
+	internalAPIGrp.GET("/fleet/overview", fleetHandler.Overview)
 	
 	// 0. 의존성 주입 및 라우터 등록 
 	deviceAPIGrp := router.Group("/device")
 	deviceAPIGrp.Use(middleware.AuthMiddleware())  // 디바이스 인증 과정을 담당하는 미들웨어
+	deviceAPIGrp.Use(middleware.CircuitBreakerMiddleware(breaker))
 	deviceAPIGrp.POST("",deviceHandler.Create)
 	deviceAPIGrp.GET("",deviceHandler.GetAll)
 	deviceAPIGrp.GET("/:ID",deviceHandler.GetByID)
 
+	// 대시보드용 보고 집계 API 등록 (보존 기간이 지난 구간은 요약 테이블로 응답)
+	aggregateRepo, aggregateRepoErr := db.NewAggregatesRepo(lgr, reader, dbMgr.Dialect())
+	if aggregateRepoErr != nil {
+		return nil, nil, aggregateRepoErr
+	}
+
+	aggregateHandler, aggregateHandlerErr := handlers.NewAggregateHandler(lgr, aggregateRepo, dvRepo)
+	if aggregateHandlerErr != nil {
+		return nil, nil, aggregateHandlerErr
+	}
+	deviceAPIGrp.GET("/reports/aggregate", aggregateHandler.Fleet)
+	deviceAPIGrp.GET("/:ID/reports/aggregate", aggregateHandler.Device)
+
+	// 저장된 보고와 주기 점검으로 알림 규칙을 평가하는 엔진
+	alertRepo, alertRepoErr := db.NewAlertsRepo(lgr, d, dbMgr.Dialect())
+	if alertRepoErr != nil {
+		return nil, nil, alertRepoErr
+	}
+
+	alertEngine, alertEngineErr := alert.NewEngine(lgr, breaker.WrapTx(dbMgr), alertRepo, obRepo, alert.Config{
+		SweepInterval: svcEnv.AlertSweepInterval,
+		QueueSize:     svcEnv.AlertQueueSize,
+	})
+	if alertEngineErr != nil {
+		return nil, nil, alertEngineErr
+	}
+
+	// 새로 저장된 보고와 디바이스 상태 변경을 실시간 스트림 구독에 전달 (서버 종료 시 스트림을 끊는다)
+	streamHub := stream.NewHub(stream.Config{
+		BufferSize:     svcEnv.StreamBufferSize,
+		MaxSubscribers: svcEnv.StreamMaxSubscribers,
+	})
+	context.AfterFunc(ctx, streamHub.Close)
+
+	streamHandler, streamHandlerErr := handlers.NewStreamHandler(lgr, streamHub, dvRepo, svcEnv.StreamHeartbeat)
+	if streamHandlerErr != nil {
+		return nil, nil, streamHandlerErr
+	}
+	deviceAPIGrp.GET("/stream", streamHandler.Fleet)
+	deviceAPIGrp.GET("/:ID/stream", streamHandler.Device)
+	internalAPIGrp.GET("/stream/hub", streamHandler.HubStats)
+
+	// 보고 저장 및 데이터베이스 장애 시 보고를 보관할 버퍼(WAL)
+	recorder, recorderErr := ingest.NewRecorder(lgr, breaker.WrapTx(dbMgr), rpRepo, dvRepo, lsRepo, obRepo, alertEngine, streamHub)
+	if recorderErr != nil {
+		return nil, nil, recorderErr
+	}
+
+	reportWAL, walErr := wal.Open(svcEnv.ReportWALDir, svcEnv.ReportWALSegmentBytes, lgr)
+	if walErr != nil {
+		return nil, nil, walErr
+	}
+
+	reportBuffer, bufferErr := ingest.NewBuffer(lgr, reportWAL, recorder, svcEnv.ReportReplayInterval)
+	if bufferErr != nil {
+		return nil, nil, bufferErr
+	}
+
+	// 배치 기록기
+	reportWriter, writerErr := ingest.NewBatchWriter(lgr, recorder, reportBuffer, ingest.WriterConfig{
+		BatchSize:     svcEnv.ReportBatchSize,
+		FlushInterval: svcEnv.ReportFlushInterval,
+		QueueSize:     svcEnv.ReportQueueSize,
+	})
+	if writerErr != nil {
+		return nil, nil, writerErr
+	}
+
+	// 보관 기간이 지난 보고를 요약 후 삭제하는 작업
+	rollupRepo, rollupRepoErr := db.NewRollupsRepo(lgr, d, dbMgr.Dialect())
+	if rollupRepoErr != nil {
+		return nil, nil, rollupRepoErr
+	}
+
+	// 삭제 전 보고를 디바이스/일 단위 파일로 보관하고, 조사 시 복원한다 (보관 디렉터리 설정 시)
+	var archiver retention.Archiver
+	var restorer *archive.Restorer
+	if svcEnv.ReportArchiveDir != "" {
+		archiveStore, storeErr := archive.NewDirStore(svcEnv.ReportArchiveDir)
+		if storeErr != nil {
+			return nil, nil, storeErr
+		}
+
+		archiveRepo, archiveRepoErr := db.NewArchivesRepo(lgr, d, dbMgr.Dialect())
+		if archiveRepoErr != nil {
+			return nil, nil, archiveRepoErr
+		}
+
+		reportArchiver, archiverErr := archive.NewArchiver(lgr, archiveStore, archiveRepo)
+		if archiverErr != nil {
+			return nil, nil, archiverErr
+		}
+		archiver = reportArchiver
+
+		var restorerErr error
+		restorer, restorerErr = archive.NewRestorer(lgr, archiveStore, breaker.WrapTx(dbMgr), archiveRepo, rpRepo)
+		if restorerErr != nil {
+			return nil, nil, restorerErr
+		}
+	}
+
+	// MariaDB는 보고 테이블을 월 단위 파티션으로 관리한다
+	var partitions retention.PartitionMaintainer
+	if dbMgr.Dialect().Name() == db.MySQLDialect.Name() {
+		partitionMgr, partitionErr := db.NewPartitionManager(lgr, d, dbMgr.Dialect(), db.PartitionConfig{
+			Ahead:     svcEnv.ReportPartitionsAhead,
+			Retention: svcEnv.ReportRetention,
+			DryRun:    svcEnv.ReportPartitionDryRun,
+		})
+		if partitionErr != nil {
+			return nil, nil, partitionErr
+		}
+		partitions = partitionMgr
+	}
+
+	retentionJob, retentionErr := retention.NewJob(lgr, breaker.WrapTx(dbMgr), rpRepo, rollupRepo, archiver, partitions, retention.Config{
+		Retention:       svcEnv.ReportRetention,
+		HourlyRetention: svcEnv.RollupHourlyRetention,
+		Interval:        svcEnv.RetentionInterval,
+		ChunkSize:       svcEnv.RetentionChunkSize,
+		RestoreTTL:      svcEnv.ReportRestoreTTL,
+	})
+	if retentionErr != nil {
+		return nil, nil, retentionErr
+	}
+
+	// 백그라운드 작업 (WAL 재전송, 보존 기간 정리 및 파티션 관리, 알림 평가, outbox 이벤트 전달, 웹훅 전송)
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	var workers sync.WaitGroup
+	for _, run := range []func(context.Context){reportBuffer.Run, retentionJob.Run, alertEngine.Run, outboxRelay.Run, webhookDispatcher.Run} {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run(workerCtx)
+		}()
+	}
+
+	// 대기열의 보고를 모두 기록한 뒤 백그라운드 작업을 멈추고 WAL을 닫는다
+	// (기록 실패한 보고는 WAL에 남아 다음 기동 시 재전송된다)
+	shutdown := func(ctx context.Context) error {
+		writerErr := reportWriter.Close(ctx)
+		stopWorkers()
+		workers.Wait()
+		return errors.Join(writerErr, reportWAL.Close(), closeSinks())
+	}
+
 	// repot API 등록 
-	reportHandler, reportHandlerErr := handlers.NewReportsHandler(lgr, rpRepo, dvRepo)
+	reportHandler, reportHandlerErr := handlers.NewReportsHandler(lgr, reportWriter, reportBuffer, retentionJob, dvRepo, roRepo, fwRepo)
 	if reportHandlerErr != nil {
-		return nil, reportHandlerErr
+		return nil, nil, reportHandlerErr
 	}
+	internalAPIGrp.GET("/reports/backlog", reportHandler.Backlog)
+	internalAPIGrp.GET("/reports/writer", reportHandler.WriterStats)
+	internalAPIGrp.GET("/reports/retention", reportHandler.RetentionStats)
 
+	// 보관 파일 목록 조회 및 복원 API 등록
+	if restorer != nil {
+		archiveHandler, archiveHandlerErr := handlers.NewArchiveHandler(lgr, restorer)
+		if archiveHandlerErr != nil {
+			return nil, nil, archiveHandlerErr
+		}
+		internalAPIGrp.GET("/reports/archives", archiveHandler.List)
+		internalAPIGrp.POST("/reports/restore", middleware.CircuitBreakerMiddleware(breaker), archiveHandler.Restore)
+	}
+
+	// 주기 보고는 데이터베이스 장애 중에도 버퍼에 보관하므로 서킷 브레이커로 차단하지 않는다
 	reportAPIGrp := router.Group("/report")
 	reportAPIGrp.POST("",reportHandler.Report)
-	reportAPIGrp.PATCH("",reportHandler.Update)
+	// 펌웨어 다운로드는 동시 실행 수와 대역폭을 제한한다 
+	downloadScheduler := firmware.NewDownloadScheduler(firmware.SchedulerConfig{
+		MaxConcurrent: svcEnv.DownloadMaxConcurrent,
+		MaxPerGroup:   svcEnv.DownloadMaxPerGroup,
+		BytesPerSec:   svcEnv.DownloadBytesPerSec,
+	})
+	reportAPIGrp.PATCH("", middleware.CircuitBreakerMiddleware(breaker), middleware.DownloadLimitMiddleware(downloadScheduler, svcEnv.DownloadGroupPrefix), reportHandler.Update)
+
+	// 펌웨어 저장소 및 배포 캠페인 API 등록 
+	firmwareHandler, firmwareHandlerErr := handlers.NewFirmwareHandler(lgr, fwRepo, roRepo, dvRepo, svcEnv.FirmwareDir)
+	if firmwareHandlerErr != nil {
+		return nil, nil, firmwareHandlerErr
+	}
+
+	firmwareAPIGrp := router.Group("/firmware")
+	firmwareAPIGrp.Use(middleware.InternalAuthMiddleware(svcEnv.AdminToken)) // 펌웨어 등록 및 배포는 운영자만 가능
+	firmwareAPIGrp.Use(middleware.CircuitBreakerMiddleware(breaker))
+	firmwareAPIGrp.POST("",firmwareHandler.Register)
+	firmwareAPIGrp.GET("",firmwareHandler.GetAll)
+	firmwareAPIGrp.POST("/deltas",firmwareHandler.CreateDelta)
+	firmwareAPIGrp.POST("/campaigns",firmwareHandler.CreateCampaign)
+	firmwareAPIGrp.GET("/campaigns/:ID/progress",firmwareHandler.Progress)
+	firmwareAPIGrp.POST("/campaigns/:ID/rollback",firmwareHandler.Rollback)
+
+	// 알림 규칙 및 알림 조회/확인/해소 API 등록
+	alertHandler, alertHandlerErr := handlers.NewAlertHandler(lgr, alertEngine, alertRepo)
+	if alertHandlerErr != nil {
+		return nil, nil, alertHandlerErr
+	}
+	internalAPIGrp.GET("/alerts/engine", alertHandler.EngineStats)
+
+	alertAPIGrp := router.Group("/alerts")
+	alertAPIGrp.Use(middleware.InternalAuthMiddleware(svcEnv.AdminToken)) // 알림 규칙 및 알림 처리는 운영자만 가능
+	alertAPIGrp.Use(middleware.CircuitBreakerMiddleware(breaker))
+	alertAPIGrp.POST("/rules", alertHandler.CreateRule)
+	alertAPIGrp.GET("/rules", alertHandler.GetRules)
+	alertAPIGrp.GET("/rules/:ID", alertHandler.GetRule)
+	alertAPIGrp.PUT("/rules/:ID", alertHandler.UpdateRule)
+	alertAPIGrp.DELETE("/rules/:ID", alertHandler.DeleteRule)
+	alertAPIGrp.GET("", alertHandler.GetAll)
+	alertAPIGrp.GET("/:ID", alertHandler.GetByID)
+	alertAPIGrp.POST("/:ID/ack", alertHandler.Acknowledge)
+	alertAPIGrp.POST("/:ID/resolve", alertHandler.Resolve)
+
+	// 웹훅 구독 및 전송 기록 조회/재전송 API 등록
+	webhookHandler, webhookHandlerErr := handlers.NewWebhookHandler(lgr, webhookDispatcher, webhookRepo)
+	if webhookHandlerErr != nil {
+		return nil, nil, webhookHandlerErr
+	}
+	internalAPIGrp.GET("/webhooks/dispatcher", webhookHandler.DispatcherStats)
+
+	webhookAPIGrp := router.Group("/webhooks")
+	webhookAPIGrp.Use(middleware.InternalAuthMiddleware(svcEnv.AdminToken))
+	webhookAPIGrp.Use(middleware.CircuitBreakerMiddleware(breaker))
+	webhookAPIGrp.POST("", webhookHandler.Create)
+	webhookAPIGrp.GET("", webhookHandler.GetAll)
+	webhookAPIGrp.GET("/:ID", webhookHandler.GetByID)
+	webhookAPIGrp.PUT("/:ID", webhookHandler.Update)
+	webhookAPIGrp.DELETE("/:ID", webhookHandler.Delete)
+	webhookAPIGrp.POST("/:ID/test", webhookHandler.Test)
+	webhookAPIGrp.GET("/:ID/deliveries", webhookHandler.GetDeliveries)
+	webhookAPIGrp.POST("/:ID/deliveries/:deliveryID/retry", webhookHandler.Retry)
 
 	// 4. 라우터 객체 반환
-	return router, nil
+	return router, shutdown, nil
+}
+
+// 설정된 이름의 outbox 전달 대상을 만든다.
+// 메시지 브로커(broker)는 outboxBrokers의 Kafka 클러스터로 보내며, 반환한 close로 브로커 연결을 닫는다.
+func outboxSinks(lgr *logger.AppLogger, svcEnv *model.ServiceEnv, dispatcher *webhook.Dispatcher) ([]outbox.Sink, func() error, error) {
+	var sinks []outbox.Sink
+	var closers []func() error
+	closeSinks := func() error {
+		var errs []error
+		for _, closeFn := range closers {
+			errs = append(errs, closeFn())
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, name := range svcEnv.OutboxSinks {
+		switch name {
+		case dispatcher.Name():
+			sinks = append(sinks, dispatcher)
+		case "log":
+			logSink, err := outbox.NewLogSink(lgr)
+			if err != nil {
+				return nil, nil, errors.Join(err, closeSinks())
+			}
+			sinks = append(sinks, logSink)
+		case "broker":
+			producer, err := outbox.NewKafkaProducer(svcEnv.OutboxBrokers)
+			if err != nil {
+				return nil, nil, errors.Join(fmt.Errorf("outbox sink broker requires outboxBrokers: %w", err), closeSinks())
+			}
+			closers = append(closers, producer.Close)
+
+			brokerSink, err := outbox.NewBrokerSink(producer, svcEnv.OutboxBrokerTopic)
+			if err != nil {
+				return nil, nil, errors.Join(err, closeSinks())
+			}
+			sinks = append(sinks, brokerSink)
+		default:
+			return nil, nil, errors.Join(fmt.Errorf("unsupported outbox sink %q (supported: webhook, log, broker)", name), closeSinks())
+		}
+	}
+	return sinks, closeSinks, nil
 }
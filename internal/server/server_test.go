@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model"
+)
+
+const testAdminToken = "test-admin-token"
+
+// SQLite 데이터베이스로 전체 라우터를 구성한다
+func newTestRouter(t *testing.T, adminToken string) http.Handler {
+	t.Helper()
+
+	lgr := logger.Setup("error", "test")
+	dir := t.TempDir()
+	dbMgr, err := db.NewSQLiteManager(filepath.Join(dir, "test.db"), lgr)
+	if err != nil {
+		t.Fatalf("NewSQLiteManager: %v", err)
+	}
+	t.Cleanup(func() { dbMgr.Disconnect() })
+
+	migrator, err := db.NewMigrator(lgr, dbMgr)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	if err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	svcEnv := &model.ServiceEnv{
+		Name:                   "test",
+		AdminToken:             adminToken,
+		DBDriver:               "sqlite",
+		DBBreakerFailures:      5,
+		DBBreakerOpenTimeout:   10 * time.Second,
+		FirmwareDir:            filepath.Join(dir, "firmware"),
+		DownloadGroupPrefix:    3,
+		ReportWALDir:           filepath.Join(dir, "wal"),
+		ReportWALSegmentBytes:  1 << 20,
+		ReportReplayInterval:   time.Second,
+		ReportBatchSize:        100,
+		ReportFlushInterval:    time.Second,
+		ReportQueueSize:        100,
+		ReportArchiveDir:       filepath.Join(dir, "archive"),
+		DeviceCacheSize:        100,
+		DeviceCacheTTL:         time.Minute,
+		DeviceCacheNegativeTTL: time.Second,
+		FleetOfflineCycles:     3,
+		FleetLowBattery:        20,
+		FleetOverviewCacheTTL:  time.Second,
+		AlertSweepInterval:     time.Minute,
+		AlertQueueSize:         100,
+		WebhookMaxAttempts:     3,
+		WebhookBackoff:         time.Second,
+		WebhookMaxBackoff:      time.Minute,
+		WebhookTimeout:         time.Second,
+		WebhookPollInterval:    time.Second,
+		OutboxPollInterval:     time.Second,
+		OutboxBatchSize:        100,
+		OutboxLeaseTTL:         time.Minute,
+		OutboxSinks:            []string{"webhook"},
+		StreamBufferSize:       16,
+		StreamMaxSubscribers:   10,
+		StreamHeartbeat:        time.Minute,
+		RollupHourlyRetention:  24 * time.Hour,
+		RetentionInterval:      time.Hour,
+		RetentionChunkSize:     100,
+		ShutdownTimeout:        5 * time.Second,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	router, shutdown, err := WebRouter(ctx, svcEnv, lgr, dbMgr)
+	if err != nil {
+		cancel()
+		t.Fatalf("WebRouter: %v", err)
+	}
+	t.Cleanup(func() {
+		shutdown(context.Background())
+		cancel()
+	})
+	return router
+}
+
+func serve(router http.Handler, method, path, token string) int {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAdminAuth(t *testing.T) {
+	router := newTestRouter(t, testAdminToken)
+
+	// 운영자 API는 관리자 토큰 없이 호출할 수 없다
+	paths := []struct {
+		method, path string
+	}{
+		{http.MethodGet, "/webhooks"},
+		{http.MethodGet, "/internal/db/stats"},
+		{http.MethodGet, "/internal/devices/cache"},
+		{http.MethodGet, "/internal/webhooks/dispatcher"},
+		{http.MethodPost, "/internal/reports/restore"},
+		{http.MethodPost, "/firmware"},
+		{http.MethodGet, "/firmware"},
+		{http.MethodPost, "/firmware/deltas"},
+		{http.MethodPost, "/firmware/campaigns"},
+		{http.MethodPost, "/firmware/campaigns/1/rollback"},
+		{http.MethodPost, "/alerts/rules"},
+		{http.MethodGet, "/alerts"},
+		{http.MethodPost, "/alerts/1/ack"},
+	}
+	for _, p := range paths {
+		if code := serve(router, p.method, p.path, ""); code != http.StatusUnauthorized {
+			t.Errorf("%s %s without token: status = %d, want 401", p.method, p.path, code)
+		}
+		if code := serve(router, p.method, p.path, "wrong-token"); code != http.StatusUnauthorized {
+			t.Errorf("%s %s with wrong token: status = %d, want 401", p.method, p.path, code)
+		}
+		if code := serve(router, p.method, p.path, testAdminToken); code == http.StatusUnauthorized {
+			t.Errorf("%s %s with admin token: status = 401", p.method, p.path)
+		}
+	}
+
+	// 관리자 토큰이 설정되지 않으면 모두 거부한다
+	unconfigured := newTestRouter(t, "")
+	if code := serve(unconfigured, http.MethodGet, "/webhooks", ""); code != http.StatusUnauthorized {
+		t.Errorf("GET /webhooks without configured token: status = %d, want 401", code)
+	}
+
+	// 디바이스 API와 상태 확인은 관리자 토큰이 필요 없다
+	if code := serve(router, http.MethodGet, "/healthz", ""); code == http.StatusUnauthorized {
+		t.Error("GET /healthz: status = 401")
+	}
+}
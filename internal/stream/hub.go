@@ -0,0 +1,253 @@
+package stream
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"go-rest-example/internal/model/data"
+)
+
+// 구독 기본값
+const (
+	defaultBufferSize     = 256
+	defaultMaxSubscribers = 1000
+)
+
+var (
+	ErrHubClosed          = errors.New("stream hub is closed")
+	ErrTooManySubscribers = errors.New("too many stream subscribers")
+	ErrSlowConsumer       = errors.New("stream subscriber is too slow, disconnected")
+)
+
+type Config struct {
+	BufferSize     int // 구독마다 보관하는 전달 대기 이벤트 수, 가득 차면 구독을 끊음 (기본값 256)
+	MaxSubscribers int // 동시에 유지할 수 있는 구독 수 (기본값 1000)
+}
+
+// Stats는 실시간 전달 현황입니다.
+type Stats struct {
+	Subscribers    int   `json:"subscribers"`    // 현재 구독 수
+	MaxSubscribers int   `json:"maxSubscribers"` // 최대 구독 수
+	BufferSize     int   `json:"bufferSize"`     // 구독별 전달 대기 이벤트 수
+	Published      int64 `json:"published"`      // 전달받은 이벤트 수 (기동 이후)
+	Delivered      int64 `json:"delivered"`      // 구독에 넣은 이벤트 수 (기동 이후)
+	Disconnected   int64 `json:"disconnected"`   // 느려서 끊은 구독 수 (기동 이후)
+	Rejected       int64 `json:"rejected"`       // 최대 구독 수를 넘어 거부한 구독 수 (기동 이후)
+}
+
+// Filter는 구독할 이벤트 조건입니다. 비어 있는 조건은 모든 이벤트에 해당합니다.
+type Filter struct {
+	ProductNumbers []string         // 디바이스
+	ProductLine    string           // 제품군 (ProductNumber 앞 3자리)
+	EventTypes     []data.EventType // 이벤트 종류
+}
+
+// Matches는 이벤트가 조건에 해당하는지 여부이다.
+func (f *Filter) Matches(e *data.Event) bool {
+	if len(f.EventTypes) > 0 && !slices.Contains(f.EventTypes, e.Type) {
+		return false
+	}
+	if len(f.ProductNumbers) > 0 && !slices.Contains(f.ProductNumbers, e.ProductNumber) {
+		return false
+	}
+	if f.ProductLine != "" {
+		if productLine, _ := data.ParseProductNumber(e.ProductNumber); productLine != f.ProductLine {
+			return false
+		}
+	}
+	return true
+}
+
+// Hub는 커밋된 보고와 디바이스 상태 변경 이벤트를 같은 프로세스의 구독(SSE 연결 등)에 바로 전달합니다.
+// 이벤트를 보관하지 않으므로 구독하기 전이나 다른 인스턴스에서 저장된 이벤트는 전달하지 않습니다.
+// 전달은 기다리지 않으며, 구독의 전달 대기 이벤트가 BufferSize만큼 쌓이면 보고 저장을 지연시키지 않도록 그 구독을 끊습니다.
+// 하나의 Publish 안에서는 이벤트 순서를 유지합니다.
+type Hub struct {
+	cfg Config
+
+	mu     sync.RWMutex
+	closed bool
+	nextID uint64
+	subs   map[uint64]*Subscription
+
+	published    atomic.Int64
+	delivered    atomic.Int64
+	disconnected atomic.Int64
+	rejected     atomic.Int64
+}
+
+func NewHub(cfg Config) *Hub {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = defaultBufferSize
+	}
+	if cfg.MaxSubscribers <= 0 {
+		cfg.MaxSubscribers = defaultMaxSubscribers
+	}
+
+	return &Hub{
+		cfg:  cfg,
+		subs: make(map[uint64]*Subscription),
+	}
+}
+
+// Subscribe - 조건에 해당하는 이벤트를 전달받는 구독을 만듭니다. 사용을 마치면 Close해야 합니다.
+func (h *Hub) Subscribe(filter Filter) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrHubClosed
+	}
+	if len(h.subs) >= h.cfg.MaxSubscribers {
+		h.rejected.Add(1)
+		return nil, ErrTooManySubscribers
+	}
+
+	h.nextID++
+	sub := &Subscription{
+		id:     h.nextID,
+		hub:    h,
+		filter: filter,
+		events: make(chan data.Event, h.cfg.BufferSize),
+		done:   make(chan struct{}),
+	}
+	h.subs[sub.id] = sub
+	return sub, nil
+}
+
+// Publish - 커밋된 이벤트를 조건에 해당하는 구독에 기다리지 않고 넣습니다.
+func (h *Hub) Publish(events []data.Event) {
+	if len(events) == 0 {
+		return
+	}
+	h.published.Add(int64(len(events)))
+
+	var slow []*Subscription
+	h.mu.RLock()
+	for _, sub := range h.subs {
+		for i := range events {
+			if !sub.filter.Matches(&events[i]) {
+				continue
+			}
+			if !sub.offer(events[i]) {
+				slow = append(slow, sub)
+				break
+			}
+			h.delivered.Add(1)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range slow {
+		if sub.end(ErrSlowConsumer) {
+			h.disconnected.Add(1)
+		}
+	}
+}
+
+// Stats - 구독 및 전달 현황을 반환합니다.
+func (h *Hub) Stats() Stats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return Stats{
+		Subscribers:    len(h.subs),
+		MaxSubscribers: h.cfg.MaxSubscribers,
+		BufferSize:     h.cfg.BufferSize,
+		Published:      h.published.Load(),
+		Delivered:      h.delivered.Load(),
+		Disconnected:   h.disconnected.Load(),
+		Rejected:       h.rejected.Load(),
+	}
+}
+
+// Close - 모든 구독을 끊고 새 구독을 받지 않습니다. (서버 종료 시 연결을 정리하기 위해 사용)
+func (h *Hub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	subs := make([]*Subscription, 0, len(h.subs))
+	for _, sub := range h.subs {
+		subs = append(subs, sub)
+	}
+	h.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.end(ErrHubClosed)
+	}
+}
+
+func (h *Hub) remove(id uint64) {
+	h.mu.Lock()
+	delete(h.subs, id)
+	h.mu.Unlock()
+}
+
+// Subscription은 Hub에서 이벤트를 전달받는 구독입니다.
+type Subscription struct {
+	id     uint64
+	hub    *Hub
+	filter Filter
+	events chan data.Event
+	done   chan struct{}
+
+	once  sync.Once
+	ended atomic.Bool
+	err   error
+}
+
+// Events - 전달받은 이벤트 (끊긴 뒤에도 닫히지 않으므로 Done과 함께 사용)
+func (s *Subscription) Events() <-chan data.Event {
+	return s.events
+}
+
+// Done - 구독이 끊기면 닫힙니다.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err - 구독이 끊긴 이유 (ErrSlowConsumer, ErrHubClosed). Close로 끊었거나 끊기지 않았으면 nil입니다.
+func (s *Subscription) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// Close - 구독을 끊습니다.
+func (s *Subscription) Close() {
+	s.end(nil)
+}
+
+// 버퍼에 여유가 있으면 이벤트를 넣는다. 이미 끊긴 구독은 넣지 않고 성공으로 본다.
+func (s *Subscription) offer(e data.Event) bool {
+	if s.ended.Load() {
+		return true
+	}
+
+	select {
+	case s.events <- e:
+		return true
+	default:
+		return false
+	}
+}
+
+// 구독을 끊고 Hub에서 제거한다. 처음 끊은 경우 true를 반환한다.
+// events는 다른 Publish가 넣는 중일 수 있으므로 닫지 않는다.
+func (s *Subscription) end(err error) bool {
+	ended := false
+	s.once.Do(func() {
+		s.err = err
+		s.ended.Store(true)
+		close(s.done)
+		ended = true
+	})
+	if ended {
+		s.hub.remove(s.id)
+	}
+	return ended
+}
@@ -0,0 +1,125 @@
+package stream
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-rest-example/internal/model/data"
+)
+
+func newTestEvent(eventType data.EventType, productNumber string) data.Event {
+	return data.Event{EventID: productNumber + "-" + string(eventType), Type: eventType, ProductNumber: productNumber, OccurredAt: time.Now()}
+}
+
+// 대기 중인 이벤트 ID를 모두 꺼낸다
+func drain(sub *Subscription) []string {
+	var ids []string
+	for {
+		select {
+		case e := <-sub.Events():
+			ids = append(ids, e.EventID)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	report := newTestEvent(data.EventReportReceived, "ABC010001")
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"product number", Filter{ProductNumbers: []string{"XYZ010001", "ABC010001"}}, true},
+		{"other product number", Filter{ProductNumbers: []string{"ABC010002"}}, false},
+		{"product line", Filter{ProductLine: "ABC"}, true},
+		{"other product line", Filter{ProductLine: "XYZ"}, false},
+		{"event type", Filter{EventTypes: []data.EventType{data.EventReportReceived}}, true},
+		{"other event type", Filter{ProductLine: "ABC", EventTypes: []data.EventType{data.EventDeviceStatusChanged}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Matches(&report); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHubPublish(t *testing.T) {
+	hub := NewHub(Config{BufferSize: 2, MaxSubscribers: 3})
+	all, _ := hub.Subscribe(Filter{})
+	abc, _ := hub.Subscribe(Filter{ProductLine: "ABC"})
+	status, _ := hub.Subscribe(Filter{EventTypes: []data.EventType{data.EventDeviceStatusChanged}})
+	defer status.Close()
+
+	// 1. 최대 구독 수를 넘으면 거부한다
+	if _, err := hub.Subscribe(Filter{}); !errors.Is(err, ErrTooManySubscribers) {
+		t.Errorf("Subscribe over the limit: err = %v, want ErrTooManySubscribers", err)
+	}
+
+	// 2. 조건에 해당하는 구독에만 순서대로 전달한다
+	hub.Publish([]data.Event{
+		newTestEvent(data.EventReportReceived, "ABC010001"),
+		newTestEvent(data.EventDeviceStatusChanged, "XYZ010001"),
+	})
+	if got := drain(abc); len(got) != 1 || got[0] != "ABC010001-report.received" {
+		t.Errorf("ABC subscriber received %v", got)
+	}
+	if got := drain(status); len(got) != 1 || got[0] != "XYZ010001-device.status_changed" {
+		t.Errorf("status subscriber received %v", got)
+	}
+
+	// 3. 버퍼가 가득 찬 구독만 끊고 다른 구독에는 계속 전달한다
+	hub.Publish([]data.Event{newTestEvent(data.EventReportReceived, "ABC010002")})
+	select {
+	case <-all.Done():
+	default:
+		t.Fatal("slow subscriber was not disconnected")
+	}
+	if !errors.Is(all.Err(), ErrSlowConsumer) {
+		t.Errorf("slow subscriber Err = %v, want ErrSlowConsumer", all.Err())
+	}
+	if got := drain(abc); len(got) != 1 {
+		t.Errorf("ABC subscriber received %v after the slow one was disconnected", got)
+	}
+
+	// 4. 직접 끊은 구독은 오류 없이 제거한다
+	abc.Close()
+	if abc.Err() != nil {
+		t.Errorf("closed subscriber Err = %v, want nil", abc.Err())
+	}
+	stats := hub.Stats()
+	if stats.Subscribers != 1 || stats.Published != 3 || stats.Delivered != 5 || stats.Disconnected != 1 || stats.Rejected != 1 {
+		t.Errorf("Stats = %+v", stats)
+	}
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub(Config{})
+	sub, err := hub.Subscribe(Filter{})
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	// 종료하면 모든 구독을 끊고 새 구독을 받지 않는다
+	hub.Close()
+	<-sub.Done()
+	if !errors.Is(sub.Err(), ErrHubClosed) {
+		t.Errorf("Err after Close = %v, want ErrHubClosed", sub.Err())
+	}
+	if _, err := hub.Subscribe(Filter{}); !errors.Is(err, ErrHubClosed) {
+		t.Errorf("Subscribe after Close: err = %v, want ErrHubClosed", err)
+	}
+
+	// 끊긴 구독에는 넣지 않는다
+	hub.Publish([]data.Event{newTestEvent(data.EventReportReceived, "ABC010001")})
+	if got := drain(sub); len(got) != 0 {
+		t.Errorf("closed subscriber received %v", got)
+	}
+	if stats := hub.Stats(); stats.Subscribers != 0 || stats.BufferSize != defaultBufferSize || stats.MaxSubscribers != defaultMaxSubscribers {
+		t.Errorf("Stats = %+v", stats)
+	}
+}
@@ -2,6 +2,7 @@ package util
 
 import (
 	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"io"
 	"os"
@@ -30,30 +31,32 @@ func IsDevMode(s string) bool {
 }
 
 // 파일 경로를 검증하는 함수
-// 파일을 읽어 복사본을 생성하여 문제가 없는지 검증 
+// 일반 파일로 존재하며 읽기 가능한지 검증
+// 기존 파일을 덮어쓰지 않도록 열기만 수행한다.
 // https://stackoverflow.com/questions/35231846/golang-check-if-string-is-valid-path
 func PathValid(fp string) error {
 	// Check if file already exists
-	if _, err := os.Stat(fp); err != nil {
+	info, err := os.Stat(fp)
+	if err != nil || !info.Mode().IsRegular() {
 		return errors.New("invaild filePath")
 	}
-	
-	// Attempt to create it
-	var d []byte
-	if err := os.WriteFile(fp, d, 0644); err != nil {
+
+	// Attempt to open it
+	f, err := os.Open(fp)
+	if err != nil {
 		// 커스텀 에러 선언 필요 
 		return errors.New("invaild filePath")
 	}
-	
-	os.Remove(fp) // And delete it
+
+	f.Close()
 	return nil
 }
 
 
-// 추가 인증 절차를 위한 파일 hash 값 추출 
+// 추가 인증 절차를 위한 파일 hash 값 추출 (hex 문자열)
 // https://stackoverflow.com/questions/15879136/how-to-calculate-sha256-file-checksum-in-go
 func CheckSum(path string) (string, error ){
-	f, err := os.Open("file.txt")
+	f, err := os.Open(path)
 	if err != nil {
 		// 커스텀 에러 선언 필요 
 		return "", err
@@ -66,5 +69,5 @@ func CheckSum(path string) (string, error ){
 	  return "", err
 	}
 
-	return string(h.Sum(nil)), nil
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
\ No newline at end of file
@@ -0,0 +1,609 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go-rest-example/internal/logger"
+)
+
+// 세그먼트 및 레코드 형식
+// 레코드 : [payload 길이 uint32][payload CRC32-C uint32][payload]
+const (
+	segmentExt         = ".wal"
+	checkpointFile     = "checkpoint"
+	recordHeaderSize   = 8
+	maxRecordSize      = 1 << 20
+	defaultSegmentSize = 16 << 20
+)
+
+var (
+	ErrInvalidDir      = errors.New("wal directory is required")
+	ErrRecordTooLarge  = errors.New("wal record is too large")
+	ErrCorruptRecord   = errors.New("wal record is corrupt")
+	ErrClosed          = errors.New("wal is closed")
+	ErrInvalidCheckpnt = errors.New("invalid wal checkpoint")
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Stats는 아직 재전송되지 않은 레코드(backlog) 현황입니다.
+type Stats struct {
+	Records  int64 `json:"records"`
+	Bytes    int64 `json:"bytes"`
+	Segments int   `json:"segments"`
+}
+
+// 재전송 위치 : 이 위치 이전의 레코드는 모두 처리되었다
+type position struct {
+	seq    uint64
+	offset int64
+}
+
+// Log는 세그먼트 파일로 나누어 저장하는 추가 전용 로그입니다.
+// 레코드는 기록 즉시 fsync되고 체크섬으로 검증되며, Replay로 기록 순서대로 꺼내 처리합니다.
+// 처리 위치는 checkpoint 파일에 저장하므로 재기동 후에도 처리하지 않은 레코드부터 이어서 재전송합니다.
+type Log struct {
+	dir         string
+	segmentSize int64
+	logger      *logger.AppLogger
+
+	mu         sync.Mutex
+	active     *os.File
+	activeSeq  uint64
+	activeSize int64
+	cp         position
+	records    int64
+	bytes      int64
+	closed     bool
+
+	replayMu sync.Mutex // Replay 동시 실행 방지
+}
+
+// Open - 디렉터리의 세그먼트를 검사하여 로그를 엽니다.
+// 마지막 세그먼트 끝의 불완전한 레코드(기록 중 종료)는 잘라내고, 새 세그먼트에 이어서 기록합니다.
+func Open(dir string, segmentSize int64, lgr *logger.AppLogger) (*Log, error) {
+	if dir == "" {
+		return nil, ErrInvalidDir
+	}
+	if segmentSize <= 0 {
+		segmentSize = defaultSegmentSize
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	l := &Log{
+		dir:         dir,
+		segmentSize: segmentSize,
+		logger:      lgr,
+	}
+
+	seqs, err := l.segments()
+	if err != nil {
+		return nil, err
+	}
+
+	cp, err := l.loadCheckpoint()
+	if err != nil {
+		return nil, err
+	}
+
+	// 이미 처리된 세그먼트 정리
+	var remaining []uint64
+	for _, seq := range seqs {
+		if seq < cp.seq {
+			os.Remove(l.segmentPath(seq))
+			continue
+		}
+		remaining = append(remaining, seq)
+	}
+
+	// 새 세그먼트 번호는 기존 세그먼트 및 처리 위치 이후로 정한다
+	next := max(cp.seq, 1)
+	if len(seqs) > 0 && seqs[len(seqs)-1] >= next {
+		next = seqs[len(seqs)-1] + 1
+	}
+	if len(remaining) == 0 {
+		cp = position{seq: next}
+	} else if remaining[0] != cp.seq {
+		cp = position{seq: remaining[0]}
+	}
+	l.cp = cp
+
+	// 남은 레코드 집계 및 마지막 세그먼트 끝 정리
+	for i, seq := range remaining {
+		start := int64(0)
+		if seq == cp.seq {
+			start = cp.offset
+		}
+
+		count, size, valid, err := l.scan(seq, start)
+		if err != nil {
+			return nil, err
+		}
+		l.records += count
+		l.bytes += size
+
+		if i == len(remaining)-1 {
+			if err := l.truncateTail(seq, valid); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := l.openSegment(next); err != nil {
+		return nil, err
+	}
+
+	if l.records > 0 {
+		lgr.Info().Int64("records", l.records).Int64("bytes", l.bytes).Str("dir", dir).Msg("wal backlog found")
+	}
+
+	return l, nil
+}
+
+// Append - 레코드를 기록하고 디스크에 fsync한 뒤 반환합니다.
+func (l *Log) Append(payload []byte) error {
+	if len(payload) > maxRecordSize {
+		return ErrRecordTooLarge
+	}
+
+	buf := make([]byte, recordHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.Checksum(payload, crcTable))
+	copy(buf[recordHeaderSize:], payload)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return ErrClosed
+	}
+
+	if l.activeSize >= l.segmentSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.active.Write(buf)
+	if err != nil {
+		// 일부만 기록된 레코드는 다음 레코드를 읽을 수 없게 하므로 잘라낸다
+		if n > 0 {
+			l.active.Truncate(l.activeSize)
+			l.active.Seek(l.activeSize, io.SeekStart)
+		}
+		return err
+	}
+	if err := l.active.Sync(); err != nil {
+		return err
+	}
+
+	l.activeSize += int64(n)
+	l.records++
+	l.bytes += int64(n)
+	return nil
+}
+
+// Replay - 처리되지 않은 레코드를 기록 순서대로 fn에 전달합니다.
+// fn이 오류를 반환하면 해당 레코드부터 다음 Replay에서 다시 전달하며, 처리된 세그먼트는 삭제합니다.
+// 손상된 레코드를 만나면 해당 세그먼트의 나머지를 건너뜁니다.
+func (l *Log) Replay(fn func(payload []byte) error) error {
+	return l.ReplayBatch(1, func(payloads [][]byte) (int, error) {
+		if err := fn(payloads[0]); err != nil {
+			return 0, err
+		}
+		return 1, nil
+	})
+}
+
+// ReplayBatch - 처리되지 않은 레코드를 기록 순서대로 최대 batchSize건씩 묶어 fn에 전달하고, 묶음마다 처리 위치를 저장합니다.
+// 묶음은 세그먼트를 넘지 않습니다. fn이 오류를 반환할 때는 앞에서부터 처리를 마친 레코드 수를 함께 반환하며,
+// 그 다음 레코드부터 다음 Replay에서 다시 전달합니다.
+func (l *Log) ReplayBatch(batchSize int, fn func(payloads [][]byte) (int, error)) error {
+	batchSize = max(batchSize, 1)
+
+	l.replayMu.Lock()
+	defer l.replayMu.Unlock()
+
+	for {
+		// 1. 처리할 범위 확인 : 진행 중인 세그먼트는 현재 기록된 위치까지만 읽는다
+		l.mu.Lock()
+		if l.closed {
+			l.mu.Unlock()
+			return ErrClosed
+		}
+		cp := l.cp
+		activeSeq, end := l.activeSeq, l.activeSize
+		l.mu.Unlock()
+
+		if cp.seq < activeSeq {
+			info, err := os.Stat(l.segmentPath(cp.seq))
+			if errors.Is(err, os.ErrNotExist) {
+				if err := l.advance(position{seq: cp.seq + 1}, 0, 0); err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			end = info.Size()
+		}
+
+		// 2. 세그먼트를 모두 처리한 경우
+		if cp.offset >= end {
+			if cp.seq < activeSeq {
+				if err := l.advance(position{seq: cp.seq + 1}, 0, 0); err != nil {
+					return err
+				}
+				os.Remove(l.segmentPath(cp.seq))
+				continue
+			}
+			drained, err := l.recycle()
+			if err != nil || drained {
+				return err
+			}
+			continue
+		}
+
+		// 3. 레코드 재전송
+		if err := l.replaySegment(cp, end, batchSize, fn); err != nil {
+			return err
+		}
+	}
+}
+
+func (l *Log) replaySegment(cp position, end int64, batchSize int, fn func(payloads [][]byte) (int, error)) error {
+	f, err := os.Open(l.segmentPath(cp.seq))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(cp.offset, io.SeekStart); err != nil {
+		return err
+	}
+	r := bufio.NewReader(io.LimitReader(f, end-cp.offset))
+
+	offset, read := cp.offset, cp.offset
+	for offset < end {
+		// 1. 최대 batchSize건의 레코드를 읽는다
+		payloads := make([][]byte, 0, batchSize)
+		var corrupt error
+		for len(payloads) < batchSize && read < end {
+			payload, err := readRecord(r)
+			if err != nil {
+				corrupt = err
+				break
+			}
+			payloads = append(payloads, payload)
+			read += int64(recordHeaderSize + len(payload))
+		}
+
+		// 2. 묶음을 전달하고 처리된 레코드까지 위치를 저장한다
+		if len(payloads) > 0 {
+			n, fnErr := fn(payloads)
+			if fnErr != nil {
+				payloads = payloads[:min(max(n, 0), len(payloads))]
+			}
+			var size int64
+			for _, payload := range payloads {
+				size += int64(recordHeaderSize + len(payload))
+			}
+			offset += size
+			if err := l.advance(position{seq: cp.seq, offset: offset}, int64(len(payloads)), size); err != nil {
+				return err
+			}
+			if fnErr != nil {
+				return fnErr
+			}
+		}
+
+		if corrupt != nil {
+			// 남은 레코드는 신뢰할 수 없으므로 세그먼트 끝으로 이동한다
+			l.logger.Error().Err(corrupt).Uint64("segment", cp.seq).Int64("offset", read).Msg("skipping corrupt wal segment tail")
+			if err := l.advance(position{seq: cp.seq, offset: end}, 0, 0); err != nil {
+				return err
+			}
+			return l.recount()
+		}
+	}
+	return nil
+}
+
+// Stats - 처리되지 않은 레코드 수와 크기를 반환합니다.
+func (l *Log) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return Stats{
+		Records:  l.records,
+		Bytes:    l.bytes,
+		Segments: int(l.activeSeq-l.cp.seq) + 1,
+	}
+}
+
+// Backlog - 처리되지 않은 레코드 수를 반환합니다.
+func (l *Log) Backlog() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.records
+}
+
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	return l.active.Close()
+}
+
+// 처리 위치를 저장하고 backlog 집계를 줄인다.
+func (l *Log) advance(cp position, records, bytes int64) error {
+	if err := l.saveCheckpoint(cp); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.cp = cp
+	l.records -= records
+	l.bytes -= bytes
+	l.mu.Unlock()
+	return nil
+}
+
+// 모든 레코드가 처리되었으면 진행 중인 세그먼트를 비우고 새 세그먼트로 교체한다.
+// 확인 사이에 새 레코드가 기록되었으면 false를 반환한다.
+func (l *Log) recycle() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed || l.cp.seq != l.activeSeq || l.cp.offset < l.activeSize {
+		return false, nil
+	}
+	if l.activeSize == 0 {
+		return true, nil
+	}
+
+	old := l.activeSeq
+	if err := l.rotate(); err != nil {
+		return false, err
+	}
+
+	cp := position{seq: l.activeSeq}
+	if err := l.saveCheckpoint(cp); err != nil {
+		return false, err
+	}
+	l.cp = cp
+	l.records, l.bytes = 0, 0
+	os.Remove(l.segmentPath(old))
+	return true, nil
+}
+
+// 진행 중인 세그먼트를 닫고 다음 세그먼트를 연다. (l.mu 보유 상태에서 호출)
+func (l *Log) rotate() error {
+	if err := l.active.Close(); err != nil {
+		return err
+	}
+	return l.openSegment(l.activeSeq + 1)
+}
+
+func (l *Log) openSegment(seq uint64) error {
+	f, err := os.OpenFile(l.segmentPath(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	// 새 파일이 디렉터리에 남도록 디렉터리도 fsync 한다
+	if err := syncDir(l.dir); err != nil {
+		f.Close()
+		return err
+	}
+
+	l.active = f
+	l.activeSeq = seq
+	l.activeSize = info.Size()
+	return nil
+}
+
+// 세그먼트의 start 위치부터 유효한 레코드 수와 크기, 마지막 유효 위치를 구한다.
+func (l *Log) scan(seq uint64, start int64) (count, size, valid int64, err error) {
+	f, err := os.Open(l.segmentPath(seq))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(start, io.SeekStart); err != nil {
+		return 0, 0, 0, err
+	}
+	r := bufio.NewReader(f)
+
+	valid = start
+	for {
+		payload, err := readRecord(r)
+		if err != nil {
+			break
+		}
+		count++
+		n := int64(recordHeaderSize + len(payload))
+		size += n
+		valid += n
+	}
+	return count, size, valid, nil
+}
+
+// 손상된 레코드를 건너뛴 뒤에는 건너뛴 레코드 수를 알 수 없으므로 남은 세그먼트를 다시 집계한다.
+func (l *Log) recount() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var records, bytes int64
+	for seq := l.cp.seq; seq <= l.activeSeq; seq++ {
+		start := int64(0)
+		if seq == l.cp.seq {
+			start = l.cp.offset
+		}
+
+		count, size, _, err := l.scan(seq, start)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		records += count
+		bytes += size
+	}
+
+	l.records, l.bytes = records, bytes
+	return nil
+}
+
+// 기록 도중 종료되어 남은 불완전한 레코드를 잘라낸다.
+func (l *Log) truncateTail(seq uint64, valid int64) error {
+	path := l.segmentPath(seq)
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() <= valid {
+		return nil
+	}
+
+	l.logger.Error().Uint64("segment", seq).Int64("size", info.Size()).Int64("valid", valid).Msg("truncating incomplete wal record")
+	return os.Truncate(path, valid)
+}
+
+func (l *Log) segments() ([]uint64, error) {
+	entries, err := os.ReadDir(l.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var seqs []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	return seqs, nil
+}
+
+func (l *Log) segmentPath(seq uint64) string {
+	return filepath.Join(l.dir, fmt.Sprintf("%020d%s", seq, segmentExt))
+}
+
+func (l *Log) loadCheckpoint() (position, error) {
+	content, err := os.ReadFile(filepath.Join(l.dir, checkpointFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return position{}, nil
+	}
+	if err != nil {
+		return position{}, err
+	}
+
+	seqStr, offsetStr, ok := strings.Cut(strings.TrimSpace(string(content)), " ")
+	if !ok {
+		return position{}, ErrInvalidCheckpnt
+	}
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	if err != nil {
+		return position{}, ErrInvalidCheckpnt
+	}
+	offset, err := strconv.ParseInt(offsetStr, 10, 64)
+	if err != nil || offset < 0 {
+		return position{}, ErrInvalidCheckpnt
+	}
+	return position{seq: seq, offset: offset}, nil
+}
+
+// 임시 파일에 기록 후 rename 하여 중간에 종료되어도 이전 또는 새 위치 중 하나만 남도록 한다.
+func (l *Log) saveCheckpoint(cp position) error {
+	path := filepath.Join(l.dir, checkpointFile)
+	tmp := path + ".tmp"
+
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(f, "%d %d\n", cp.seq, cp.offset); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(l.dir)
+}
+
+// 레코드 하나를 읽고 체크섬을 검증한다.
+func readRecord(r io.Reader) ([]byte, error) {
+	var header [recordHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrCorruptRecord
+		}
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[0:4])
+	if size > maxRecordSize {
+		return nil, ErrCorruptRecord
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, ErrCorruptRecord
+	}
+	if crc32.Checksum(payload, crcTable) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, ErrCorruptRecord
+	}
+	return payload, nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
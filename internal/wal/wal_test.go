@@ -0,0 +1,247 @@
+package wal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-rest-example/internal/logger"
+)
+
+func openTestLog(t *testing.T, dir string, segmentSize int64) *Log {
+	t.Helper()
+
+	l, err := Open(dir, segmentSize, logger.Setup("error", "test"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func appendRecords(t *testing.T, l *Log, from, to int) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		if err := l.Append([]byte(fmt.Sprintf("record-%03d", i))); err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+	}
+}
+
+// Replay가 전달한 레코드를 모두 모은다
+func replayAll(t *testing.T, l *Log) []string {
+	t.Helper()
+
+	var got []string
+	if err := l.Replay(func(payload []byte) error {
+		got = append(got, string(payload))
+		return nil
+	}); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	return got
+}
+
+func segmentCount(t *testing.T, dir string) int {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(matches)
+}
+
+func TestAppendReplay(t *testing.T) {
+	l := openTestLog(t, t.TempDir(), 0)
+	appendRecords(t, l, 0, 3)
+
+	if stats := l.Stats(); stats.Records != 3 || stats.Bytes != 3*(recordHeaderSize+10) || stats.Segments != 1 {
+		t.Errorf("Stats = %+v", stats)
+	}
+
+	got := replayAll(t, l)
+	if len(got) != 3 || got[0] != "record-000" || got[2] != "record-002" {
+		t.Errorf("Replay = %v", got)
+	}
+	if l.Backlog() != 0 {
+		t.Errorf("Backlog after Replay = %d, want 0", l.Backlog())
+	}
+
+	// 처리된 레코드는 다시 전달하지 않는다
+	appendRecords(t, l, 3, 4)
+	if got := replayAll(t, l); len(got) != 1 || got[0] != "record-003" {
+		t.Errorf("second Replay = %v", got)
+	}
+}
+
+func TestReplayResumesAfterError(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, dir, 0)
+	appendRecords(t, l, 0, 5)
+
+	// 세 번째 레코드 처리에 실패하면 거기서 멈춘다
+	errSink := errors.New("sink failed")
+	var got []string
+	err := l.Replay(func(payload []byte) error {
+		if string(payload) == "record-002" {
+			return errSink
+		}
+		got = append(got, string(payload))
+		return nil
+	})
+	if !errors.Is(err, errSink) {
+		t.Fatalf("Replay: err = %v, want errSink", err)
+	}
+	if len(got) != 2 || l.Backlog() != 3 {
+		t.Fatalf("Replay delivered %v, backlog %d", got, l.Backlog())
+	}
+
+	// 다시 열어도 체크포인트 이후의 레코드부터 전달한다
+	l.Close()
+	l = openTestLog(t, dir, 0)
+	if l.Backlog() != 3 {
+		t.Errorf("Backlog after reopen = %d, want 3", l.Backlog())
+	}
+	if got := replayAll(t, l); len(got) != 3 || got[0] != "record-002" {
+		t.Errorf("Replay after reopen = %v", got)
+	}
+}
+
+func TestReplayBatch(t *testing.T) {
+	dir := t.TempDir()
+	// 레코드 네 건마다 새 세그먼트
+	l := openTestLog(t, dir, 4*(recordHeaderSize+10))
+	appendRecords(t, l, 0, 10)
+
+	// 묶음은 batchSize와 세그먼트를 넘지 않고, 실패하면 처리를 마친 레코드 다음부터 다시 전달한다
+	errSink := errors.New("sink failed")
+	var sizes []int
+	err := l.ReplayBatch(3, func(payloads [][]byte) (int, error) {
+		sizes = append(sizes, len(payloads))
+		if string(payloads[len(payloads)-1]) == "record-006" {
+			return 2, errSink
+		}
+		return len(payloads), nil
+	})
+	if !errors.Is(err, errSink) {
+		t.Fatalf("ReplayBatch: err = %v, want errSink", err)
+	}
+	if fmt.Sprint(sizes) != "[3 1 3]" || l.Backlog() != 4 {
+		t.Fatalf("batch sizes = %v, backlog %d, want [3 1 3] and 4", sizes, l.Backlog())
+	}
+
+	var got []string
+	if err := l.ReplayBatch(3, func(payloads [][]byte) (int, error) {
+		for _, payload := range payloads {
+			got = append(got, string(payload))
+		}
+		return len(payloads), nil
+	}); err != nil {
+		t.Fatalf("ReplayBatch: %v", err)
+	}
+	if len(got) != 4 || got[0] != "record-006" || got[3] != "record-009" || l.Backlog() != 0 {
+		t.Errorf("ReplayBatch after error = %v, backlog %d", got, l.Backlog())
+	}
+}
+
+func TestSegmentRotation(t *testing.T) {
+	dir := t.TempDir()
+	// 레코드 두 건마다 새 세그먼트
+	l := openTestLog(t, dir, 2*(recordHeaderSize+10))
+	appendRecords(t, l, 0, 7)
+
+	if n := segmentCount(t, dir); n != 4 {
+		t.Errorf("%d segments, want 4", n)
+	}
+	if stats := l.Stats(); stats.Segments != 4 || stats.Records != 7 {
+		t.Errorf("Stats = %+v", stats)
+	}
+
+	got := replayAll(t, l)
+	if len(got) != 7 || got[6] != "record-006" {
+		t.Errorf("Replay = %v", got)
+	}
+
+	// 처리된 세그먼트는 삭제한다
+	if n := segmentCount(t, dir); n != 1 {
+		t.Errorf("%d segments after Replay, want 1", n)
+	}
+	if stats := l.Stats(); stats.Segments != 1 || stats.Records != 0 || stats.Bytes != 0 {
+		t.Errorf("Stats after Replay = %+v", stats)
+	}
+}
+
+func TestTornTail(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, dir, 0)
+	appendRecords(t, l, 0, 2)
+	l.Close()
+
+	// 기록 도중 종료되어 헤더만 일부 남은 레코드
+	path := filepath.Join(dir, fmt.Sprintf("%020d%s", 1, segmentExt))
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 10, 1, 2})
+	f.Close()
+
+	l = openTestLog(t, dir, 0)
+	if info, _ := os.Stat(path); info.Size() != 2*(recordHeaderSize+10) {
+		t.Errorf("segment size = %d, want torn record truncated", info.Size())
+	}
+	appendRecords(t, l, 2, 3)
+	if got := replayAll(t, l); len(got) != 3 || got[2] != "record-002" {
+		t.Errorf("Replay = %v", got)
+	}
+}
+
+func TestCorruptRecordSkipped(t *testing.T) {
+	dir := t.TempDir()
+	l := openTestLog(t, dir, 0)
+	appendRecords(t, l, 0, 3)
+
+	// 두 번째 레코드의 payload를 변경하면 체크섬이 맞지 않는다
+	path := filepath.Join(dir, fmt.Sprintf("%020d%s", 1, segmentExt))
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteAt([]byte("X"), 2*recordHeaderSize+10)
+	f.Close()
+
+	if got := replayAll(t, l); len(got) != 1 || got[0] != "record-000" {
+		t.Errorf("Replay = %v, want only the record before the corruption", got)
+	}
+	if l.Backlog() != 0 {
+		t.Errorf("Backlog = %d, want 0", l.Backlog())
+	}
+}
+
+func TestAppendLimits(t *testing.T) {
+	l := openTestLog(t, t.TempDir(), 0)
+
+	if err := l.Append(make([]byte, maxRecordSize+1)); !errors.Is(err, ErrRecordTooLarge) {
+		t.Errorf("Append oversized record: err = %v, want ErrRecordTooLarge", err)
+	}
+	if err := l.Append(make([]byte, maxRecordSize)); err != nil {
+		t.Errorf("Append max size record: %v", err)
+	}
+
+	l.Close()
+	if err := l.Append([]byte("x")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Append after Close: err = %v, want ErrClosed", err)
+	}
+	if err := l.Replay(func([]byte) error { return nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("Replay after Close: err = %v, want ErrClosed", err)
+	}
+}
+
+func TestOpenRequiresDir(t *testing.T) {
+	if _, err := Open("", 0, logger.Setup("error", "test")); !errors.Is(err, ErrInvalidDir) {
+		t.Errorf("Open(\"\"): err = %v, want ErrInvalidDir", err)
+	}
+}
@@ -0,0 +1,472 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+	"go-rest-example/internal/model/external"
+)
+
+// 웹훅 전송 기본값
+const (
+	defaultMaxAttempts  = 8
+	defaultBackoff      = 10 * time.Second
+	defaultMaxBackoff   = time.Hour
+	defaultTimeout      = 10 * time.Second
+	defaultPollInterval = 5 * time.Second
+	deliverBatch        = 100 // 한 번에 조회할 전송 시간이 된 전송 수
+	deliverConcurrency  = 8   // 동시에 전송할 구독 수
+	maxResponseBody     = 64 << 10
+)
+
+// 수신 측이 확인하는 요청 헤더
+const (
+	HeaderEvent     = "X-Webhook-Event"     // 이벤트 종류
+	HeaderEventID   = "X-Webhook-ID"        // 이벤트 ID (재전송되어도 같은 값, 수신 측 중복 제거용)
+	HeaderDelivery  = "X-Webhook-Delivery"  // 전송 ID
+	HeaderTimestamp = "X-Webhook-Timestamp" // 서명 시간 (unix seconds)
+	HeaderSignature = "X-Webhook-Signature" // sha256=hex(HMAC-SHA256(secret, timestamp + "." + body))
+)
+
+var ErrInvalidDispatcherRequired = errors.New("missing required inputs to create webhook Dispatcher")
+
+type Config struct {
+	MaxAttempts  int           // 최대 전송 시도 횟수, 넘으면 dead로 남김 (기본값 8)
+	Backoff      time.Duration // 첫 재시도 대기 시간, 실패할 때마다 두 배 (기본값 10초)
+	MaxBackoff   time.Duration // 재시도 대기 시간 상한 (기본값 1시간)
+	Timeout      time.Duration // 전송 요청 한 건의 제한 시간 (기본값 10초)
+	PollInterval time.Duration // 전송 시간이 된 전송 조회 주기 (기본값 5초)
+
+	AllowPrivateTargets bool // 루프백/사설/링크 로컬 등 내부 주소로의 전송 허용 (개발 및 시험용, 기본값 false)
+}
+
+// Stats는 웹훅 전송 현황입니다.
+type Stats struct {
+	Published  int64     `json:"published"`            // 전달받은 이벤트 수 (기동 이후)
+	Created    int64     `json:"created"`              // 기록한 구독별 전송 수 (기동 이후)
+	Delivered  int64     `json:"delivered"`            // 전송에 성공한 수 (기동 이후)
+	Failed     int64     `json:"failed"`               // 실패한 전송 시도 수 (기동 이후)
+	Dead       int64     `json:"dead"`                 // 최대 시도 횟수를 넘어 dead로 남긴 전송 수 (기동 이후)
+	LastPollAt time.Time `json:"lastPollAt,omitempty"` // 마지막 전송 조회 시간
+	LastError  string    `json:"lastError,omitempty"`  // 마지막 기록/전송 조회 오류
+}
+
+// Dispatcher는 outbox에서 전달받은 이벤트를 구독별 전송으로 기록한 뒤 구독 URL로 전송합니다.
+// 전송은 데이터베이스에 pending으로 기록되므로 재기동되어도 이어서 전송하며(at-least-once),
+// 실패하면 Backoff부터 두 배씩(MaxBackoff 이하) 기다려 재시도하고 MaxAttempts를 넘으면 dead로 남깁니다.
+// 여러 인스턴스가 함께 전송해도 전송마다 먼저 가져간(Claim) 인스턴스만 전송합니다.
+// 이미 기록한 이벤트를 outbox가 다시 전달해도 구독마다 전송은 하나만 기록합니다.
+// 같은 구독의 전송은 기록된 순서대로 보내지만, 재시도되는 전송은 이후 이벤트보다 늦게 도착할 수 있습니다.
+// 수신 측은 HeaderSignature로 본문을 검증하고, HeaderEventID로 중복 수신을 걸러야 합니다.
+type Dispatcher struct {
+	cfg    Config
+	txMgr  db.TxManager
+	repo   db.WebhooksDataService
+	client *http.Client
+	logger *logger.AppLogger
+
+	wake chan struct{}
+
+	mu         sync.Mutex
+	lastPollAt time.Time
+	lastError  string
+
+	published atomic.Int64
+	created   atomic.Int64
+	delivered atomic.Int64
+	failed    atomic.Int64
+	dead      atomic.Int64
+}
+
+func NewDispatcher(lgr *logger.AppLogger, txMgr db.TxManager, repo db.WebhooksDataService, cfg Config) (*Dispatcher, error) {
+	if lgr == nil || txMgr == nil || repo == nil {
+		return nil, ErrInvalidDispatcherRequired
+	}
+
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = defaultBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = defaultMaxBackoff
+	}
+	cfg.MaxBackoff = max(cfg.MaxBackoff, cfg.Backoff)
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+
+	return &Dispatcher{
+		cfg:    cfg,
+		txMgr:  txMgr,
+		repo:   repo,
+		client: newClient(cfg),
+		logger: lgr,
+		wake:   make(chan struct{}, 1),
+	}, nil
+}
+
+// 내부 주소로의 전송을 허용하지 않으면 연결할 때마다 주소를 확인하는 클라이언트를 만든다.
+// 프록시를 거치면 실제 연결 주소를 확인할 수 없으므로 환경 변수의 프록시 설정은 사용하지 않는다.
+func newClient(cfg Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !cfg.AllowPrivateTargets {
+		dialer := &net.Dialer{Timeout: cfg.Timeout, KeepAlive: 30 * time.Second, Control: dialControl}
+		transport.Proxy = nil
+		transport.DialContext = dialer.DialContext
+	}
+	return &http.Client{Timeout: cfg.Timeout, Transport: transport}
+}
+
+// Name - outbox 전달 대상 이름
+func (d *Dispatcher) Name() string {
+	return "webhook"
+}
+
+// Stats - 전송 현황을 반환합니다.
+func (d *Dispatcher) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return Stats{
+		Published:  d.published.Load(),
+		Created:    d.created.Load(),
+		Delivered:  d.delivered.Load(),
+		Failed:     d.failed.Load(),
+		Dead:       d.dead.Load(),
+		LastPollAt: d.lastPollAt,
+		LastError:  d.lastError,
+	}
+}
+
+// Run - ctx가 종료될 때까지 전송 시간이 된 전송을 보냅니다.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.DeliverDue(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// 새로 기록한 전송을 다음 조회 주기를 기다리지 않고 보내도록 알린다
+func (d *Dispatcher) notify() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Publish - 이벤트를 구독한 활성 구독마다 pending 전송으로 기록합니다.
+// 기록하지 못하면 오류를 반환하여 outbox가 다시 전달하도록 하며, 이미 기록된 이벤트는 다시 기록하지 않습니다.
+func (d *Dispatcher) Publish(ctx context.Context, events []data.Event) error {
+	subs, err := d.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return d.fail(err, "failed to select webhook subscriptions")
+	}
+
+	now := time.Now()
+	var deliveries []data.WebhookDelivery
+	for i := range events {
+		event := &events[i]
+
+		var payload []byte
+		for j := range subs {
+			if !subs[j].Matches(event.Type) {
+				continue
+			}
+			if payload == nil {
+				if payload, err = json.Marshal(external.NewEventRes(event)); err != nil {
+					return d.fail(err, "failed to encode webhook event")
+				}
+			}
+			deliveries = append(deliveries, newDelivery(subs[j].SubscriptionID, event, payload, now))
+		}
+	}
+	d.published.Add(int64(len(events)))
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := d.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		return d.fail(err, "failed to create webhook deliveries")
+	}
+
+	d.created.Add(int64(len(deliveries)))
+	d.notify()
+	return nil
+}
+
+func newDelivery(subscriptionID int64, event *data.Event, payload []byte, now time.Time) data.WebhookDelivery {
+	return data.WebhookDelivery{
+		SubscriptionID: subscriptionID,
+		EventID:        event.EventID,
+		EventType:      event.Type,
+		Payload:        payload,
+		State:          data.DeliveryPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}
+}
+
+// DeliverDue - 전송 시간이 된 전송을 모두 보내고 시도한 전송 수를 반환합니다.
+func (d *Dispatcher) DeliverDue(ctx context.Context, now time.Time) int {
+	d.mu.Lock()
+	d.lastPollAt = now
+	d.mu.Unlock()
+
+	attempted := 0
+	for ctx.Err() == nil {
+		due, err := d.repo.ListDue(ctx, now, deliverBatch)
+		if err != nil {
+			d.fail(err, "failed to select due webhook deliveries")
+			return attempted
+		}
+		if len(due) == 0 {
+			return attempted
+		}
+
+		subs, err := d.subscriptions(ctx)
+		if err != nil {
+			d.fail(err, "failed to select webhook subscriptions")
+			return attempted
+		}
+
+		// 구독마다 기록된 순서대로 보내고, 서로 다른 구독은 deliverConcurrency 개까지 동시에 보낸다
+		var order []int64
+		bySub := make(map[int64][]*data.WebhookDelivery)
+		for i := range due {
+			subscriptionID := due[i].SubscriptionID
+			if _, ok := bySub[subscriptionID]; !ok {
+				order = append(order, subscriptionID)
+			}
+			bySub[subscriptionID] = append(bySub[subscriptionID], &due[i])
+		}
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, deliverConcurrency)
+		var claimed atomic.Int64
+		for _, subscriptionID := range order {
+			sub, ok := subs[subscriptionID]
+			if !ok {
+				continue
+			}
+
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(deliveries []*data.WebhookDelivery) {
+				defer func() { <-sem; wg.Done() }()
+				for _, delivery := range deliveries {
+					if d.attempt(ctx, sub, delivery, now) {
+						claimed.Add(1)
+					}
+				}
+			}(bySub[subscriptionID])
+		}
+		wg.Wait()
+
+		attempted += int(claimed.Load())
+		// 다른 인스턴스가 모두 가져갔으면 다음 주기에 다시 조회한다
+		if len(due) < deliverBatch || claimed.Load() == 0 {
+			return attempted
+		}
+	}
+
+	return attempted
+}
+
+func (d *Dispatcher) subscriptions(ctx context.Context) (map[int64]*data.WebhookSubscription, error) {
+	subs, err := d.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*data.WebhookSubscription, len(subs))
+	for i := range subs {
+		byID[subs[i].SubscriptionID] = &subs[i]
+	}
+	return byID, nil
+}
+
+// 전송을 가져와 보내고 결과를 기록한다. 다른 인스턴스가 먼저 가져갔으면 false를 반환한다.
+func (d *Dispatcher) attempt(ctx context.Context, sub *data.WebhookSubscription, delivery *data.WebhookDelivery, now time.Time) bool {
+	// 1. 전송 권한 획득 (결과를 기록하지 못하고 종료되면 제한 시간의 두 배 이후 다시 전송)
+	ok, err := d.repo.Claim(ctx, delivery.DeliveryID, now, now.Add(2*d.cfg.Timeout))
+	if err != nil || !ok {
+		return false
+	}
+
+	// 2. 전송
+	statusCode, sendErr := d.send(ctx, sub, delivery.DeliveryID, delivery.EventID, delivery.EventType, delivery.Payload)
+
+	// 3. 결과 기록 : 성공, 재시도(지수 백오프), 최대 시도 횟수 초과 시 dead
+	attemptedAt := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &attemptedAt
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = ""
+
+	switch {
+	case sendErr == nil:
+		delivery.State = data.DeliveryDelivered
+		delivery.DeliveredAt = &attemptedAt
+		d.delivered.Add(1)
+	case delivery.Attempts >= d.cfg.MaxAttempts:
+		delivery.State = data.DeliveryDead
+		delivery.LastError = sendErr.Error()
+		d.failed.Add(1)
+		d.dead.Add(1)
+		d.logger.Error().Err(sendErr).Int64("deliveryID", delivery.DeliveryID).Int("attempts", delivery.Attempts).
+			Msg("webhook delivery exceeded max attempts")
+	default:
+		delivery.NextAttemptAt = attemptedAt.Add(d.backoff(delivery.Attempts))
+		delivery.LastError = sendErr.Error()
+		d.failed.Add(1)
+	}
+
+	if err := d.repo.RecordAttempt(ctx, delivery); err != nil {
+		d.fail(err, "failed to record webhook delivery attempt")
+	}
+	return true
+}
+
+// 실패한 시도 횟수에 따른 재시도 대기 시간 : Backoff * 2^(attempts-1), MaxBackoff 이하
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.cfg.Backoff
+	for i := 1; i < attempts && wait < d.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.cfg.MaxBackoff)
+}
+
+// 서명한 본문을 구독 URL로 보내고 응답 상태 코드를 반환한다. 2xx가 아니면 오류이다.
+func (d *Dispatcher) send(
+	ctx context.Context,
+	sub *data.WebhookSubscription,
+	deliveryID int64,
+	eventID string,
+	eventType data.EventType,
+	payload []byte,
+) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(eventType))
+	req.Header.Set(HeaderEventID, eventID)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(deliveryID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Ping - 구독 URL로 webhook.ping 이벤트를 바로 보내고 결과를 반환합니다. (전송 기록은 남기지 않음)
+func (d *Dispatcher) Ping(ctx context.Context, sub *data.WebhookSubscription) external.WebhookTestRes {
+	event, err := data.NewEvent(data.EventWebhookPing, "", time.Now(), map[string]int64{"subscriptionID": sub.SubscriptionID})
+	if err != nil {
+		return external.WebhookTestRes{Error: err.Error()}
+	}
+	payload, err := json.Marshal(external.NewEventRes(&event))
+	if err != nil {
+		return external.WebhookTestRes{Error: err.Error()}
+	}
+
+	start := time.Now()
+	statusCode, err := d.send(ctx, sub, 0, event.EventID, event.Type, payload)
+	res := external.WebhookTestRes{
+		Delivered:  err == nil,
+		StatusCode: statusCode,
+		Duration:   time.Since(start).String(),
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// DeleteSubscription - 웹훅 구독과 구독의 전송 기록을 하나의 트랜잭션으로 삭제합니다.
+func (d *Dispatcher) DeleteSubscription(ctx context.Context, subscriptionID int64) error {
+	return d.txMgr.WithTx(ctx, func(tx db.DBTX) error {
+		return d.repo.WithTx(tx).DeleteSubscription(ctx, subscriptionID)
+	})
+}
+
+// Requeue - dead로 남은 전송을 바로 다시 보내도록 합니다.
+func (d *Dispatcher) Requeue(ctx context.Context, subscriptionID int64, deliveryID int64) (*data.WebhookDelivery, error) {
+	delivery, err := d.repo.Requeue(ctx, subscriptionID, deliveryID, time.Now())
+	if err != nil {
+		return delivery, err
+	}
+
+	d.notify()
+	return delivery, nil
+}
+
+// Sign - 웹훅 본문의 서명 헤더 값을 만듭니다. 수신 측은 같은 방법으로 계산한 값과 비교합니다.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret - 서명 키를 만듭니다. (32바이트 난수의 hex)
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (d *Dispatcher) fail(err error, msg string) error {
+	d.logger.Error().Err(err).Msg(msg)
+
+	d.mu.Lock()
+	d.lastError = err.Error()
+	d.mu.Unlock()
+	return err
+}
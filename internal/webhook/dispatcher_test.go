@@ -0,0 +1,320 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go-rest-example/internal/db"
+	"go-rest-example/internal/logger"
+	"go-rest-example/internal/model/data"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// 수신한 요청을 기록하고 statuses 순서대로 응답하는 웹훅 수신 서버
+type testReceiver struct {
+	t        *testing.T
+	statuses []int
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rv *testReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rv.mu.Lock()
+	n := len(rv.requests)
+	rv.requests = append(rv.requests, r)
+	rv.bodies = append(rv.bodies, body)
+	rv.mu.Unlock()
+
+	// 수신 측과 같은 방법으로 서명을 검증한다
+	if got, want := r.Header.Get(HeaderSignature), Sign(testSecret, r.Header.Get(HeaderTimestamp), body); got != want {
+		rv.t.Errorf("signature = %q, want %q", got, want)
+	}
+
+	status := http.StatusOK
+	if n < len(rv.statuses) {
+		status = rv.statuses[n]
+	}
+	w.WriteHeader(status)
+}
+
+type testEnv struct {
+	dispatcher *Dispatcher
+	repo       db.WebhooksDataService
+	sub        data.WebhookSubscription
+}
+
+func newTestEnv(t *testing.T, url string, cfg Config) *testEnv {
+	t.Helper()
+	ctx := context.Background()
+	lgr := logger.Setup("error", "test")
+
+	mgr, err := db.NewSQLiteManager(filepath.Join(t.TempDir(), "test.db"), lgr)
+	if err != nil {
+		t.Fatalf("NewSQLiteManager: %v", err)
+	}
+	t.Cleanup(func() { mgr.Disconnect() })
+
+	migrator, err := db.NewMigrator(lgr, mgr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	repo, err := db.NewWebhooksRepo(lgr, mgr.DB(), mgr.Dialect())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dispatcher, err := NewDispatcher(lgr, mgr, repo, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	sub := data.WebhookSubscription{
+		URL:        url,
+		EventTypes: []data.EventType{data.EventDeviceCreated},
+		Secret:     testSecret,
+		Enabled:    true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if sub.SubscriptionID, err = repo.CreateSubscription(ctx, &sub); err != nil {
+		t.Fatalf("CreateSubscription: %v", err)
+	}
+
+	return &testEnv{dispatcher: dispatcher, repo: repo, sub: sub}
+}
+
+func (e *testEnv) publish(t *testing.T) data.Event {
+	t.Helper()
+
+	event, err := data.NewEvent(data.EventDeviceCreated, "ABC010001", time.Now(), map[string]string{"productNumber": "ABC010001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.dispatcher.Publish(context.Background(), []data.Event{event}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	return event
+}
+
+func (e *testEnv) delivery(t *testing.T) data.WebhookDelivery {
+	t.Helper()
+
+	deliveries, err := e.repo.ListDeliveries(context.Background(), data.DeliveryListQuery{SubscriptionID: e.sub.SubscriptionID})
+	if err != nil {
+		t.Fatalf("ListDeliveries: %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("%d deliveries, want 1", len(deliveries))
+	}
+	return deliveries[0]
+}
+
+// 재시도 대기 시간과 관계없이 바로 보내도록 충분히 이후 시각으로 전송한다
+func deliverNow(d *Dispatcher) int {
+	return d.DeliverDue(context.Background(), time.Now().Add(time.Hour))
+}
+
+func TestDeliverRetry(t *testing.T) {
+	receiver := &testReceiver{t: t, statuses: []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusNoContent}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	env := newTestEnv(t, server.URL, Config{MaxAttempts: 5, Backoff: time.Millisecond, AllowPrivateTargets: true})
+	event := env.publish(t)
+
+	// 같은 이벤트를 다시 전달받아도 전송은 하나만 기록한다
+	if err := env.dispatcher.Publish(context.Background(), []data.Event{event}); err != nil {
+		t.Fatalf("Publish again: %v", err)
+	}
+
+	for i, want := range []data.DeliveryState{data.DeliveryPending, data.DeliveryPending, data.DeliveryDelivered} {
+		if n := deliverNow(env.dispatcher); n != 1 {
+			t.Fatalf("attempt %d: DeliverDue = %d, want 1", i+1, n)
+		}
+		delivery := env.delivery(t)
+		if delivery.State != want || delivery.Attempts != i+1 {
+			t.Fatalf("attempt %d: state = %s, attempts = %d", i+1, delivery.State, delivery.Attempts)
+		}
+	}
+	if n := deliverNow(env.dispatcher); n != 0 {
+		t.Errorf("DeliverDue after delivered = %d, want 0", n)
+	}
+
+	delivery := env.delivery(t)
+	if delivery.LastStatusCode != http.StatusNoContent || delivery.DeliveredAt == nil || delivery.LastError != "" {
+		t.Errorf("delivered = %+v", delivery)
+	}
+
+	// 재시도되어도 이벤트 ID와 전송 ID, 본문은 같다
+	if len(receiver.requests) != 3 {
+		t.Fatalf("receiver got %d requests, want 3", len(receiver.requests))
+	}
+	for i, r := range receiver.requests {
+		if r.Header.Get(HeaderEventID) != event.EventID ||
+			r.Header.Get(HeaderEvent) != string(data.EventDeviceCreated) ||
+			r.Header.Get(HeaderDelivery) != strconv.FormatInt(delivery.DeliveryID, 10) ||
+			r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request %d headers = %v", i, r.Header)
+		}
+		if string(receiver.bodies[i]) != string(receiver.bodies[0]) {
+			t.Errorf("request %d body differs from the first attempt", i)
+		}
+	}
+
+	if stats := env.dispatcher.Stats(); stats.Published != 2 || stats.Delivered != 1 || stats.Failed != 2 || stats.Dead != 0 {
+		t.Errorf("Stats = %+v", stats)
+	}
+}
+
+func TestDeliverDead(t *testing.T) {
+	receiver := &testReceiver{t: t, statuses: []int{500, 500, 500}}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	env := newTestEnv(t, server.URL, Config{MaxAttempts: 2, Backoff: time.Millisecond, AllowPrivateTargets: true})
+	env.publish(t)
+
+	deliverNow(env.dispatcher)
+	deliverNow(env.dispatcher)
+	delivery := env.delivery(t)
+	if delivery.State != data.DeliveryDead || delivery.Attempts != 2 || delivery.LastStatusCode != 500 || delivery.LastError == "" {
+		t.Fatalf("delivery = %+v, want dead after 2 attempts", delivery)
+	}
+
+	// dead로 남은 전송은 다시 보내지 않는다
+	if n := deliverNow(env.dispatcher); n != 0 {
+		t.Errorf("DeliverDue after dead = %d, want 0", n)
+	}
+
+	// Requeue하면 처음부터 다시 보낸다
+	if _, err := env.dispatcher.Requeue(context.Background(), env.sub.SubscriptionID, delivery.DeliveryID); err != nil {
+		t.Fatalf("Requeue: %v", err)
+	}
+	deliverNow(env.dispatcher)
+	deliverNow(env.dispatcher)
+	if delivery := env.delivery(t); delivery.State != data.DeliveryDelivered {
+		t.Errorf("delivery after Requeue = %+v", delivery)
+	}
+	if stats := env.dispatcher.Stats(); stats.Dead != 1 {
+		t.Errorf("Dead = %d, want 1", stats.Dead)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{cfg: Config{Backoff: time.Second, MaxBackoff: 10 * time.Second}}
+
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 8 * time.Second, 5: 10 * time.Second, 30: 10 * time.Second} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	// printf '1700000000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686"
+	got := Sign("secret", "1700000000", []byte(`{"a":1}`))
+	if got != want {
+		t.Fatalf("Sign = %q, want %q", got, want)
+	}
+	for _, other := range []string{
+		Sign("other", "1700000000", []byte(`{"a":1}`)),
+		Sign("secret", "1700000001", []byte(`{"a":1}`)),
+		Sign("secret", "1700000000", []byte(`{"a":2}`)),
+	} {
+		if other == got {
+			t.Error("Sign does not depend on secret, timestamp and body")
+		}
+	}
+}
+
+func TestAllowedAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":          true,
+		"2606:2800:220:1::1":     true,
+		"127.0.0.1":              false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.0.1":            false,
+		"169.254.169.254":        false,
+		"100.100.100.200":        false,
+		"0.0.0.0":                false,
+		"224.0.0.1":              false,
+		"::1":                    false,
+		"fd00::1":                false,
+		"fe80::1":                false,
+		"::ffff:127.0.0.1":       false,
+		"64:ff9b::a9fe:a9fe":     false,
+		"::ffff:93.184.216.34":   true,
+		"255.255.255.255":        false,
+		"198.18.0.1":             false,
+		"2001:db8::1":            true,
+		"ff02::1":                false,
+		"192.0.0.170":            false,
+		"100.63.255.255":         true,
+		"::":                     false,
+		"fe80::1%eth0":           false,
+		"64:ff9b:1::a00:1":       false,
+		"203.0.113.1":            true,
+		"fc00::1":                false,
+		"::ffff:169.254.169.254": false,
+	} {
+		if got := allowedAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("allowedAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestCheckTarget(t *testing.T) {
+	ctx := context.Background()
+	d := &Dispatcher{cfg: Config{}}
+
+	for _, url := range []string{"http://127.0.0.1:8080/hook", "http://[::1]/hook", "http://169.254.169.254/latest/meta-data", "http://localhost/hook"} {
+		if err := d.CheckTarget(ctx, url); err == nil {
+			t.Errorf("CheckTarget(%s): expected error", url)
+		}
+	}
+	if err := d.CheckTarget(ctx, "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("CheckTarget(public address): %v", err)
+	}
+
+	d.cfg.AllowPrivateTargets = true
+	if err := d.CheckTarget(ctx, "http://127.0.0.1:8080/hook"); err != nil {
+		t.Errorf("CheckTarget with AllowPrivateTargets: %v", err)
+	}
+}
+
+// 등록 이후 주소가 바뀌어도 연결 시점에 내부 주소로의 전송을 막는다
+func TestSendBlocksPrivateTarget(t *testing.T) {
+	receiver := &testReceiver{t: t}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	env := newTestEnv(t, server.URL, Config{MaxAttempts: 1})
+	env.publish(t)
+	deliverNow(env.dispatcher)
+
+	if len(receiver.requests) != 0 {
+		t.Errorf("receiver got %d requests, want 0", len(receiver.requests))
+	}
+	if delivery := env.delivery(t); delivery.State != data.DeliveryDead || delivery.LastError == "" {
+		t.Errorf("delivery = %+v, want dead with dial error", delivery)
+	}
+}
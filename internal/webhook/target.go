@@ -0,0 +1,86 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"syscall"
+)
+
+var ErrForbiddenTarget = errors.New("webhook target address is not allowed")
+
+// IsPrivate/IsLoopback 등으로 구분되지 않는 내부 대역
+// (CGNAT 및 일부 클라우드 메타데이터, IETF 프로토콜 할당, 벤치마크, NAT64)
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// 웹훅을 보낼 수 있는 주소인지 확인한다.
+// 루프백, 사설, 링크 로컬(클라우드 메타데이터 169.254.169.254 포함), 멀티캐스트, 미지정 주소 및 내부 대역은 허용하지 않는다.
+func allowedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// 연결 직전에 실제로 연결할 주소를 확인한다.
+// 등록 이후 DNS 응답이 바뀌거나(DNS rebinding) 리다이렉트되어도 내부 주소로는 연결하지 않는다.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, address)
+	}
+	if !allowedAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, addrPort.Addr())
+	}
+	return nil
+}
+
+// CheckTarget - 구독 URL의 호스트가 가리키는 모든 주소가 웹훅을 보낼 수 있는 주소인지 확인합니다.
+// AllowPrivateTargets 설정 시에는 확인하지 않습니다.
+func (d *Dispatcher) CheckTarget(ctx context.Context, rawURL string) error {
+	if d.cfg.AllowPrivateTargets {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !allowedAddr(addr) {
+			return fmt.Errorf("%w: %s", ErrForbiddenTarget, addr)
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("%w: cannot resolve %s", ErrForbiddenTarget, host)
+	}
+	for _, addr := range addrs {
+		if !allowedAddr(addr) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenTarget, host, addr)
+		}
+	}
+	return nil
+}
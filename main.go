@@ -1,4 +1,4 @@
-package gorestexample
+package main
 
 import (
 	"context"
@@ -7,13 +7,17 @@ import (
 
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"go-rest-example/internal/archive"
 	"go-rest-example/internal/db"
 	"go-rest-example/internal/logger"
 	"go-rest-example/internal/model"
+	"go-rest-example/internal/model/external"
 	"go-rest-example/internal/server"
 )
 
@@ -22,6 +26,55 @@ const (
 	serviceName = ""
 	defaultPort = "8080"
 	defaultLogLevel = "info"
+	defaultFirmwareDir = "./firmware"
+	defaultDBDriver = "mariadb"
+	defaultSQLitePath = "./data/iot_device.db"
+	defaultDownloadMaxConcurrent = 100
+	defaultDownloadMaxPerGroup = 20
+	defaultDownloadGroupPrefix = 3
+	defaultDBMaxOpenConns = 10
+	defaultDBMaxIdleConns = 10
+	defaultDBConnMaxLifetime = 3 * time.Minute
+	defaultDBDialTimeout = 10 * time.Second
+	defaultDBCharset = "utf8mb4"
+	defaultDBConnectAttempts = 10
+	defaultDBConnectBackoff = time.Second
+	defaultDBConnectMaxBackoff = 30 * time.Second
+	defaultDBBreakerFailures = 5
+	defaultDBBreakerOpenTimeout = 10 * time.Second
+	defaultReportWALDir = "./data/wal"
+	defaultReportWALSegmentBytes = 16 << 20
+	defaultReportReplayInterval = 5 * time.Second
+	defaultReportBatchSize = 200
+	defaultReportFlushInterval = time.Second
+	defaultReportQueueSize = 10000
+	defaultDeviceCacheSize = 10000
+	defaultDeviceCacheTTL = 30 * time.Second
+	defaultDeviceCacheNegativeTTL = 5 * time.Second
+	defaultFleetOfflineCycles = 3
+	defaultFleetLowBattery = 20
+	defaultFleetOverviewCacheTTL = 10 * time.Second
+	defaultAlertSweepInterval = time.Minute
+	defaultAlertQueueSize = 10000
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookBackoff = 10 * time.Second
+	defaultWebhookMaxBackoff = time.Hour
+	defaultWebhookTimeout = 10 * time.Second
+	defaultWebhookPollInterval = 5 * time.Second
+	defaultOutboxPollInterval = time.Second
+	defaultOutboxBatchSize = 500
+	defaultOutboxLeaseTTL = 30 * time.Second
+	defaultOutboxSinks = "webhook"
+	defaultOutboxBrokerTopic = "device-events"
+	defaultStreamBufferSize = 256
+	defaultStreamMaxSubscribers = 1000
+	defaultStreamHeartbeat = 15 * time.Second
+	defaultRollupHourlyRetention = 90 * 24 * time.Hour
+	defaultRetentionInterval = time.Hour
+	defaultRetentionChunkSize = 1000
+	defaultReportPartitionsAhead = 3
+	defaultReportRestoreTTL = 7 * 24 * time.Hour
+	defaultShutdownTimeout = 30 * time.Second
 )
 
 var version string
@@ -51,13 +104,39 @@ func run() error {
 	lgr := logger.Setup(svcenv.LogLevel, svcenv.Name)
 	
 	//setup : database 연결
-	dbConnMgr, dbErr := setupDB(lgr, svcenv)
+	dbConnMgr, dbErr := setupDB(ctx, lgr, svcenv)
 	if dbErr != nil {
 		return dbErr
 	}
 
+	// 서브 커맨드 : migrate [up|down [n]|status|force <version> [applied|reverted]]
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		defer cleanup(lgr, dbConnMgr)
+		return runMigrate(ctx, lgr, dbConnMgr, os.Args[2:])
+	}
+
+	// 서브 커맨드 : partitions [plan|apply]
+	if len(os.Args) > 1 && os.Args[1] == "partitions" {
+		defer cleanup(lgr, dbConnMgr)
+		return runPartitions(ctx, lgr, svcenv, dbConnMgr, os.Args[2:])
+	}
+
+	// 서브 커맨드 : archive [list|restore] <from> <to> [productNumber]
+	if len(os.Args) > 1 && os.Args[1] == "archive" {
+		defer cleanup(lgr, dbConnMgr)
+		return runArchive(ctx, lgr, svcenv, dbConnMgr, os.Args[2:])
+	}
+
+	// 기동 시 스키마 마이그레이션 적용
+	if svcenv.MigrateOnStart {
+		if err := runMigrate(ctx, lgr, dbConnMgr, []string{"up"}); err != nil {
+			cleanup(lgr, dbConnMgr)
+			return err
+		}
+	}
+
 	go func(){
-		errChan <- server.Start(svcenv, lgr, dbConnMgr)
+		errChan <- server.Start(ctx, svcenv, lgr, dbConnMgr)
 	}()
 
 	lgr.Info().
@@ -71,7 +150,7 @@ func run() error {
 	select {
 	case <-ctx.Done():
 		lgr.Info().Msg("graceful shutdown signal received")
-		err := <-errChan // wait for go routines to exit
+		err := <-errChan // wait for in-flight requests and queued reports to be flushed
 		cleanup(lgr, dbConnMgr)
 		return err
 	case err := <-errChan:
@@ -99,6 +178,23 @@ func getEnvConfig() (*model.ServiceEnv, error) {
 		port = defaultPort
 	}
 
+	// 데이터베이스 종류
+	// 기본값 mariadb (postgres, sqlite: 외부 DB 없이 개발/CI 환경에서 실행)
+	dbDriver := os.Getenv("dbDriver")
+	if dbDriver == "" {
+		dbDriver = defaultDBDriver
+	}
+	if dbDriver != "mariadb" && dbDriver != "postgres" && dbDriver != "sqlite" {
+		return nil, fmt.Errorf("unsupported dbDriver: %s", dbDriver)
+	}
+
+	// SQLite 파일 경로 (":memory:" 지정 시 메모리 DB)
+	// 기본값 ./data/iot_device.db
+	sqlitePath := os.Getenv("sqlitePath")
+	if sqlitePath == "" {
+		sqlitePath = defaultSQLitePath
+	}
+
 	// 데이터베이스 호스트 정보
 	// 기본값 localhost
 	host := os.Getenv("host")
@@ -114,47 +210,518 @@ func getEnvConfig() (*model.ServiceEnv, error) {
 	}
 
 	// 데이터베이스 패스워드
-	// 필수 (보안상 기본값 없음)
+	// mariadb, postgres 사용 시 필수 (보안상 기본값 없음)
 	password := os.Getenv("password")
-	if password == "" {
+	if password == "" && dbDriver != "sqlite" {
 		return nil, errors.New("database password is required")
 	}
 
+	// 운영자 API(/internal, /firmware, /alerts, /webhooks) 인증 토큰
+	// 기본값 없음 (설정하지 않으면 운영자 API 요청을 모두 거부)
+	adminToken := os.Getenv("adminToken")
+
 	// 데이터베이스 포트 번호
-	// 기본값 3306 (MySQL/MariaDB 기본 포트)
+	// 기본값 3306 (MySQL/MariaDB 기본 포트), postgres 사용 시 5432
 	dbPort := os.Getenv("dbport")
 	if dbPort == "" {
 		dbPort = "3306"
+		if dbDriver == "postgres" {
+			dbPort = "5432"
+		}
+	}
+
+	// 조회 전용 replica 주소 목록 (host:port 를 쉼표로 구분)
+	// 기본값 없음 (모든 조회를 primary에서 수행)
+	var dbReplicas []string
+	for _, addr := range strings.Split(os.Getenv("dbReplicas"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			dbReplicas = append(dbReplicas, addr)
+		}
 	}
 
+	// 커넥션 풀 설정
+	// 기본값 최대 10개, 유휴 10개, 최대 사용 시간 3분, 유휴 시간 제한 없음
+	dbMaxOpenConns, err := getEnvInt("dbMaxOpenConns", defaultDBMaxOpenConns)
+	if err != nil {
+		return nil, err
+	}
+
+	dbMaxIdleConns, err := getEnvInt("dbMaxIdleConns", defaultDBMaxIdleConns)
+	if err != nil {
+		return nil, err
+	}
+
+	dbConnMaxLifetime, err := getEnvDuration("dbConnMaxLifetime", defaultDBConnMaxLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	dbConnMaxIdleTime, err := getEnvDuration("dbConnMaxIdleTime", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// 접속/읽기/쓰기 제한 시간 (예: 5s)
+	// 기본값 접속 10초, 읽기/쓰기 제한 없음
+	dbDialTimeout, err := getEnvDuration("dbDialTimeout", defaultDBDialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	dbReadTimeout, err := getEnvDuration("dbReadTimeout", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	dbWriteTimeout, err := getEnvDuration("dbWriteTimeout", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// 기동 시 DB 접속 재시도
+	// 기본값 10회, 1초부터 두 배씩 최대 30초 대기
+	dbConnectAttempts, err := getEnvInt("dbConnectAttempts", defaultDBConnectAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	dbConnectBackoff, err := getEnvDuration("dbConnectBackoff", defaultDBConnectBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	dbConnectMaxBackoff, err := getEnvDuration("dbConnectMaxBackoff", defaultDBConnectMaxBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	// DB 서킷 브레이커
+	// 기본값 연속 5회 장애 시 10초 동안 차단
+	dbBreakerFailures, err := getEnvInt("dbBreakerFailures", defaultDBBreakerFailures)
+	if err != nil {
+		return nil, err
+	}
+
+	dbBreakerOpenTimeout, err := getEnvDuration("dbBreakerOpenTimeout", defaultDBBreakerOpenTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// PostgreSQL SSL 모드
+	// 기본값 disable
+	dbSSLMode := os.Getenv("dbSSLMode")
+
 	// DB 명칭 확인
-	// 필수
+	// mariadb, postgres 사용 시 필수
 	dbname := os.Getenv("dbname")
-	if dbname == "" {
+	if dbname == "" && dbDriver != "sqlite" {
 		return nil, errors.New("database name is required")
 	}
 
+	// MariaDB TLS, 문자셋, 시간대
+	// 기본값 TLS 사용 안 함 (dbTLSCA 지정 시 인증서 검증), 문자셋 utf8mb4, 시간대 UTC
+	dbTLS := os.Getenv("dbTLS")
+	dbTLSCA := os.Getenv("dbTLSCA")
+
+	dbCharset := os.Getenv("dbCharset")
+	if dbCharset == "" {
+		dbCharset = defaultDBCharset
+	}
+	dbCollation := os.Getenv("dbCollation")
+
+	dbLoc := os.Getenv("dbLoc")
+	if dbLoc == "" {
+		dbLoc = "UTC"
+	}
+
 	// 로그 레벨 지정
 	logLevel := os.Getenv("logLevel")
 	if logLevel == "" {
 		logLevel = defaultLogLevel
 	}
 
+	// 펌웨어 이미지 저장 경로
+	// 기본값 ./firmware
+	firmwareDir := os.Getenv("firmwareDir")
+	if firmwareDir == "" {
+		firmwareDir = defaultFirmwareDir
+	}
+
+	// 기동 시 스키마 마이그레이션 적용 여부
+	// 기본값 false
+	migrateOnStart := false
+	if v := os.Getenv("migrateOnStart"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migrateOnStart: %v", err)
+		}
+		migrateOnStart = b
+	}
+
+	// 펌웨어 다운로드 제한
+	// 기본값 전체 100, 그룹별 20, 대역폭 제한 없음
+	downloadMaxConcurrent, err := getEnvInt("downloadMaxConcurrent", defaultDownloadMaxConcurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	downloadMaxPerGroup, err := getEnvInt("downloadMaxPerGroup", defaultDownloadMaxPerGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	downloadBytesPerSec, err := getEnvInt("downloadBytesPerSec", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	downloadGroupPrefix, err := getEnvInt("downloadGroupPrefix", defaultDownloadGroupPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// DB 장애 중 보고 보관(WAL)
+	// 기본값 ./data/wal, 세그먼트 16MiB, 재전송 주기 5초
+	reportWALDir := os.Getenv("reportWALDir")
+	if reportWALDir == "" {
+		reportWALDir = defaultReportWALDir
+	}
+
+	reportWALSegmentBytes, err := getEnvInt("reportWALSegmentBytes", defaultReportWALSegmentBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	reportReplayInterval, err := getEnvDuration("reportReplayInterval", defaultReportReplayInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	// 보고 배치 기록
+	// 기본값 배치 200건, 1초 주기, 대기열 10000건
+	reportBatchSize, err := getEnvInt("reportBatchSize", defaultReportBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	reportFlushInterval, err := getEnvDuration("reportFlushInterval", defaultReportFlushInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	reportQueueSize, err := getEnvInt("reportQueueSize", defaultReportQueueSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// 디바이스 조회 캐시
+	// 기본값 10000대, 30초 보관, 존재하지 않는 디바이스 5초 보관
+	deviceCacheSize, err := getEnvInt("deviceCacheSize", defaultDeviceCacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceCacheTTL, err := getEnvDuration("deviceCacheTTL", defaultDeviceCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceCacheNegativeTTL, err := getEnvDuration("deviceCacheNegativeTTL", defaultDeviceCacheNegativeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// 관제 화면용 전체 디바이스 현황
+	// 기본값 보고 주기 3회 동안 보고가 없으면 오프라인, 배터리 20% 이하 부족, 집계 결과 10초 보관
+	fleetOfflineCycles, err := getEnvInt("fleetOfflineCycles", defaultFleetOfflineCycles)
+	if err != nil {
+		return nil, err
+	}
+
+	fleetLowBattery, err := getEnvInt("fleetLowBattery", defaultFleetLowBattery)
+	if err != nil {
+		return nil, err
+	}
+
+	fleetOverviewCacheTTL, err := getEnvDuration("fleetOverviewCacheTTL", defaultFleetOverviewCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// 알림 규칙 평가
+	// 기본값 1분마다 오프라인 점검 및 규칙 재조회, 평가 대기열 10000 묶음
+	alertSweepInterval, err := getEnvDuration("alertSweepInterval", defaultAlertSweepInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	alertQueueSize, err := getEnvInt("alertQueueSize", defaultAlertQueueSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// 웹훅 전송
+	// 기본값 최대 8회 시도, 10초부터 두 배씩(최대 1시간) 재시도, 요청 제한 시간 10초, 5초마다 전송 조회
+	webhookMaxAttempts, err := getEnvInt("webhookMaxAttempts", defaultWebhookMaxAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookBackoff, err := getEnvDuration("webhookBackoff", defaultWebhookBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookMaxBackoff, err := getEnvDuration("webhookMaxBackoff", defaultWebhookMaxBackoff)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookTimeout, err := getEnvDuration("webhookTimeout", defaultWebhookTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookPollInterval, err := getEnvDuration("webhookPollInterval", defaultWebhookPollInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	// 내부 주소(루프백, 사설, 링크 로컬 등)로의 웹훅 전송 허용 여부
+	// 기본값 false (개발 및 시험 환경에서만 사용)
+	webhookAllowPrivateTargets := false
+	if v := os.Getenv("webhookAllowPrivateTargets"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhookAllowPrivateTargets: %v", err)
+		}
+		webhookAllowPrivateTargets = b
+	}
+
+	// outbox 이벤트 전달
+	// 기본값 1초마다 500건씩 전달, 전달 임대 30초, 전달 대상 webhook (webhook, log를 쉼표로 구분)
+	outboxPollInterval, err := getEnvDuration("outboxPollInterval", defaultOutboxPollInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxBatchSize, err := getEnvInt("outboxBatchSize", defaultOutboxBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxLeaseTTL, err := getEnvDuration("outboxLeaseTTL", defaultOutboxLeaseTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxSinksEnv := os.Getenv("outboxSinks")
+	if outboxSinksEnv == "" {
+		outboxSinksEnv = defaultOutboxSinks
+	}
+	var outboxSinks []string
+	for _, name := range strings.Split(outboxSinksEnv, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			outboxSinks = append(outboxSinks, name)
+		}
+	}
+
+	// 메시지 브로커(Kafka) 전달 대상 (outboxSinks에 broker 지정 시 사용)
+	// 브로커 주소(host:port)를 쉼표로 구분, 기본값 topic device-events
+	var outboxBrokers []string
+	for _, addr := range strings.Split(os.Getenv("outboxBrokers"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			outboxBrokers = append(outboxBrokers, addr)
+		}
+	}
+
+	outboxBrokerTopic := os.Getenv("outboxBrokerTopic")
+	if outboxBrokerTopic == "" {
+		outboxBrokerTopic = defaultOutboxBrokerTopic
+	}
+
+	// 보고 및 디바이스 상태 실시간 스트림 (SSE)
+	// 기본값 구독별 대기 이벤트 256건(넘으면 끊음), 최대 1000개 구독, 15초마다 연결 유지 확인
+	streamBufferSize, err := getEnvInt("streamBufferSize", defaultStreamBufferSize)
+	if err != nil {
+		return nil, err
+	}
+
+	streamMaxSubscribers, err := getEnvInt("streamMaxSubscribers", defaultStreamMaxSubscribers)
+	if err != nil {
+		return nil, err
+	}
+
+	streamHeartbeat, err := getEnvDuration("streamHeartbeat", defaultStreamHeartbeat)
+	if err != nil {
+		return nil, err
+	}
+
+	// 보고 보존 기간 및 요약/정리 작업
+	// 기본값 원본 보고 계속 보관(0), 시간 단위 요약 90일 보관, 1시간 주기, 1000건씩 처리
+	reportRetention, err := getEnvDuration("reportRetention", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	rollupHourlyRetention, err := getEnvDuration("rollupHourlyRetention", defaultRollupHourlyRetention)
+	if err != nil {
+		return nil, err
+	}
+
+	retentionInterval, err := getEnvDuration("retentionInterval", defaultRetentionInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	retentionChunkSize, err := getEnvInt("retentionChunkSize", defaultRetentionChunkSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// 보관 기간이 지난 보고를 삭제 전에 저장할 보관 파일 디렉터리, 복원한 보고 보관 시간
+	// 기본값 보관하지 않음(빈 값), 복원 보고 7일 보관
+	reportArchiveDir := os.Getenv("reportArchiveDir")
+
+	reportRestoreTTL, err := getEnvDuration("reportRestoreTTL", defaultReportRestoreTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// MariaDB 보고 월 파티션
+	// 기본값 3개월 앞까지 생성, DDL 실행 (dry-run 시 실행하지 않고 로그로만 출력)
+	reportPartitionsAhead, err := getEnvInt("reportPartitionsAhead", defaultReportPartitionsAhead)
+	if err != nil {
+		return nil, err
+	}
+
+	reportPartitionDryRun := false
+	if v := os.Getenv("reportPartitionDryRun"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reportPartitionDryRun: %v", err)
+		}
+		reportPartitionDryRun = b
+	}
+
+	// 종료 대기 시간
+	// 기본값 30초
+	shutdownTimeout, err := getEnvDuration("shutdownTimeout", defaultShutdownTimeout)
+	if err != nil {
+		return nil, err
+	}
+
 	// ServiceEnv 구조체 생성 및 반환
 	envConfigurations := &model.ServiceEnv{
-		Name:     envName,
-		Host:     host,
-		User:     user,
-		Password: password,
-		Port:     port,
-		DBPort:   dbPort,
-		DBname:   dbname,
-		LogLevel: logLevel,
+		Name:                       envName,
+		Host:                       host,
+		User:                       user,
+		Password:                   password,
+		AdminToken:                 adminToken,
+		Port:                       port,
+		DBDriver:                   dbDriver,
+		SQLitePath:                 sqlitePath,
+		DBPort:                     dbPort,
+		DBSSLMode:                  dbSSLMode,
+		DBReplicas:                 dbReplicas,
+		DBMaxOpenConns:             dbMaxOpenConns,
+		DBMaxIdleConns:             dbMaxIdleConns,
+		DBConnMaxLifetime:          dbConnMaxLifetime,
+		DBConnMaxIdleTime:          dbConnMaxIdleTime,
+		DBDialTimeout:              dbDialTimeout,
+		DBReadTimeout:              dbReadTimeout,
+		DBWriteTimeout:             dbWriteTimeout,
+		DBTLS:                      dbTLS,
+		DBTLSCAFile:                dbTLSCA,
+		DBCharset:                  dbCharset,
+		DBCollation:                dbCollation,
+		DBLoc:                      dbLoc,
+		DBConnectAttempts:          dbConnectAttempts,
+		DBConnectBackoff:           dbConnectBackoff,
+		DBConnectMaxBackoff:        dbConnectMaxBackoff,
+		DBBreakerFailures:          dbBreakerFailures,
+		DBBreakerOpenTimeout:       dbBreakerOpenTimeout,
+		DBname:                     dbname,
+		LogLevel:                   logLevel,
+		MigrateOnStart:             migrateOnStart,
+		FirmwareDir:                firmwareDir,
+		DownloadMaxConcurrent:      downloadMaxConcurrent,
+		DownloadMaxPerGroup:        downloadMaxPerGroup,
+		DownloadBytesPerSec:        int64(downloadBytesPerSec),
+		DownloadGroupPrefix:        downloadGroupPrefix,
+		ReportWALDir:               reportWALDir,
+		ReportWALSegmentBytes:      int64(reportWALSegmentBytes),
+		ReportReplayInterval:       reportReplayInterval,
+		ReportBatchSize:            reportBatchSize,
+		ReportFlushInterval:        reportFlushInterval,
+		ReportQueueSize:            reportQueueSize,
+		DeviceCacheSize:            deviceCacheSize,
+		DeviceCacheTTL:             deviceCacheTTL,
+		DeviceCacheNegativeTTL:     deviceCacheNegativeTTL,
+		FleetOfflineCycles:         fleetOfflineCycles,
+		FleetLowBattery:            fleetLowBattery,
+		FleetOverviewCacheTTL:      fleetOverviewCacheTTL,
+		AlertSweepInterval:         alertSweepInterval,
+		AlertQueueSize:             alertQueueSize,
+		WebhookMaxAttempts:         webhookMaxAttempts,
+		WebhookBackoff:             webhookBackoff,
+		WebhookMaxBackoff:          webhookMaxBackoff,
+		WebhookTimeout:             webhookTimeout,
+		WebhookPollInterval:        webhookPollInterval,
+		WebhookAllowPrivateTargets: webhookAllowPrivateTargets,
+		OutboxPollInterval:         outboxPollInterval,
+		OutboxBatchSize:            outboxBatchSize,
+		OutboxLeaseTTL:             outboxLeaseTTL,
+		OutboxSinks:                outboxSinks,
+		OutboxBrokers:              outboxBrokers,
+		OutboxBrokerTopic:          outboxBrokerTopic,
+		StreamBufferSize:           streamBufferSize,
+		StreamMaxSubscribers:       streamMaxSubscribers,
+		StreamHeartbeat:            streamHeartbeat,
+		ReportRetention:            reportRetention,
+		RollupHourlyRetention:      rollupHourlyRetention,
+		RetentionInterval:          retentionInterval,
+		RetentionChunkSize:         retentionChunkSize,
+		ReportArchiveDir:           reportArchiveDir,
+		ReportRestoreTTL:           reportRestoreTTL,
+		ReportPartitionsAhead:      reportPartitionsAhead,
+		ReportPartitionDryRun:      reportPartitionDryRun,
+		ShutdownTimeout:            shutdownTimeout,
 	}
 
 	return envConfigurations, nil
 }
 
+// 시간 간격 환경 변수를 읽는다. (예: 30s, 3m) 값이 없으면 기본값을 사용한다.
+func getEnvDuration(key string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", key, err)
+	}
+
+	return d, nil
+}
+
+// 정수형 환경 변수를 읽는다. 값이 없으면 기본값을 사용한다.
+func getEnvInt(key string, def int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", key, err)
+	}
+
+	return i, nil
+}
+
 
 
 func exitCode(err error) int {
@@ -165,20 +732,60 @@ func exitCode(err error) int {
 }
 
 
-func setupDB(lgr *logger.AppLogger, svcEnv *model.ServiceEnv) (db.DBManager, error) {
+func setupDB(ctx context.Context, lgr *logger.AppLogger, svcEnv *model.ServiceEnv) (db.DBManager, error) {
+	if svcEnv.DBDriver == "sqlite" {
+		// 파일 DB인 경우 상위 디렉터리를 먼저 만든다
+		if svcEnv.SQLitePath != ":memory:" {
+			if err := os.MkdirAll(filepath.Dir(svcEnv.SQLitePath), 0o755); err != nil {
+				return nil, fmt.Errorf("failed to create sqlite directory: %v", err)
+			}
+		}
+		return db.NewSQLiteManager(svcEnv.SQLitePath, lgr)
+	}
+
 	portInt, err := strconv.Atoi(svcEnv.DBPort)
 	if err != nil {
 		return nil, fmt.Errorf("invalid port: %v", err)
 	}
+
+	if svcEnv.DBDriver == "postgres" {
+		return db.NewPostgresManager(&db.PostgresCredentials{
+			User:     svcEnv.User,
+			Password: svcEnv.Password,
+			Host:     svcEnv.Host,
+			Port:     portInt,
+			Database: svcEnv.DBname,
+			SSLMode:  svcEnv.DBSSLMode,
+			Replicas: svcEnv.DBReplicas,
+		}, lgr)
+	}
 	connOpts := &db.MariaDBCredentials{
 		User:     svcEnv.User,
 		Password: svcEnv.Password,
 		Host:     svcEnv.Host,
 		Port:     portInt,
 		Database: svcEnv.DBname,
+		Replicas: svcEnv.DBReplicas,
+
+		MaxOpenConns:    svcEnv.DBMaxOpenConns,
+		MaxIdleConns:    svcEnv.DBMaxIdleConns,
+		ConnMaxLifetime: svcEnv.DBConnMaxLifetime,
+		ConnMaxIdleTime: svcEnv.DBConnMaxIdleTime,
+		DialTimeout:     svcEnv.DBDialTimeout,
+		ReadTimeout:     svcEnv.DBReadTimeout,
+		WriteTimeout:    svcEnv.DBWriteTimeout,
+		TLS:             svcEnv.DBTLS,
+		TLSCAFile:       svcEnv.DBTLSCAFile,
+		Charset:         svcEnv.DBCharset,
+		Collation:       svcEnv.DBCollation,
+		Loc:             svcEnv.DBLoc,
+
+		ConnectAttempts:   svcEnv.DBConnectAttempts,
+		ConnectBackoff:    svcEnv.DBConnectBackoff,
+		ConnectMaxBackoff: svcEnv.DBConnectMaxBackoff,
 	}
 
-	dbConnMgr, dberr := db.NewMariaDBManager(connOpts, lgr)
+	dbConnMgr, dberr := db.NewMariaDBManager(ctx, connOpts, lgr)
 	if dberr != nil {
 		return nil, dberr
 	}
@@ -187,6 +794,180 @@ func setupDB(lgr *logger.AppLogger, svcEnv *model.ServiceEnv) (db.DBManager, err
 
 }
 	
+// 스키마 마이그레이션 서브 커맨드 실행
+// up : 미적용 마이그레이션 전체 적용, down [n] : 최근 n개(기본 1) 되돌림, status : 적용 현황 출력
+// force <version> [applied|reverted] : 중간에 실패한(dirty) 마이그레이션을 수동 정리한 뒤 상태 확정 (기본 applied)
+func runMigrate(ctx context.Context, lgr *logger.AppLogger, dbConnMgr db.DBManager, args []string) error {
+	migrator, err := db.NewMigrator(lgr, dbConnMgr)
+	if err != nil {
+		return err
+	}
+
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "up":
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+		lgr.Info().Msg("schema migrations are up to date")
+		return nil
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid migrate down steps: %s", args[1])
+			}
+		}
+		return migrator.Down(ctx, steps)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, st := range statuses {
+			appliedAt := "pending"
+			if st.Applied {
+				appliedAt = st.AppliedAt.UTC().Format(time.RFC3339)
+			}
+			if st.Dirty {
+				appliedAt += " (dirty)"
+			}
+			fmt.Printf("%04d %-30s %s\n", st.Version, st.Name, appliedAt)
+		}
+		return nil
+	case "force":
+		if len(args) < 2 {
+			return fmt.Errorf("migrate force requires a version")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid migrate force version: %s", args[1])
+		}
+		applied := true
+		if len(args) > 2 {
+			switch args[2] {
+			case "applied":
+			case "reverted":
+				applied = false
+			default:
+				return fmt.Errorf("invalid migrate force state: %s", args[2])
+			}
+		}
+		return migrator.Force(ctx, version, applied)
+	default:
+		return fmt.Errorf("unknown migrate command: %s", cmd)
+	}
+}
+
+// 보고 파티션 관리 서브 커맨드 실행 (MariaDB 전용)
+// plan : 실행할 DDL 출력 (dry-run), apply : DDL 실행 후 실행한 DDL 출력
+func runPartitions(ctx context.Context, lgr *logger.AppLogger, svcenv *model.ServiceEnv, dbConnMgr db.DBManager, args []string) error {
+	cmd := "plan"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+	if cmd != "plan" && cmd != "apply" {
+		return fmt.Errorf("unknown partitions command: %s", cmd)
+	}
+
+	partitionMgr, err := db.NewPartitionManager(lgr, dbConnMgr.DB(), dbConnMgr.Dialect(), db.PartitionConfig{
+		Ahead:     svcenv.ReportPartitionsAhead,
+		Retention: svcenv.ReportRetention,
+		DryRun:    svcenv.ReportPartitionDryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	var ddl []string
+	if cmd == "plan" {
+		ddl, err = partitionMgr.Plan(ctx, time.Now())
+	} else {
+		ddl, err = partitionMgr.Apply(ctx, time.Now())
+	}
+	for _, stmt := range ddl {
+		fmt.Println(stmt + ";")
+	}
+	return err
+}
+
+// 보관 파일 서브 커맨드 실행
+// list : 보관 파일 목록 출력, restore : 보고 시간 [from, to) 범위의 보관된 보고를 reports 테이블에 복원
+// from, to는 RFC3339 또는 YYYY-MM-DD(UTC) 형식
+func runArchive(ctx context.Context, lgr *logger.AppLogger, svcenv *model.ServiceEnv, dbConnMgr db.DBManager, args []string) error {
+	if len(args) < 3 {
+		return errors.New("usage: archive [list|restore] <from> <to> [productNumber]")
+	}
+	if svcenv.ReportArchiveDir == "" {
+		return errors.New("reportArchiveDir is not configured")
+	}
+
+	rangeReq := external.ArchiveRangeReq{}
+	var err error
+	if rangeReq.From, err = parseArchiveTime(args[1]); err != nil {
+		return err
+	}
+	if rangeReq.To, err = parseArchiveTime(args[2]); err != nil {
+		return err
+	}
+	if len(args) > 3 {
+		rangeReq.ProductNumber = args[3]
+	}
+	if err := rangeReq.Validate(); err != nil {
+		return err
+	}
+
+	store, err := archive.NewDirStore(svcenv.ReportArchiveDir)
+	if err != nil {
+		return err
+	}
+	archiveRepo, err := db.NewArchivesRepo(lgr, dbConnMgr.DB(), dbConnMgr.Dialect())
+	if err != nil {
+		return err
+	}
+	reportRepo, err := db.NewReportsRepo(lgr, dbConnMgr.DB(), dbConnMgr.DB(), dbConnMgr.Dialect())
+	if err != nil {
+		return err
+	}
+	restorer, err := archive.NewRestorer(lgr, store, dbConnMgr, archiveRepo, reportRepo)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		archives, err := restorer.List(ctx, rangeReq.From, rangeReq.To, rangeReq.ProductNumber)
+		if err != nil {
+			return err
+		}
+		for _, a := range archives {
+			fmt.Printf("%s %-9s %6d %s\n", a.DayStart.UTC().Format("2006-01-02"), a.ProductNumber, a.Records, a.ObjectKey)
+		}
+		return nil
+	case "restore":
+		result, err := restorer.Restore(ctx, rangeReq.From, rangeReq.To, rangeReq.ProductNumber)
+		fmt.Printf("archives: %d, records: %d, restored: %d\n", result.Archives, result.Records, result.Restored)
+		return err
+	default:
+		return fmt.Errorf("unknown archive command: %s", args[0])
+	}
+}
+
+func parseArchiveTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid archive time %q: use RFC3339 or YYYY-MM-DD", v)
+	}
+	return t, nil
+}
+
 func cleanup(lgr *logger.AppLogger, dbConnMgr db.DBManager) {
 	if err := dbConnMgr.Disconnect(); err != nil {
 		lgr.Error().Err(err).Msg("failed to close DB connection, potential connection leak")